@@ -207,9 +207,21 @@ fn invalid_value_usage(flag: &str, err: &clap::Error) -> Option<String> {
     if flag == "--retry" || flag == "--redirects" {
         return Some("must be a non-negative integer".to_string());
     }
-    if flag == "--connect-timeout" || flag == "--retry-delay" || flag == "--timeout" {
+    if flag == "--connect-timeout"
+        || flag == "--keepalive"
+        || flag == "--retry-delay"
+        || flag == "--retry-max-delay"
+        || flag == "--retry-max-time"
+        || flag == "--timeout"
+    {
         return Some("must be a non-negative number".to_string());
     }
+    if flag == "--chunk-size" {
+        return Some("must be a positive integer".to_string());
+    }
+    if flag == "--retry-jitter" {
+        return Some("must be a number between 0 and 1".to_string());
+    }
 
     let values = context_strings(err, ContextKind::ValidValue);
     if values.is_empty() {
@@ -297,6 +309,8 @@ async fn run_inner(cli: &mut Cli) -> Result<i32, FetchError> {
     }
 
     normalize_extra_args(cli)?;
+    apply_data_values(cli)?;
+    apply_request_items(cli)?;
 
     if crate::skill::is_action(cli) {
         return crate::skill::execute(cli);
@@ -328,6 +342,8 @@ async fn run_inner(cli: &mut Cli) -> Result<i32, FetchError> {
     let direct_cli_sources = DirectCliSources::capture(cli);
 
     apply_from_curl(cli)?;
+    apply_raw(cli);
+    apply_body_only(cli);
     let direct_inspection_ignored_flags = if cli.inspect_dns {
         crate::dns::inspect::ignored_inspection_flags(cli)
     } else if cli.inspect_tls {
@@ -335,14 +351,20 @@ async fn run_inner(cli: &mut Cli) -> Result<i32, FetchError> {
     } else {
         Vec::new()
     };
-    let config_path = crate::config::apply(cli)?;
+    let applied_config = crate::config::apply(cli)?;
     crate::config::validate(cli)?;
+    if let Some(theme) = cli.theme.as_deref().and_then(core::Theme::from_name) {
+        core::set_theme(theme);
+    }
     crate::cli::selected_http_version(cli).map_err(FetchError::Message)?;
     crate::cli::normalize_range_values(&mut cli.ranges).map_err(FetchError::Message)?;
     validate_proto_schema_files(cli)?;
     validate_client_certificate_flags(cli, direct_cli_sources)?;
+    validate_insecure_allowed(cli)?;
     validate_auth_credentials(cli)?;
-    print_config_debug(cli, config_path.as_deref());
+    apply_netrc_credentials(cli)?;
+    apply_proxy_user(cli)?;
+    print_config_debug(cli, applied_config.as_ref());
 
     if cli.update {
         return crate::update::execute(cli).await;
@@ -351,6 +373,99 @@ async fn run_inner(cli: &mut Cli) -> Result<i32, FetchError> {
     if cli.remote_header_name && !cli.remote_name {
         return Err("flag '--remote-header-name' requires '--remote-name'".into());
     }
+    if cli.output_dir.is_some() && !cli.remote_name {
+        return Err("flag '--output-dir' requires '--remote-name'".into());
+    }
+    if cli.write_meta && cli.output.as_deref().is_none_or(|path| path == "-") && !cli.remote_name {
+        return Err("flag '--write-meta' requires '--output' or '--remote-name'".into());
+    }
+    if cli.allow_unfilled && cli.path_params.is_empty() {
+        return Err("flag '--allow-unfilled' requires '--path-param'".into());
+    }
+    if let Some(size) = cli.chunk_size {
+        if size == 0 {
+            return Err(FetchError::invalid_value(
+                "--chunk-size",
+                "0",
+                "must be a positive integer",
+            ));
+        }
+        if cli.output.as_deref().is_none_or(|path| path == "-") && !cli.remote_name {
+            return Err("flag '--chunk-size' requires '--output' or '--remote-name'".into());
+        }
+    }
+    if cli.continue_at.is_some()
+        && cli.output.as_deref().is_none_or(|path| path == "-")
+        && !cli.remote_name
+    {
+        return Err("flag '--continue-at' requires '--output' or '--remote-name'".into());
+    }
+    if let Some(value) = cli.local_port.as_deref() {
+        crate::net::LocalPortRange::parse(value)?;
+        if matches!(
+            crate::cli::selected_http_version(cli),
+            Ok(Some(crate::cli::HttpVersion::Http3))
+        ) {
+            return Err("flag '--local-port' cannot be used with HTTP/3".into());
+        }
+    }
+    if let Some(value) = cli.interface.as_deref() {
+        crate::net::InterfaceAddrs::resolve(value)?;
+    }
+    crate::net::TcpKeepalive::from_flags(cli.keepalive, cli.no_keepalive)?;
+    for value in &cli.resolve {
+        crate::net::ResolveOverride::parse(value)?;
+    }
+    for value in &cli.connect_to {
+        crate::net::ConnectTo::parse(value)?;
+    }
+    if let Some(value) = cli.ciphers.as_deref() {
+        crate::tls::cipher_suites(value)?;
+    }
+    crate::tls::ca_certificate_paths(&[], &cli.ca_path)?;
+    if let Some(value) = cli.accept_fallback.as_deref() {
+        crate::http::accept_fallbacks(value)?;
+    }
+    if let Some(value) = cli.checksum.as_deref() {
+        crate::http::ExpectedChecksum::parse(value, cli.checksum_verify.as_deref())?;
+    }
+    if let Some(key) = cli.idempotency_key.as_deref()
+        && !key.is_empty()
+        && http::HeaderValue::from_str(key).is_err()
+    {
+        return Err(FetchError::invalid_value(
+            "--idempotency-key",
+            key,
+            "must be a valid header value",
+        ));
+    }
+    if let Some(size) = cli.max_headers
+        && size < 8_192
+    {
+        return Err(FetchError::invalid_value(
+            "--max-headers",
+            size.to_string(),
+            "must be at least 8192",
+        ));
+    }
+    if let Some(size) = cli.h2_max_frame_size
+        && !(16_384..=16_777_215).contains(&size)
+    {
+        return Err(FetchError::invalid_value(
+            "--h2-max-frame-size",
+            size.to_string(),
+            "must be between 16384 and 16777215",
+        ));
+    }
+    if let Some(size) = cli.h2_initial_window_size
+        && size > 2_147_483_647
+    {
+        return Err(FetchError::invalid_value(
+            "--h2-initial-window-size",
+            size.to_string(),
+            "must be at most 2147483647",
+        ));
+    }
 
     if let Some(path) = cli.har.as_deref() {
         if path == "-" {
@@ -367,6 +482,9 @@ async fn run_inner(cli: &mut Cli) -> Result<i32, FetchError> {
         if cli.dry_run {
             return Err("flag '--har' cannot be used with '--dry-run'".into());
         }
+        if cli.trace_headers_only {
+            return Err("flag '--har' cannot be used with '--trace-headers-only'".into());
+        }
         if cli.inspect_dns || cli.inspect_tls {
             return Err("flag '--har' cannot be used with inspection modes".into());
         }
@@ -375,15 +493,30 @@ async fn run_inner(cli: &mut Cli) -> Result<i32, FetchError> {
         }
     }
 
+    if cli.check_hosts.is_some() {
+        return crate::http::check_hosts(cli).await;
+    }
+
     if cli.url.is_none() && cli.has_grpc_discovery() && !cli.has_proto_schema() {
         return Err("<URL> must be provided unless --proto-file or --proto-desc is set".into());
     }
     if cli.url.is_none() && !cli.has_grpc_discovery() {
         return Err("<URL> must be provided".into());
     }
+    if !cli.path_params.is_empty()
+        && let Some(url) = cli.url.as_deref()
+    {
+        let url = crate::cli::expand_path_params(url, &cli.path_params, cli.allow_unfilled)
+            .map_err(FetchError::Message)?;
+        cli.url = Some(url);
+    }
+    apply_expand_env(cli)?;
 
     if let Some(value) = cli.auto_update.as_deref() {
-        crate::update::maybe_spawn_auto_update(value, config_path.as_deref());
+        let config_path = applied_config
+            .as_ref()
+            .map(|applied| applied.path.as_path());
+        crate::update::maybe_spawn_auto_update(value, config_path);
     }
 
     if cli.inspect_dns {
@@ -434,6 +567,95 @@ fn normalize_extra_args(cli: &mut Cli) -> Result<(), FetchError> {
     Ok(())
 }
 
+/// Set the '--data' body. Repeated values are joined with '&' like curl,
+/// reading '@file' and '@-' parts in order. A single value is kept as given,
+/// so files and stdin still stream.
+pub(crate) fn apply_data_values(cli: &mut Cli) -> Result<(), FetchError> {
+    match cli.data_values.as_slice() {
+        [] => {}
+        [value] => cli.data = Some(value.clone()),
+        values => {
+            let mut parts = Vec::with_capacity(values.len());
+            for value in values {
+                let expanded = match cli.expand_env {
+                    Some(_) => expand_body_value(cli, value, false)?,
+                    None => None,
+                };
+                parts.push(from_curl::DataValue {
+                    is_raw: expanded.is_some(),
+                    value: expanded.unwrap_or_else(|| value.clone()),
+                    is_urlencode: false,
+                });
+            }
+            let data = materialize_curl_data(&parts)?;
+            cli.data = Some(String::from_utf8_lossy(&data).into_owned());
+            cli.data_is_literal = true;
+            cli.data_literal_bytes = Some(data);
+        }
+    }
+    Ok(())
+}
+
+/// Turn off content decoding, formatting, and the pager for `--raw`. This runs
+/// before the config file is applied so its defaults cannot turn them back on.
+fn apply_raw(cli: &mut Cli) {
+    if !cli.raw {
+        return;
+    }
+    cli.compress = Some("off".to_string());
+    cli.format = Some("off".to_string());
+    cli.pager = Some("off".to_string());
+}
+
+/// `--body-only` runs as `--silent`, except that gRPC status and schema errors
+/// are still printed.
+fn apply_body_only(cli: &mut Cli) {
+    if cli.body_only {
+        cli.silent = true;
+    }
+}
+
+/// Turn trailing `KEY=VALUE` items into a JSON object body and `KEY==VALUE`
+/// items into query parameters.
+fn apply_request_items(cli: &mut Cli) -> Result<(), FetchError> {
+    let mut body = serde_json::Map::new();
+    for item in std::mem::take(&mut cli.items) {
+        let Some((key, value)) = item.split_once('=') else {
+            return Err(format!("unexpected argument: {item:?}").into());
+        };
+        if key.is_empty() {
+            return Err(format!("invalid request item {item:?}: key must not be empty").into());
+        }
+        match value.strip_prefix('=') {
+            Some(value) => cli.query.push(format!("{key}={value}")),
+            None => {
+                body.insert(
+                    key.to_string(),
+                    serde_json::Value::String(value.to_string()),
+                );
+            }
+        }
+    }
+    if body.is_empty() {
+        return Ok(());
+    }
+
+    let body_flag = [
+        ("data", cli.data.is_some()),
+        ("json", cli.json.is_some()),
+        ("xml", cli.xml.is_some()),
+        ("form", !cli.form.is_empty()),
+        ("multipart", !cli.multipart.is_empty()),
+    ]
+    .into_iter()
+    .find_map(|(flag, set)| set.then_some(flag));
+    if let Some(flag) = body_flag {
+        return Err(format!("request body items cannot be used with '--{flag}'").into());
+    }
+    cli.json = Some(serde_json::Value::Object(body).to_string());
+    Ok(())
+}
+
 fn validate_proto_schema_files(cli: &Cli) -> Result<(), FetchError> {
     if let Some(path) = cli.proto_desc.as_deref() {
         check_file_exists(path)?;
@@ -482,11 +704,11 @@ fn validate_auth_credentials(cli: &mut Cli) -> Result<(), FetchError> {
     Ok(())
 }
 
-fn print_config_debug(cli: &Cli, path: Option<&std::path::Path>) {
+fn print_config_debug(cli: &Cli, applied: Option<&crate::config::AppliedConfig>) {
     if cli.silent || cli.verbose < 3 {
         return;
     }
-    let Some(path) = path else {
+    let Some(applied) = applied else {
         return;
     };
 
@@ -494,14 +716,194 @@ fn print_config_debug(cli: &Cli, path: Option<&std::path::Path>) {
     printer.write_info_prefix();
     printer.write_styled("Config", &[core::Sequence::Bold, core::Sequence::Yellow]);
     printer.push_str(": '");
-    printer.write_styled(&path.display().to_string(), &[core::Sequence::Dim]);
+    printer.write_styled(&applied.path.display().to_string(), &[core::Sequence::Dim]);
     printer.push_str("'\n");
+    let mut sections = vec![("global".to_string(), applied.global.as_slice())];
+    if let Some((host, keys)) = &applied.host {
+        sections.push((format!("host [{host}]"), keys.as_slice()));
+    }
+    sections.push((
+        "overridden by flags".to_string(),
+        applied.overridden.as_slice(),
+    ));
+    for (label, keys) in sections {
+        if keys.is_empty() {
+            continue;
+        }
+        printer.write_info_prefix();
+        printer.push_str("  ");
+        printer.write_styled(&label, &[core::Sequence::Dim]);
+        printer.push_str(": ");
+        printer.push_str(&keys.join(", "));
+        printer.push('\n');
+    }
     printer.write_info_prefix();
     printer.push('\n');
     let mut stderr = std::io::stderr();
     let _ = printer.flush_to(&mut stderr);
 }
 
+/// Use credentials from a netrc file as basic auth when '--netrc' or
+/// '--netrc-file' is set and no other authentication was given. A missing
+/// default file or an unknown host sends the request without credentials.
+fn apply_netrc_credentials(cli: &mut Cli) -> Result<(), FetchError> {
+    if !cli.netrc && cli.netrc_file.is_none() {
+        return Ok(());
+    }
+    let has_authorization_header = cli.headers.iter().any(|header| {
+        header
+            .split_once(':')
+            .is_some_and(|(name, _)| name.trim().eq_ignore_ascii_case("authorization"))
+    });
+    if cli.basic.is_some()
+        || cli.bearer.is_some()
+        || cli.digest.is_some()
+        || cli.aws_sigv4.is_some()
+        || has_authorization_header
+    {
+        return Ok(());
+    }
+    let Some(host) = cli.url.as_deref().and_then(crate::config::url_hostname) else {
+        return Ok(());
+    };
+
+    let path = crate::fileutil::expand_home(cli.netrc_file.as_deref().unwrap_or("~/.netrc"));
+    let contents = match std::fs::read_to_string(&path) {
+        Ok(contents) => contents,
+        Err(err) if err.kind() == std::io::ErrorKind::NotFound && cli.netrc_file.is_none() => {
+            return Ok(());
+        }
+        Err(err) => {
+            return Err(format!("failed to read netrc file '{}': {err}", path.display()).into());
+        }
+    };
+    if let Some(credentials) = crate::auth::netrc::lookup(&contents, &host) {
+        cli.basic = Some(format!("{}:{}", credentials.login, credentials.password));
+    }
+    Ok(())
+}
+
+/// Reject `--insecure`, whether given directly, through `--from-curl`, or by
+/// a config file, when FETCH_NO_INSECURE is set to a true value.
+fn validate_insecure_allowed(cli: &Cli) -> Result<(), FetchError> {
+    if !cli.insecure {
+        return Ok(());
+    }
+    let locked = std::env::var("FETCH_NO_INSECURE")
+        .is_ok_and(|value| !matches!(value.trim(), "" | "0" | "false" | "off"));
+    if locked {
+        return Err(
+            "'--insecure' is disabled by the FETCH_NO_INSECURE environment variable".into(),
+        );
+    }
+    Ok(())
+}
+
+/// Move '--proxy-user' credentials into the userinfo of the '--proxy' URL,
+/// replacing any it already has. HTTP proxies send them as
+/// Proxy-Authorization and SOCKS5 proxies use them for username/password
+/// authentication.
+fn apply_proxy_user(cli: &mut Cli) -> Result<(), FetchError> {
+    let Some(value) = cli.proxy_user.as_deref() else {
+        return Ok(());
+    };
+    let value = validate_user_password_option("proxy-user", value)?;
+    let Some(proxy) = cli.proxy.as_deref() else {
+        return Err("flag '--proxy-user' requires '--proxy'".into());
+    };
+    let proxy = if proxy.contains("://") {
+        proxy.to_string()
+    } else {
+        format!("http://{proxy}")
+    };
+    let mut url = crate::net::parse_proxy_url(&proxy)?;
+    let (username, password) = value.split_once(':').expect("validated USER:PASS");
+    if url.set_username(username).is_err() || url.set_password(Some(password)).is_err() {
+        return Err(format!("invalid proxy '{proxy}': cannot set credentials").into());
+    }
+    cli.proxy = Some(url.to_string());
+    Ok(())
+}
+
+/// Expand environment variables in the '--data', '--json', or '--xml' value
+/// for '--expand-env'.
+fn apply_expand_env(cli: &mut Cli) -> Result<(), FetchError> {
+    if cli.expand_env.is_none() {
+        return Ok(());
+    }
+    let (flag, value) = if let Some(value) = cli.data.as_deref() {
+        ("data", value)
+    } else if let Some(value) = cli.json.as_deref() {
+        ("json", value)
+    } else if let Some(value) = cli.xml.as_deref() {
+        ("xml", value)
+    } else {
+        return Err("flag '--expand-env' requires '--data', '--json', or '--xml'".into());
+    };
+    // Repeated '--data' values are expanded one by one as they are joined.
+    if cli.data_literal_bytes.is_some() {
+        return Ok(());
+    }
+    let is_literal = flag == "data" && cli.data_is_literal;
+    let Some(expanded) = expand_body_value(cli, value, is_literal)? else {
+        return Ok(());
+    };
+
+    // The expanded body is sent as is, so a leading '@' it gained must not
+    // be read as a file reference.
+    if expanded.starts_with('@') && flag != "data" {
+        return Err(format!("the expanded '--{flag}' value must not start with '@'").into());
+    }
+    match flag {
+        "data" => {
+            cli.data_is_literal |= expanded.starts_with('@');
+            cli.data = Some(expanded);
+        }
+        "json" => cli.json = Some(expanded),
+        _ => cli.xml = Some(expanded),
+    }
+    Ok(())
+}
+
+/// Expand one request body value, returning `None` when it is left as is.
+/// Inline values are always expanded; '@file' contents are read and expanded
+/// only for '--expand-env=files', and stdin is never expanded. Each variable
+/// that is not set gets one warning.
+fn expand_body_value(
+    cli: &Cli,
+    value: &str,
+    is_literal: bool,
+) -> Result<Option<String>, FetchError> {
+    let template = match value.strip_prefix('@') {
+        Some("-") if !is_literal => return Ok(None),
+        Some(path) if !is_literal => {
+            if cli.expand_env.as_deref() != Some("files") {
+                return Ok(None);
+            }
+            let path = crate::fileutil::expand_home(path);
+            std::fs::read_to_string(&path).map_err(|err| {
+                FetchError::Message(format!(
+                    "failed to read '{}' for '--expand-env': {err}",
+                    path.display()
+                ))
+            })?
+        }
+        _ => value.to_string(),
+    };
+    let (expanded, missing) =
+        crate::cli::expand_env_vars(&template, |name| std::env::var(name).ok())
+            .map_err(FetchError::Message)?;
+    if !cli.silent {
+        for name in &missing {
+            crate::error::write_warning_with_color(
+                format!("environment variable '{name}' is not set; expanding to an empty string"),
+                cli.color.as_deref(),
+            );
+        }
+    }
+    Ok(Some(expanded))
+}
+
 fn validate_user_password_option(option: &str, value: &str) -> Result<String, FetchError> {
     if !value.contains(':') {
         return Err(format!(
@@ -527,7 +929,7 @@ fn print_help(cli: &Cli, verbose: bool) -> Result<(), FetchError> {
         let stdout_is_terminal = core::stdio().stdout_is_terminal();
         let mut printer =
             core::Printer::with_color_setting(cli.color.as_deref(), stdout_is_terminal);
-        crate::format::markdown::format_markdown_to(VERBOSE_HELP.as_bytes(), &mut printer)
+        crate::format::markdown::format_markdown_to(VERBOSE_HELP.as_bytes(), &mut printer, true)
             .map_err(|err| FetchError::Message(err.to_string()))?;
         let mut help = printer.into_bytes();
         if !help.ends_with(b"\n") {
@@ -556,7 +958,19 @@ fn apply_from_curl(cli: &mut Cli) -> Result<(), FetchError> {
     };
 
     validate_from_curl_exclusives(cli)?;
+    let command_from_stdin = command == "-";
+    let command = if command_from_stdin {
+        read_curl_command_from_stdin()?
+    } else {
+        command
+    };
     let parsed = from_curl::parse(&command)?;
+    if command_from_stdin && curl_reads_stdin(&parsed) {
+        return Err(
+            "'--from-curl -' reads the command from stdin, so its request body cannot also come from stdin"
+                .into(),
+        );
+    }
     let mut url = apply_proto_restriction(&parsed.url, &parsed.allowed_proto)?;
 
     cli.method = if parsed.method.is_empty() {
@@ -565,7 +979,12 @@ fn apply_from_curl(cli: &mut Cli) -> Result<(), FetchError> {
         Some(parsed.method.clone())
     };
 
-    for header in &parsed.headers {
+    let file_headers = read_curl_header_files(&parsed.header_files)?;
+    let has_content_type = parsed.has_content_type
+        || file_headers
+            .iter()
+            .any(|header| header.name.eq_ignore_ascii_case("content-type"));
+    for header in parsed.headers.iter().chain(&file_headers) {
         cli.headers
             .push(format!("{}: {}", header.name, header.value));
     }
@@ -587,7 +1006,7 @@ fn apply_from_curl(cli: &mut Cli) -> Result<(), FetchError> {
             cli.data = Some(value.to_string());
             cli.data_is_literal = false;
             cli.data_literal_bytes = None;
-            if !parsed.has_content_type {
+            if !has_content_type {
                 cli.headers
                     .push("Content-Type: application/x-www-form-urlencoded".to_string());
             }
@@ -599,7 +1018,7 @@ fn apply_from_curl(cli: &mut Cli) -> Result<(), FetchError> {
                 cli.data = Some(String::from_utf8_lossy(&data).into_owned());
                 cli.data_is_literal = true;
                 cli.data_literal_bytes = Some(data);
-                if !parsed.has_content_type {
+                if !has_content_type {
                     cli.headers
                         .push("Content-Type: application/x-www-form-urlencoded".to_string());
                 }
@@ -634,12 +1053,21 @@ fn apply_from_curl(cli: &mut Cli) -> Result<(), FetchError> {
     if !parsed.aws_sigv4.is_empty() {
         cli.aws_sigv4 = Some(parse_aws_sigv4(&parsed.aws_sigv4)?);
     }
+    if parsed.netrc {
+        cli.netrc = true;
+    }
+    if !parsed.netrc_file.is_empty() {
+        cli.netrc_file = Some(parsed.netrc_file.clone());
+    }
 
     if !parsed.output.is_empty() {
         cli.output = Some(parsed.output.clone());
     }
     cli.remote_name = parsed.remote_name;
     cli.remote_header_name = parsed.remote_header_name;
+    if parsed.continue_at {
+        cli.continue_at = Some("auto".to_string());
+    }
 
     if parsed.insecure {
         cli.insecure = true;
@@ -650,9 +1078,18 @@ fn apply_from_curl(cli: &mut Cli) -> Result<(), FetchError> {
     if !parsed.tls_max_version.is_empty() {
         cli.max_tls = Some(parsed.tls_max_version.clone());
     }
+    if !parsed.ciphers.is_empty() || !parsed.tls13_ciphers.is_empty() {
+        cli.ciphers = Some(crate::tls::cipher_list_from_curl(
+            &parsed.ciphers,
+            &parsed.tls13_ciphers,
+        ));
+    }
     if !parsed.ca_cert.is_empty() {
         cli.ca_cert.push(parsed.ca_cert.clone());
     }
+    if !parsed.ca_path.is_empty() {
+        cli.ca_path.push(parsed.ca_path.clone());
+    }
     if !parsed.cert.is_empty() {
         cli.cert = Some(parsed.cert.clone());
     }
@@ -675,14 +1112,25 @@ fn apply_from_curl(cli: &mut Cli) -> Result<(), FetchError> {
     if parsed.connect_timeout > 0.0 {
         cli.connect_timeout = Some(parsed.connect_timeout);
     }
+    if parsed.keepalive_time > 0.0 {
+        cli.keepalive = Some(parsed.keepalive_time);
+    }
+    if parsed.no_keepalive {
+        // curl's --no-keepalive only stops TCP probes; it still reuses
+        // connections, unlike fetch's --no-keepalive.
+        cli.keepalive = Some(0.0);
+    }
     if !parsed.proxy.is_empty() {
         cli.proxy = Some(parsed.proxy.clone());
     }
+    if !parsed.proxy_user.is_empty() {
+        cli.proxy_user = Some(parsed.proxy_user.clone());
+    }
     if !parsed.unix_socket.is_empty() {
         cli.unix = Some(parsed.unix_socket.clone());
     }
     if !parsed.doh_url.is_empty() {
-        cli.dns_server = Some(parsed.doh_url.clone());
+        cli.dns_servers = vec![parsed.doh_url.clone()];
     }
     if !parsed.ech.is_empty() {
         cli.ech = Some(match parsed.ech.as_str() {
@@ -698,11 +1146,18 @@ fn apply_from_curl(cli: &mut Cli) -> Result<(), FetchError> {
     if parsed.retry_delay > 0.0 {
         cli.retry_delay = Some(parsed.retry_delay);
     }
+    if parsed.retry_connrefused {
+        cli.retry_connrefused = true;
+    }
+    if parsed.retry_max_time > 0.0 {
+        cli.retry_max_time = Some(parsed.retry_max_time);
+    }
     cli.ranges.extend(parsed.ranges.iter().cloned());
 
     match parsed.http_version.as_str() {
         "1.0" | "1.1" => cli.http = Some("1".to_string()),
         "2" => cli.http = Some("2".to_string()),
+        "2-prior-knowledge" => cli.http2_prior_knowledge = true,
         "3" => cli.http = Some("3".to_string()),
         _ => {}
     }
@@ -711,6 +1166,9 @@ fn apply_from_curl(cli: &mut Cli) -> Result<(), FetchError> {
     if parsed.silent {
         cli.silent = true;
     }
+    if cli.progress.is_none() && !parsed.progress.is_empty() {
+        cli.progress = Some(parsed.progress.clone());
+    }
 
     cli.url = Some(url);
     cli.from_curl = None;
@@ -727,6 +1185,44 @@ fn streaming_curl_data_value(values: &[from_curl::DataValue]) -> Option<&str> {
     value.value.starts_with('@').then_some(value.value.as_str())
 }
 
+/// Read the headers from each `-H @FILE`, one `Name: Value` per non-empty line.
+fn read_curl_header_files(paths: &[String]) -> Result<Vec<from_curl::Header>, FetchError> {
+    let mut headers = Vec::new();
+    for path in paths {
+        let contents =
+            std::fs::read_to_string(crate::fileutil::expand_home(path)).map_err(|err| {
+                FetchError::Message(format!("unable to read curl header file '{path}': {err}"))
+            })?;
+        for line in contents.lines() {
+            if line.trim().is_empty() {
+                continue;
+            }
+            let header = from_curl::parse_header(line)
+                .map_err(|err| FetchError::Message(format!("{err} in '{path}'")))?;
+            headers.push(header);
+        }
+    }
+    Ok(headers)
+}
+
+fn read_curl_command_from_stdin() -> Result<String, FetchError> {
+    let mut command = String::new();
+    std::io::stdin().read_to_string(&mut command)?;
+    if command.trim().is_empty() {
+        return Err("'--from-curl -' requires a curl command on stdin".into());
+    }
+    Ok(command)
+}
+
+/// Whether any body the parsed command sends is read from stdin.
+fn curl_reads_stdin(parsed: &from_curl::ParsedCurl) -> bool {
+    parsed.upload_file == "-"
+        || parsed
+            .data_values
+            .iter()
+            .any(|value| !value.is_raw && !value.is_urlencode && value.value == "@-")
+}
+
 fn validate_from_curl_exclusives(cli: &Cli) -> Result<(), FetchError> {
     if cli.url.is_some() {
         return Err("'--from-curl' and a URL argument cannot be used together".into());
@@ -960,6 +1456,11 @@ fn append_raw_query(url: &mut String, query: &str) {
 
 fn parse_aws_sigv4(value: &str) -> Result<String, FetchError> {
     let parts: Vec<&str> = value.split(':').collect();
+    // With only the providers, e.g. 'aws:amz', curl infers the region and
+    // service from the host.
+    if parts.len() <= 2 && parts[0].eq_ignore_ascii_case("aws") {
+        return Ok(String::new());
+    }
     if parts.len() == 4 {
         let region = parts[2];
         let service = parts[3];
@@ -1268,6 +1769,80 @@ mod tests {
         assert_eq!(cli.data_literal_bytes.as_deref(), Some(expected.as_bytes()));
     }
 
+    #[test]
+    fn repeated_data_values_are_joined_like_curl() {
+        let dir = tempfile::tempdir().unwrap();
+        let path = dir.path().join("part.txt");
+        std::fs::write(&path, b"from=file").unwrap();
+        let file_arg = format!("@{}", path.display());
+        let mut cli = Cli::try_parse_from([
+            "fetch",
+            "-d",
+            "a=1",
+            "--data",
+            &file_arg,
+            "-d",
+            "b=2",
+            "https://example.com",
+        ])
+        .unwrap();
+
+        apply_data_values(&mut cli).unwrap();
+
+        assert_eq!(cli.data.as_deref(), Some("a=1&from=file&b=2"));
+        assert!(cli.data_is_literal);
+        assert_eq!(
+            cli.data_literal_bytes.as_deref(),
+            Some(b"a=1&from=file&b=2".as_slice())
+        );
+
+        let mut cli =
+            Cli::try_parse_from(["fetch", "-d", &file_arg, "https://example.com"]).unwrap();
+        apply_data_values(&mut cli).unwrap();
+        assert_eq!(cli.data.as_deref(), Some(file_arg.as_str()));
+        assert!(!cli.data_is_literal);
+        assert_eq!(cli.data_literal_bytes, None);
+    }
+
+    #[test]
+    fn from_curl_no_keepalive_disables_probes_but_keeps_connection_reuse() {
+        let mut cli = Cli::try_parse_from([
+            "fetch",
+            "--from-curl",
+            "curl --no-keepalive https://example.com",
+        ])
+        .unwrap();
+
+        apply_from_curl(&mut cli).unwrap();
+
+        assert_eq!(cli.keepalive, Some(0.0));
+        assert!(!cli.no_keepalive);
+        assert!(
+            crate::net::TcpKeepalive::from_flags(cli.keepalive, cli.no_keepalive)
+                .unwrap()
+                .is_disabled()
+        );
+    }
+
+    #[test]
+    fn from_curl_ciphers_leave_tls13_suites_unrestricted() {
+        let mut cli = Cli::try_parse_from([
+            "fetch",
+            "--from-curl",
+            "curl --ciphers ECDHE-RSA-AES128-GCM-SHA256 https://example.com",
+        ])
+        .unwrap();
+
+        apply_from_curl(&mut cli).unwrap();
+
+        assert_eq!(
+            cli.ciphers.as_deref(),
+            Some(
+                "TLS_AES_128_GCM_SHA256:TLS_AES_256_GCM_SHA384:TLS_CHACHA20_POLY1305_SHA256:ECDHE-RSA-AES128-GCM-SHA256"
+            )
+        );
+    }
+
     #[test]
     fn from_curl_single_file_data_uses_streaming_body_source() {
         let dir = tempfile::tempdir().unwrap();
@@ -1529,6 +2104,20 @@ mod tests {
         assert_eq!(cli.aws_sigv4.as_deref(), Some("us-east-1/s3"));
     }
 
+    #[test]
+    fn from_curl_aws_sigv4_providers_only_infers_region_and_service() {
+        let mut cli = Cli::try_parse_from([
+            "fetch",
+            "--from-curl",
+            r#"curl --aws-sigv4 "aws:amz" https://s3.us-west-2.amazonaws.com/bucket"#,
+        ])
+        .unwrap();
+
+        apply_from_curl(&mut cli).unwrap();
+
+        assert_eq!(cli.aws_sigv4.as_deref(), Some(""));
+    }
+
     #[test]
     fn basic_auth_parsing_preserves_spaces() {
         let mut cli =
@@ -1703,6 +2292,68 @@ mod tests {
         assert_eq!(err, "unexpected argument: \"extra\"");
     }
 
+    #[test]
+    fn request_items_build_json_body_and_query_params() {
+        let mut cli = Cli::try_parse_from([
+            "fetch",
+            "-q",
+            "page=1",
+            "example.com",
+            "name=Ada Lovelace",
+            "role=admin=true",
+            "limit==50",
+            "name=Ada",
+        ])
+        .unwrap();
+
+        apply_request_items(&mut cli).unwrap();
+
+        assert_eq!(cli.url.as_deref(), Some("example.com"));
+        assert_eq!(
+            cli.json.as_deref(),
+            Some(r#"{"name":"Ada","role":"admin=true"}"#)
+        );
+        assert_eq!(cli.query, ["page=1", "limit=50"]);
+        assert!(cli.items.is_empty());
+    }
+
+    #[test]
+    fn request_items_without_body_fields_leave_body_unset() {
+        let mut cli =
+            Cli::try_parse_from(["fetch", "-d", "raw", "example.com", "q==search"]).unwrap();
+
+        apply_data_values(&mut cli).unwrap();
+        apply_request_items(&mut cli).unwrap();
+
+        assert_eq!(cli.data.as_deref(), Some("raw"));
+        assert_eq!(cli.json, None);
+        assert_eq!(cli.query, ["q=search"]);
+    }
+
+    #[test]
+    fn request_items_reject_invalid_tokens_and_other_body_flags() {
+        for (args, want) in [
+            (
+                vec!["fetch", "example.com", "other.example"],
+                "unexpected argument: \"other.example\"",
+            ),
+            (
+                vec!["fetch", "example.com", "=value"],
+                "invalid request item \"=value\": key must not be empty",
+            ),
+            (
+                vec!["fetch", "-f", "a=b", "example.com", "name=Ada"],
+                "request body items cannot be used with '--form'",
+            ),
+        ] {
+            let mut cli = Cli::try_parse_from(&args).unwrap();
+
+            let err = apply_request_items(&mut cli).unwrap_err().to_string();
+
+            assert_eq!(err, want, "{args:?}");
+        }
+    }
+
     #[test]
     fn key_without_cert_reports_go_style_required_flag() {
         let cli =
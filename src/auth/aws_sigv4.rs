@@ -40,6 +40,8 @@ pub enum AwsSigV4Error {
     MissingEnvVar(&'static str),
     #[error("invalid aws-sigv4 format: {0}, expected REGION/SERVICE")]
     InvalidConfig(String),
+    #[error("invalid aws-sigv4 format: cannot infer REGION/SERVICE from host '{0}'")]
+    UninferableHost(String),
     #[error("invalid header value for AWS signing: {0}")]
     InvalidHeaderValue(String),
 }
@@ -56,6 +58,80 @@ pub fn parse_config(value: &str) -> Result<Config, AwsSigV4Error> {
     Ok(Config::new(region, service))
 }
 
+/// Resolve a `--aws-sigv4` value for a request to `url`. An empty value infers
+/// the region and service from the standard AWS endpoint naming of the host.
+pub fn config_for_url(value: &str, url: &Url) -> Result<Config, AwsSigV4Error> {
+    if !value.trim().is_empty() {
+        return parse_config(value);
+    }
+    let host = url.host_str().unwrap_or_default();
+    infer_config(host).ok_or_else(|| AwsSigV4Error::UninferableHost(host.to_string()))
+}
+
+/// Infer the region and service from an AWS endpoint hostname such as
+/// `s3.us-west-2.amazonaws.com`, `abc123.execute-api.us-east-1.amazonaws.com`,
+/// or `xyz.lambda-url.eu-west-1.on.aws`.
+pub fn infer_config(host: &str) -> Option<Config> {
+    let host = host.trim_end_matches('.').to_ascii_lowercase();
+    let prefix = [".amazonaws.com", ".amazonaws.com.cn", ".on.aws"]
+        .iter()
+        .find_map(|suffix| host.strip_suffix(suffix))?;
+    let labels: Vec<&str> = prefix.split('.').collect();
+    if let Some(pos) = labels.iter().rposition(|label| is_region(label)) {
+        // Most endpoints put the service before the region, but a few, like
+        // OpenSearch's DOMAIN.REGION.es.amazonaws.com, put it after.
+        let service = match labels.get(pos + 1) {
+            Some(service) => service,
+            None => labels[..pos]
+                .iter()
+                .rev()
+                .find(|label| **label != "dualstack")?,
+        };
+        return Some(Config::new(labels[pos], endpoint_service(service)));
+    }
+
+    // Legacy global S3 endpoints: s3.amazonaws.com, BUCKET.s3.amazonaws.com,
+    // and s3-REGION.amazonaws.com.
+    let last = *labels.last()?;
+    if last == "s3" || last == "s3-external-1" {
+        return Some(Config::new("us-east-1", "s3"));
+    }
+    let region = last.strip_prefix("s3-")?;
+    is_region(region).then(|| Config::new(region, "s3"))
+}
+
+/// Map an endpoint label to the service name used for signing.
+fn endpoint_service(label: &str) -> &str {
+    if label == "s3" || label.starts_with("s3-") {
+        return "s3";
+    }
+    if label == "lambda-url" {
+        return "lambda";
+    }
+    label.strip_suffix("-fips").unwrap_or(label)
+}
+
+/// Report whether `label` looks like an AWS region, e.g. `us-east-1` or
+/// `us-gov-west-1`.
+fn is_region(label: &str) -> bool {
+    let parts: Vec<&str> = label.split('-').collect();
+    let Some((number, names)) = parts.split_last() else {
+        return false;
+    };
+    names.len() >= 2
+        && names[0].len() == 2
+        && names.iter().all(|name| is_lowercase_word(name))
+        && is_digits(number)
+}
+
+fn is_lowercase_word(value: &str) -> bool {
+    !value.is_empty() && value.bytes().all(|b| b.is_ascii_lowercase())
+}
+
+fn is_digits(value: &str) -> bool {
+    !value.is_empty() && value.bytes().all(|b| b.is_ascii_digit())
+}
+
 pub fn sign(
     method: &str,
     url: &Url,
@@ -686,4 +762,69 @@ mod tests {
         assert_eq!(config.service, "s3");
         assert!(parse_config("us-east-1").is_err());
     }
+
+    #[test]
+    fn test_infer_config() {
+        let cases = [
+            ("s3.us-west-2.amazonaws.com", "us-west-2", "s3"),
+            ("my-bucket.s3.us-west-2.amazonaws.com", "us-west-2", "s3"),
+            (
+                "my.dotted.bucket.s3.eu-west-1.amazonaws.com",
+                "eu-west-1",
+                "s3",
+            ),
+            ("s3.dualstack.ap-south-1.amazonaws.com", "ap-south-1", "s3"),
+            ("s3-fips.us-gov-west-1.amazonaws.com", "us-gov-west-1", "s3"),
+            ("s3.amazonaws.com", "us-east-1", "s3"),
+            ("examplebucket.s3.amazonaws.com", "us-east-1", "s3"),
+            ("s3-us-west-1.amazonaws.com", "us-west-1", "s3"),
+            (
+                "abc123.execute-api.us-west-2.amazonaws.com",
+                "us-west-2",
+                "execute-api",
+            ),
+            ("dynamodb.us-east-1.amazonaws.com", "us-east-1", "dynamodb"),
+            ("sqs-fips.us-east-2.amazonaws.com", "us-east-2", "sqs"),
+            ("xyz.lambda-url.eu-west-1.on.aws", "eu-west-1", "lambda"),
+            ("search-logs.us-east-1.es.amazonaws.com", "us-east-1", "es"),
+            ("s3.cn-north-1.amazonaws.com.cn", "cn-north-1", "s3"),
+            ("SQS.US-EAST-1.AMAZONAWS.COM.", "us-east-1", "sqs"),
+        ];
+        for (host, region, service) in cases {
+            let config = infer_config(host).unwrap_or_else(|| panic!("{host}"));
+            assert_eq!(config.region, region, "{host}");
+            assert_eq!(config.service, service, "{host}");
+        }
+
+        for host in [
+            "example.com",
+            "127.0.0.1",
+            "amazonaws.com",
+            "iam.amazonaws.com",
+            "s3-bucket.amazonaws.com",
+            "us-east-1.amazonaws.com",
+        ] {
+            assert!(infer_config(host).is_none(), "{host}");
+        }
+    }
+
+    #[test]
+    fn test_config_for_url() {
+        let url = Url::parse("https://s3.us-west-2.amazonaws.com/bucket/key").unwrap();
+        let config = config_for_url("", &url).unwrap();
+        assert_eq!(
+            (config.region.as_str(), config.service.as_str()),
+            ("us-west-2", "s3")
+        );
+
+        let config = config_for_url("eu-west-1/execute-api", &url).unwrap();
+        assert_eq!(config.region, "eu-west-1");
+        assert_eq!(config.service, "execute-api");
+
+        let url = Url::parse("https://example.com/").unwrap();
+        assert_eq!(
+            config_for_url("", &url).unwrap_err().to_string(),
+            "invalid aws-sigv4 format: cannot infer REGION/SERVICE from host 'example.com'"
+        );
+    }
 }
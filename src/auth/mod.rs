@@ -1,2 +1,3 @@
 pub mod aws_sigv4;
 pub mod digest;
+pub mod netrc;
@@ -0,0 +1,168 @@
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct Credentials {
+    pub login: String,
+    pub password: String,
+}
+
+#[derive(Debug, Default)]
+struct Entry<'a> {
+    /// The machine name, or `None` for the `default` entry.
+    machine: Option<&'a str>,
+    login: Option<&'a str>,
+    password: Option<&'a str>,
+}
+
+/// Find the credentials for `host` in the contents of a netrc file. The first
+/// `machine` entry matching the host wins, then the `default` entry. Entries
+/// without a `login` are ignored.
+pub fn lookup(contents: &str, host: &str) -> Option<Credentials> {
+    let entries = parse_entries(contents);
+    let entry = entries
+        .iter()
+        .find(|entry| {
+            entry
+                .machine
+                .is_some_and(|name| name.eq_ignore_ascii_case(host))
+        })
+        .or_else(|| entries.iter().find(|entry| entry.machine.is_none()))?;
+    Some(Credentials {
+        login: entry.login?.to_string(),
+        password: entry.password.unwrap_or_default().to_string(),
+    })
+}
+
+fn parse_entries(contents: &str) -> Vec<Entry<'_>> {
+    let mut entries: Vec<Entry<'_>> = Vec::new();
+    let mut tokens = tokenize(contents).into_iter();
+    while let Some(token) = tokens.next() {
+        match token {
+            "machine" => {
+                let Some(name) = tokens.next() else {
+                    break;
+                };
+                entries.push(Entry {
+                    machine: Some(name),
+                    ..Entry::default()
+                });
+            }
+            "default" => entries.push(Entry::default()),
+            "login" | "password" | "account" => {
+                let value = tokens.next();
+                let Some(entry) = entries.last_mut() else {
+                    continue;
+                };
+                match token {
+                    "login" => entry.login = value,
+                    "password" => entry.password = value,
+                    _ => {}
+                }
+            }
+            _ => {}
+        }
+    }
+    entries
+}
+
+fn tokenize(contents: &str) -> Vec<&str> {
+    let mut tokens = Vec::new();
+    let mut lines = contents.lines();
+    while let Some(line) = lines.next() {
+        for token in line.split_whitespace() {
+            if token.starts_with('#') {
+                break;
+            }
+            if token == "macdef" {
+                // A macro definition runs until the next blank line.
+                for line in lines.by_ref() {
+                    if line.trim().is_empty() {
+                        break;
+                    }
+                }
+                break;
+            }
+            tokens.push(token);
+        }
+    }
+    tokens
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn credentials(login: &str, password: &str) -> Option<Credentials> {
+        Some(Credentials {
+            login: login.to_string(),
+            password: password.to_string(),
+        })
+    }
+
+    #[test]
+    fn test_lookup() {
+        let contents = "\
+# personal hosts
+machine api.example.com login alice password s3cret
+machine other.example.com
+  login bob
+  password hunter2
+
+default login anonymous password guest
+";
+        let cases = [
+            (
+                "exact machine",
+                "api.example.com",
+                credentials("alice", "s3cret"),
+            ),
+            (
+                "multi-line entry",
+                "other.example.com",
+                credentials("bob", "hunter2"),
+            ),
+            (
+                "case-insensitive host",
+                "API.Example.COM",
+                credentials("alice", "s3cret"),
+            ),
+            (
+                "default entry",
+                "unknown.example.com",
+                credentials("anonymous", "guest"),
+            ),
+        ];
+        for (name, host, want) in cases {
+            assert_eq!(lookup(contents, host), want, "{name}");
+        }
+    }
+
+    #[test]
+    fn test_lookup_without_match_or_login() {
+        let contents = "machine api.example.com login alice password s3cret\n";
+        assert_eq!(lookup(contents, "other.example.com"), None);
+        assert_eq!(
+            lookup("machine api.example.com password s3cret", "api.example.com"),
+            None
+        );
+        assert_eq!(
+            lookup("machine api.example.com login alice", "api.example.com"),
+            credentials("alice", "")
+        );
+        assert_eq!(lookup("", "api.example.com"), None);
+    }
+
+    #[test]
+    fn test_lookup_skips_macdef_blocks() {
+        let contents = "\
+machine ftp.example.com login ftp password ftp
+macdef init
+machine api.example.com login macro password body
+cd /pub
+
+machine api.example.com login alice password s3cret
+";
+        assert_eq!(
+            lookup(contents, "api.example.com"),
+            credentials("alice", "s3cret")
+        );
+    }
+}
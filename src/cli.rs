@@ -1,4 +1,5 @@
 use clap::{ArgAction, Parser};
+use percent_encoding::{AsciiSet, NON_ALPHANUMERIC, utf8_percent_encode};
 
 pub mod completion;
 pub mod from_curl;
@@ -97,6 +98,33 @@ impl PagerMode {
     }
 }
 
+#[derive(Clone, Copy, Debug, Eq, PartialEq)]
+pub enum ProgressMode {
+    Auto,
+    Bar,
+    Spinner,
+    Off,
+}
+
+impl ProgressMode {
+    pub const VALUES: &[&str] = &["auto", "bar", "spinner", "none"];
+
+    pub fn from_cli(cli: &Cli) -> Self {
+        Self::from_value(cli.progress.as_deref().unwrap_or("auto"))
+            .expect("progress mode is validated by clap")
+    }
+
+    pub fn from_value(value: &str) -> Option<Self> {
+        match value {
+            "auto" => Some(Self::Auto),
+            "bar" => Some(Self::Bar),
+            "spinner" => Some(Self::Spinner),
+            "none" => Some(Self::Off),
+            _ => None,
+        }
+    }
+}
+
 #[derive(Debug, Parser)]
 #[command(
     name = "fetch",
@@ -108,6 +136,23 @@ pub struct Cli {
     #[arg(value_name = "URL", help = "The URL to make a request to")]
     pub url: Option<String>,
 
+    #[arg(value_name = "ITEM", help = "JSON body KEY=VALUE or query KEY==VALUE")]
+    pub items: Vec<String>,
+
+    #[arg(
+        long = "accept-fallback",
+        value_name = "TYPES",
+        conflicts_with = "grpc",
+        help = "Retry a 406 with broader Accept types"
+    )]
+    pub accept_fallback: Option<String>,
+
+    #[arg(
+        long = "allow-unfilled",
+        help = "Leave unmatched {NAME} URL placeholders"
+    )]
+    pub allow_unfilled: bool,
+
     #[arg(
         long,
         conflicts_with_all = ["discard", "grpc", "grpc_describe", "grpc_list", "remote_name"],
@@ -145,15 +190,113 @@ pub struct Cli {
     )]
     pub bearer: Option<String>,
 
+    #[arg(long = "body-only", help = "Print only the body and errors")]
+    pub body_only: bool,
+
     #[arg(long, help = "Print the build information")]
     pub buildinfo: bool,
 
     #[arg(long, value_name = "PATH", help = "CA certificate file path")]
     pub ca_cert: Vec<String>,
 
+    #[arg(long, value_name = "DIR", help = "Directory of CA certificate files")]
+    pub ca_path: Vec<String>,
+
     #[arg(long, value_name = "PATH", help = "Client certificate for mTLS")]
     pub cert: Option<String>,
 
+    #[arg(
+        long = "check-hosts",
+        value_name = "@FILE",
+        conflicts_with_all = [
+            "url",
+            "article",
+            "checksum",
+            "chunk_size",
+            "continue_at",
+            "data",
+            "decode_jwt",
+            "discard",
+            "dry_run",
+            "edit",
+            "extract",
+            "extract_all",
+            "fail_on_empty",
+            "fail_on_truncation",
+            "form",
+            "grpc",
+            "har",
+            "hex",
+            "json",
+            "line_numbers",
+            "minify",
+            "multipart",
+            "output",
+            "output_dir",
+            "raw",
+            "remote_header_name",
+            "remote_name",
+            "schema",
+            "sort_keys",
+            "split_output",
+            "to_json",
+            "to_yaml",
+            "unwrap_json",
+            "write_meta",
+            "xml",
+        ],
+        help = "Check status and latency of listed URLs"
+    )]
+    pub check_hosts: Option<String>,
+
+    #[arg(
+        long,
+        value_name = "DIGEST",
+        help = "Verify or print the response body digest"
+    )]
+    pub checksum: Option<String>,
+
+    #[arg(
+        long = "checksum-verify",
+        value_name = "HEX",
+        requires = "checksum",
+        help = "Fail unless the --checksum digest matches"
+    )]
+    pub checksum_verify: Option<String>,
+
+    #[arg(
+        long = "chunk-size",
+        value_name = "BYTES",
+        conflicts_with_all = [
+            "article",
+            "copy",
+            "data",
+            "discard",
+            "edit",
+            "extract",
+            "form",
+            "grpc",
+            "har",
+            "json",
+            "multipart",
+            "ranges",
+            "remote_header_name",
+            "schema",
+            "to_json",
+            "to_yaml",
+            "xml",
+        ],
+        help = "Download in ranges of BYTES"
+    )]
+    pub chunk_size: Option<u64>,
+
+    #[arg(
+        long,
+        value_name = "LIST",
+        help = "Allowed TLS cipher suites, colon-separated"
+    )]
+    pub ciphers: Option<String>,
+
     #[arg(long, help = "Overwrite existing output file")]
     pub clobber: bool,
 
@@ -191,16 +334,74 @@ pub struct Cli {
     )]
     pub connect_timeout: Option<f64>,
 
+    #[arg(
+        long = "connect-to",
+        value_name = "ROUTE",
+        conflicts_with = "unix",
+        help = "Connect via HOST:PORT:HOST2:PORT2 route"
+    )]
+    pub connect_to: Vec<String>,
+
+    #[arg(
+        short = 'C',
+        long = "continue-at",
+        value_name = "MODE",
+        value_parser = ["auto"],
+        hide_possible_values = true,
+        conflicts_with_all = [
+            "article",
+            "chunk_size",
+            "copy",
+            "data",
+            "discard",
+            "edit",
+            "extract",
+            "form",
+            "grpc",
+            "har",
+            "json",
+            "multipart",
+            "ranges",
+            "remote_header_name",
+            "schema",
+            "to_json",
+            "to_yaml",
+            "xml",
+        ],
+        help = "Resume into an existing file [auto]"
+    )]
+    pub continue_at: Option<String>,
+
     #[arg(long, help = "Copy the response body to clipboard")]
     pub copy: bool,
 
     #[arg(
-        short = 'd',
         long,
+        conflicts_with_all = [
+            "chunk_size",
+            "continue_at",
+            "dry_run",
+            "grpc",
+            "har",
+            "trace_headers_only",
+        ],
+        help = "Print the request as a curl command"
+    )]
+    pub curl: bool,
+
+    #[arg(
+        short = 'd',
+        long = "data",
+        id = "data",
         value_name = "[@]VALUE",
         conflicts_with_all = ["form", "json", "multipart", "xml"],
         help = "Send a request body"
     )]
+    pub data_values: Vec<String>,
+
+    /// The `--data` body: a single value as given, or repeated values joined
+    /// with `&`.
+    #[arg(skip)]
     pub data: Option<String>,
 
     #[arg(skip)]
@@ -209,6 +410,9 @@ pub struct Cli {
     #[arg(skip)]
     pub data_literal_bytes: Option<Vec<u8>>,
 
+    #[arg(long = "decode-jwt", help = "Decode JWTs in the output")]
+    pub decode_jwt: bool,
+
     #[arg(
         long,
         value_name = "USER:PASS",
@@ -229,7 +433,7 @@ pub struct Cli {
         value_name = "IP[:PORT]|URL",
         help = "Custom DNS resolver endpoint"
     )]
-    pub dns_server: Option<String>,
+    pub dns_servers: Vec<String>,
 
     #[arg(long = "dry-run", help = "Print out the request info and exit")]
     pub dry_run: bool,
@@ -249,6 +453,45 @@ pub struct Cli {
     #[arg(short = 'e', long, help = "Use an editor to modify the request body")]
     pub edit: bool,
 
+    #[arg(
+        long = "expand-env",
+        value_name = "SCOPE",
+        num_args = 0..=1,
+        require_equals = true,
+        default_missing_value = "inline",
+        value_parser = ["inline", "files"],
+        hide_possible_values = true,
+        help = "Expand $VARS in the body [inline, files]"
+    )]
+    pub expand_env: Option<String>,
+
+    #[arg(
+        long,
+        value_name = "PATH",
+        conflicts_with_all = ["article", "discard", "output", "remote_name"],
+        help = "Print the JSON value at a path"
+    )]
+    pub extract: Option<String>,
+
+    #[arg(
+        long = "extract-all",
+        requires = "extract",
+        help = "Print every value matched by --extract"
+    )]
+    pub extract_all: bool,
+
+    #[arg(
+        long = "fail-on-empty",
+        help = "Fail if a 2xx response has an empty body"
+    )]
+    pub fail_on_empty: bool,
+
+    #[arg(
+        long = "fail-on-truncation",
+        help = "Fail if the response body is truncated"
+    )]
+    pub fail_on_truncation: bool,
+
     #[arg(
         short = 'f',
         long,
@@ -287,6 +530,30 @@ pub struct Cli {
     #[arg(long = "grpc-list", help = "List available gRPC services")]
     pub grpc_list: bool,
 
+    #[arg(
+        long = "h2-initial-window-size",
+        value_name = "BYTES",
+        hide = true,
+        help = "HTTP/2 initial stream window size"
+    )]
+    pub h2_initial_window_size: Option<u32>,
+
+    #[arg(
+        long = "h2-max-concurrent-streams",
+        value_name = "NUM",
+        hide = true,
+        help = "HTTP/2 max concurrent streams to advertise"
+    )]
+    pub h2_max_concurrent_streams: Option<u32>,
+
+    #[arg(
+        long = "h2-max-frame-size",
+        value_name = "BYTES",
+        hide = true,
+        help = "HTTP/2 max frame size to advertise"
+    )]
+    pub h2_max_frame_size: Option<u32>,
+
     #[arg(
         short = 'H',
         long = "header",
@@ -298,17 +565,35 @@ pub struct Cli {
     #[arg(short = 'h', long, help = "Print help")]
     pub help: bool,
 
+    #[arg(
+        long,
+        conflicts_with_all = [
+            "article",
+            "discard",
+            "extract",
+            "minify",
+            "output",
+            "remote_name",
+            "schema",
+            "split_output",
+            "to_json",
+            "to_yaml",
+        ],
+        help = "Print the response body as a hexdump"
+    )]
+    pub hex: bool,
+
     #[arg(
         long,
         value_name = "VERSION",
-        conflicts_with_all = ["http1", "http2", "http3"],
+        conflicts_with_all = ["http1", "http2", "http2_prior_knowledge", "http3"],
         help = "HTTP version to use [1, 2, 3]"
     )]
     pub http: Option<String>,
 
     #[arg(
         long = "http1",
-        conflicts_with_all = ["http", "http2", "http3"],
+        conflicts_with_all = ["http", "http2", "http2_prior_knowledge", "http3"],
         hide = true
     )]
     pub http1: bool,
@@ -320,13 +605,30 @@ pub struct Cli {
     )]
     pub http2: bool,
 
+    #[arg(
+        long = "http2-prior-knowledge",
+        conflicts_with_all = ["http", "http1", "http3"],
+        help = "Use HTTP/2 without upgrade, including h2c"
+    )]
+    pub http2_prior_knowledge: bool,
+
     #[arg(
         long = "http3",
-        conflicts_with_all = ["http", "http1", "http2"],
+        conflicts_with_all = ["http", "http1", "http2", "http2_prior_knowledge"],
         hide = true
     )]
     pub http3: bool,
 
+    #[arg(
+        long = "idempotency-key",
+        value_name = "KEY",
+        num_args = 0..=1,
+        require_equals = true,
+        default_missing_value = "",
+        help = "Set an Idempotency-Key (random if empty)"
+    )]
+    pub idempotency_key: Option<String>,
+
     #[arg(long = "ignore-status", help = "Do not exit nonzero for HTTP 4xx/5xx")]
     pub ignore_status: bool,
 
@@ -357,6 +659,14 @@ pub struct Cli {
     )]
     pub install_skill: Option<String>,
 
+    #[arg(
+        long,
+        value_name = "NAME|IP",
+        conflicts_with_all = ["proxy", "unix"],
+        help = "Bind to a network interface or local IP"
+    )]
+    pub interface: Option<String>,
+
     #[arg(
         short = 'j',
         long,
@@ -366,9 +676,51 @@ pub struct Cli {
     )]
     pub json: Option<String>,
 
+    #[arg(
+        long = "json-merge",
+        value_name = "PATH",
+        requires = "json",
+        help = "Merge the --json body into a JSON file"
+    )]
+    pub json_merge: Option<String>,
+
+    #[arg(
+        long,
+        value_name = "SECONDS",
+        allow_hyphen_values = true,
+        conflicts_with = "no_keepalive",
+        help = "TCP keep-alive idle time (0 disables)"
+    )]
+    pub keepalive: Option<f64>,
+
     #[arg(long, value_name = "PATH", help = "Client private key for mTLS")]
     pub key: Option<String>,
 
+    #[arg(long = "line-numbers", help = "Number the lines of formatted output")]
+    pub line_numbers: bool,
+
+    #[arg(
+        long = "local-port",
+        value_name = "LOW-HIGH",
+        conflicts_with_all = ["proxy", "unix"],
+        help = "Bind to a local port in a range"
+    )]
+    pub local_port: Option<String>,
+
+    #[arg(
+        long = "max-decompress-ratio",
+        value_name = "N",
+        help = "Limit how far a body may expand on decode"
+    )]
+    pub max_decompress_ratio: Option<u32>,
+
+    #[arg(
+        long = "max-headers",
+        value_name = "BYTES",
+        help = "Limit response headers to BYTES"
+    )]
+    pub max_headers: Option<u32>,
+
     #[arg(
         long = "max-tls",
         value_name = "VERSION",
@@ -392,6 +744,21 @@ pub struct Cli {
     )]
     pub min_tls: Option<String>,
 
+    #[arg(
+        long,
+        conflicts_with_all = [
+            "article",
+            "discard",
+            "extract",
+            "output",
+            "remote_name",
+            "to_json",
+            "to_yaml",
+        ],
+        help = "Minify HTML and CSS responses"
+    )]
+    pub minify: bool,
+
     #[arg(
         short = 'F',
         long,
@@ -401,9 +768,31 @@ pub struct Cli {
     )]
     pub multipart: Vec<String>,
 
+    #[arg(long, help = "Read credentials from ~/.netrc")]
+    pub netrc: bool,
+
+    #[arg(
+        long = "netrc-file",
+        value_name = "PATH",
+        help = "Read credentials from a netrc file"
+    )]
+    pub netrc_file: Option<String>,
+
     #[arg(long = "no-encode", hide = true)]
     pub no_encode: bool,
 
+    #[arg(
+        long = "no-keepalive",
+        help = "Disable TCP keep-alive and connection reuse"
+    )]
+    pub no_keepalive: bool,
+
+    #[arg(
+        long = "no-formatter-delegation",
+        help = "Print Markdown code blocks verbatim"
+    )]
+    pub no_formatter_delegation: bool,
+
     #[arg(
         long,
         value_name = "MODE",
@@ -413,6 +802,14 @@ pub struct Cli {
     )]
     pub pager: Option<String>,
 
+    #[arg(
+        long = "path-param",
+        value_name = "NAME=VALUE",
+        conflicts_with_all = ["check_hosts"],
+        help = "Fill a {NAME} placeholder in the URL"
+    )]
+    pub path_params: Vec<String>,
+
     #[arg(
         short = 'o',
         long,
@@ -422,9 +819,25 @@ pub struct Cli {
     )]
     pub output: Option<String>,
 
+    #[arg(
+        long = "output-dir",
+        value_name = "DIR",
+        help = "Save --remote-name downloads under DIR"
+    )]
+    pub output_dir: Option<String>,
+
     #[arg(long, value_name = "PATH", help = "Write a HAR 1.2 sidecar file")]
     pub har: Option<String>,
 
+    #[arg(
+        long,
+        value_name = "STYLE",
+        value_parser = clap::builder::PossibleValuesParser::new(ProgressMode::VALUES),
+        hide_possible_values = true,
+        help = "Progress style [auto, bar, spinner, none]"
+    )]
+    pub progress: Option<String>,
+
     #[arg(
         long = "proto-desc",
         value_name = "PATH",
@@ -451,6 +864,13 @@ pub struct Cli {
     #[arg(long, value_name = "PROXY", help = "Configure a proxy")]
     pub proxy: Option<String>,
 
+    #[arg(
+        long = "proxy-user",
+        value_name = "USER:PASS",
+        help = "Set credentials for the proxy"
+    )]
+    pub proxy_user: Option<String>,
+
     #[arg(
         short = 'q',
         long = "query",
@@ -459,6 +879,9 @@ pub struct Cli {
     )]
     pub query: Vec<String>,
 
+    #[arg(long = "quiet-insecure", help = "Hide the --insecure warning")]
+    pub quiet_insecure: bool,
+
     #[arg(
         short = 'r',
         long = "range",
@@ -468,6 +891,26 @@ pub struct Cli {
     )]
     pub ranges: Vec<String>,
 
+    #[arg(
+        long,
+        conflicts_with_all = [
+            "compress",
+            "decode_jwt",
+            "extract",
+            "format",
+            "hex",
+            "line_numbers",
+            "minify",
+            "no_encode",
+            "pager",
+            "to_json",
+            "to_yaml",
+            "unwrap_json",
+        ],
+        help = "Print the body exactly as received"
+    )]
+    pub raw: bool,
+
     #[arg(
         long,
         value_name = "NUM",
@@ -492,6 +935,23 @@ pub struct Cli {
     )]
     pub remote_name: bool,
 
+    #[arg(long = "require-https", help = "Fail unless every request uses HTTPS")]
+    pub require_https: bool,
+
+    #[arg(
+        long = "resolve",
+        value_name = "HOST:PORT:ADDR",
+        conflicts_with = "unix",
+        help = "Connect to ADDR for HOST:PORT"
+    )]
+    pub resolve: Vec<String>,
+
+    #[arg(
+        long = "respect-rate-limit",
+        help = "Wait for rate limit resets between requests"
+    )]
+    pub respect_rate_limit: bool,
+
     #[arg(
         long,
         value_name = "NUM",
@@ -508,6 +968,59 @@ pub struct Cli {
     )]
     pub retry_delay: Option<f64>,
 
+    #[arg(
+        long = "retry-backoff",
+        value_name = "MULTIPLIER",
+        allow_hyphen_values = true,
+        help = "Backoff multiplier or linear [default: 2]"
+    )]
+    pub retry_backoff: Option<String>,
+
+    #[arg(
+        long = "retry-connrefused",
+        help = "Retry when the connection is refused"
+    )]
+    pub retry_connrefused: bool,
+
+    #[arg(
+        long = "retry-jitter",
+        value_name = "FRACTION",
+        allow_hyphen_values = true,
+        help = "Retry delay jitter fraction [default: 0.25]"
+    )]
+    pub retry_jitter: Option<f64>,
+
+    #[arg(
+        long = "retry-max-time",
+        value_name = "SECONDS",
+        allow_hyphen_values = true,
+        help = "Maximum total time spent retrying"
+    )]
+    pub retry_max_time: Option<f64>,
+
+    #[arg(
+        long = "retry-max-delay",
+        value_name = "SECONDS",
+        allow_hyphen_values = true,
+        help = "Maximum delay between retries [default: 30]"
+    )]
+    pub retry_max_delay: Option<f64>,
+
+    #[arg(
+        long = "retry-on-status",
+        value_name = "LIST",
+        help = "Extra status codes to retry, e.g. 500-504"
+    )]
+    pub retry_on_status: Option<String>,
+
+    #[arg(
+        long,
+        value_name = "PATH",
+        conflicts_with_all = ["article", "discard", "output", "remote_name"],
+        help = "Validate a JSON response against a schema"
+    )]
+    pub schema: Option<String>,
+
     #[arg(
         short = 'S',
         long,
@@ -534,6 +1047,34 @@ pub struct Cli {
     #[arg(long = "sort-headers", help = "Sort displayed headers by name")]
     pub sort_headers: bool,
 
+    #[arg(long = "sort-keys", help = "Sort JSON object keys when formatting")]
+    pub sort_keys: bool,
+
+    #[arg(
+        long = "split-output",
+        value_name = "PATTERN",
+        conflicts_with_all = [
+            "article",
+            "check_hosts",
+            "discard",
+            "grpc",
+            "output",
+            "remote_name",
+            "to_yaml",
+        ],
+        help = "Write each JSON array element to a file"
+    )]
+    pub split_output: Option<String>,
+
+    #[arg(
+        long,
+        value_name = "NAME",
+        value_parser = ["default", "monochrome", "solarized"],
+        hide_possible_values = true,
+        help = "Color theme [default, monochrome, solarized]"
+    )]
+    pub theme: Option<String>,
+
     #[arg(
         short = 't',
         long,
@@ -546,11 +1087,41 @@ pub struct Cli {
     #[arg(short = 'T', long, help = "Display a timing waterfall chart")]
     pub timing: bool,
 
+    #[arg(
+        long = "to-json",
+        conflicts_with_all = ["article", "discard", "output", "remote_name"],
+        help = "Convert XML or YAML responses to JSON"
+    )]
+    pub to_json: bool,
+
+    #[arg(
+        long = "to-yaml",
+        conflicts_with_all = [
+            "article",
+            "discard",
+            "extract",
+            "output",
+            "remote_name",
+            "schema",
+            "to_json",
+        ],
+        help = "Convert JSON or XML responses to YAML"
+    )]
+    pub to_yaml: bool,
+
     #[arg(long, value_name = "VERSION", hide = true)]
     pub tls: Option<String>,
 
+    #[arg(
+        long = "trace-headers-only",
+        conflicts_with = "dry_run",
+        help = "Print the request line and headers and exit"
+    )]
+    pub trace_headers_only: bool,
+
     #[arg(
         long,
+        alias = "unix-socket",
         value_name = "PATH",
         help = "Make the request over a unix socket"
     )]
@@ -570,6 +1141,9 @@ pub struct Cli {
     )]
     pub uninstall_skill: Option<String>,
 
+    #[arg(long = "unwrap-json", help = "Format JSON nested in a JSON string")]
+    pub unwrap_json: bool,
+
     #[arg(
         short = 'v',
         long = "verbose",
@@ -581,6 +1155,13 @@ pub struct Cli {
     #[arg(short = 'V', long, help = "Print version")]
     pub version: bool,
 
+    #[arg(
+        long = "write-meta",
+        conflicts_with_all = ["article", "chunk_size", "continue_at"],
+        help = "Save response metadata beside the output"
+    )]
+    pub write_meta: bool,
+
     #[arg(
         long = "ws-interactive",
         value_name = "MODE",
@@ -629,6 +1210,12 @@ impl Cli {
     pub fn retry_delay(&self) -> f64 {
         self.retry_delay.unwrap_or(1.0)
     }
+
+    /// The `--dns-server` resolvers joined with commas, in the order they are
+    /// tried.
+    pub fn dns_server(&self) -> Option<String> {
+        (!self.dns_servers.is_empty()).then(|| self.dns_servers.join(","))
+    }
 }
 
 pub fn normalize_range_values(values: &mut [String]) -> Result<(), String> {
@@ -638,6 +1225,145 @@ pub fn normalize_range_values(values: &mut [String]) -> Result<(), String> {
     Ok(())
 }
 
+/// Substitute `{NAME}` placeholders in a URL with percent-encoded
+/// `--path-param` values.
+pub fn expand_path_params(
+    url: &str,
+    params: &[String],
+    allow_unfilled: bool,
+) -> Result<String, String> {
+    let mut values = Vec::with_capacity(params.len());
+    for raw in params {
+        let Some((name, value)) = raw
+            .split_once('=')
+            .filter(|(name, _)| is_placeholder_name(name))
+        else {
+            return Err(format!(
+                "invalid value '{raw}' for option '--path-param': must be in the format NAME=VALUE"
+            ));
+        };
+        values.push((name, value));
+    }
+
+    let mut out = String::with_capacity(url.len());
+    let mut used = Vec::new();
+    let mut rest = url;
+    while let Some(open) = rest.find('{') {
+        out.push_str(&rest[..open]);
+        let after = &rest[open + 1..];
+        let Some(name) = after
+            .find('}')
+            .map(|close| &after[..close])
+            .filter(|name| is_placeholder_name(name))
+        else {
+            out.push('{');
+            rest = after;
+            continue;
+        };
+        match values.iter().rev().find(|(param, _)| *param == name) {
+            Some((_, value)) => {
+                used.push(name);
+                out.extend(utf8_percent_encode(value, PATH_PARAM_ENCODE_SET));
+            }
+            None if allow_unfilled => {
+                out.push('{');
+                out.push_str(name);
+                out.push('}');
+            }
+            None => {
+                return Err(format!(
+                    "URL placeholder '{{{name}}}' has no value; set '--path-param {name}=VALUE' or use '--allow-unfilled'"
+                ));
+            }
+        }
+        rest = &after[name.len() + 1..];
+    }
+    out.push_str(rest);
+
+    if let Some((name, _)) = values.iter().find(|(name, _)| !used.contains(name)) {
+        return Err(format!(
+            "path parameter '{name}' does not match a placeholder in the URL"
+        ));
+    }
+    Ok(out)
+}
+
+const PATH_PARAM_ENCODE_SET: &AsciiSet = &NON_ALPHANUMERIC
+    .remove(b'-')
+    .remove(b'.')
+    .remove(b'_')
+    .remove(b'~');
+
+fn is_placeholder_name(name: &str) -> bool {
+    !name.is_empty()
+        && name
+            .bytes()
+            .all(|byte| byte.is_ascii_alphanumeric() || byte == b'_' || byte == b'-')
+}
+
+/// Substitute `$NAME` and `${NAME}` references in a request body with values
+/// from `lookup`, for `--expand-env`. `$$` writes a literal `$`, and a `$`
+/// that does not start a reference is kept as is. Variables that are not set
+/// expand to an empty string and are returned, in order and without
+/// repeats, so the caller can warn about them.
+pub fn expand_env_vars(
+    input: &str,
+    lookup: impl Fn(&str) -> Option<String>,
+) -> Result<(String, Vec<String>), String> {
+    let mut out = String::with_capacity(input.len());
+    let mut missing: Vec<String> = Vec::new();
+    let mut rest = input;
+    while let Some(dollar) = rest.find('$') {
+        out.push_str(&rest[..dollar]);
+        let after = &rest[dollar + 1..];
+        let (name, next) = if let Some(braced) = after.strip_prefix('{') {
+            let Some(close) = braced.find('}') else {
+                return Err(format!(
+                    "unterminated '${{' in request body: {}",
+                    &rest[dollar..]
+                ));
+            };
+            let name = &braced[..close];
+            if !is_env_var_name(name) {
+                return Err(format!(
+                    "invalid environment variable name '{name}' in request body"
+                ));
+            }
+            (name, &braced[close + 1..])
+        } else if let Some(next) = after.strip_prefix('$') {
+            out.push('$');
+            rest = next;
+            continue;
+        } else {
+            let len = after
+                .find(|ch: char| !(ch.is_ascii_alphanumeric() || ch == '_'))
+                .unwrap_or(after.len());
+            let name = &after[..len];
+            if !is_env_var_name(name) {
+                out.push('$');
+                rest = after;
+                continue;
+            }
+            (name, &after[len..])
+        };
+        match lookup(name) {
+            Some(value) => out.push_str(&value),
+            None if !missing.iter().any(|seen| seen == name) => missing.push(name.to_string()),
+            None => {}
+        }
+        rest = next;
+    }
+    out.push_str(rest);
+    Ok((out, missing))
+}
+
+fn is_env_var_name(name: &str) -> bool {
+    name.starts_with(|ch: char| ch.is_ascii_alphabetic() || ch == '_')
+        && name
+            .bytes()
+            .all(|byte| byte.is_ascii_alphanumeric() || byte == b'_')
+}
+
 pub fn parse_http_version(value: Option<&str>) -> Result<Option<HttpVersion>, String> {
     match value {
         None => Ok(None),
@@ -653,7 +1379,7 @@ pub fn parse_http_version(value: Option<&str>) -> Result<Option<HttpVersion>, St
 pub fn selected_http_version(cli: &Cli) -> Result<Option<HttpVersion>, String> {
     if cli.http1 {
         Ok(Some(HttpVersion::Http1))
-    } else if cli.http2 {
+    } else if cli.http2 || cli.http2_prior_knowledge {
         Ok(Some(HttpVersion::Http2))
     } else if cli.http3 {
         Ok(Some(HttpVersion::Http3))
@@ -663,7 +1389,7 @@ pub fn selected_http_version(cli: &Cli) -> Result<Option<HttpVersion>, String> {
 }
 
 pub fn has_http_version_flag(cli: &Cli) -> bool {
-    cli.http.is_some() || cli.http1 || cli.http2 || cli.http3
+    cli.http.is_some() || cli.http1 || cli.http2 || cli.http2_prior_knowledge || cli.http3
 }
 
 pub fn http_version_flag_name(cli: &Cli) -> Option<&'static str> {
@@ -673,6 +1399,8 @@ pub fn http_version_flag_name(cli: &Cli) -> Option<&'static str> {
         Some("http1")
     } else if cli.http2 {
         Some("http2")
+    } else if cli.http2_prior_knowledge {
+        Some("http2-prior-knowledge")
     } else if cli.http3 {
         Some("http3")
     } else {
@@ -722,7 +1450,8 @@ mod tests {
         command.write_help(&mut output).unwrap();
         let help = String::from_utf8(output).unwrap();
 
-        assert!(help.contains("[URL]  The URL to make a request to"));
+        assert!(help.contains("[URL]      The URL to make a request to"));
+        assert!(help.contains("[ITEM]...  JSON body KEY=VALUE or query KEY==VALUE"));
         assert!(
             help.contains("--aws-sigv4 <REGION/SERVICE>  Sign the request using AWS signature V4")
         );
@@ -746,7 +1475,7 @@ mod tests {
             )
         );
         assert!(!help.contains("--http1"));
-        assert!(!help.contains("--http2"));
+        assert!(!help.contains("--http2 "));
         assert!(!help.contains("--http3"));
 
         for line in help.lines() {
@@ -832,6 +1561,86 @@ mod tests {
         assert!(err.contains("invalid range end '-1'"));
     }
 
+    #[test]
+    fn path_params_fill_and_encode_url_placeholders() {
+        let params = ["id=42".to_string(), "slug=a b/c".to_string()];
+
+        let url = expand_path_params(
+            "https://api.example/users/{id}/posts/{slug}",
+            &params,
+            false,
+        )
+        .unwrap();
+
+        assert_eq!(url, "https://api.example/users/42/posts/a%20b%2Fc");
+        assert_eq!(
+            expand_path_params("https://api.example/{id}?q={a b}", &params[..1], false).unwrap(),
+            "https://api.example/42?q={a b}"
+        );
+    }
+
+    #[test]
+    fn path_params_report_unfilled_and_unused_names() {
+        let params = ["id=42".to_string()];
+
+        let err =
+            expand_path_params("https://api.example/{id}/{rest}", &params, false).unwrap_err();
+        assert_eq!(
+            err,
+            "URL placeholder '{rest}' has no value; set '--path-param rest=VALUE' or use '--allow-unfilled'"
+        );
+        assert_eq!(
+            expand_path_params("https://api.example/{id}/{rest}", &params, true).unwrap(),
+            "https://api.example/42/{rest}"
+        );
+
+        let err = expand_path_params("https://api.example/", &params, false).unwrap_err();
+        assert_eq!(
+            err,
+            "path parameter 'id' does not match a placeholder in the URL"
+        );
+
+        let err =
+            expand_path_params("https://api.example/{id}", &["id".to_string()], false).unwrap_err();
+        assert_eq!(
+            err,
+            "invalid value 'id' for option '--path-param': must be in the format NAME=VALUE"
+        );
+    }
+
+    #[test]
+    fn expand_env_vars_substitutes_and_reports_missing_names() {
+        let lookup = |name: &str| match name {
+            "TOKEN" => Some("abc".to_string()),
+            "USER_ID" => Some("42".to_string()),
+            _ => None,
+        };
+
+        let (out, missing) = expand_env_vars(
+            r#"{"token":"$TOKEN","id":${USER_ID},"cost":"$$5","x":"$MISSING$MISSING"}"#,
+            lookup,
+        )
+        .unwrap();
+        assert_eq!(out, r#"{"token":"abc","id":42,"cost":"$5","x":""}"#);
+        assert_eq!(missing, ["MISSING"]);
+
+        let (out, missing) = expand_env_vars("a $ b $1 ${USER_ID}x $", lookup).unwrap();
+        assert_eq!(out, "a $ b $1 42x $");
+        assert!(missing.is_empty());
+    }
+
+    #[test]
+    fn expand_env_vars_rejects_malformed_braces() {
+        assert_eq!(
+            expand_env_vars("x ${TOKEN", |_| None).unwrap_err(),
+            "unterminated '${' in request body: ${TOKEN"
+        );
+        assert_eq!(
+            expand_env_vars("${1A}", |_| None).unwrap_err(),
+            "invalid environment variable name '1A' in request body"
+        );
+    }
+
     #[test]
     fn timeout_flags_accept_negative_values_for_validation() {
         let cli = Cli::try_parse_from([
@@ -870,6 +1679,7 @@ mod tests {
         let tests = [
             ("--http1", Some(HttpVersion::Http1)),
             ("--http2", Some(HttpVersion::Http2)),
+            ("--http2-prior-knowledge", Some(HttpVersion::Http2)),
             ("--http3", Some(HttpVersion::Http3)),
         ];
 
@@ -889,4 +1699,77 @@ mod tests {
             "invalid value '1.1' for option '--http': must be one of [1, 2, 3]"
         );
     }
+
+    #[test]
+    fn progress_flag_accepts_every_progress_mode_value() {
+        for value in ProgressMode::VALUES {
+            let cli =
+                Cli::try_parse_from(["fetch", "--progress", value, "http://example.com"]).unwrap();
+            assert!(ProgressMode::from_value(cli.progress.as_deref().unwrap()).is_some());
+        }
+        assert!(
+            Cli::try_parse_from(["fetch", "--progress", "dots", "http://example.com"]).is_err()
+        );
+    }
+
+    #[test]
+    fn check_hosts_conflicts_with_response_body_flags() {
+        for args in [
+            &["--extract", "id"][..],
+            &["--minify"],
+            &["--schema", "schema.json"],
+            &["--to-json"],
+            &["--to-yaml"],
+            &["--split-output", "item-{index}.json"],
+            &["--fail-on-empty"],
+            &["--fail-on-truncation"],
+            &["--hex"],
+            &["--raw"],
+            &["--write-meta"],
+            &["--decode-jwt"],
+            &["--unwrap-json"],
+            &["--sort-keys"],
+            &["--line-numbers"],
+            &["--checksum", "sha256"],
+            &["--continue-at", "auto"],
+            &["--output-dir", "out"],
+        ] {
+            let err = Cli::try_parse_from(
+                ["fetch", "--check-hosts", "@hosts.txt"]
+                    .into_iter()
+                    .chain(args.iter().copied()),
+            )
+            .unwrap_err();
+            assert_eq!(
+                err.kind(),
+                clap::error::ErrorKind::ArgumentConflict,
+                "{args:?}"
+            );
+        }
+    }
+
+    #[test]
+    fn raw_conflicts_with_body_transforming_flags() {
+        for args in [
+            &["--extract", "id"][..],
+            &["--minify"],
+            &["--to-json"],
+            &["--to-yaml"],
+            &["--unwrap-json"],
+            &["--decode-jwt"],
+            &["--line-numbers"],
+        ] {
+            let err = Cli::try_parse_from(
+                ["fetch", "--raw", "example.com"]
+                    .into_iter()
+                    .chain(args.iter().copied()),
+            )
+            .unwrap_err();
+            assert_eq!(
+                err.kind(),
+                clap::error::ErrorKind::ArgumentConflict,
+                "{args:?}"
+            );
+        }
+    }
 }
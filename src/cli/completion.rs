@@ -54,6 +54,10 @@ const COMPLETE_VALUES: &[FlagValue] = &[
         value: "",
     },
 ];
+const CONTINUE_AT_VALUES: &[FlagValue] = &[FlagValue {
+    key: "auto",
+    value: "Continue from the size of the existing file",
+}];
 const COMPRESS_VALUES: &[FlagValue] = &[
     FlagValue {
         key: "auto",
@@ -80,6 +84,16 @@ const COMPRESS_VALUES: &[FlagValue] = &[
         value: "Disable compression negotiation",
     },
 ];
+const EXPAND_ENV_VALUES: &[FlagValue] = &[
+    FlagValue {
+        key: "inline",
+        value: "Expand inline --data, --json, and --xml values",
+    },
+    FlagValue {
+        key: "files",
+        value: "Also expand @file contents",
+    },
+];
 const AGENT_VALUES: &[FlagValue] = &[
     FlagValue {
         key: "agents",
@@ -144,6 +158,24 @@ const PAGER_VALUES: &[FlagValue] = &[
         value: "Disable pager",
     },
 ];
+const PROGRESS_VALUES: &[FlagValue] = &[
+    FlagValue {
+        key: "auto",
+        value: "Choose a bar or spinner automatically",
+    },
+    FlagValue {
+        key: "bar",
+        value: "Use a bar when the size is known",
+    },
+    FlagValue {
+        key: "spinner",
+        value: "Always use a spinner",
+    },
+    FlagValue {
+        key: "none",
+        value: "Disable the progress display",
+    },
+];
 const HTTP_VALUES: &[FlagValue] = &[
     FlagValue {
         key: "1",
@@ -172,6 +204,20 @@ const IMAGE_VALUES: &[FlagValue] = &[
         value: "Disable image display",
     },
 ];
+const THEME_VALUES: &[FlagValue] = &[
+    FlagValue {
+        key: "default",
+        value: "Standard terminal colors",
+    },
+    FlagValue {
+        key: "monochrome",
+        value: "Bold and dim text without colors",
+    },
+    FlagValue {
+        key: "solarized",
+        value: "Solarized accent colors",
+    },
+];
 const TLS_VALUES: &[FlagValue] = &[
     FlagValue {
         key: "1.2",
@@ -198,6 +244,18 @@ const WS_INTERACTIVE_VALUES: &[FlagValue] = &[
 ];
 
 const FLAGS: &[Flag] = &[
+    flag(
+        None,
+        "accept-fallback",
+        "TYPES",
+        "Retry a 406 with broader Accept types",
+    ),
+    flag(
+        None,
+        "allow-unfilled",
+        "",
+        "Leave unmatched {NAME} URL placeholders",
+    ),
     flag(
         None,
         "article",
@@ -217,9 +275,36 @@ const FLAGS: &[Flag] = &[
         "Enable HTTP basic authentication",
     ),
     flag(None, "bearer", "TOKEN", "Enable HTTP bearer authentication"),
+    flag(None, "body-only", "", "Print only the body and errors"),
     flag(None, "buildinfo", "", "Print the build information"),
     flag(None, "ca-cert", "PATH", "CA certificate file path"),
+    flag(None, "ca-path", "DIR", "Directory of CA certificate files"),
     flag(None, "cert", "PATH", "Client certificate for mTLS"),
+    flag(
+        None,
+        "check-hosts",
+        "@FILE",
+        "Check status and latency of listed URLs",
+    ),
+    flag(
+        None,
+        "checksum",
+        "DIGEST",
+        "Verify or print the response body digest",
+    ),
+    flag(
+        None,
+        "checksum-verify",
+        "HEX",
+        "Fail unless the --checksum digest matches",
+    ),
+    flag(None, "chunk-size", "BYTES", "Download in ranges of BYTES"),
+    flag(
+        None,
+        "ciphers",
+        "LIST",
+        "Allowed TLS cipher suites, colon-separated",
+    ),
     flag(None, "clobber", "", "Overwrite existing output file"),
     Flag {
         short: None,
@@ -252,8 +337,24 @@ const FLAGS: &[Flag] = &[
         "SECONDS",
         "Timeout for connection establishment",
     ),
+    flag(
+        None,
+        "connect-to",
+        "ROUTE",
+        "Connect via HOST:PORT:HOST2:PORT2 route",
+    ),
+    Flag {
+        short: Some('C'),
+        long: "continue-at",
+        args: "MODE",
+        description: "Resume into an existing file",
+        aliases: &[],
+        values: CONTINUE_AT_VALUES,
+    },
     flag(None, "copy", "", "Copy the response body to clipboard"),
+    flag(None, "curl", "", "Print the request as a curl command"),
     flag(Some('d'), "data", "[@]VALUE", "Send a request body"),
+    flag(None, "decode-jwt", "", "Decode JWTs in the output"),
     flag(
         None,
         "digest",
@@ -275,6 +376,33 @@ const FLAGS: &[Flag] = &[
         "",
         "Use an editor to modify the request body",
     ),
+    Flag {
+        short: None,
+        long: "expand-env",
+        args: "SCOPE",
+        description: "Expand $VARS in the request body",
+        aliases: &[],
+        values: EXPAND_ENV_VALUES,
+    },
+    flag(None, "extract", "PATH", "Print the JSON value at a path"),
+    flag(
+        None,
+        "extract-all",
+        "",
+        "Print every value matched by --extract",
+    ),
+    flag(
+        None,
+        "fail-on-empty",
+        "",
+        "Fail if a 2xx response has an empty body",
+    ),
+    flag(
+        None,
+        "fail-on-truncation",
+        "",
+        "Fail if the response body is truncated",
+    ),
     flag(
         Some('f'),
         "form",
@@ -317,7 +445,26 @@ const FLAGS: &[Flag] = &[
         "NAME:VALUE",
         "Set headers for the request",
     ),
+    flag(
+        None,
+        "h2-initial-window-size",
+        "BYTES",
+        "HTTP/2 initial stream window size",
+    ),
+    flag(
+        None,
+        "h2-max-concurrent-streams",
+        "NUM",
+        "HTTP/2 max concurrent streams to advertise",
+    ),
+    flag(
+        None,
+        "h2-max-frame-size",
+        "BYTES",
+        "HTTP/2 max frame size to advertise",
+    ),
     flag(Some('h'), "help", "", "Print help"),
+    flag(None, "hex", "", "Print the response body as a hexdump"),
     Flag {
         short: None,
         long: "http",
@@ -328,7 +475,19 @@ const FLAGS: &[Flag] = &[
     },
     flag(None, "http1", "", "Force HTTP/1.1"),
     flag(None, "http2", "", "Force HTTP/2"),
+    flag(
+        None,
+        "http2-prior-knowledge",
+        "",
+        "Use HTTP/2 without upgrade, including h2c",
+    ),
     flag(None, "http3", "", "Force HTTP/3"),
+    flag(
+        None,
+        "idempotency-key",
+        "",
+        "Set an Idempotency-Key (random if empty)",
+    ),
     flag(
         None,
         "ignore-status",
@@ -347,7 +506,49 @@ const FLAGS: &[Flag] = &[
     flag(None, "inspect-dns", "", "Inspect DNS resolution"),
     flag(None, "inspect-tls", "", "Inspect the TLS certificate chain"),
     flag(Some('j'), "json", "[@]VALUE", "Send a JSON request body"),
+    flag(
+        None,
+        "json-merge",
+        "PATH",
+        "Merge the --json body into a JSON file",
+    ),
+    flag(
+        None,
+        "keepalive",
+        "SECONDS",
+        "TCP keep-alive idle time (0 disables)",
+    ),
     flag(None, "key", "PATH", "Client private key for mTLS"),
+    flag(
+        None,
+        "line-numbers",
+        "",
+        "Number the lines of formatted output",
+    ),
+    flag(
+        None,
+        "interface",
+        "NAME|IP",
+        "Bind to a network interface or local IP",
+    ),
+    flag(
+        None,
+        "local-port",
+        "LOW-HIGH",
+        "Bind to a local port in a range",
+    ),
+    flag(
+        None,
+        "max-decompress-ratio",
+        "N",
+        "Limit how far a body may expand on decode",
+    ),
+    flag(
+        None,
+        "max-headers",
+        "BYTES",
+        "Limit response headers to BYTES",
+    ),
     Flag {
         short: None,
         long: "max-tls",
@@ -372,12 +573,32 @@ const FLAGS: &[Flag] = &[
         aliases: &[],
         values: TLS_VALUES,
     },
+    flag(None, "minify", "", "Minify HTML and CSS responses"),
     flag(
         Some('F'),
         "multipart",
         "NAME=[@]VALUE",
         "Send a multipart form body",
     ),
+    flag(None, "netrc", "", "Read credentials from ~/.netrc"),
+    flag(
+        None,
+        "netrc-file",
+        "PATH",
+        "Read credentials from a netrc file",
+    ),
+    flag(
+        None,
+        "no-keepalive",
+        "",
+        "Disable TCP keep-alive and connection reuse",
+    ),
+    flag(
+        None,
+        "no-formatter-delegation",
+        "",
+        "Print Markdown code blocks verbatim",
+    ),
     Flag {
         short: None,
         long: "pager",
@@ -392,6 +613,26 @@ const FLAGS: &[Flag] = &[
         "PATH",
         "Write the response body to a file",
     ),
+    flag(
+        None,
+        "output-dir",
+        "DIR",
+        "Save --remote-name downloads under DIR",
+    ),
+    flag(
+        None,
+        "path-param",
+        "NAME=VALUE",
+        "Fill a {NAME} placeholder in the URL",
+    ),
+    Flag {
+        short: None,
+        long: "progress",
+        args: "STYLE",
+        description: "Progress style",
+        aliases: &[],
+        values: PROGRESS_VALUES,
+    },
     flag(
         None,
         "proto-desc",
@@ -411,13 +652,21 @@ const FLAGS: &[Flag] = &[
         "Import path for proto compilation",
     ),
     flag(None, "proxy", "PROXY", "Configure a proxy"),
+    flag(
+        None,
+        "proxy-user",
+        "USER:PASS",
+        "Set credentials for the proxy",
+    ),
     flag(
         Some('q'),
         "query",
         "KEY=VALUE",
         "Append query parameters to the url",
     ),
+    flag(None, "quiet-insecure", "", "Hide the --insecure warning"),
     flag(Some('r'), "range", "RANGE", "Request a specific byte range"),
+    flag(None, "raw", "", "Print the body exactly as received"),
     flag(None, "redirects", "NUM", "Maximum number of redirects"),
     flag(
         Some('J'),
@@ -433,13 +682,73 @@ const FLAGS: &[Flag] = &[
         aliases: &["output-current-dir"],
         values: EMPTY_VALUES,
     },
+    flag(
+        None,
+        "require-https",
+        "",
+        "Fail unless every request uses HTTPS",
+    ),
+    flag(
+        None,
+        "resolve",
+        "HOST:PORT:ADDR",
+        "Connect to ADDR for HOST:PORT",
+    ),
+    flag(
+        None,
+        "respect-rate-limit",
+        "",
+        "Wait for rate limit resets between requests",
+    ),
     flag(None, "retry", "NUM", "Maximum number of retries"),
+    flag(
+        None,
+        "retry-backoff",
+        "MULTIPLIER",
+        "Backoff multiplier or linear",
+    ),
+    flag(
+        None,
+        "retry-connrefused",
+        "",
+        "Retry when the connection is refused",
+    ),
     flag(
         None,
         "retry-delay",
         "SECONDS",
         "Initial delay between retries",
     ),
+    flag(
+        None,
+        "retry-jitter",
+        "FRACTION",
+        "Retry delay jitter fraction",
+    ),
+    flag(
+        None,
+        "retry-max-delay",
+        "SECONDS",
+        "Maximum delay between retries",
+    ),
+    flag(
+        None,
+        "retry-max-time",
+        "SECONDS",
+        "Maximum total time spent retrying",
+    ),
+    flag(
+        None,
+        "retry-on-status",
+        "LIST",
+        "Extra status codes to retry",
+    ),
+    flag(
+        None,
+        "schema",
+        "PATH",
+        "Validate a JSON response against a schema",
+    ),
     flag(
         Some('S'),
         "session",
@@ -457,6 +766,26 @@ const FLAGS: &[Flag] = &[
     },
     flag(None, "skill", "", "Print the bundled SKILL.md"),
     flag(None, "sort-headers", "", "Sort displayed headers by name"),
+    flag(
+        None,
+        "sort-keys",
+        "",
+        "Sort JSON object keys when formatting",
+    ),
+    flag(
+        None,
+        "split-output",
+        "PATTERN",
+        "Write each JSON array element to a file",
+    ),
+    Flag {
+        short: None,
+        long: "theme",
+        args: "NAME",
+        description: "Color theme",
+        aliases: &[],
+        values: THEME_VALUES,
+    },
     flag(
         Some('t'),
         "timeout",
@@ -464,7 +793,22 @@ const FLAGS: &[Flag] = &[
         "Timeout applied to the request",
     ),
     flag(Some('T'), "timing", "", "Display a timing waterfall chart"),
-    flag(None, "unix", "PATH", "Make the request over a unix socket"),
+    flag(None, "to-json", "", "Convert XML or YAML responses to JSON"),
+    flag(None, "to-yaml", "", "Convert JSON or XML responses to YAML"),
+    flag(
+        None,
+        "trace-headers-only",
+        "",
+        "Print the request line and headers and exit",
+    ),
+    Flag {
+        short: None,
+        long: "unix",
+        args: "PATH",
+        description: "Make the request over a unix socket",
+        aliases: &["unix-socket"],
+        values: EMPTY_VALUES,
+    },
     flag(None, "update", "", "Update the fetch binary in place"),
     Flag {
         short: None,
@@ -474,8 +818,20 @@ const FLAGS: &[Flag] = &[
         aliases: &[],
         values: AGENT_VALUES,
     },
+    flag(
+        None,
+        "unwrap-json",
+        "",
+        "Format JSON nested in a JSON string",
+    ),
     flag(Some('v'), "verbose", "", "Verbosity of the output"),
     flag(Some('V'), "version", "", "Print version"),
+    flag(
+        None,
+        "write-meta",
+        "",
+        "Save response metadata beside the output",
+    ),
     Flag {
         short: None,
         long: "ws-interactive",
@@ -716,8 +1072,9 @@ fn complete_value(flag: &Flag, prefix: &str, value: &str) -> Vec<Completion> {
     }
 
     match flag.long {
-        "ca-cert" | "cert" | "config" | "key" | "output" | "proto-desc" | "proto-file"
-        | "proto-import" | "unix" => complete_path(prefix, value),
+        "ca-cert" | "ca-path" | "cert" | "config" | "json-merge" | "key" | "netrc-file"
+        | "output" | "output-dir" | "proto-desc" | "proto-file" | "proto-import" | "schema"
+        | "split-output" | "unix" => complete_path(prefix, value),
         "data" | "json" | "xml" => value
             .strip_prefix('@')
             .map(|path| complete_path(&format!("{prefix}@"), path))
@@ -24,11 +24,17 @@ pub struct ParsedCurl {
     pub url: String,
     pub method: String,
     pub headers: Vec<Header>,
+    /// Files named by `-H @FILE`, each holding one header per line. They are
+    /// read when the command is applied, not while parsing.
+    pub header_files: Vec<String>,
     pub data_values: Vec<DataValue>,
     pub basic_auth: String,
     pub digest_auth: bool,
     pub aws_sigv4: String,
     pub bearer: String,
+    /// Set by -n/--netrc and --netrc-optional.
+    pub netrc: bool,
+    pub netrc_file: String,
     pub form_fields: Vec<FormField>,
     pub upload_file: String,
     pub head: bool,
@@ -36,23 +42,44 @@ pub struct ParsedCurl {
     pub output: String,
     pub remote_name: bool,
     pub remote_header_name: bool,
+    /// Set by `-C -`, which resumes from the size of the existing output.
+    pub continue_at: bool,
     pub follow_redirects: bool,
+    /// Set by --location-trusted, which keeps credentials on cross-host
+    /// redirects.
+    pub location_trusted: bool,
     pub max_redirects: usize,
     pub max_redirects_set: bool,
+    /// --max-filesize in bytes; 0 when unset.
+    pub max_file_size: u64,
     pub timeout: f64,
     pub connect_timeout: f64,
+    /// --keepalive-time in seconds; 0 when unset.
+    pub keepalive_time: f64,
+    pub no_keepalive: bool,
     pub proxy: String,
+    pub proxy_user: String,
     pub doh_url: String,
     pub http_version: String,
     pub tls_max_version: String,
     pub tls_version: String,
+    /// --ciphers list, which curl applies to TLS 1.2 and below.
+    pub ciphers: String,
+    /// --tls13-ciphers list.
+    pub tls13_ciphers: String,
     pub ca_cert: String,
+    pub ca_path: String,
     pub cert: String,
     pub key: String,
     pub unix_socket: String,
+    /// "4" or "6" from curl's -4/--ipv4 or -6/--ipv6. fetch has no matching
+    /// option yet, so the value is recorded but not applied.
+    pub ip_version: String,
     pub ranges: Vec<String>,
     pub retry: usize,
     pub retry_delay: f64,
+    pub retry_connrefused: bool,
+    pub retry_max_time: f64,
     pub get_flag: bool,
     pub verbose: u8,
     pub silent: bool,
@@ -63,6 +90,7 @@ pub struct ParsedCurl {
     pub has_accept: bool,
     pub allowed_proto: String,
     pub ech: String,
+    pub progress: String,
     json_defaults: bool,
 }
 
@@ -274,9 +302,7 @@ fn parse_long_flag(
         }
         "header" => {
             let (value, consumed) = consume_arg(name)?;
-            let header = parse_header(&value)?;
-            remember_header_flags(parsed, &header);
-            parsed.headers.push(header);
+            push_header(parsed, value)?;
             Ok(consumed)
         }
         "url" => {
@@ -354,6 +380,15 @@ fn parse_long_flag(
             parsed.digest_auth = true;
             Ok(0)
         }
+        "netrc" | "netrc-optional" => {
+            parsed.netrc = true;
+            Ok(0)
+        }
+        "netrc-file" => {
+            let (value, consumed) = consume_arg(name)?;
+            parsed.netrc_file = value;
+            Ok(consumed)
+        }
         "insecure" => {
             parsed.insecure = true;
             Ok(0)
@@ -363,6 +398,11 @@ fn parse_long_flag(
             parsed.ca_cert = value;
             Ok(consumed)
         }
+        "capath" => {
+            let (value, consumed) = consume_arg(name)?;
+            parsed.ca_path = value;
+            Ok(consumed)
+        }
         "cert" => {
             let (value, consumed) = consume_arg(name)?;
             parsed.cert = value;
@@ -394,6 +434,16 @@ fn parse_long_flag(
             parsed.tls_max_version = value;
             Ok(consumed)
         }
+        "ciphers" => {
+            let (value, consumed) = consume_arg(name)?;
+            parsed.ciphers = value;
+            Ok(consumed)
+        }
+        "tls13-ciphers" => {
+            let (value, consumed) = consume_arg(name)?;
+            parsed.tls13_ciphers = value;
+            Ok(consumed)
+        }
         "output" => {
             let (value, consumed) = consume_arg(name)?;
             parsed.output = value;
@@ -407,10 +457,25 @@ fn parse_long_flag(
             parsed.remote_header_name = true;
             Ok(0)
         }
+        "continue-at" => {
+            let (value, consumed) = consume_arg(name)?;
+            parsed.continue_at = parse_continue_at("--continue-at", &value)?;
+            Ok(consumed)
+        }
         "location" => {
             parsed.follow_redirects = true;
             Ok(0)
         }
+        "location-trusted" => {
+            parsed.follow_redirects = true;
+            parsed.location_trusted = true;
+            Ok(0)
+        }
+        "max-filesize" => {
+            let (value, consumed) = consume_arg(name)?;
+            parsed.max_file_size = parse_file_size("--max-filesize", &value)?;
+            Ok(consumed)
+        }
         "max-redirs" => {
             let (value, consumed) = consume_arg(name)?;
             parsed.max_redirects = parse_nonnegative_usize("--max-redirs", &value)?;
@@ -427,11 +492,25 @@ fn parse_long_flag(
             parsed.connect_timeout = parse_nonnegative_f64("--connect-timeout", &value)?;
             Ok(consumed)
         }
+        "keepalive-time" => {
+            let (value, consumed) = consume_arg(name)?;
+            parsed.keepalive_time = parse_nonnegative_f64("--keepalive-time", &value)?;
+            Ok(consumed)
+        }
+        "no-keepalive" => {
+            parsed.no_keepalive = true;
+            Ok(0)
+        }
         "proxy" => {
             let (value, consumed) = consume_arg(name)?;
             parsed.proxy = value;
             Ok(consumed)
         }
+        "proxy-user" => {
+            let (value, consumed) = consume_arg(name)?;
+            parsed.proxy_user = value;
+            Ok(consumed)
+        }
         "unix-socket" => {
             let (value, consumed) = consume_arg(name)?;
             parsed.unix_socket = value;
@@ -452,6 +531,15 @@ fn parse_long_flag(
             parsed.retry_delay = parse_nonnegative_f64("--retry-delay", &value)?;
             Ok(consumed)
         }
+        "retry-max-time" => {
+            let (value, consumed) = consume_arg(name)?;
+            parsed.retry_max_time = parse_nonnegative_f64("--retry-max-time", &value)?;
+            Ok(consumed)
+        }
+        "retry-connrefused" => {
+            parsed.retry_connrefused = true;
+            Ok(0)
+        }
         "range" => {
             let (value, consumed) = consume_arg(name)?;
             parsed.ranges.push(value);
@@ -474,6 +562,10 @@ fn parse_long_flag(
             parsed.http_version = "2".to_string();
             Ok(0)
         }
+        "http2-prior-knowledge" => {
+            parsed.http_version = "2-prior-knowledge".to_string();
+            Ok(0)
+        }
         "http3" => {
             parsed.http_version = "3".to_string();
             Ok(0)
@@ -502,8 +594,23 @@ fn parse_long_flag(
             parsed.silent = true;
             Ok(0)
         }
-        name if long_flag_matches_fetch_default(name) => Ok(0),
-        name if long_flag_is_curl_presentation_only(name) => Ok(0),
+        name if long_flag_matches_fetch_default(name) || long_flag_is_ignored(name) => Ok(0),
+        "ipv4" => {
+            parsed.ip_version = "4".to_string();
+            Ok(0)
+        }
+        "ipv6" => {
+            parsed.ip_version = "6".to_string();
+            Ok(0)
+        }
+        "progress-bar" => {
+            parsed.progress = "bar".to_string();
+            Ok(0)
+        }
+        "no-progress-meter" => {
+            parsed.progress = "none".to_string();
+            Ok(0)
+        }
         "proto" => {
             let (value, consumed) = consume_arg(name)?;
             parsed.allowed_proto = value;
@@ -516,18 +623,20 @@ fn parse_long_flag(
 fn long_flag_matches_fetch_default(name: &str) -> bool {
     matches!(
         name,
-        "compressed" | "fail-with-body" | "no-keepalive" | "show-error"
+        "compressed" | "fail-with-body" | "globoff" | "show-error" | "tcp-nodelay"
     )
 }
 
-fn long_flag_is_curl_presentation_only(name: &str) -> bool {
-    matches!(name, "no-progress-meter" | "progress-bar")
+/// Flags that only tune connection details fetch manages itself, or that
+/// apply to non-HTTP protocols, and so can be dropped without changing the
+/// request.
+fn long_flag_is_ignored(name: &str) -> bool {
+    matches!(name, "compressed-ssh" | "no-alpn" | "no-sessionid")
 }
 
 fn unsupported_semantic_long_flag(name: &str) -> Option<String> {
     match name {
         "fail" => Some(unsupported_fail_flag("--fail")),
-        "netrc" => Some(unsupported_netrc_flag("--netrc")),
         "no-buffer" => Some(unsupported_no_buffer_flag("--no-buffer")),
         "proto-default" => Some(
             "curl --proto-default is not supported by --from-curl; specify the URL scheme explicitly"
@@ -542,17 +651,12 @@ fn unsupported_semantic_long_flag(name: &str) -> Option<String> {
 }
 
 fn short_flag_matches_fetch_default(flag: char) -> bool {
-    matches!(flag, 'S')
-}
-
-fn short_flag_is_curl_presentation_only(flag: char) -> bool {
-    matches!(flag, '#')
+    matches!(flag, 'S' | 'g')
 }
 
 fn unsupported_semantic_short_flag(flag: char) -> Option<String> {
     match flag {
         'f' => Some(unsupported_fail_flag("-f")),
-        'n' => Some(unsupported_netrc_flag("-n/--netrc")),
         'N' => Some(unsupported_no_buffer_flag("-N/--no-buffer")),
         _ => None,
     }
@@ -564,12 +668,6 @@ fn unsupported_fail_flag(flag: &str) -> String {
     )
 }
 
-fn unsupported_netrc_flag(flag: &str) -> String {
-    format!(
-        "curl {flag} is not supported by --from-curl; use --basic USER:PASS, --bearer TOKEN, or an explicit Authorization header (-H 'Authorization: ...') instead"
-    )
-}
-
 fn unsupported_no_buffer_flag(flag: &str) -> String {
     format!(
         "curl {flag} is not supported by --from-curl; fetch does not implement curl's unbuffered output mode"
@@ -605,9 +703,7 @@ fn parse_short_flags(
             }
             'H' => {
                 let (value, consumed) = consume_arg(flag)?;
-                let header = parse_header(&value)?;
-                remember_header_flags(parsed, &header);
-                parsed.headers.push(header);
+                push_header(parsed, value)?;
                 total += consumed;
             }
             'd' => {
@@ -649,6 +745,11 @@ fn parse_short_flags(
                 parsed.proxy = value;
                 total += consumed;
             }
+            'U' => {
+                let (value, consumed) = consume_arg(flag)?;
+                parsed.proxy_user = value;
+                total += consumed;
+            }
             'm' => {
                 let (value, consumed) = consume_arg(flag)?;
                 parsed.timeout = parse_nonnegative_f64("-m", &value)?;
@@ -659,6 +760,11 @@ fn parse_short_flags(
                 parsed.ranges.push(value);
                 total += consumed;
             }
+            'C' => {
+                let (value, consumed) = consume_arg(flag)?;
+                parsed.continue_at = parse_continue_at("-C", &value)?;
+                total += consumed;
+            }
             'A' => {
                 let (value, consumed) = consume_arg(flag)?;
                 parsed.user_agent = value;
@@ -677,6 +783,7 @@ fn parse_short_flags(
             }
             'I' => parsed.head = true,
             'k' => parsed.insecure = true,
+            'n' => parsed.netrc = true,
             'O' => parsed.remote_name = true,
             'J' => parsed.remote_header_name = true,
             'L' => parsed.follow_redirects = true,
@@ -684,8 +791,10 @@ fn parse_short_flags(
             'v' => parsed.verbose = parsed.verbose.saturating_add(1),
             's' => parsed.silent = true,
             '0' => parsed.http_version = "1.0".to_string(),
+            '4' => parsed.ip_version = "4".to_string(),
+            '6' => parsed.ip_version = "6".to_string(),
             flag if short_flag_matches_fetch_default(flag) => {}
-            flag if short_flag_is_curl_presentation_only(flag) => {}
+            '#' => parsed.progress = "bar".to_string(),
             flag => {
                 if let Some(message) = unsupported_semantic_short_flag(flag) {
                     return Err(message);
@@ -717,6 +826,34 @@ fn parse_nonnegative_usize(flag: &str, value: &str) -> Result<usize, String> {
         .map_err(|_| format!("invalid {flag} value: {value}"))
 }
 
+/// Parse a curl byte count with an optional binary `k`, `M`, or `G` suffix.
+fn parse_file_size(flag: &str, value: &str) -> Result<u64, String> {
+    let invalid = || format!("invalid {flag} value: {value}");
+    let (digits, multiplier) = match value.char_indices().last() {
+        Some((idx, 'k' | 'K')) => (&value[..idx], 1 << 10),
+        Some((idx, 'm' | 'M')) => (&value[..idx], 1 << 20),
+        Some((idx, 'g' | 'G')) => (&value[..idx], 1 << 30),
+        _ => (value, 1),
+    };
+    if digits.is_empty() || !digits.bytes().all(|byte| byte.is_ascii_digit()) {
+        return Err(invalid());
+    }
+    digits
+        .parse::<u64>()
+        .ok()
+        .and_then(|size| size.checked_mul(multiplier))
+        .ok_or_else(invalid)
+}
+
+fn parse_continue_at(flag: &str, value: &str) -> Result<bool, String> {
+    if value == "-" {
+        return Ok(true);
+    }
+    Err(format!(
+        "curl {flag} {value} is not supported by --from-curl; use '{flag} -' to resume from the size of the existing output file"
+    ))
+}
+
 fn parse_nonnegative_f64(flag: &str, value: &str) -> Result<f64, String> {
     let parsed = value
         .parse::<f64>()
@@ -727,7 +864,18 @@ fn parse_nonnegative_f64(flag: &str, value: &str) -> Result<f64, String> {
     Ok(parsed)
 }
 
-fn parse_header(value: &str) -> Result<Header, String> {
+fn push_header(parsed: &mut ParsedCurl, value: String) -> Result<(), String> {
+    if let Some(path) = value.strip_prefix('@') {
+        parsed.header_files.push(path.to_string());
+        return Ok(());
+    }
+    let header = parse_header(&value)?;
+    remember_header_flags(parsed, &header);
+    parsed.headers.push(header);
+    Ok(())
+}
+
+pub fn parse_header(value: &str) -> Result<Header, String> {
     let (name, value) = value
         .split_once(':')
         .ok_or_else(|| format!("invalid header: {value:?}"))?;
@@ -908,6 +1056,17 @@ mod tests {
             }]
         );
 
+        let parsed = parse(
+            r#"curl -H @headers.txt --header @~/more.txt -H "X-Test: value" https://example.com"#,
+        )
+        .unwrap();
+        assert_eq!(parsed.header_files, vec!["headers.txt", "~/more.txt"]);
+        assert_eq!(parsed.headers.len(), 1);
+        assert_eq!(
+            parse("curl -H X-Test https://example.com").unwrap_err(),
+            r#"invalid header: "X-Test""#
+        );
+
         let parsed = parse(r#"curl --request=PUT --url https://example.com"#).unwrap();
         assert_eq!(parsed.method, "PUT");
         assert_eq!(parsed.url, "https://example.com");
@@ -939,15 +1098,50 @@ mod tests {
         let parsed =
             parse(r#"curl --aws-sigv4 "aws:amz:us-east-1:s3" https://example.com"#).unwrap();
         assert_eq!(parsed.aws_sigv4, "aws:amz:us-east-1:s3");
+
+        let parsed = parse("curl -x proxy:8080 -U puser:ppass https://example.com").unwrap();
+        assert_eq!(parsed.proxy, "proxy:8080");
+        assert_eq!(parsed.proxy_user, "puser:ppass");
+        let parsed = parse("curl --proxy-user puser:ppass https://example.com").unwrap();
+        assert_eq!(parsed.proxy_user, "puser:ppass");
+    }
+
+    #[test]
+    fn test_parse_progress_flags() {
+        for (command, want) in [
+            ("curl https://example.com", ""),
+            ("curl --progress-bar https://example.com", "bar"),
+            ("curl -s# https://example.com", "bar"),
+            ("curl --no-progress-meter https://example.com", "none"),
+            ("curl -# --no-progress-meter https://example.com", "none"),
+        ] {
+            assert_eq!(parse(command).unwrap().progress, want, "{command}");
+        }
     }
 
     #[test]
     fn test_parse_default_matching_and_unsupported_semantic_flags() {
         let parsed = parse(
-            "curl --compressed --show-error --fail-with-body --no-keepalive --no-progress-meter --progress-bar -S -# https://example.com",
+            "curl --compressed --show-error --fail-with-body --no-progress-meter --progress-bar -S -# https://example.com",
         )
         .unwrap();
         assert_eq!(parsed.url, "https://example.com");
+        assert_eq!(parsed.progress, "bar");
+
+        let parsed = parse(
+            "curl --globoff -g --tcp-nodelay --no-alpn --no-sessionid --compressed-ssh 'https://example.com/[1-2]'",
+        )
+        .unwrap();
+        assert_eq!(parsed.url, "https://example.com/[1-2]");
+        assert_eq!(parsed.ip_version, "");
+        for (command, want) in [
+            ("curl -4 https://example.com", "4"),
+            ("curl --ipv6 https://example.com", "6"),
+            ("curl -6 --ipv4 https://example.com", "4"),
+            ("curl -sg6 https://example.com", "6"),
+        ] {
+            assert_eq!(parse(command).unwrap().ip_version, want, "{command}");
+        }
 
         for (command, flag, want) in [
             (
@@ -988,23 +1182,32 @@ mod tests {
     }
 
     #[test]
-    fn test_parse_netrc_is_rejected_with_auth_advice() {
-        for (command, flag) in [
-            ("curl --netrc https://example.com", "--netrc"),
-            ("curl -n https://example.com", "-n/--netrc"),
+    fn test_parse_netrc_flags() {
+        for command in [
+            "curl --netrc https://example.com",
+            "curl -n https://example.com",
+            "curl -sn https://example.com",
+            "curl --netrc-optional https://example.com",
         ] {
-            let err = parse(command).unwrap_err();
-            assert!(err.contains(flag), "{command}: {err}");
-            assert!(err.contains("--basic"), "{command}: {err}");
-            assert!(err.contains("--bearer"), "{command}: {err}");
-            assert!(err.contains("Authorization"), "{command}: {err}");
+            let parsed = parse(command).unwrap();
+            assert!(parsed.netrc, "{command}");
+            assert!(parsed.netrc_file.is_empty(), "{command}");
         }
+
+        let parsed = parse("curl --netrc-file ./netrc https://example.com").unwrap();
+        assert!(!parsed.netrc);
+        assert_eq!(parsed.netrc_file, "./netrc");
+        let parsed = parse("curl --netrc-file=./netrc https://example.com").unwrap();
+        assert_eq!(parsed.netrc_file, "./netrc");
+
+        let err = parse("curl --netrc-file").unwrap_err();
+        assert_eq!(err, "--netrc-file requires an argument");
     }
 
     #[test]
     fn test_parse_network_and_retry() {
         let parsed = parse(
-            "curl -L --max-redirs 5 --max-time 1.5 --connect-timeout 0.25 --retry 3 --retry-delay 0.5 https://example.com",
+            "curl -L --max-redirs 5 --max-time 1.5 --connect-timeout 0.25 --retry 3 --retry-delay 0.5 --retry-connrefused --retry-max-time 20 --keepalive-time 30 --no-keepalive https://example.com",
         )
         .unwrap();
         assert!(parsed.follow_redirects);
@@ -1014,6 +1217,78 @@ mod tests {
         assert_eq!(parsed.connect_timeout, 0.25);
         assert_eq!(parsed.retry, 3);
         assert_eq!(parsed.retry_delay, 0.5);
+        assert!(parsed.retry_connrefused);
+        assert_eq!(parsed.retry_max_time, 20.0);
+        assert_eq!(parsed.keepalive_time, 30.0);
+        assert!(parsed.no_keepalive);
+    }
+
+    #[test]
+    fn test_parse_http2_prior_knowledge() {
+        let parsed = parse("curl --http2-prior-knowledge http://localhost:8080").unwrap();
+        assert_eq!(parsed.http_version, "2-prior-knowledge");
+    }
+
+    #[test]
+    fn test_parse_ca_paths() {
+        let parsed =
+            parse("curl --cacert ca.pem --capath /etc/ssl/custom https://example.com").unwrap();
+        assert_eq!(parsed.ca_cert, "ca.pem");
+        assert_eq!(parsed.ca_path, "/etc/ssl/custom");
+    }
+
+    #[test]
+    fn test_parse_ciphers() {
+        let parsed = parse(
+            "curl --tlsv1.2 --tls-max 1.3 --ciphers ECDHE-RSA-AES128-GCM-SHA256 --tls13-ciphers TLS_AES_128_GCM_SHA256 https://example.com",
+        )
+        .unwrap();
+        assert_eq!(parsed.tls_version, "1.2");
+        assert_eq!(parsed.tls_max_version, "1.3");
+        assert_eq!(parsed.ciphers, "ECDHE-RSA-AES128-GCM-SHA256");
+        assert_eq!(parsed.tls13_ciphers, "TLS_AES_128_GCM_SHA256");
+    }
+
+    #[test]
+    fn test_parse_location_trusted_and_max_filesize() {
+        let parsed = parse("curl --location-trusted https://example.com").unwrap();
+        assert!(parsed.follow_redirects);
+        assert!(parsed.location_trusted);
+
+        let parsed = parse("curl -L https://example.com").unwrap();
+        assert!(!parsed.location_trusted);
+        assert_eq!(parsed.max_file_size, 0);
+
+        for (value, want) in [
+            ("0", 0),
+            ("1500", 1500),
+            ("2k", 2 << 10),
+            ("2K", 2 << 10),
+            ("3M", 3 << 20),
+            ("1g", 1 << 30),
+        ] {
+            let command = format!("curl --max-filesize {value} https://example.com");
+            assert_eq!(parse(&command).unwrap().max_file_size, want, "{command}");
+        }
+        for value in ["", "k", "+10", "1.5M", "10T", "99999999999999999999G"] {
+            let command = format!("curl --max-filesize '{value}' https://example.com");
+            let err = parse(&command).unwrap_err();
+            assert!(
+                err.contains("invalid --max-filesize value"),
+                "{command}: {err}"
+            );
+        }
+    }
+
+    #[test]
+    fn test_parse_continue_at() {
+        let parsed = parse("curl -C - -o file.bin https://example.com").unwrap();
+        assert!(parsed.continue_at);
+        let parsed = parse("curl --continue-at - -O https://example.com/file.bin").unwrap();
+        assert!(parsed.continue_at);
+
+        let err = parse("curl -C 100 -o file.bin https://example.com").unwrap_err();
+        assert!(err.contains("curl -C 100 is not supported"), "{err}");
     }
 
     #[test]
@@ -1025,6 +1300,8 @@ mod tests {
             "curl --connect-timeout -5 https://example.com",
             "curl --retry -1 https://example.com",
             "curl --retry-delay -5 https://example.com",
+            "curl --retry-max-time -5 https://example.com",
+            "curl --max-filesize -5 https://example.com",
         ] {
             let err = parse(command).unwrap_err();
             assert!(err.contains("invalid"), "{command}: {err}");
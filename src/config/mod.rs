@@ -68,6 +68,7 @@ struct ConfigValues {
     session: Option<String>,
     silent: Option<bool>,
     sort_headers: Option<bool>,
+    theme: Option<String>,
     timeout: Option<f64>,
     timing: Option<bool>,
     verbosity: Option<u8>,
@@ -102,6 +103,7 @@ enum ConfigField {
     Session,
     Silent,
     SortHeaders,
+    Theme,
     Timeout,
     Timing,
     Verbosity,
@@ -281,7 +283,7 @@ static CONFIG_OPTIONS: &[ConfigOption] = &[
         #[cfg(test)]
         cli_flags: &["dns-server"],
         trim: ConfigValueTrim::Both,
-        cli_source: |cli| cli.dns_server.is_some(),
+        cli_source: |cli| !cli.dns_servers.is_empty(),
         parse: |path, line_num, config, _key, value| {
             validate_dns_server(path, line_num, value)?;
             config.dns_server = Some(value.to_string());
@@ -289,8 +291,8 @@ static CONFIG_OPTIONS: &[ConfigOption] = &[
         },
         overlay: |target, higher| choose(&mut target.dns_server, &higher.dns_server),
         apply: |cli, values, _sources| {
-            if cli.dns_server.is_none() {
-                cli.dns_server = values.dns_server.clone();
+            if cli.dns_servers.is_empty() {
+                cli.dns_servers = values.dns_server.iter().cloned().collect();
             }
         },
     },
@@ -616,15 +618,15 @@ static CONFIG_OPTIONS: &[ConfigOption] = &[
     },
     ConfigOption {
         field: ConfigField::Retry,
-        keys: &["retry"],
+        keys: &["retry", "retries"],
         #[cfg(test)]
-        documented_keys: &["retry"],
+        documented_keys: &["retry", "retries"],
         #[cfg(test)]
         cli_flags: &["retry"],
         trim: ConfigValueTrim::Both,
         cli_source: |cli| cli.retry.is_some(),
-        parse: |path, line_num, config, _key, value| {
-            config.retry = Some(parse_nonnegative_usize(path, line_num, "retry", value)?);
+        parse: |path, line_num, config, key, value| {
+            config.retry = Some(parse_nonnegative_usize(path, line_num, key, value)?);
             Ok(())
         },
         overlay: |target, higher| choose(&mut target.retry, &higher.retry),
@@ -729,6 +731,27 @@ static CONFIG_OPTIONS: &[ConfigOption] = &[
             }
         },
     },
+    ConfigOption {
+        field: ConfigField::Theme,
+        keys: &["theme"],
+        #[cfg(test)]
+        documented_keys: &["theme"],
+        #[cfg(test)]
+        cli_flags: &["theme"],
+        trim: ConfigValueTrim::Both,
+        cli_source: |cli| cli.theme.is_some(),
+        parse: |path, line_num, config, _key, value| {
+            validate_choice(path, line_num, "theme", value, crate::core::Theme::NAMES)?;
+            config.theme = Some(value.to_string());
+            Ok(())
+        },
+        overlay: |target, higher| choose(&mut target.theme, &higher.theme),
+        apply: |cli, values, _sources| {
+            if cli.theme.is_none() {
+                cli.theme = values.theme.clone();
+            }
+        },
+    },
     ConfigOption {
         field: ConfigField::Timeout,
         keys: &["timeout"],
@@ -842,20 +865,30 @@ impl CliConfigSources {
     }
 }
 
-pub fn apply(cli: &mut Cli) -> Result<Option<PathBuf>, FetchError> {
+/// The config file that was applied, with the keys each section contributed
+/// and the keys that command-line flags took precedence over.
+#[derive(Debug, Default, PartialEq)]
+pub struct AppliedConfig {
+    pub path: PathBuf,
+    pub global: Vec<&'static str>,
+    pub host: Option<(String, Vec<&'static str>)>,
+    pub overridden: Vec<&'static str>,
+}
+
+pub fn apply(cli: &mut Cli) -> Result<Option<AppliedConfig>, FetchError> {
     let Some((path, contents)) = get_config_file(cli.config.as_deref())? else {
         return Ok(None);
     };
 
     let file = parse_file(&path, &contents).map_err(FetchError::Message)?;
     let sources = CliConfigSources::capture(cli);
-    apply_file(cli, &file, sources);
+    let applied = apply_file(cli, &file, sources);
     validate(cli)?;
-    Ok(Some(file.path))
+    Ok(Some(applied))
 }
 
 pub fn apply_best_effort(cli: &mut Cli) -> Option<PathBuf> {
-    apply(cli).ok().flatten()
+    apply(cli).ok().flatten().map(|applied| applied.path)
 }
 
 pub fn validate(cli: &Cli) -> Result<(), FetchError> {
@@ -942,20 +975,52 @@ fn default_config_candidates(
     paths
 }
 
-fn apply_file(cli: &mut Cli, file: &ConfigFile, sources: CliConfigSources) {
+fn apply_file(cli: &mut Cli, file: &ConfigFile, sources: CliConfigSources) -> AppliedConfig {
+    let mut applied = AppliedConfig {
+        path: file.path.clone(),
+        global: set_keys(&file.global),
+        ..AppliedConfig::default()
+    };
     let mut values = file.global.clone();
-    if let Some(host_cfg) = cli
+    if let Some((host, host_cfg)) = cli
         .url
         .as_deref()
         .and_then(url_hostname)
-        .and_then(|hostname| file.host_config(&hostname))
+        .and_then(|hostname| file.host_section(&hostname))
     {
         values.overlay(host_cfg);
+        applied.host = Some((host.to_string(), set_keys(host_cfg)));
     }
 
     for option in CONFIG_OPTIONS {
+        // Repeatable options merge with the flags rather than being replaced.
+        if sources.contains(option.field)
+            && !matches!(
+                option.field,
+                ConfigField::CaCert | ConfigField::Headers | ConfigField::Query
+            )
+            && option_is_set(option, &values)
+        {
+            applied.overridden.push(option.keys[0]);
+        }
         (option.apply)(cli, &values, &sources);
     }
+    applied
+}
+
+/// Return the primary key of every option the section sets.
+fn set_keys(values: &ConfigValues) -> Vec<&'static str> {
+    CONFIG_OPTIONS
+        .iter()
+        .filter(|option| option_is_set(option, values))
+        .map(|option| option.keys[0])
+        .collect()
+}
+
+fn option_is_set(option: &ConfigOption, values: &ConfigValues) -> bool {
+    let mut merged = ConfigValues::default();
+    (option.overlay)(&mut merged, values);
+    merged != ConfigValues::default()
 }
 
 fn prepend_vec<T>(target: &mut Vec<T>, mut values: Vec<T>) {
@@ -1251,8 +1316,10 @@ fn parse_query(value: &str) -> String {
 }
 
 fn validate_dns_server(path: &Path, line_num: usize, value: &str) -> Result<(), String> {
-    crate::dns::custom::parse_dns_server(value)
-        .map_err(|err| value_error(path, line_num, "dns-server", value, &err.to_string()))?;
+    for server in crate::dns::custom::dns_server_list(value) {
+        crate::dns::custom::parse_dns_server(server)
+            .map_err(|err| value_error(path, line_num, "dns-server", value, &err.to_string()))?;
+    }
     Ok(())
 }
 
@@ -1396,13 +1463,20 @@ fn choose<T: Clone>(target: &mut Option<T>, value: &Option<T>) {
 }
 
 impl ConfigFile {
+    #[cfg(test)]
     fn host_config(&self, hostname: &str) -> Option<&ConfigValues> {
+        self.host_section(hostname).map(|(_, config)| config)
+    }
+
+    /// Return the matching host section name and its values, preferring an
+    /// exact match over the most specific wildcard.
+    fn host_section(&self, hostname: &str) -> Option<(&str, &ConfigValues)> {
         if hostname.is_empty() {
             return None;
         }
         let hostname = hostname.to_ascii_lowercase();
-        if let Some(config) = self.hosts.get(&hostname) {
-            return Some(config);
+        if let Some((host, config)) = self.hosts.get_key_value(&hostname) {
+            return Some((host, config));
         }
 
         let mut best = None;
@@ -1412,7 +1486,7 @@ impl ConfigFile {
                 continue;
             };
             if hostname.ends_with(suffix) && suffix.len() > best_len {
-                best = Some(config);
+                best = Some((host.as_str(), config));
                 best_len = suffix.len();
             }
         }
@@ -1463,7 +1537,7 @@ fn absolute_path(path: PathBuf) -> Result<PathBuf, FetchError> {
     Ok(env::current_dir()?.join(path))
 }
 
-fn url_hostname(raw: &str) -> Option<String> {
+pub(crate) fn url_hostname(raw: &str) -> Option<String> {
     if raw.contains("://") {
         return url::Url::parse(raw)
             .ok()
@@ -1593,10 +1667,11 @@ mod tests {
     #[test]
     fn parse_file_accepts_global_presentation_settings() {
         let path = PathBuf::from("test/config");
-        let file = parse_file(&path, "color = off\nformat = on\n").unwrap();
+        let file = parse_file(&path, "color = off\nformat = on\ntheme = monochrome\n").unwrap();
 
         assert_eq!(file.global.color.as_deref(), Some("off"));
         assert_eq!(file.global.format.as_deref(), Some("on"));
+        assert_eq!(file.global.theme.as_deref(), Some("monochrome"));
     }
 
     #[test]
@@ -1782,6 +1857,10 @@ mod tests {
             parse_file(&path, "retry = 0\n").unwrap().global.retry,
             Some(0)
         );
+        assert_eq!(
+            parse_file(&path, "retries = 2\n").unwrap().global.retry,
+            Some(2)
+        );
 
         for value in ["-1", "abc"] {
             let err = parse_file(&path, &format!("retry = {value}\n")).unwrap_err();
@@ -2280,6 +2359,46 @@ mod tests {
         assert_eq!(cli.query, vec!["global=1", "host=1", "cli=1"]);
     }
 
+    #[test]
+    fn apply_file_reports_section_keys_and_flag_overrides() {
+        let path = PathBuf::from("test/config");
+        let file = parse_file(
+            &path,
+            "
+              timeout = 10
+              header = X-Global: 1
+              [*.example.com]
+              color = on
+              format = off
+            ",
+        )
+        .unwrap();
+        let mut cli = Cli::try_parse_from([
+            "fetch",
+            "--format",
+            "on",
+            "--timeout",
+            "5",
+            "-H",
+            "X-Cli: 1",
+            "https://api.example.com",
+        ])
+        .unwrap();
+
+        let sources = CliConfigSources::capture(&cli);
+        let applied = apply_file(&mut cli, &file, sources);
+
+        assert_eq!(
+            applied,
+            AppliedConfig {
+                path,
+                global: vec!["header", "timeout"],
+                host: Some(("*.example.com".to_string(), vec!["color", "format"])),
+                overridden: vec!["format", "timeout"],
+            }
+        );
+    }
+
     #[test]
     fn default_config_candidates_match_go_search_order() {
         let unix = default_config_candidates(
@@ -259,6 +259,71 @@ impl Sequence {
     }
 }
 
+/// A palette for colored output. Formatters style text with the basic
+/// `Sequence` colors, and the printer asks the active theme which SGR
+/// parameters to write for each one, so every formatter follows the theme.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub enum Theme {
+    #[default]
+    Default,
+    Monochrome,
+    Solarized,
+}
+
+impl Theme {
+    pub const NAMES: &'static [&'static str] = &["default", "monochrome", "solarized"];
+
+    pub fn from_name(name: &str) -> Option<Self> {
+        match name {
+            "default" => Some(Self::Default),
+            "monochrome" => Some(Self::Monochrome),
+            "solarized" => Some(Self::Solarized),
+            _ => None,
+        }
+    }
+
+    /// The SGR parameters to write for `sequence`, or `None` when the theme
+    /// drops it. Resets and text attributes such as bold are never changed.
+    pub fn code(self, sequence: Sequence) -> Option<&'static str> {
+        match (self, sequence) {
+            (
+                _,
+                Sequence::Reset
+                | Sequence::Bold
+                | Sequence::Dim
+                | Sequence::Italic
+                | Sequence::Underline,
+            )
+            | (Self::Default, _) => Some(sequence.code()),
+            // Keep emphasis but no hues, for terminals with unreadable palettes.
+            (Self::Monochrome, _) => None,
+            (Self::Solarized, color) => Some(match color {
+                Sequence::Black => "38;2;7;54;66",
+                Sequence::Red => "38;2;220;50;47",
+                Sequence::Green => "38;2;133;153;0",
+                Sequence::Yellow => "38;2;181;137;0",
+                Sequence::Blue => "38;2;38;139;210",
+                Sequence::Magenta => "38;2;211;54;130",
+                Sequence::Cyan => "38;2;42;161;152",
+                Sequence::White => "38;2;238;232;213",
+                other => other.code(),
+            }),
+        }
+    }
+}
+
+static THEME: OnceLock<Theme> = OnceLock::new();
+
+/// Select the theme used by every printer created afterwards. Only the first
+/// call has an effect.
+pub fn set_theme(theme: Theme) {
+    let _ = THEME.set(theme);
+}
+
+pub fn theme() -> Theme {
+    THEME.get().copied().unwrap_or_default()
+}
+
 pub fn color_for_status(code: u16) -> Sequence {
     match code {
         200..=299 => Sequence::Green,
@@ -271,6 +336,7 @@ pub fn color_for_status(code: u16) -> Sequence {
 pub struct Printer {
     buf: Vec<u8>,
     use_color: bool,
+    theme: Theme,
 }
 
 impl Printer {
@@ -278,6 +344,7 @@ impl Printer {
         Self {
             buf: Vec::new(),
             use_color,
+            theme: theme(),
         }
     }
 
@@ -298,9 +365,12 @@ impl Printer {
     }
 
     pub fn set(&mut self, sequence: Sequence) {
-        if self.use_color {
+        if !self.use_color {
+            return;
+        }
+        if let Some(code) = self.theme.code(sequence) {
             self.buf.extend_from_slice(b"\x1b[");
-            self.buf.extend_from_slice(sequence.code().as_bytes());
+            self.buf.extend_from_slice(code.as_bytes());
             self.buf.push(b'm');
         }
     }
@@ -319,7 +389,7 @@ impl Printer {
     }
 
     pub fn write_styled(&mut self, value: &str, styles: &[Sequence]) {
-        if self.use_color {
+        if self.use_color && styles.iter().any(|style| self.theme.code(*style).is_some()) {
             for style in styles {
                 self.set(*style);
             }
@@ -354,6 +424,10 @@ impl Printer {
         self.buf.clear();
     }
 
+    pub fn truncate(&mut self, len: usize) {
+        self.buf.truncate(len);
+    }
+
     pub fn bytes(&self) -> &[u8] {
         &self.buf
     }
@@ -541,6 +615,34 @@ mod tests {
         assert!(!color_enabled(Some("auto"), false));
     }
 
+    #[test]
+    fn themes_remap_colors_and_keep_attributes() {
+        let styled = |theme| {
+            let mut printer = Printer {
+                theme,
+                ..Printer::new(true)
+            };
+            printer.write_styled("key", &[Sequence::Blue, Sequence::Bold]);
+            printer.write_styled("value", &[Sequence::Green]);
+            printer.into_string().unwrap()
+        };
+
+        assert_eq!(
+            styled(Theme::Default),
+            "\x1b[34m\x1b[1mkey\x1b[0m\x1b[32mvalue\x1b[0m"
+        );
+        assert_eq!(styled(Theme::Monochrome), "\x1b[1mkey\x1b[0mvalue");
+        assert_eq!(
+            styled(Theme::Solarized),
+            "\x1b[38;2;38;139;210m\x1b[1mkey\x1b[0m\x1b[38;2;133;153;0mvalue\x1b[0m"
+        );
+
+        for name in Theme::NAMES {
+            assert!(Theme::from_name(name).is_some(), "{name}");
+        }
+        assert_eq!(Theme::from_name("dark"), None);
+    }
+
     #[test]
     fn format_enabled_matches_go_auto_policy() {
         assert!(format_enabled(Some("on"), false));
@@ -1,3 +1,4 @@
+use std::future::Future;
 use std::net::{IpAddr, SocketAddr};
 use std::time::Duration;
 
@@ -27,6 +28,50 @@ pub(crate) enum ParsedDnsServer {
     Doh(Url),
 }
 
+/// Split a `--dns-server` value into the resolvers it names. Repeated flags
+/// are joined with commas, and the resolvers are tried in that order.
+pub(crate) fn dns_server_list(value: &str) -> impl Iterator<Item = &str> {
+    value
+        .split(',')
+        .map(str::trim)
+        .filter(|server| !server.is_empty())
+}
+
+/// Return the resolver when `value` names exactly one.
+pub(crate) fn single_dns_server(value: &str) -> Option<&str> {
+    let mut servers = dns_server_list(value);
+    let server = servers.next()?;
+    servers.next().is_none().then_some(server)
+}
+
+pub(crate) fn is_doh_dns_server(value: &str) -> bool {
+    value.starts_with("http://") || value.starts_with("https://")
+}
+
+/// Run `lookup` against each resolver in `value` until one succeeds. When all
+/// of them fail, the error from the last resolver is returned.
+pub(crate) async fn with_failover<'a, T, F, Fut>(
+    value: &'a str,
+    mut lookup: F,
+) -> Result<T, FetchError>
+where
+    F: FnMut(&'a str) -> Fut,
+    Fut: Future<Output = Result<T, FetchError>>,
+{
+    let mut last_err = None;
+    for server in dns_server_list(value) {
+        match lookup(server).await {
+            Ok(result) => return Ok(result),
+            Err(err) => last_err = Some(err),
+        }
+    }
+    Err(last_err.unwrap_or_else(|| {
+        FetchError::Message(format!(
+            "invalid value '{value}' for option '--dns-server': no DNS server given"
+        ))
+    }))
+}
+
 pub(crate) fn parse_dns_server(value: &str) -> Result<ParsedDnsServer, FetchError> {
     if value.starts_with("http://") || value.starts_with("https://") {
         let url = Url::parse(value).map_err(|err| {
@@ -170,7 +215,17 @@ pub(crate) async fn lookup_ips(
     if let Ok(ip) = host.parse::<IpAddr>() {
         return Ok(vec![ip]);
     }
+    with_failover(dns_server, |server| {
+        lookup_server_ips(server, host, timeout)
+    })
+    .await
+}
 
+async fn lookup_server_ips(
+    dns_server: &str,
+    host: &str,
+    timeout: Option<Duration>,
+) -> Result<Vec<IpAddr>, FetchError> {
     match parse_dns_server(dns_server)? {
         ParsedDnsServer::Udp(addr) => crate::dns::resolver::lookup_udp_addr(&addr, host, timeout)
             .await
@@ -225,6 +280,45 @@ mod tests {
         assert_eq!(addrs, [SocketAddr::new("127.0.0.1".parse().unwrap(), 0)]);
     }
 
+    #[test]
+    fn dns_server_list_splits_comma_separated_resolvers() {
+        let servers: Vec<_> =
+            dns_server_list("1.1.1.1, https://dns.example/dns-query,,tls://9.9.9.9").collect();
+        assert_eq!(
+            servers,
+            ["1.1.1.1", "https://dns.example/dns-query", "tls://9.9.9.9"]
+        );
+
+        assert_eq!(single_dns_server(" 1.1.1.1 "), Some("1.1.1.1"));
+        assert_eq!(single_dns_server("1.1.1.1,8.8.8.8"), None);
+        assert_eq!(single_dns_server(""), None);
+    }
+
+    #[tokio::test]
+    async fn with_failover_tries_resolvers_in_order() {
+        let mut tried = Vec::new();
+        let result = with_failover("bad,good,unused", |server| {
+            tried.push(server);
+            async move {
+                match server {
+                    "good" | "unused" => Ok(server),
+                    _ => Err(FetchError::Runtime(format!("{server} failed"))),
+                }
+            }
+        })
+        .await
+        .unwrap();
+        assert_eq!(result, "good");
+        assert_eq!(tried, ["bad", "good"]);
+
+        let err = with_failover("first,second", |server| async move {
+            Err::<(), _>(FetchError::Runtime(format!("{server} failed")))
+        })
+        .await
+        .unwrap_err();
+        assert_eq!(err.to_string(), "second failed");
+    }
+
     #[test]
     fn parse_dns_server_accepts_bare_ip_for_udp() {
         let parsed = parse_dns_server("1.1.1.1").unwrap();
@@ -213,7 +213,7 @@ async fn execute_inner(cli: &Cli, ignored_flags: &[&'static str]) -> Result<i32,
     let inspected = connect_timeout
         .run(inspect_result(
             &url,
-            cli.dns_server.as_deref(),
+            cli.dns_server().as_deref(),
             connect_timeout,
         ))
         .await;
@@ -653,7 +653,13 @@ async fn resolver_target(dns_server: Option<&str>) -> Result<ResolverTarget, Fet
             None,
             std::fs::read_to_string("/etc/resolv.conf").ok().as_deref(),
         )),
-        Some(server) => match crate::dns::custom::parse_dns_server(server)? {
+        // Inspection reports a single resolver, so only the first of several
+        // configured servers is queried.
+        Some(servers) => match crate::dns::custom::parse_dns_server(
+            crate::dns::custom::dns_server_list(servers)
+                .next()
+                .unwrap_or(servers),
+        )? {
             crate::dns::custom::ParsedDnsServer::Udp(addr) => Ok(ResolverTarget::Udp {
                 label: format!("udp {addr}"),
                 addr,
@@ -245,21 +245,11 @@ pub(crate) async fn lookup_https_records(
         return Ok(Vec::new());
     }
     match resolver {
-        HttpsRecordResolver::Custom(server)
-            if server.starts_with("http://") || server.starts_with("https://") =>
-        {
-            let server_url = Url::parse(server).map_err(|err| {
-                FetchError::Message(format!("invalid dns-server '{server}': {err}"))
-            })?;
-            lookup_doh_https_records(&server_url, host, timeout).await
-        }
-        HttpsRecordResolver::Custom(server) => {
-            let server_addr = crate::dns::resolver::normalize_udp_dns_server(server)
-                .map_err(|err| FetchError::Message(err.to_string()))?;
-            let server_addr = server_addr.parse::<SocketAddr>().map_err(|err| {
-                FetchError::Message(format!("invalid dns-server '{server}': {err}"))
-            })?;
-            lookup_udp_https_records(server_addr, host, TimeoutBudget::new(timeout)).await
+        HttpsRecordResolver::Custom(servers) => {
+            crate::dns::custom::with_failover(servers, |server| {
+                lookup_custom_https_records(server, host, timeout)
+            })
+            .await
         }
         HttpsRecordResolver::System => {
             system::lookup_https_records(host, TimeoutBudget::new(timeout)).await
@@ -267,6 +257,24 @@ pub(crate) async fn lookup_https_records(
     }
 }
 
+async fn lookup_custom_https_records(
+    server: &str,
+    host: &str,
+    timeout: Option<Duration>,
+) -> Result<Vec<SvcbRecord>, FetchError> {
+    if crate::dns::custom::is_doh_dns_server(server) {
+        let server_url = Url::parse(server)
+            .map_err(|err| FetchError::Message(format!("invalid dns-server '{server}': {err}")))?;
+        return lookup_doh_https_records(&server_url, host, timeout).await;
+    }
+    let server_addr = crate::dns::resolver::normalize_udp_dns_server(server)
+        .map_err(|err| FetchError::Message(err.to_string()))?;
+    let server_addr = server_addr
+        .parse::<SocketAddr>()
+        .map_err(|err| FetchError::Message(format!("invalid dns-server '{server}': {err}")))?;
+    lookup_udp_https_records(server_addr, host, TimeoutBudget::new(timeout)).await
+}
+
 async fn lookup_doh_https_records(
     server_url: &Url,
     host: &str,
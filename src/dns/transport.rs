@@ -148,6 +148,7 @@ async fn tls_connector(insecure: bool) -> Result<TlsConnector, DnsTransportError
         None,
         None,
         None,
+        None,
     )
     .map_err(|err| DnsTransportError(err.to_string()))?;
     Ok(TlsConnector::from(Arc::new(config)))
@@ -168,6 +169,7 @@ pub(crate) async fn quic_connection(
         None,
         None,
         None,
+        None,
     )
     .map_err(|err| DnsTransportError(err.to_string()))?;
     tls.alpn_protocols = vec![b"doq".to_vec()];
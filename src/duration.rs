@@ -12,10 +12,21 @@ const NANOS_PER_DAY: u128 = 24 * NANOS_PER_HOUR;
 
 pub(crate) const MAX_DURATION_SECONDS: f64 = i64::MAX as f64 / 1_000_000_000_f64;
 
+const REQUEST_TIMEOUT_PREFIX: &str = "request timed out after ";
+const CONNECT_TIMEOUT_PREFIX: &str = "connect timed out after ";
+
+/// The part of a request that a timeout limits, used to word its error.
+#[derive(Clone, Copy, Debug, Eq, PartialEq)]
+enum TimeoutPhase {
+    Request,
+    Connect,
+}
+
 #[derive(Clone, Copy, Debug)]
 pub(crate) struct TimeoutBudget {
     timeout: Option<Duration>,
     started_at: Instant,
+    phase: TimeoutPhase,
 }
 
 impl TimeoutBudget {
@@ -27,16 +38,25 @@ impl TimeoutBudget {
         Self {
             timeout,
             started_at,
+            phase: TimeoutPhase::Request,
         }
     }
 
+    /// Budget for the connection phase. When '--connect-timeout' is the
+    /// tighter limit, running out reports a connect timeout rather than a
+    /// request timeout.
     pub(crate) fn for_connect(
         connect_timeout: Option<Duration>,
         request_timeout: Option<Duration>,
         request_started_at: Instant,
     ) -> Result<Self, FetchError> {
         let request_remaining = remaining_timeout(request_timeout, request_started_at)?;
-        Ok(Self::new(min_timeout(connect_timeout, request_remaining)))
+        let timeout = min_timeout(connect_timeout, request_remaining);
+        let mut budget = Self::new(timeout);
+        if connect_timeout.is_some() && timeout == connect_timeout {
+            budget.phase = TimeoutPhase::Connect;
+        }
+        Ok(budget)
     }
 
     pub(crate) fn timeout(self) -> Option<Duration> {
@@ -44,11 +64,19 @@ impl TimeoutBudget {
     }
 
     pub(crate) fn remaining(self) -> Result<Option<Duration>, FetchError> {
-        remaining_timeout(self.timeout, self.started_at)
+        remaining_timeout(self.timeout, self.started_at).map_err(|_| self.timeout_error())
     }
 
     pub(crate) fn timeout_error(self) -> FetchError {
-        request_timeout_error(self.timeout.expect("timeout checked by caller"))
+        FetchError::Runtime(self.timeout_message())
+    }
+
+    pub(crate) fn timeout_message(self) -> String {
+        let timeout = self.timeout.expect("timeout checked by caller");
+        match self.phase {
+            TimeoutPhase::Request => request_timeout_message(timeout),
+            TimeoutPhase::Connect => connect_timeout_message(timeout),
+        }
     }
 
     pub(crate) async fn run<T>(
@@ -104,7 +132,28 @@ pub(crate) fn request_timeout_error(timeout: Duration) -> FetchError {
 }
 
 pub(crate) fn request_timeout_message(timeout: Duration) -> String {
-    format!("request timed out after {}", format_go_duration(timeout))
+    format!("{REQUEST_TIMEOUT_PREFIX}{}", format_go_duration(timeout))
+}
+
+pub(crate) fn connect_timeout_message(timeout: Duration) -> String {
+    format!("{CONNECT_TIMEOUT_PREFIX}{}", format_go_duration(timeout))
+}
+
+/// Return the byte offset of a request or connect timeout message within
+/// `message`, if it contains one.
+pub(crate) fn find_timeout_message(message: &str) -> Option<usize> {
+    [REQUEST_TIMEOUT_PREFIX, CONNECT_TIMEOUT_PREFIX]
+        .iter()
+        .filter_map(|prefix| message.find(prefix))
+        .min()
+}
+
+pub(crate) fn is_timeout_message(message: &str) -> bool {
+    find_timeout_message(message) == Some(0)
+}
+
+pub(crate) fn is_connect_timeout_message(message: &str) -> bool {
+    message.starts_with(CONNECT_TIMEOUT_PREFIX)
 }
 
 pub(crate) fn format_go_duration(duration: Duration) -> String {
@@ -345,6 +394,62 @@ mod tests {
         assert!(budget.timeout().unwrap() <= Duration::from_millis(250));
     }
 
+    #[test]
+    fn timeout_budget_for_connect_names_the_phase_that_ran_out() {
+        let started = Instant::now() - Duration::from_millis(20);
+        let connect = TimeoutBudget::for_connect(
+            Some(Duration::from_millis(10)),
+            Some(Duration::from_secs(5)),
+            Instant::now(),
+        )
+        .unwrap();
+        assert_eq!(
+            connect.timeout_error().to_string(),
+            "connect timed out after 10ms"
+        );
+        let expired = TimeoutBudget {
+            started_at: started,
+            ..connect
+        };
+        assert_eq!(
+            expired.remaining().unwrap_err().to_string(),
+            "connect timed out after 10ms"
+        );
+
+        let request = TimeoutBudget::for_connect(
+            Some(Duration::from_secs(5)),
+            Some(Duration::from_millis(10)),
+            Instant::now(),
+        )
+        .unwrap();
+        assert!(
+            request
+                .timeout_error()
+                .to_string()
+                .starts_with("request timed out after ")
+        );
+
+        let unlimited = TimeoutBudget::for_connect(None, None, Instant::now()).unwrap();
+        assert_eq!(unlimited.timeout(), None);
+    }
+
+    #[test]
+    fn timeout_messages_are_recognized_in_error_text() {
+        let connect = connect_timeout_message(Duration::from_millis(50));
+        assert_eq!(connect, "connect timed out after 50ms");
+        assert!(is_timeout_message(&connect));
+        assert!(is_connect_timeout_message(&connect));
+
+        let request = request_timeout_message(Duration::from_secs(1));
+        assert!(is_timeout_message(&request));
+        assert!(!is_connect_timeout_message(&request));
+
+        let wrapped = format!("IO error: {connect}");
+        assert!(!is_timeout_message(&wrapped));
+        assert_eq!(find_timeout_message(&wrapped), Some("IO error: ".len()));
+        assert_eq!(find_timeout_message("connection refused"), None);
+    }
+
     #[test]
     fn remaining_timeout_reports_expired_request_budget() {
         let err = remaining_timeout(
@@ -96,8 +96,15 @@ impl FlagDef {
 
 pub(crate) static FLAGS: &[FlagDef] = &[
     // ── Request ─────────────────────────────────────────────────────────
-    FlagDef::new("--data", Some(FlagCategory::Request), |c| c.data.is_some()).with_from_curl(),
+    FlagDef::new("--data", Some(FlagCategory::Request), |c| {
+        c.data.is_some() || !c.data_values.is_empty()
+    })
+    .with_from_curl(),
     FlagDef::new("--json", Some(FlagCategory::Request), |c| c.json.is_some()).with_from_curl(),
+    FlagDef::new("--json-merge", Some(FlagCategory::Request), |c| {
+        c.json_merge.is_some()
+    })
+    .with_ws_always(),
     FlagDef::new("--xml", Some(FlagCategory::Request), |c| c.xml.is_some())
         .with_from_curl()
         .with_ws_always(),
@@ -139,6 +146,10 @@ pub(crate) static FLAGS: &[FlagDef] = &[
     FlagDef::new("--har", Some(FlagCategory::Response), |c| c.har.is_some())
         .with_from_curl()
         .with_ws_always(),
+    FlagDef::new("--schema", Some(FlagCategory::Response), |c| {
+        c.schema.is_some()
+    })
+    .with_ws_always(),
     FlagDef::new("--remote-name", Some(FlagCategory::Request), |c| {
         c.remote_name
     })
@@ -149,8 +160,16 @@ pub(crate) static FLAGS: &[FlagDef] = &[
     })
     .with_from_curl()
     .with_ws_always(),
+    FlagDef::new("--output-dir", Some(FlagCategory::Request), |c| {
+        c.output_dir.is_some()
+    })
+    .with_ws_always(),
     FlagDef::new("--copy", Some(FlagCategory::Request), |c| c.copy).with_ws_always(),
     FlagDef::new("--clobber", Some(FlagCategory::Request), |c| c.clobber).with_ws_always(),
+    FlagDef::new("--write-meta", Some(FlagCategory::Request), |c| {
+        c.write_meta
+    })
+    .with_ws_always(),
     FlagDef::new("--method", Some(FlagCategory::Request), |c| {
         c.method.is_some()
     })
@@ -163,7 +182,19 @@ pub(crate) static FLAGS: &[FlagDef] = &[
         !c.query.is_empty()
     })
     .with_from_curl(),
+    FlagDef::new("--path-param", Some(FlagCategory::Request), |c| {
+        !c.path_params.is_empty()
+    })
+    .with_from_curl(),
+    FlagDef::new("--allow-unfilled", Some(FlagCategory::Request), |c| {
+        c.allow_unfilled
+    })
+    .with_from_curl(),
     FlagDef::new("--edit", Some(FlagCategory::Request), |c| c.edit).with_ws_always(),
+    FlagDef::new("--expand-env", Some(FlagCategory::Request), |c| {
+        c.expand_env.is_some()
+    })
+    .with_from_curl(),
     FlagDef::new("--session", Some(FlagCategory::Request), |c| {
         c.session.is_some()
     }),
@@ -178,21 +209,116 @@ pub(crate) static FLAGS: &[FlagDef] = &[
     })
     .with_from_curl()
     .with_ws_always(),
+    FlagDef::new("--retry-connrefused", Some(FlagCategory::Request), |c| {
+        c.retry_connrefused
+    })
+    .with_from_curl()
+    .with_ws_always(),
+    FlagDef::new("--retry-max-delay", Some(FlagCategory::Request), |c| {
+        c.retry_max_delay.is_some()
+    })
+    .with_ws_always(),
+    FlagDef::new("--retry-jitter", Some(FlagCategory::Request), |c| {
+        c.retry_jitter.is_some()
+    })
+    .with_ws_always(),
+    FlagDef::new("--retry-backoff", Some(FlagCategory::Request), |c| {
+        c.retry_backoff.is_some()
+    })
+    .with_ws_always(),
+    FlagDef::new("--retry-on-status", Some(FlagCategory::Request), |c| {
+        c.retry_on_status.is_some()
+    })
+    .with_ws_always(),
+    FlagDef::new("--retry-max-time", Some(FlagCategory::Request), |c| {
+        c.retry_max_time.is_some()
+    })
+    .with_from_curl()
+    .with_ws_always(),
+    FlagDef::new("--respect-rate-limit", Some(FlagCategory::Request), |c| {
+        c.respect_rate_limit
+    })
+    .with_ws_always(),
     FlagDef::new("--redirects", Some(FlagCategory::Request), |c| {
         c.redirects.is_some()
     })
     .with_from_curl(),
+    FlagDef::new("--require-https", Some(FlagCategory::Request), |c| {
+        c.require_https
+    })
+    .with_ws_plain(),
     FlagDef::new("--range", Some(FlagCategory::Request), |c| {
         !c.ranges.is_empty()
     })
     .with_from_curl(),
+    FlagDef::new("--idempotency-key", Some(FlagCategory::Request), |c| {
+        c.idempotency_key.is_some()
+    })
+    .with_ws_always(),
+    FlagDef::new("--accept-fallback", Some(FlagCategory::Request), |c| {
+        c.accept_fallback.is_some()
+    })
+    .with_ws_always(),
+    FlagDef::new("--check-hosts", Some(FlagCategory::Request), |c| {
+        c.check_hosts.is_some()
+    })
+    .with_from_curl()
+    .with_ws_always(),
+    FlagDef::new("--checksum", Some(FlagCategory::Response), |c| {
+        c.checksum.is_some()
+    })
+    .with_ws_always(),
+    FlagDef::new("--checksum-verify", Some(FlagCategory::Response), |c| {
+        c.checksum_verify.is_some()
+    })
+    .with_ws_always(),
+    FlagDef::new("--chunk-size", Some(FlagCategory::Request), |c| {
+        c.chunk_size.is_some()
+    })
+    .with_ws_always(),
+    FlagDef::new("--continue-at", Some(FlagCategory::Request), |c| {
+        c.continue_at.is_some()
+    })
+    .with_ws_always(),
     FlagDef::new("--timing", Some(FlagCategory::Request), |c| c.timing),
     FlagDef::new("--proxy", Some(FlagCategory::Request), |c| {
         c.proxy.is_some()
     })
     .with_from_curl(),
+    FlagDef::new("--proxy-user", Some(FlagCategory::Request), |c| {
+        c.proxy_user.is_some()
+    })
+    .with_from_curl(),
     FlagDef::new("--discard", Some(FlagCategory::Request), |c| c.discard).with_ws_always(),
     FlagDef::new("--unix", Some(FlagCategory::Request), |c| c.unix.is_some()).with_from_curl(),
+    FlagDef::new("--local-port", Some(FlagCategory::Request), |c| {
+        c.local_port.is_some()
+    })
+    .with_ws_always(),
+    FlagDef::new("--interface", Some(FlagCategory::Request), |c| {
+        c.interface.is_some()
+    })
+    .with_ws_always(),
+    FlagDef::new("--keepalive", Some(FlagCategory::Request), |c| {
+        c.keepalive.is_some()
+    })
+    .with_from_curl(),
+    FlagDef::new("--no-keepalive", Some(FlagCategory::Request), |c| {
+        c.no_keepalive
+    })
+    .with_from_curl(),
+    FlagDef::new("--max-headers", Some(FlagCategory::Request), |c| {
+        c.max_headers.is_some()
+    })
+    .with_ws_always(),
+    FlagDef::new("--resolve", Some(FlagCategory::Request), |c| {
+        !c.resolve.is_empty()
+    })
+    .with_ws_always(),
+    FlagDef::new("--connect-to", Some(FlagCategory::Request), |c| {
+        !c.connect_to.is_empty()
+    })
+    .with_ws_always(),
     // ── Auth ────────────────────────────────────────────────────────────
     FlagDef::new("--basic", Some(FlagCategory::Auth), |c| c.basic.is_some()).with_from_curl(),
     FlagDef::new("--bearer", Some(FlagCategory::Auth), |c| c.bearer.is_some()).with_from_curl(),
@@ -203,11 +329,21 @@ pub(crate) static FLAGS: &[FlagDef] = &[
         c.aws_sigv4.is_some()
     })
     .with_from_curl(),
+    FlagDef::new("--netrc", Some(FlagCategory::Auth), |c| c.netrc),
+    FlagDef::new("--netrc-file", Some(FlagCategory::Auth), |c| {
+        c.netrc_file.is_some()
+    }),
     // ── Response ────────────────────────────────────────────────────────
     FlagDef::new("--article", Some(FlagCategory::Response), |c| c.article).with_ws_always(),
     FlagDef::new("--compress", Some(FlagCategory::Response), |c| {
         c.compress.is_some()
     }),
+    FlagDef::new(
+        "--max-decompress-ratio",
+        Some(FlagCategory::Response),
+        |c| c.max_decompress_ratio.is_some(),
+    )
+    .with_ws_always(),
     FlagDef::new("--no-encode", Some(FlagCategory::Response), |c| c.no_encode),
     FlagDef::new("--format", Some(FlagCategory::Response), |c| {
         c.format.is_some()
@@ -215,15 +351,32 @@ pub(crate) static FLAGS: &[FlagDef] = &[
     FlagDef::new("--image", Some(FlagCategory::Response), |c| {
         c.image.is_some()
     }),
+    FlagDef::new("--raw", Some(FlagCategory::Response), |c| c.raw),
     FlagDef::new("--pager", Some(FlagCategory::Response), |c| {
         c.pager.is_some()
     }),
+    FlagDef::new("--progress", Some(FlagCategory::Response), |c| {
+        c.progress.is_some()
+    }),
     FlagDef::new("--ignore-status", Some(FlagCategory::Response), |c| {
         c.ignore_status
     }),
     FlagDef::new("--sort-headers", Some(FlagCategory::Response), |c| {
         c.sort_headers
     }),
+    FlagDef::new("--sort-keys", Some(FlagCategory::Response), |c| c.sort_keys).with_ws_always(),
+    FlagDef::new("--unwrap-json", Some(FlagCategory::Response), |c| {
+        c.unwrap_json
+    })
+    .with_ws_always(),
+    FlagDef::new("--line-numbers", Some(FlagCategory::Response), |c| {
+        c.line_numbers
+    })
+    .with_ws_always(),
+    FlagDef::new("--decode-jwt", Some(FlagCategory::Response), |c| {
+        c.decode_jwt
+    })
+    .with_ws_always(),
     FlagDef::new("--ws-interactive", Some(FlagCategory::Response), |c| {
         c.ws_interactive.is_some()
     }),
@@ -231,12 +384,49 @@ pub(crate) static FLAGS: &[FlagDef] = &[
         c.ws_message_mode.is_some()
     }),
     FlagDef::new("--dry-run", Some(FlagCategory::Response), |c| c.dry_run),
+    FlagDef::new("--trace-headers-only", Some(FlagCategory::Response), |c| {
+        c.trace_headers_only
+    }),
+    FlagDef::new("--curl", Some(FlagCategory::Response), |c| c.curl).with_ws_always(),
+    FlagDef::new("--extract", Some(FlagCategory::Response), |c| {
+        c.extract.is_some()
+    })
+    .with_ws_always(),
+    FlagDef::new("--extract-all", Some(FlagCategory::Response), |c| {
+        c.extract_all
+    })
+    .with_ws_always(),
+    FlagDef::new("--split-output", Some(FlagCategory::Response), |c| {
+        c.split_output.is_some()
+    })
+    .with_ws_always(),
+    FlagDef::new("--to-json", Some(FlagCategory::Response), |c| c.to_json).with_ws_always(),
+    FlagDef::new("--to-yaml", Some(FlagCategory::Response), |c| c.to_yaml).with_ws_always(),
+    FlagDef::new("--minify", Some(FlagCategory::Response), |c| c.minify).with_ws_always(),
+    FlagDef::new("--hex", Some(FlagCategory::Response), |c| c.hex).with_ws_always(),
+    FlagDef::new(
+        "--no-formatter-delegation",
+        Some(FlagCategory::Response),
+        |c| c.no_formatter_delegation,
+    )
+    .with_ws_always(),
+    FlagDef::new("--fail-on-empty", Some(FlagCategory::Response), |c| {
+        c.fail_on_empty
+    })
+    .with_ws_always(),
+    FlagDef::new("--fail-on-truncation", Some(FlagCategory::Response), |c| {
+        c.fail_on_truncation
+    })
+    .with_ws_always(),
     // ── Resolver (not in any ignored group; used by inspection) ───────
-    FlagDef::new("--dns-server", None, |c| c.dns_server.is_some()).with_from_curl(),
+    FlagDef::new("--dns-server", None, |c| !c.dns_servers.is_empty()).with_from_curl(),
     // ── TLS ────────────────────────────────────────────────────────────
     FlagDef::new("--insecure", Some(FlagCategory::Tls), |c| c.insecure)
         .with_from_curl()
         .with_ws_plain(),
+    FlagDef::new("--quiet-insecure", Some(FlagCategory::Tls), |c| {
+        c.quiet_insecure
+    }),
     FlagDef::new("--max-tls", Some(FlagCategory::Tls), |c| {
         c.max_tls.is_some()
     })
@@ -250,6 +440,11 @@ pub(crate) static FLAGS: &[FlagDef] = &[
     FlagDef::new("--tls", Some(FlagCategory::Tls), |c| c.tls.is_some())
         .with_from_curl()
         .with_ws_plain(),
+    FlagDef::new("--ciphers", Some(FlagCategory::Tls), |c| {
+        c.ciphers.is_some()
+    })
+    .with_from_curl()
+    .with_ws_plain(),
     FlagDef::new("--cert", Some(FlagCategory::Tls), |c| c.cert.is_some())
         .with_from_curl()
         .with_ws_plain(),
@@ -261,6 +456,11 @@ pub(crate) static FLAGS: &[FlagDef] = &[
     })
     .with_from_curl()
     .with_ws_plain(),
+    FlagDef::new("--ca-path", Some(FlagCategory::Tls), |c| {
+        !c.ca_path.is_empty()
+    })
+    .with_from_curl()
+    .with_ws_plain(),
     FlagDef::new("--ech", Some(FlagCategory::Tls), |c| c.ech.is_some())
         .with_from_curl()
         .with_ws_plain(),
@@ -271,7 +471,31 @@ pub(crate) static FLAGS: &[FlagDef] = &[
     .with_from_curl(),
     FlagDef::new("--http1", Some(FlagCategory::HttpVersion), |c| c.http1).with_from_curl(),
     FlagDef::new("--http2", Some(FlagCategory::HttpVersion), |c| c.http2).with_from_curl(),
+    FlagDef::new(
+        "--http2-prior-knowledge",
+        Some(FlagCategory::HttpVersion),
+        |c| c.http2_prior_knowledge,
+    )
+    .with_from_curl(),
     FlagDef::new("--http3", Some(FlagCategory::HttpVersion), |c| c.http3).with_from_curl(),
+    FlagDef::new(
+        "--h2-max-frame-size",
+        Some(FlagCategory::HttpVersion),
+        |c| c.h2_max_frame_size.is_some(),
+    )
+    .with_ws_always(),
+    FlagDef::new(
+        "--h2-initial-window-size",
+        Some(FlagCategory::HttpVersion),
+        |c| c.h2_initial_window_size.is_some(),
+    )
+    .with_ws_always(),
+    FlagDef::new(
+        "--h2-max-concurrent-streams",
+        Some(FlagCategory::HttpVersion),
+        |c| c.h2_max_concurrent_streams.is_some(),
+    )
+    .with_ws_always(),
     // ── Timeout ────────────────────────────────────────────────────────
     FlagDef::new("--timeout", None, |c| c.timeout.is_some()).with_from_curl(),
     FlagDef::new("--connect-timeout", None, |c| c.connect_timeout.is_some()).with_from_curl(),
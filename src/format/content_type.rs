@@ -15,6 +15,7 @@ pub enum ContentType {
     Ndjson,
     Protobuf,
     Sse,
+    Toml,
     Xml,
     Yaml,
 }
@@ -120,6 +121,7 @@ const MIME_RULES: &[MimeRule] = mime_rules![
     ("text", "css", Css, Some(".css"), "text/css; charset=utf-8", ["css"]),
     ("text", "csv", Csv, Some(".csv"), "text/csv; charset=utf-8", ["csv"]),
     ("application", "csv", Csv, Some(".csv"), "application/csv", []),
+    ("text", "tab-separated-values", Csv, Some(".tsv"), "text/tab-separated-values; charset=utf-8", ["tsv"]),
     ("application", "json", Json, Some(".json"), "application/json", ["json"]),
     ("application", "x-ndjson", Ndjson, Some(".ndjson"), "application/x-ndjson", ["ndjson"]),
     ("application", "ndjson", Ndjson, Some(".ndjson"), "application/ndjson", []),
@@ -132,6 +134,9 @@ const MIME_RULES: &[MimeRule] = mime_rules![
     ("application", "x-yaml", Yaml, Some(".yaml"), "application/x-yaml", []),
     ("text", "yaml", Yaml, Some(".yaml"), "text/yaml", []),
     ("text", "x-yaml", Yaml, Some(".yaml"), "text/x-yaml", []),
+    ("application", "toml", Toml, Some(".toml"), "application/toml", ["toml"]),
+    ("text", "toml", Toml, Some(".toml"), "text/toml", []),
+    ("text", "x-toml", Toml, Some(".toml"), "text/x-toml", []),
     ("text", "markdown", Markdown, Some(".md"), "text/markdown; charset=utf-8", ["md"]),
     ("text", "x-markdown", Markdown, Some(".md"), "text/x-markdown; charset=utf-8", []),
     ("text", "event-stream", Sse, Some(".sse"), "text/event-stream", ["sse"]),
@@ -248,6 +253,8 @@ fn policy_for_parts(typ: &str, subtype: &str) -> MimePolicy {
                 MimePolicy::new(ContentType::Xml, Some(".xml"), None)
             } else if subtype.ends_with("+yaml") {
                 MimePolicy::new(ContentType::Yaml, Some(".yaml"), None)
+            } else if subtype.ends_with("+toml") {
+                MimePolicy::new(ContentType::Toml, Some(".toml"), None)
             } else {
                 MimePolicy::UNKNOWN
             }
@@ -311,6 +318,7 @@ pub fn sniff_content_type(buf: &[u8]) -> ContentType {
     }
 
     match b[0] {
+        b'[' if is_toml_table_header(b) => ContentType::Toml,
         b'{' | b'[' => ContentType::Json,
         b'<' => sniff_markup(b),
         b'-' if b.len() >= 3 && b[1] == b'-' && b[2] == b'-' => ContentType::Yaml,
@@ -344,6 +352,29 @@ fn sniff_markup(b: &[u8]) -> ContentType {
     ContentType::Unknown
 }
 
+/// Reports whether the first line is a bare TOML table header such as
+/// `[package]` or `[[bin]]`, which can never be a valid JSON array.
+fn is_toml_table_header(b: &[u8]) -> bool {
+    let line = b.split(|c| *c == b'\n').next().unwrap_or_default();
+    let line = trim_ascii_space(line);
+    let (name, close) = if let Some(rest) = line.strip_prefix(b"[[") {
+        (rest, b"]]".as_slice())
+    } else {
+        (&line[1..], b"]".as_slice())
+    };
+    let Some(name) = name.strip_suffix(close) else {
+        return false;
+    };
+    let name = trim_ascii_space(name);
+    if matches!(name, b"true" | b"false" | b"null") {
+        return false;
+    }
+    name.first().is_some_and(|c| is_letter(*c) || *c == b'_')
+        && name
+            .iter()
+            .all(|c| c.is_ascii_alphanumeric() || matches!(c, b'_' | b'-' | b'.'))
+}
+
 fn sniff_tag(b: &[u8]) -> ContentType {
     if is_html_tag(b) {
         ContentType::Html
@@ -510,6 +541,19 @@ mod tests {
                 b"\xEF\xBB\xBF---\nkey: value",
                 ContentType::Yaml,
             ),
+            (
+                "toml table header",
+                b"[package]\nname = \"fetch\"",
+                ContentType::Toml,
+            ),
+            (
+                "toml array of tables",
+                b"[[bin]]\nname = \"fetch\"",
+                ContentType::Toml,
+            ),
+            ("toml dotted header", b"[a.b-c]", ContentType::Toml),
+            ("json array of literals", b"[true]", ContentType::Json),
+            ("json nested array", b"[[1, 2]]", ContentType::Json),
             ("png image", b"\x89PNG\r\n\x1a\n", ContentType::Image),
             ("jpeg image", b"\xff\xd8\xff\xe0", ContentType::Image),
             ("gif image", b"GIF89a", ContentType::Image),
@@ -606,6 +650,19 @@ mod tests {
                 ContentType::Csv,
                 "shift_jis",
             ),
+            (
+                "tsv",
+                Some("text/tab-separated-values"),
+                ContentType::Csv,
+                "",
+            ),
+            ("toml", Some("application/toml"), ContentType::Toml, ""),
+            (
+                "toml suffix",
+                Some("application/vnd.example+toml; charset=utf-8"),
+                ContentType::Toml,
+                "utf-8",
+            ),
             (
                 "grpc json",
                 Some("application/grpc+json"),
@@ -295,6 +295,59 @@ pub(crate) fn format_css_to_indented(
     formatter.format()
 }
 
+pub fn minify_css(buf: &[u8]) -> Vec<u8> {
+    let mut tok = CssTokenizer::new(buf);
+    let mut out = String::with_capacity(buf.len());
+    let mut prev: Option<CssToken> = None;
+    let mut pending_space = false;
+    loop {
+        let token = tok.next();
+        match token.typ {
+            CssTokenType::Eof => break,
+            CssTokenType::Whitespace | CssTokenType::Comment => {
+                pending_space = true;
+                continue;
+            }
+            _ => {}
+        }
+        let prev_is_semicolon = prev.as_ref().is_some_and(|prev| is_delim(prev, ";"));
+        if prev_is_semicolon && (is_delim(&token, ";") || is_delim(&token, "}")) {
+            out.pop();
+        } else if pending_space
+            && prev
+                .as_ref()
+                .is_some_and(|prev| minified_space_between(prev, &token))
+        {
+            out.push(' ');
+        }
+        pending_space = false;
+        out.push_str(&token.value);
+        prev = Some(token);
+    }
+    out.into_bytes()
+}
+
+fn minified_space_between(prev: &CssToken, next: &CssToken) -> bool {
+    let drop_after = match prev.typ {
+        CssTokenType::Function => true,
+        CssTokenType::Delim => matches!(
+            prev.value.as_str(),
+            "{" | "}" | ";" | "," | ":" | ">" | "~" | "(" | "[" | "="
+        ),
+        _ => false,
+    };
+    let drop_before = next.typ == CssTokenType::Delim
+        && matches!(
+            next.value.as_str(),
+            "{" | "}" | ";" | "," | ">" | "~" | ")" | "]" | "=" | "!"
+        );
+    !drop_after && !drop_before
+}
+
+fn is_delim(token: &CssToken, value: &str) -> bool {
+    token.typ == CssTokenType::Delim && token.value == value
+}
+
 struct CssFormatter<'a, 'out> {
     tok: CssTokenizer<'a>,
     out: &'out mut Printer,
@@ -1274,6 +1327,32 @@ mod tests {
         assert!(output.contains("var("), "{output}");
     }
 
+    #[test]
+    fn minify_css_strips_insignificant_whitespace_and_comments() {
+        let cases = [
+            ("", ""),
+            ("body { color: red; }", "body{color:red}"),
+            (
+                "/* header */\nh1 ,  h2 > a:hover {\n  margin : 0 auto ;;\n  color: #fff !important;\n}\n",
+                "h1,h2>a:hover{margin :0 auto;color:#fff!important}",
+            ),
+            ("div p .x { top: 0 }", "div p .x{top:0}"),
+            (
+                ".a { width: calc(100% - 2 * 1px); content: \"a  b\"; }",
+                ".a{width:calc(100% - 2 * 1px);content:\"a  b\"}",
+            ),
+            (
+                "@media screen and (max-width: 600px) { .a { color: red; } }",
+                "@media screen and (max-width:600px){.a{color:red}}",
+            ),
+            ("a[href = 'x'] { }", "a[href='x']{}"),
+        ];
+        for (input, expected) in cases {
+            let output = String::from_utf8(minify_css(input.as_bytes())).unwrap();
+            assert_eq!(output, expected, "{input:?}");
+        }
+    }
+
     #[test]
     fn formats_css_with_color_when_requested() {
         let output =
@@ -2,6 +2,10 @@ use std::fmt;
 
 use crate::core::{Printer, Sequence};
 
+/// Widest a table column may grow on a terminal before its cells are cut
+/// short with an ellipsis. Vertical mode always shows full values.
+const MAX_COLUMN_WIDTH: usize = 48;
+
 #[derive(Debug, Clone, PartialEq, Eq)]
 pub struct CsvError(String);
 
@@ -51,14 +55,15 @@ pub(crate) fn format_csv_to_with_terminal_cols(
         return Ok(());
     }
 
-    let column_widths = calculate_column_widths(&records);
+    let table = table_records(&records, terminal_columns > 0);
+    let column_widths = calculate_column_widths(&table);
     let total_width = calculate_total_width(&column_widths);
     if terminal_columns > 0 && total_width > terminal_columns && records.len() > 1 {
         write_vertical_to(out, &records);
         return Ok(());
     }
 
-    for (index, row) in records.iter().enumerate() {
+    for (index, row) in table.iter().enumerate() {
         if index > 0 {
             out.push('\n');
         }
@@ -137,7 +142,16 @@ fn detect_delimiter(buf: &[u8]) -> char {
     let mut max_count = 0;
     let mut best_delimiter = ',';
     for delimiter in delimiters {
-        let count = first_line.matches(delimiter).count();
+        let mut in_quotes = false;
+        let count = first_line
+            .chars()
+            .filter(|ch| {
+                if *ch == '"' {
+                    in_quotes = !in_quotes;
+                }
+                !in_quotes && *ch == delimiter
+            })
+            .count();
         if count > max_count {
             max_count = count;
             best_delimiter = delimiter;
@@ -147,6 +161,36 @@ fn detect_delimiter(buf: &[u8]) -> char {
     best_delimiter
 }
 
+/// Prepares cells for table mode: line breaks inside quoted fields become `↵`
+/// so each record stays on one line, and on a terminal, cells wider than
+/// [`MAX_COLUMN_WIDTH`] are truncated.
+fn table_records(records: &[Vec<String>], truncate: bool) -> Vec<Vec<String>> {
+    records
+        .iter()
+        .map(|row| row.iter().map(|cell| table_cell(cell, truncate)).collect())
+        .collect()
+}
+
+fn table_cell(cell: &str, truncate: bool) -> String {
+    let cell = cell.replace("\r\n", "↵").replace(['\n', '\r'], "↵");
+    if !truncate || display_width(&cell) <= MAX_COLUMN_WIDTH {
+        return cell;
+    }
+
+    let mut out = String::new();
+    let mut width = 0;
+    for ch in cell.chars() {
+        let ch_width = display_width(ch.encode_utf8(&mut [0; 4]));
+        if width + ch_width > MAX_COLUMN_WIDTH - 1 {
+            break;
+        }
+        out.push(ch);
+        width += ch_width;
+    }
+    out.push('…');
+    out
+}
+
 fn calculate_column_widths(records: &[Vec<String>]) -> Vec<usize> {
     let max_columns = records.iter().map(Vec::len).max().unwrap_or(0);
     let mut widths = vec![0; max_columns];
@@ -335,6 +379,11 @@ mod tests {
             ("no delimiters defaults to comma", "abc", ','),
             ("mixed prefers most common", "a,b,c;d", ','),
             ("multiline uses first line", "a;b;c\na,b,c,d,e", ';'),
+            (
+                "ignores delimiters inside quotes",
+                "\"a,b,c\"\td\te\n",
+                '\t',
+            ),
         ];
 
         for (name, input, want) in tests {
@@ -453,6 +502,32 @@ mod tests {
         assert!(output.contains("\x1b[32mAlice\x1b[0m"));
     }
 
+    #[test]
+    fn table_mode_keeps_embedded_newlines_on_one_line() {
+        let output = String::from_utf8(
+            format_csv(b"name,bio\nAlice,\"Line1\r\nLine2\"\nBob,Simple", false).unwrap(),
+        )
+        .unwrap();
+
+        assert_eq!(output, "name   bio\nAlice  Line1↵Line2\nBob    Simple\n");
+    }
+
+    #[test]
+    fn table_mode_truncates_wide_cells_on_a_terminal() {
+        let wide = "x".repeat(MAX_COLUMN_WIDTH + 10);
+        let input = format!("id,text\n1,{wide}\n");
+
+        let output = format_csv_with_terminal_cols(input.as_bytes(), false, 80).unwrap();
+        let want = format!("{}…", "x".repeat(MAX_COLUMN_WIDTH - 1));
+        assert_eq!(output, format!("id  text\n1   {want}\n"));
+
+        let output = format_csv_with_terminal_cols(input.as_bytes(), false, 0).unwrap();
+        assert!(output.contains(&wide), "{output}");
+
+        let output = format_csv_with_terminal_cols(input.as_bytes(), false, 20).unwrap();
+        assert!(output.contains(&format!("text: {wide}")), "{output}");
+    }
+
     #[test]
     fn vertical_mode_uses_terminal_width_boundary() {
         let output =
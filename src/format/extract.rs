@@ -0,0 +1,234 @@
+use serde_json::Value;
+
+#[derive(Clone, Debug, PartialEq, Eq)]
+enum Segment {
+    Key(String),
+    Index(i64),
+    Wildcard,
+}
+
+#[derive(Clone, Debug, PartialEq, Eq)]
+pub struct JsonPath {
+    source: String,
+    segments: Vec<Segment>,
+}
+
+impl JsonPath {
+    pub fn parse(source: &str) -> Result<Self, String> {
+        let mut segments = Vec::new();
+        let rest = source.trim();
+        let mut rest = rest.strip_prefix('$').unwrap_or(rest);
+        let mut expect_key = !rest.is_empty() && !rest.starts_with(['.', '[']);
+        while !rest.is_empty() || expect_key {
+            if expect_key {
+                let end = rest.find(['.', '[']).unwrap_or(rest.len());
+                let key = &rest[..end];
+                if key.is_empty() {
+                    return Err("path contains an empty key".to_string());
+                }
+                segments.push(if key == "*" {
+                    Segment::Wildcard
+                } else {
+                    Segment::Key(key.to_string())
+                });
+                rest = &rest[end..];
+                expect_key = false;
+            } else if let Some(after) = rest.strip_prefix('.') {
+                rest = after;
+                expect_key = true;
+            } else if let Some(after) = rest.strip_prefix('[') {
+                let (segment, after) = parse_bracket(after)?;
+                segments.push(segment);
+                rest = after;
+            } else {
+                let ch = rest.chars().next().expect("path is not empty");
+                return Err(format!("unexpected '{ch}' in path"));
+            }
+        }
+        Ok(Self {
+            source: source.to_string(),
+            segments,
+        })
+    }
+
+    pub fn as_str(&self) -> &str {
+        &self.source
+    }
+
+    pub fn select<'a>(&self, root: &'a Value) -> Vec<&'a Value> {
+        let mut current = vec![root];
+        for segment in &self.segments {
+            let mut next = Vec::new();
+            for value in current {
+                match (segment, value) {
+                    (Segment::Key(key), Value::Object(map)) => next.extend(map.get(key)),
+                    (Segment::Index(index), Value::Array(items)) => {
+                        let index = if *index < 0 {
+                            items.len().checked_sub(index.unsigned_abs() as usize)
+                        } else {
+                            Some(*index as usize)
+                        };
+                        next.extend(index.and_then(|index| items.get(index)));
+                    }
+                    (Segment::Wildcard, Value::Array(items)) => next.extend(items),
+                    (Segment::Wildcard, Value::Object(map)) => next.extend(map.values()),
+                    _ => {}
+                }
+            }
+            current = next;
+        }
+        current
+    }
+}
+
+fn parse_bracket(input: &str) -> Result<(Segment, &str), String> {
+    if let Some(quote) = input.chars().next().filter(|ch| matches!(ch, '"' | '\'')) {
+        let mut key = String::new();
+        let mut chars = input[1..].char_indices();
+        while let Some((index, ch)) = chars.next() {
+            match ch {
+                '\\' => match chars.next() {
+                    Some((_, escaped)) => key.push(escaped),
+                    None => break,
+                },
+                ch if ch == quote => {
+                    let after = &input[1 + index + 1..];
+                    let Some(after) = after.strip_prefix(']') else {
+                        return Err("expected ']' after quoted key".to_string());
+                    };
+                    return Ok((Segment::Key(key), after));
+                }
+                ch => key.push(ch),
+            }
+        }
+        return Err("unterminated quoted key in path".to_string());
+    }
+
+    let Some(end) = input.find(']') else {
+        return Err("unterminated '[' in path".to_string());
+    };
+    let inner = input[..end].trim();
+    let segment = if inner == "*" {
+        Segment::Wildcard
+    } else {
+        inner
+            .parse::<i64>()
+            .map(Segment::Index)
+            .map_err(|_| format!("invalid array index '{inner}'"))?
+    };
+    Ok((segment, &input[end + 1..]))
+}
+
+pub fn write_extracted_value(value: &Value, out: &mut Vec<u8>) {
+    match value {
+        Value::String(value) => out.extend_from_slice(value.as_bytes()),
+        value => out.extend_from_slice(value.to_string().as_bytes()),
+    }
+    out.push(b'\n');
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    use serde_json::json;
+
+    fn select(path: &str, value: &Value) -> Vec<Value> {
+        JsonPath::parse(path)
+            .unwrap()
+            .select(value)
+            .into_iter()
+            .cloned()
+            .collect()
+    }
+
+    #[test]
+    fn parse_supports_dotted_bracketed_and_wildcard_segments() {
+        let cases = [
+            ("", vec![]),
+            ("$", vec![]),
+            ("token", vec![Segment::Key("token".into())]),
+            (
+                "$.data.items[0]",
+                vec![
+                    Segment::Key("data".into()),
+                    Segment::Key("items".into()),
+                    Segment::Index(0),
+                ],
+            ),
+            (
+                ".items[*].id",
+                vec![
+                    Segment::Key("items".into()),
+                    Segment::Wildcard,
+                    Segment::Key("id".into()),
+                ],
+            ),
+            (
+                r#"headers["content-type"]['a\'b'][-1].*"#,
+                vec![
+                    Segment::Key("headers".into()),
+                    Segment::Key("content-type".into()),
+                    Segment::Key("a'b".into()),
+                    Segment::Index(-1),
+                    Segment::Wildcard,
+                ],
+            ),
+        ];
+        for (path, expected) in cases {
+            assert_eq!(JsonPath::parse(path).unwrap().segments, expected, "{path}");
+        }
+    }
+
+    #[test]
+    fn parse_rejects_malformed_paths() {
+        let cases = [
+            ("a..b", "path contains an empty key"),
+            ("a.", "path contains an empty key"),
+            ("a[0", "unterminated '[' in path"),
+            ("a[x]", "invalid array index 'x'"),
+            ("a[\"b", "unterminated quoted key in path"),
+            ("a[\"b\"x", "expected ']' after quoted key"),
+            ("a[0]b", "unexpected 'b' in path"),
+        ];
+        for (path, expected) in cases {
+            assert_eq!(JsonPath::parse(path).unwrap_err(), expected, "{path}");
+        }
+    }
+
+    #[test]
+    fn select_returns_every_matching_value() {
+        let value = json!({
+            "token": "abc",
+            "items": [{"id": 1}, {"id": 2}, {"name": "no id"}],
+            "nested": {"a": true, "b": null},
+        });
+        assert_eq!(select("token", &value), vec![json!("abc")]);
+        assert_eq!(select("items[1].id", &value), vec![json!(2)]);
+        assert_eq!(select("items[-1].name", &value), vec![json!("no id")]);
+        assert_eq!(select("items[*].id", &value), vec![json!(1), json!(2)]);
+        assert_eq!(select("nested.*", &value), vec![json!(true), json!(null)]);
+        assert_eq!(select("$", &value), vec![value.clone()]);
+        assert!(select("missing", &value).is_empty());
+        assert!(select("items[9]", &value).is_empty());
+        assert!(select("items[-9]", &value).is_empty());
+        assert!(select("token.length", &value).is_empty());
+    }
+
+    #[test]
+    fn extracted_values_print_raw_scalars_and_compact_json() {
+        let mut out = Vec::new();
+        for value in [
+            json!("line"),
+            json!(1.5),
+            json!(null),
+            json!({"a": [1, "b"]}),
+        ] {
+            write_extracted_value(&value, &mut out);
+        }
+        assert_eq!(
+            String::from_utf8(out).unwrap(),
+            "line\n1.5\nnull\n{\"a\":[1,\"b\"]}\n"
+        );
+    }
+}
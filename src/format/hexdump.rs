@@ -0,0 +1,127 @@
+use crate::core::{Printer, Sequence};
+
+/// Bytes shown on each hexdump line.
+const LINE_BYTES: usize = 16;
+
+#[cfg(test)]
+pub(crate) fn format_hexdump(bytes: &[u8], color: bool) -> String {
+    let mut out = Printer::new(color);
+    let mut formatter = HexdumpFormatter::new();
+    formatter.push(bytes, &mut out);
+    formatter.finish(&mut out);
+    out.into_string().expect("hexdump output is valid UTF-8")
+}
+
+/// Writes bytes as `hexdump -C` style lines: an offset, sixteen hex bytes in
+/// two groups of eight, and the printable ASCII characters. Input can arrive
+/// in chunks of any size; a partial line is held until more bytes arrive or
+/// the input is finished.
+#[derive(Debug, Default)]
+pub struct HexdumpFormatter {
+    offset: u64,
+    pending: Vec<u8>,
+}
+
+impl HexdumpFormatter {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    pub fn push(&mut self, mut bytes: &[u8], out: &mut Printer) {
+        if !self.pending.is_empty() {
+            let needed = LINE_BYTES - self.pending.len();
+            let take = needed.min(bytes.len());
+            self.pending.extend_from_slice(&bytes[..take]);
+            bytes = &bytes[take..];
+            if self.pending.len() < LINE_BYTES {
+                return;
+            }
+            let line = std::mem::take(&mut self.pending);
+            self.write_line(&line, out);
+        }
+
+        let mut lines = bytes.chunks_exact(LINE_BYTES);
+        for line in &mut lines {
+            self.write_line(line, out);
+        }
+        self.pending.extend_from_slice(lines.remainder());
+    }
+
+    pub fn finish(&mut self, out: &mut Printer) {
+        if !self.pending.is_empty() {
+            let line = std::mem::take(&mut self.pending);
+            self.write_line(&line, out);
+        }
+    }
+
+    fn write_line(&mut self, line: &[u8], out: &mut Printer) {
+        out.write_styled(&format!("{:08x}", self.offset), &[Sequence::Dim]);
+        out.push_str("  ");
+        for index in 0..LINE_BYTES {
+            match line.get(index) {
+                Some(byte) => out.push_str(&format!("{byte:02x} ")),
+                None => out.push_str("   "),
+            }
+            if index == LINE_BYTES / 2 - 1 {
+                out.push(' ');
+            }
+        }
+        out.push_str(" |");
+        for &byte in line {
+            if byte.is_ascii_graphic() || byte == b' ' {
+                out.push(byte as char);
+            } else {
+                out.write_styled(".", &[Sequence::Dim]);
+            }
+        }
+        out.push_str("|\n");
+        self.offset += line.len() as u64;
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn hexdump_writes_offsets_hex_groups_and_ascii() {
+        assert_eq!(
+            format_hexdump(b"Hello, world!\n\x00\x01\xffabc", false),
+            "00000000  48 65 6c 6c 6f 2c 20 77  6f 72 6c 64 21 0a 00 01  |Hello, world!...|\n\
+             00000010  ff 61 62 63                                       |.abc|\n"
+        );
+        assert_eq!(format_hexdump(b"", false), "");
+    }
+
+    #[test]
+    fn hexdump_lines_do_not_depend_on_chunk_boundaries() {
+        let bytes: Vec<u8> = (0..=255).cycle().take(1000).collect();
+        let whole = format_hexdump(&bytes, false);
+        assert_eq!(whole.lines().count(), 63);
+        assert!(
+            whole.ends_with(
+                "000003e0  e0 e1 e2 e3 e4 e5 e6 e7                           |........|\n"
+            ),
+            "{whole}"
+        );
+
+        for chunk_size in [1, 7, 16, 33, 4096] {
+            let mut out = Printer::new(false);
+            let mut formatter = HexdumpFormatter::new();
+            for chunk in bytes.chunks(chunk_size) {
+                formatter.push(chunk, &mut out);
+            }
+            formatter.finish(&mut out);
+            assert_eq!(out.into_string().unwrap(), whole, "chunk size {chunk_size}");
+        }
+    }
+
+    #[test]
+    fn hexdump_dims_offsets_and_non_printable_bytes() {
+        assert_eq!(
+            format_hexdump(b"a\n", true),
+            "\x1b[2m00000000\x1b[0m  61 0a                                             \
+             |a\x1b[2m.\x1b[0m|\n"
+        );
+    }
+}
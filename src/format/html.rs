@@ -9,6 +9,10 @@ const BLUE: Sequence = Sequence::Blue;
 const CYAN: Sequence = Sequence::Cyan;
 const GREEN: Sequence = Sequence::Green;
 
+/// Browsers only look for a `<meta>` charset declaration in the first 1024
+/// bytes of a document.
+const META_CHARSET_SCAN_BYTES: usize = 1024;
+
 #[derive(Debug, Clone, PartialEq, Eq)]
 pub struct HtmlError(String);
 
@@ -306,6 +310,50 @@ pub fn format_html_to(buf: &[u8], out: &mut Printer) -> Result<(), HtmlError> {
     Ok(())
 }
 
+/// Return the charset declared by a `<meta charset>` or `<meta http-equiv>`
+/// tag near the start of the document.
+pub fn meta_charset(buf: &[u8]) -> Option<&str> {
+    let mut rest = &buf[..buf.len().min(META_CHARSET_SCAN_BYTES)];
+    while let Some(start) = find_ascii_case_insensitive(rest, b"<meta") {
+        let tag = &rest[start + b"<meta".len()..];
+        let end = tag.iter().position(|&b| b == b'>').unwrap_or(tag.len());
+        if tag
+            .first()
+            .is_some_and(|&b| b.is_ascii_whitespace() || b == b'/')
+            && let Some(charset) = charset_attr_value(&tag[..end])
+        {
+            return Some(charset);
+        }
+        rest = &tag[end..];
+    }
+    None
+}
+
+fn charset_attr_value(attrs: &[u8]) -> Option<&str> {
+    let start = find_ascii_case_insensitive(attrs, b"charset")?;
+    let value = attrs[start + b"charset".len()..]
+        .trim_ascii_start()
+        .strip_prefix(b"=")?
+        .trim_ascii_start();
+    let value = value
+        .strip_prefix(b"\"")
+        .or_else(|| value.strip_prefix(b"'"))
+        .unwrap_or(value);
+    let end = value
+        .iter()
+        .position(|&b| matches!(b, b'"' | b'\'' | b';' | b'/') || b.is_ascii_whitespace())
+        .unwrap_or(value.len());
+    std::str::from_utf8(&value[..end])
+        .ok()
+        .filter(|charset| !charset.is_empty())
+}
+
+fn find_ascii_case_insensitive(haystack: &[u8], needle: &[u8]) -> Option<usize> {
+    haystack
+        .windows(needle.len())
+        .position(|window| window.eq_ignore_ascii_case(needle))
+}
+
 struct HtmlFormatter<'a, 'out> {
     tokenizer: HtmlTokenizer<'a>,
     out: &'out mut Printer,
@@ -535,6 +583,132 @@ impl<'a, 'out> HtmlFormatter<'a, 'out> {
     }
 }
 
+pub fn minify_html(buf: &[u8]) -> Vec<u8> {
+    let mut minifier = HtmlMinifier {
+        tokenizer: HtmlTokenizer::new(buf),
+        out: Vec::with_capacity(buf.len()),
+        stack: Vec::new(),
+        pending_space: false,
+        after_block: true,
+    };
+    minifier.minify();
+    minifier.out
+}
+
+struct HtmlMinifier<'a> {
+    tokenizer: HtmlTokenizer<'a>,
+    out: Vec<u8>,
+    stack: Vec<String>,
+    pending_space: bool,
+    after_block: bool,
+}
+
+impl HtmlMinifier<'_> {
+    fn minify(&mut self) {
+        loop {
+            let raw_tag = self
+                .stack
+                .last()
+                .filter(|tag| is_raw_text_element(tag) || *tag == "textarea")
+                .cloned();
+            match self.tokenizer.next(raw_tag.as_deref()) {
+                HtmlToken::Eof => return,
+                HtmlToken::Doctype(data) => {
+                    self.write_tag_boundary(true);
+                    self.out
+                        .extend_from_slice(format!("<!DOCTYPE {data}>").as_bytes());
+                }
+                HtmlToken::StartTag { name, attrs } => self.minify_start_tag(&name, &attrs, false),
+                HtmlToken::SelfClosingTag { name, attrs } => {
+                    self.minify_start_tag(&name, &attrs, true);
+                }
+                HtmlToken::EndTag(name) => self.minify_end_tag(&name),
+                HtmlToken::Text(text) => self.minify_text(&text),
+                HtmlToken::Comment(_) => {}
+            }
+        }
+    }
+
+    fn minify_start_tag(&mut self, name: &str, attrs: &[HtmlAttr], self_closing: bool) {
+        let tag_name_lower = name.to_ascii_lowercase();
+        let is_void = is_void_element(&tag_name_lower);
+        self.write_tag_boundary(is_whitespace_insensitive_element(&tag_name_lower));
+
+        let mut tag = format!("<{name}");
+        for attr in attrs {
+            tag.push(' ');
+            tag.push_str(&attr.name);
+            if let Some(value) = &attr.value {
+                tag.push_str("=\"");
+                tag.push_str(&value.replace('"', "&quot;"));
+                tag.push('"');
+            }
+        }
+        tag.push_str(if self_closing && !is_void { "/>" } else { ">" });
+        self.out.extend_from_slice(tag.as_bytes());
+
+        if !self_closing && !is_void {
+            self.stack.push(tag_name_lower);
+        }
+    }
+
+    fn minify_end_tag(&mut self, name: &str) {
+        let tag_name_lower = name.to_ascii_lowercase();
+        if is_void_element(&tag_name_lower) {
+            return;
+        }
+        if let Some(i) = self.stack.iter().rposition(|tag| *tag == tag_name_lower) {
+            self.stack.truncate(i);
+        }
+        self.write_tag_boundary(is_whitespace_insensitive_element(&tag_name_lower));
+        self.out.extend_from_slice(format!("</{name}>").as_bytes());
+    }
+
+    fn minify_text(&mut self, text: &[u8]) {
+        match self.stack.last().map(String::as_str) {
+            Some("style") => {
+                self.out.extend_from_slice(&css::minify_css(text));
+                return;
+            }
+            Some("script") => {
+                self.out.extend_from_slice(trim_ascii_whitespace(text));
+                return;
+            }
+            _ => {}
+        }
+        if self
+            .stack
+            .iter()
+            .any(|tag| is_preserve_whitespace_element(tag))
+        {
+            self.out.extend_from_slice(text);
+            self.after_block = false;
+            return;
+        }
+
+        for &byte in text {
+            if byte.is_ascii_whitespace() {
+                self.pending_space = true;
+                continue;
+            }
+            if self.pending_space && !self.after_block {
+                self.out.push(b' ');
+            }
+            self.pending_space = false;
+            self.after_block = false;
+            self.out.push(byte);
+        }
+    }
+
+    fn write_tag_boundary(&mut self, is_block: bool) {
+        if self.pending_space && !is_block && !self.after_block {
+            self.out.push(b' ');
+        }
+        self.pending_space = false;
+        self.after_block = is_block;
+    }
+}
+
 fn write_indent(out: &mut Printer, level: usize) {
     for _ in 0..level {
         out.push_str("  ");
@@ -706,6 +880,28 @@ fn is_block_element(tag: &str) -> bool {
     )
 }
 
+fn is_whitespace_insensitive_element(tag: &str) -> bool {
+    is_block_element(tag)
+        && !matches!(
+            tag,
+            "img"
+                | "input"
+                | "select"
+                | "textarea"
+                | "canvas"
+                | "video"
+                | "audio"
+                | "iframe"
+                | "object"
+                | "embed"
+                | "wbr"
+                | "script"
+                | "noscript"
+                | "template"
+                | "datalist"
+        )
+}
+
 fn is_raw_text_element(tag: &str) -> bool {
     matches!(tag, "script" | "style")
 }
@@ -778,6 +974,42 @@ mod tests {
         }
     }
 
+    #[test]
+    fn minify_html_collapses_whitespace_and_drops_comments() {
+        let cases = [
+            ("", ""),
+            (
+                "<!DOCTYPE html>\n<html>\n  <head>\n    <title> Test </title>\n  </head>\n</html>\n",
+                "<!DOCTYPE html><html><head><title>Test</title></head></html>",
+            ),
+            (
+                "<div class=\"a\">\n  <!-- note -->\n  <p>Text   with\n <b>bold</b> <i>words</i> </p>\n</div>",
+                "<div class=\"a\"><p>Text with <b>bold</b> <i>words</i></p></div>",
+            ),
+            (
+                "<p>a<br>\n  b <img src=\"x.png\" alt='say \"hi\"'/></p>",
+                "<p>a<br>b <img src=\"x.png\" alt=\"say &quot;hi&quot;\"></p>",
+            ),
+            (
+                "<pre>  keep\n    this</pre>\n<textarea> a\n b </textarea>",
+                "<pre>  keep\n    this</pre><textarea> a\n b </textarea>",
+            ),
+            (
+                "<script>\n  if (a < b) { run(); }\n</script>",
+                "<script>if (a < b) { run(); }</script>",
+            ),
+            (
+                "<style>\n  body { color: red; }\n</style>",
+                "<style>body{color:red}</style>",
+            ),
+            ("<svg><path d=\"M0\"/></svg>", "<svg><path d=\"M0\"/></svg>"),
+        ];
+        for (input, expected) in cases {
+            let output = String::from_utf8(minify_html(input.as_bytes())).unwrap();
+            assert_eq!(output, expected, "{input:?}");
+        }
+    }
+
     #[test]
     fn test_format_html_output() {
         let output = formatted("<html><body><p>text</p></body></html>");
@@ -811,6 +1043,39 @@ mod tests {
         assert!(output.contains("<!-- test comment -->"), "{output}");
     }
 
+    #[test]
+    fn meta_charset_reads_charset_and_http_equiv_declarations() {
+        let cases: [(&[u8], Option<&str>); 6] = [
+            (
+                br#"<head><meta charset="windows-1252"></head>"#,
+                Some("windows-1252"),
+            ),
+            (b"<META CHARSET=iso-8859-1>", Some("iso-8859-1")),
+            (
+                br#"<meta http-equiv="Content-Type" content="text/html; charset=Shift_JIS" />"#,
+                Some("Shift_JIS"),
+            ),
+            (
+                br#"<meta name="viewport"><meta charset='utf-8'>"#,
+                Some("utf-8"),
+            ),
+            (br#"<metadata charset="latin1"><p>charset=x</p>"#, None),
+            (br#"<meta charset="">"#, None),
+        ];
+        for (input, want) in cases {
+            assert_eq!(
+                meta_charset(input),
+                want,
+                "{}",
+                String::from_utf8_lossy(input)
+            );
+        }
+
+        let mut late = vec![b' '; META_CHARSET_SCAN_BYTES];
+        late.extend_from_slice(br#"<meta charset="latin1">"#);
+        assert_eq!(meta_charset(&late), None);
+    }
+
     #[test]
     fn test_format_html_void_elements() {
         let cases = [
@@ -1,7 +1,10 @@
-use std::fmt::Write as _;
+use std::collections::HashSet;
+use std::fmt::{self, Write as _};
 
+use serde::de::{DeserializeSeed, Deserializer, MapAccess, SeqAccess, Visitor};
 use serde_json::Value;
 
+use super::PartialFormatError;
 use crate::core::{Printer, Sequence};
 
 #[cfg(test)]
@@ -31,6 +34,63 @@ pub fn format_json_to(bytes: &[u8], out: &mut Printer) -> Result<(), serde_json:
     Ok(())
 }
 
+/// How a JSON response body is rewritten before it is formatted.
+#[derive(Clone, Copy, Debug, Default)]
+pub struct JsonOptions {
+    /// Write object keys in sorted order instead of the order they were
+    /// received in.
+    pub sort_keys: bool,
+    /// Replace a top-level string whose contents are a JSON object or array
+    /// with that parsed value, for payloads that were encoded twice.
+    pub unwrap_strings: bool,
+}
+
+/// Format a JSON document, keeping the output for a leading value that is
+/// followed by invalid trailing bytes.
+pub fn format_json_prefix_to(
+    bytes: &[u8],
+    out: &mut Printer,
+    options: JsonOptions,
+) -> Result<(), PartialFormatError> {
+    let mut stream = serde_json::Deserializer::from_slice(bytes).into_iter::<Value>();
+    let Some(Ok(mut value)) = stream.next() else {
+        return Err(PartialFormatError { formatted: 0 });
+    };
+    if options.unwrap_strings {
+        value = unwrap_json_string(value);
+    }
+    if options.sort_keys {
+        value.sort_all_objects();
+    }
+    format_json_value_to(&value, out);
+    let formatted = stream.byte_offset();
+    if bytes[formatted..]
+        .iter()
+        .all(|byte| matches!(byte, b' ' | b'\t' | b'\n' | b'\r'))
+    {
+        Ok(())
+    } else {
+        Err(PartialFormatError { formatted })
+    }
+}
+
+/// Parse the contents of a JSON string value, repeating for payloads encoded
+/// more than twice. Strings that do not hold an object or array are kept,
+/// so `"123"` or `"hello"` are not changed.
+fn unwrap_json_string(value: Value) -> Value {
+    let Value::String(inner) = &value else {
+        return value;
+    };
+    match serde_json::from_str::<Value>(inner) {
+        Ok(inner @ (Value::Object(_) | Value::Array(_))) => inner,
+        Ok(inner @ Value::String(_)) => match unwrap_json_string(inner) {
+            unwrapped @ (Value::Object(_) | Value::Array(_)) => unwrapped,
+            _ => value,
+        },
+        _ => value,
+    }
+}
+
 pub(crate) fn format_json_value_to(value: &Value, out: &mut Printer) {
     write_value(out, value, 0);
     out.push('\n');
@@ -53,6 +113,118 @@ pub fn format_ndjson_to(bytes: &[u8], out: &mut Printer) -> Result<(), serde_jso
     Ok(())
 }
 
+/// Format NDJSON records up to the first invalid record.
+pub fn format_ndjson_prefix_to(bytes: &[u8], out: &mut Printer) -> Result<(), PartialFormatError> {
+    let mut stream = serde_json::Deserializer::from_slice(bytes).into_iter::<Value>();
+    let mut formatted = 0;
+    while let Some(value) = stream.next() {
+        let Ok(value) = value else {
+            return Err(PartialFormatError { formatted });
+        };
+        write_line_value(out, &value);
+        out.push('\n');
+        formatted = stream.byte_offset();
+    }
+    Ok(())
+}
+
+/// Return the path of each object key that repeats an earlier key in the
+/// same object. Formatting keeps only the last value for such keys.
+pub fn duplicate_keys(bytes: &[u8]) -> Vec<String> {
+    let mut found = Vec::new();
+    let mut deserializer = serde_json::Deserializer::from_slice(bytes);
+    let seed = DuplicateKeySeed {
+        path: "$".to_string(),
+        found: &mut found,
+    };
+    if seed.deserialize(&mut deserializer).is_err() {
+        return Vec::new();
+    }
+    found
+}
+
+struct DuplicateKeySeed<'a> {
+    path: String,
+    found: &'a mut Vec<String>,
+}
+
+impl<'de> DeserializeSeed<'de> for DuplicateKeySeed<'_> {
+    type Value = ();
+
+    fn deserialize<D: Deserializer<'de>>(self, deserializer: D) -> Result<(), D::Error> {
+        deserializer.deserialize_any(self)
+    }
+}
+
+impl<'de> Visitor<'de> for DuplicateKeySeed<'_> {
+    type Value = ();
+
+    fn expecting(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str("a JSON value")
+    }
+
+    fn visit_bool<E>(self, _value: bool) -> Result<(), E> {
+        Ok(())
+    }
+
+    fn visit_i64<E>(self, _value: i64) -> Result<(), E> {
+        Ok(())
+    }
+
+    fn visit_u64<E>(self, _value: u64) -> Result<(), E> {
+        Ok(())
+    }
+
+    fn visit_f64<E>(self, _value: f64) -> Result<(), E> {
+        Ok(())
+    }
+
+    fn visit_str<E>(self, _value: &str) -> Result<(), E> {
+        Ok(())
+    }
+
+    fn visit_unit<E>(self) -> Result<(), E> {
+        Ok(())
+    }
+
+    fn visit_seq<A: SeqAccess<'de>>(self, mut seq: A) -> Result<(), A::Error> {
+        let mut index = 0;
+        while seq
+            .next_element_seed(DuplicateKeySeed {
+                path: format!("{}[{index}]", self.path),
+                found: &mut *self.found,
+            })?
+            .is_some()
+        {
+            index += 1;
+        }
+        Ok(())
+    }
+
+    fn visit_map<A: MapAccess<'de>>(self, mut map: A) -> Result<(), A::Error> {
+        let mut seen = HashSet::new();
+        while let Some(key) = map.next_key::<String>()? {
+            let path = if !key.is_empty()
+                && key
+                    .chars()
+                    .all(|c| c.is_ascii_alphanumeric() || c == '_' || c == '-')
+            {
+                format!("{}.{key}", self.path)
+            } else {
+                format!("{}[{}]", self.path, Value::String(key.clone()))
+            };
+            if !seen.insert(key) && !self.found.contains(&path) {
+                self.found.push(path.clone());
+            }
+            map.next_value_seed(DuplicateKeySeed {
+                path,
+                found: &mut *self.found,
+            })?;
+        }
+        Ok(())
+    }
+}
+
 fn write_value(out: &mut Printer, value: &Value, indent: usize) {
     match value {
         Value::Null => out.push_str("null"),
@@ -205,6 +377,24 @@ mod tests {
         assert_eq!(String::from_utf8(got).unwrap(), "{\n  \"ok\": \"yes\"\n}\n");
     }
 
+    #[test]
+    fn formats_json_numbers_with_their_original_digits() {
+        let input =
+            br#"{"id":9007199254740993,"big":18446744073709551616,"price":0.1000000000000000055511151231257827,"exp":1E+400,"zero":-0.0,"scale":1.50}"#;
+
+        let got = format_json(input, false).unwrap();
+        assert_eq!(
+            String::from_utf8(got).unwrap(),
+            "{\n  \"id\": 9007199254740993,\n  \"big\": 18446744073709551616,\n  \"price\": 0.1000000000000000055511151231257827,\n  \"exp\": 1E+400,\n  \"zero\": -0.0,\n  \"scale\": 1.50\n}\n"
+        );
+
+        let got = format_json_line(br#"[9007199254740993,1.50]"#, false).unwrap();
+        assert_eq!(
+            String::from_utf8(got).unwrap(),
+            "[9007199254740993, 1.50]\n"
+        );
+    }
+
     #[test]
     fn formats_json_with_color_when_requested() {
         let got = format_json(br#"{"ok":"yes"}"#, true).unwrap();
@@ -255,6 +445,109 @@ mod tests {
         assert!(format_ndjson(br#"{"ok":true} {invalid"#, false).is_err());
     }
 
+    #[test]
+    fn format_prefix_reports_formatted_input_length() {
+        let mut out = Printer::new(false);
+        assert_eq!(
+            format_json_prefix_to(b"[1] \n", &mut out, JsonOptions::default()),
+            Ok(())
+        );
+        assert_eq!(out.bytes(), b"[\n  1\n]\n");
+
+        let mut out = Printer::new(false);
+        assert_eq!(
+            format_json_prefix_to(b"[1] x", &mut out, JsonOptions::default()),
+            Err(PartialFormatError { formatted: 3 })
+        );
+        assert_eq!(
+            format_json_prefix_to(b"[1, x", &mut Printer::new(false), JsonOptions::default()),
+            Err(PartialFormatError { formatted: 0 })
+        );
+
+        let mut out = Printer::new(false);
+        assert_eq!(
+            format_ndjson_prefix_to(b"{\"a\":1}\n{\"b\":2}\nnope\n", &mut out),
+            Err(PartialFormatError { formatted: 15 })
+        );
+        assert_eq!(out.bytes(), b"{ \"a\": 1 }\n{ \"b\": 2 }\n");
+    }
+
+    #[test]
+    fn format_prefix_sorts_nested_object_keys_when_requested() {
+        let input = br#"{"b":1,"a":{"z":true,"c":[{"y":0,"x":0}]}}"#;
+
+        let mut out = Printer::new(false);
+        let options = JsonOptions {
+            sort_keys: true,
+            ..JsonOptions::default()
+        };
+        format_json_prefix_to(input, &mut out, options).unwrap();
+        assert_eq!(
+            String::from_utf8(out.into_bytes()).unwrap(),
+            "{\n  \"a\": {\n    \"c\": [\n      {\n        \"x\": 0,\n        \"y\": 0\n      }\n    ],\n    \"z\": true\n  },\n  \"b\": 1\n}\n"
+        );
+
+        let mut out = Printer::new(false);
+        format_json_prefix_to(input, &mut out, JsonOptions::default()).unwrap();
+        assert!(
+            String::from_utf8(out.into_bytes())
+                .unwrap()
+                .starts_with("{\n  \"b\": 1,")
+        );
+    }
+
+    #[test]
+    fn format_prefix_unwraps_json_encoded_strings_when_requested() {
+        let options = JsonOptions {
+            unwrap_strings: true,
+            ..JsonOptions::default()
+        };
+        let format = |input: &[u8], options| {
+            let mut out = Printer::new(false);
+            format_json_prefix_to(input, &mut out, options).unwrap();
+            String::from_utf8(out.into_bytes()).unwrap()
+        };
+
+        for (input, want) in [
+            (
+                r#""{\"b\":1,\"a\":[true]}""#,
+                "{\n  \"b\": 1,\n  \"a\": [\n    true\n  ]\n}\n",
+            ),
+            (r#"" [1, 2] ""#, "[\n  1,\n  2\n]\n"),
+            (r#""\"{\\\"a\\\":1}\"""#, "{\n  \"a\": 1\n}\n"),
+            (r#""123""#, "\"123\"\n"),
+            (r#""\"hello\"""#, "\"\\\"hello\\\"\"\n"),
+            (r#""{not json}""#, "\"{not json}\"\n"),
+            (r#"{"a":"{\"b\":1}"}"#, "{\n  \"a\": \"{\\\"b\\\":1}\"\n}\n"),
+        ] {
+            assert_eq!(format(input.as_bytes(), options), want, "{input}");
+        }
+
+        let input = r#""{\"b\":1,\"a\":2}""#.as_bytes();
+        assert_eq!(
+            format(input, JsonOptions::default()),
+            "\"{\\\"b\\\":1,\\\"a\\\":2}\"\n"
+        );
+        let options = JsonOptions {
+            sort_keys: true,
+            unwrap_strings: true,
+        };
+        assert_eq!(format(input, options), "{\n  \"a\": 2,\n  \"b\": 1\n}\n");
+    }
+
+    #[test]
+    fn duplicate_keys_reports_paths_of_repeated_object_keys() {
+        let input =
+            br#"{"id":1,"id":2,"items":[{"a":1},{"a":1,"b":2,"a":3,"a":4}],"x y":{},"x y":1.5}"#;
+
+        assert_eq!(
+            duplicate_keys(input),
+            ["$.id", "$.items[1].a", r#"$["x y"]"#]
+        );
+        assert!(duplicate_keys(br#"{"a":{"a":1},"b":[{"a":1},{"a":2}]}"#).is_empty());
+        assert!(duplicate_keys(br#"{"a":1,"a":"#).is_empty());
+    }
+
     #[test]
     fn escapes_json_strings_like_go_formatter() {
         let cases = [
@@ -0,0 +1,175 @@
+use base64::Engine;
+use serde_json::Value;
+
+use super::json;
+use crate::core::{Printer, Sequence};
+
+const LABEL_STYLE: &[Sequence] = &[Sequence::Bold, Sequence::Blue];
+
+/// The JSON header and payload of a JWT, with its signature kept as the
+/// original base64url text.
+#[derive(Debug, Clone, PartialEq)]
+pub struct DecodedJwt<'a> {
+    pub header: Value,
+    pub payload: Value,
+    pub signature: &'a str,
+}
+
+/// Decode a token shaped like `HEADER.PAYLOAD.SIGNATURE`. The header must be
+/// a JSON object and the payload valid JSON; anything else is not treated as
+/// a JWT.
+pub fn decode_jwt(token: &str) -> Option<DecodedJwt<'_>> {
+    let mut segments = token.split('.');
+    let (Some(header), Some(payload), Some(signature), None) = (
+        segments.next(),
+        segments.next(),
+        segments.next(),
+        segments.next(),
+    ) else {
+        return None;
+    };
+    if !signature.bytes().all(is_base64url_byte) {
+        return None;
+    }
+    let header = decode_segment(header).filter(Value::is_object)?;
+    let payload = decode_segment(payload)?;
+    Some(DecodedJwt {
+        header,
+        payload,
+        signature,
+    })
+}
+
+fn decode_segment(segment: &str) -> Option<Value> {
+    if segment.is_empty() || !segment.bytes().all(is_base64url_byte) {
+        return None;
+    }
+    let bytes = base64::engine::general_purpose::URL_SAFE_NO_PAD
+        .decode(segment)
+        .ok()?;
+    serde_json::from_slice(&bytes).ok()
+}
+
+fn is_base64url_byte(byte: u8) -> bool {
+    byte.is_ascii_alphanumeric() || matches!(byte, b'-' | b'_')
+}
+
+/// Find the distinct JWTs in `text`, in the order they first appear. A JWT
+/// header is a JSON object, so its base64url encoding starts with `eyJ`.
+pub fn find_jwts(text: &str) -> Vec<DecodedJwt<'_>> {
+    let mut found: Vec<DecodedJwt<'_>> = Vec::new();
+    let mut tokens = Vec::new();
+    for candidate in text.split(|ch: char| !(ch.is_ascii() && is_token_byte(ch as u8))) {
+        if !candidate.starts_with("eyJ") || tokens.contains(&candidate) {
+            continue;
+        }
+        if let Some(jwt) = decode_jwt(candidate) {
+            tokens.push(candidate);
+            found.push(jwt);
+        }
+    }
+    found
+}
+
+fn is_token_byte(byte: u8) -> bool {
+    is_base64url_byte(byte) || byte == b'.'
+}
+
+/// Write the decoded header and payload as formatted JSON, followed by the
+/// signature, which is dimmed and left encoded.
+pub fn write_jwt_to(out: &mut Printer, jwt: &DecodedJwt<'_>) {
+    out.write_styled("JWT header", LABEL_STYLE);
+    out.push_str(":\n");
+    json::format_json_value_to(&jwt.header, out);
+    out.write_styled("JWT payload", LABEL_STYLE);
+    out.push_str(":\n");
+    json::format_json_value_to(&jwt.payload, out);
+    out.write_styled("JWT signature", LABEL_STYLE);
+    out.push_str(": ");
+    out.write_styled(jwt.signature, &[Sequence::Dim]);
+    out.push('\n');
+}
+
+/// Write every JWT found in `text`, each after a blank line so the blocks
+/// stand apart from the formatted body before them.
+pub fn write_jwts_to(out: &mut Printer, text: &str) {
+    for jwt in find_jwts(text) {
+        out.push('\n');
+        write_jwt_to(out, &jwt);
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    // {"alg":"HS256","typ":"JWT"}.{"sub":"1234567890","name":"John Doe","iat":1516239022}
+    const TOKEN: &str = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.\
+        eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ.\
+        SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c";
+
+    #[test]
+    fn decode_jwt_parses_header_and_payload() {
+        let jwt = decode_jwt(TOKEN).unwrap();
+        assert_eq!(
+            jwt.header,
+            serde_json::json!({"alg": "HS256", "typ": "JWT"})
+        );
+        assert_eq!(jwt.payload["sub"], "1234567890");
+        assert_eq!(jwt.payload["iat"], 1516239022);
+        assert_eq!(jwt.signature, "SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c");
+
+        // Unsecured tokens have an empty signature.
+        let unsecured = "eyJhbGciOiJub25lIn0.eyJhIjoxfQ.";
+        assert_eq!(decode_jwt(unsecured).unwrap().signature, "");
+    }
+
+    #[test]
+    fn decode_jwt_rejects_invalid_segments() {
+        for token in [
+            "eyJhbGciOiJub25lIn0.eyJhIjoxfQ",
+            "eyJhbGciOiJub25lIn0.eyJhIjoxfQ.sig.extra",
+            "eyJhbGciOiJub25lIn0..sig",
+            "eyJhbGciOiJub25lIn0.bm90IGpzb24.sig",
+            "eyJhbGciOiJub25lIn0.eyJhIjoxfQ.si+g",
+            "WzFd.eyJhIjoxfQ.sig",
+            "eyJhbGciOi.eyJhIjoxfQ.sig",
+        ] {
+            assert_eq!(decode_jwt(token), None, "{token}");
+        }
+    }
+
+    #[test]
+    fn find_jwts_scans_text_for_distinct_tokens() {
+        let body = format!(
+            "{{\"access_token\":\"{TOKEN}\",\"id_token\":\"{TOKEN}\",\
+             \"other\":\"eyJub3QiOiJhIGp3dCJ9\",\"version\":\"1.2.3\"}}"
+        );
+        let found = find_jwts(&body);
+        assert_eq!(found.len(), 1);
+        assert_eq!(found[0], decode_jwt(TOKEN).unwrap());
+
+        assert!(find_jwts("Bearer abc.def.ghi").is_empty());
+        assert_eq!(find_jwts(&format!("Bearer {TOKEN}")).len(), 1);
+    }
+
+    #[test]
+    fn write_jwts_formats_json_and_keeps_signature() {
+        let mut out = Printer::new(false);
+        write_jwts_to(&mut out, &format!("token={TOKEN}"));
+        assert_eq!(
+            out.into_string().unwrap(),
+            "\nJWT header:\n{\n  \"alg\": \"HS256\",\n  \"typ\": \"JWT\"\n}\n\
+             JWT payload:\n{\n  \"sub\": \"1234567890\",\n  \"name\": \"John Doe\",\n  \"iat\": 1516239022\n}\n\
+             JWT signature: SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c\n"
+        );
+
+        let mut out = Printer::new(true);
+        write_jwt_to(&mut out, &decode_jwt(TOKEN).unwrap());
+        let output = out.into_string().unwrap();
+        assert!(
+            output.contains("\x1b[2mSflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c\x1b[0m"),
+            "{output:?}"
+        );
+    }
+}
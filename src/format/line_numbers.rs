@@ -0,0 +1,94 @@
+use std::io::Write as _;
+
+use crate::core::{Printer, Sequence};
+
+const ESC: u8 = 0x1b;
+
+/// Prefix each line of formatted output with a dim, right-aligned line
+/// number. Lines are counted by newline bytes, which never appear inside an
+/// ANSI escape sequence. The gutter ends with a reset, so any style that was
+/// still active at the end of a line is written again after the gutter.
+pub fn number_lines(bytes: &[u8], use_color: bool) -> Vec<u8> {
+    let total = bytes.split_inclusive(|byte| *byte == b'\n').count();
+    let width = total.to_string().len();
+
+    let mut out = Printer::new(use_color);
+    let mut active = Vec::new();
+    for (index, line) in bytes.split_inclusive(|byte| *byte == b'\n').enumerate() {
+        out.write_styled(&format!("{:>width$} │", index + 1), &[Sequence::Dim]);
+        out.push(' ');
+        out.write_all(&active)
+            .expect("write to printer cannot fail");
+        out.write_all(line).expect("write to printer cannot fail");
+        track_active_styles(&mut active, line);
+    }
+    out.into_bytes()
+}
+
+/// Update the SGR sequences in effect after `line`: a reset clears them, and
+/// any other `ESC [ ... m` sequence is added.
+fn track_active_styles(active: &mut Vec<u8>, line: &[u8]) {
+    let mut index = 0;
+    while index < line.len() {
+        if line[index] != ESC || line.get(index + 1) != Some(&b'[') {
+            index += 1;
+            continue;
+        }
+        let Some(len) = line[index + 2..]
+            .iter()
+            .position(|byte| (0x40..=0x7e).contains(byte))
+        else {
+            return;
+        };
+        let end = index + 2 + len;
+        if line[end] == b'm' {
+            let params = &line[index + 2..end];
+            if params.is_empty() || params == b"0" {
+                active.clear();
+            } else {
+                active.extend_from_slice(&line[index..=end]);
+            }
+        }
+        index = end + 1;
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn number_lines_right_aligns_numbers_to_the_widest() {
+        let input: String = (1..=10).map(|line| format!("line {line}\n")).collect();
+        let output = String::from_utf8(number_lines(input.as_bytes(), false)).unwrap();
+        let lines: Vec<&str> = output.lines().collect();
+        assert_eq!(lines.len(), 10);
+        assert_eq!(lines[0], " 1 │ line 1");
+        assert_eq!(lines[9], "10 │ line 10");
+
+        assert_eq!(number_lines(b"a\nb", false), "1 │ a\n2 │ b".as_bytes());
+        assert_eq!(number_lines(b"\n", false), "1 │ \n".as_bytes());
+        assert!(number_lines(b"", false).is_empty());
+    }
+
+    #[test]
+    fn number_lines_dims_the_gutter_and_restores_open_styles() {
+        let input = b"\x1b[32m\"multi\nline\"\x1b[0m\n\x1b[1mnext\x1b[0m\n";
+        let output = String::from_utf8(number_lines(input, true)).unwrap();
+        assert_eq!(
+            output,
+            "\x1b[2m1 │\x1b[0m \x1b[32m\"multi\n\
+             \x1b[2m2 │\x1b[0m \x1b[32mline\"\x1b[0m\n\
+             \x1b[2m3 │\x1b[0m \x1b[1mnext\x1b[0m\n"
+        );
+    }
+
+    #[test]
+    fn track_active_styles_clears_on_reset() {
+        let mut active = Vec::new();
+        track_active_styles(&mut active, b"\x1b[34m\x1b[1mkey");
+        assert_eq!(active, b"\x1b[34m\x1b[1m");
+        track_active_styles(&mut active, b"\x1b[m done \x1b[2K");
+        assert!(active.is_empty());
+    }
+}
@@ -1,7 +1,7 @@
 use std::fmt;
 
 use crate::core::{Printer, Sequence};
-use crate::format::{css, html, json, xml, yaml};
+use crate::format::{css, html, json, toml, xml, yaml};
 
 const BOLD: Sequence = Sequence::Bold;
 const DIM: Sequence = Sequence::Dim;
@@ -24,17 +24,28 @@ impl std::error::Error for MarkdownError {}
 #[cfg(test)]
 pub(crate) fn format_markdown(buf: &[u8], color: bool) -> Result<Vec<u8>, MarkdownError> {
     let mut out = Printer::new(color);
-    format_markdown_to(buf, &mut out)?;
+    format_markdown_to(buf, &mut out, true)?;
     Ok(out.into_bytes())
 }
 
-pub fn format_markdown_to(buf: &[u8], out: &mut Printer) -> Result<(), MarkdownError> {
-    let rendered = render_markdown_bytes(buf, out.use_color())?;
+/// Render Markdown to the printer. When `delegate_code_blocks` is false,
+/// fenced code blocks are printed verbatim instead of being passed to the
+/// formatter for their language.
+pub fn format_markdown_to(
+    buf: &[u8],
+    out: &mut Printer,
+    delegate_code_blocks: bool,
+) -> Result<(), MarkdownError> {
+    let rendered = render_markdown_bytes(buf, out.use_color(), delegate_code_blocks)?;
     out.push_str(&String::from_utf8_lossy(&rendered));
     Ok(())
 }
 
-fn render_markdown_bytes(buf: &[u8], color: bool) -> Result<Vec<u8>, MarkdownError> {
+fn render_markdown_bytes(
+    buf: &[u8],
+    color: bool,
+    delegate_code_blocks: bool,
+) -> Result<Vec<u8>, MarkdownError> {
     if buf.is_empty() {
         return Ok(Vec::new());
     }
@@ -70,7 +81,10 @@ fn render_markdown_bytes(buf: &[u8], color: bool) -> Result<Vec<u8>, MarkdownErr
         return Ok(out.into_bytes());
     }
 
-    let renderer = Renderer { color };
+    let renderer = Renderer {
+        color,
+        delegate_code_blocks,
+    };
     out.push_str(&renderer.render(&body, 0));
     Ok(out.into_bytes())
 }
@@ -114,6 +128,7 @@ fn trim_trailing_line_ending(buf: &mut Vec<u8>) {
 
 struct Renderer {
     color: bool,
+    delegate_code_blocks: bool,
 }
 
 impl Renderer {
@@ -228,7 +243,12 @@ impl Renderer {
         fence: Fence,
         bq_depth: usize,
     ) -> usize {
+        // An indented fence usually belongs to a list item. Keep the fence at
+        // its original column and strip that indentation from the body, as
+        // CommonMark does, so the block lines up under its item.
+        let indent = " ".repeat(fence.indent);
         self.write_prefix(out, bq_depth);
+        out.push_str(&indent);
         self.write_styled(out, "```", &[DIM]);
         if !fence.lang.is_empty() {
             self.write_styled(out, fence.lang, &[DIM]);
@@ -242,17 +262,18 @@ impl Renderer {
                 i += 1;
                 break;
             }
-            body.push(lines[i]);
+            body.push(strip_fence_indent(lines[i], fence.indent));
             i += 1;
         }
 
         let mut delegated = false;
-        if bq_depth == 0 && !fence.lang.is_empty() && !body.is_empty() {
+        if self.delegate_code_blocks && !fence.lang.is_empty() && !body.is_empty() {
             let content = body.join("\n");
             if let Some(formatted) = format_code_block(fence.lang, content.as_bytes(), self.color) {
-                out.push_str(&String::from_utf8_lossy(&formatted));
-                if !out.ends_with('\n') {
-                    out.push('\n');
+                let formatted = String::from_utf8_lossy(&formatted);
+                let formatted = formatted.strip_suffix('\n').unwrap_or(&formatted);
+                for line in formatted.split('\n') {
+                    self.write_code_line(out, line, &indent, bq_depth);
                 }
                 delegated = true;
             }
@@ -260,18 +281,28 @@ impl Renderer {
 
         if !delegated {
             for line in body {
-                self.write_prefix(out, bq_depth);
-                self.write_styled(out, line, &[CYAN]);
-                out.push('\n');
+                let mut styled = String::new();
+                self.write_styled(&mut styled, line, &[CYAN]);
+                self.write_code_line(out, &styled, &indent, bq_depth);
             }
         }
 
         self.write_prefix(out, bq_depth);
+        out.push_str(&indent);
         self.write_styled(out, "```", &[DIM]);
         out.push('\n');
         i
     }
 
+    fn write_code_line(&self, out: &mut String, line: &str, indent: &str, bq_depth: usize) {
+        self.write_prefix(out, bq_depth);
+        if !line.is_empty() {
+            out.push_str(indent);
+            out.push_str(line);
+        }
+        out.push('\n');
+    }
+
     fn render_indented_code(
         &self,
         out: &mut String,
@@ -476,6 +507,7 @@ impl Renderer {
 
 #[derive(Debug, Clone, Copy)]
 struct Fence<'a> {
+    indent: usize,
     marker: char,
     len: usize,
     lang: &'a str,
@@ -781,7 +813,12 @@ fn parse_fence_open(line: &str) -> Option<Fence<'_>> {
     }
     let info = trimmed[len..].trim();
     let lang = info.split_whitespace().next().unwrap_or("");
-    Some(Fence { marker, len, lang })
+    Some(Fence {
+        indent: line.len() - trimmed.len(),
+        marker,
+        len,
+        lang,
+    })
 }
 
 fn parse_fence_close(line: &str, marker: char, min_len: usize) -> bool {
@@ -790,6 +827,15 @@ fn parse_fence_close(line: &str, marker: char, min_len: usize) -> bool {
     len >= min_len && trimmed[len..].trim().is_empty()
 }
 
+fn strip_fence_indent(line: &str, indent: usize) -> &str {
+    let spaces = line
+        .bytes()
+        .take(indent)
+        .take_while(|byte| *byte == b' ')
+        .count();
+    &line[spaces..]
+}
+
 fn is_indented_code(line: &str) -> bool {
     line.starts_with("    ")
 }
@@ -926,6 +972,7 @@ fn format_code_block(lang: &str, content: &[u8], color: bool) -> Option<Vec<u8>>
         "xml" => format_with_printer(color, |out| xml::format_xml_to(content, out)).ok(),
         "html" => format_with_printer(color, |out| html::format_html_to(content, out)).ok(),
         "css" => format_with_printer(color, |out| css::format_css_to(content, out)).ok(),
+        "toml" => format_with_printer(color, |out| toml::format_toml_to(content, out)).ok(),
         _ => None,
     }
 }
@@ -1157,6 +1204,50 @@ mod tests {
         let output = format("```json\n{\"a\":1}\n```");
         assert!(output.contains("  "));
         assert!(output.contains("\"a\""));
+
+        let output = format_color("```toml\n[server]\nport = 8080\n```");
+        assert!(output.contains("\x1b[36m\x1b[1m[server]\x1b[0m"));
+        assert!(output.contains("\x1b[34m\x1b[1mport\x1b[0m = \x1b[33m8080\x1b[0m"));
+    }
+
+    #[test]
+    fn test_format_markdown_nested_code_block_delegation() {
+        let cases = [
+            (
+                "list item",
+                "- step one:\n\n  ```json\n  {\"a\":1}\n  ```\n- step two",
+                "- step one:\n\n  ```json\n  {\n    \"a\": 1\n  }\n  ```\n\n- step two\n",
+            ),
+            (
+                "blockquote",
+                "> ```json\n> {\"a\":1,\"b\":2}\n> ```",
+                "> ```json\n> {\n>   \"a\": 1,\n>   \"b\": 2\n> }\n> ```\n",
+            ),
+            (
+                "list item in blockquote",
+                "> - item\n>   ```json\n>   {\"a\":1}\n>   ```",
+                "> - item\n> \n>   ```json\n>   {\n>     \"a\": 1\n>   }\n>   ```\n",
+            ),
+            (
+                "undelegated list item keeps body indentation",
+                "1. run:\n   ```sh\n   fetch \\\n     example.com\n   ```",
+                "1. run:\n\n   ```sh\n   fetch \\\n     example.com\n   ```\n",
+            ),
+        ];
+
+        for (name, input, want) in cases {
+            assert_eq!(format(input), want, "{name}");
+        }
+    }
+
+    #[test]
+    fn test_format_markdown_code_block_without_delegation() {
+        let mut out = Printer::new(false);
+        format_markdown_to(b"```json\n{\"a\":1}\n```", &mut out, false).unwrap();
+        assert_eq!(
+            String::from_utf8(out.into_bytes()).unwrap(),
+            "```json\n{\"a\":1}\n```\n"
+        );
     }
 
     #[test]
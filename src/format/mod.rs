@@ -2,12 +2,24 @@ pub mod article;
 pub mod content_type;
 pub mod css;
 pub mod csv;
+pub mod extract;
 pub mod grpc;
+pub mod hexdump;
 pub mod html;
 pub mod json;
+pub mod jwt;
+pub mod line_numbers;
 pub mod markdown;
 pub mod msgpack;
 pub mod protobuf;
 pub mod sse;
+pub mod toml;
 pub mod xml;
 pub mod yaml;
+
+/// A formatter failure that records how many leading input bytes were fully
+/// formatted, so callers can print the rest of the input unformatted.
+#[derive(Clone, Copy, Debug, Eq, PartialEq)]
+pub struct PartialFormatError {
+    pub formatted: usize,
+}
@@ -237,11 +237,11 @@ impl<'a> MsgPackParser<'a> {
         Ok(())
     }
 
+    /// Binary values are opaque, so only their size is shown.
     fn write_binary(&mut self, out: &mut String, len: usize) -> Result<(), MsgPackError> {
-        let bytes = self.read_exact(len)?;
-        out.push('"');
-        base64::engine::general_purpose::STANDARD.encode_string(bytes, out);
-        out.push('"');
+        self.read_exact(len)?;
+        let unit = if len == 1 { "byte" } else { "bytes" };
+        write!(out, "\"<{len} {unit}>\"").expect("write to string cannot fail");
         Ok(())
     }
 
@@ -372,7 +372,7 @@ mod tests {
         let got = String::from_utf8(format_msgpack(&input, false).unwrap()).unwrap();
         assert_eq!(
             got,
-            "{\n  \"str\": \"hello\",\n  \"arr\": [\n    true,\n    null,\n    -5\n  ],\n  \"bin\": \"YWJj\",\n  \"float\": 1,\n  \"-1\": \"neg\"\n}\n"
+            "{\n  \"str\": \"hello\",\n  \"arr\": [\n    true,\n    null,\n    -5\n  ],\n  \"bin\": \"<3 bytes>\",\n  \"float\": 1,\n  \"-1\": \"neg\"\n}\n"
         );
     }
 
@@ -404,10 +404,18 @@ mod tests {
     }
 
     #[test]
-    fn binary_values_are_base64_encoded_without_utf8_validation() {
+    fn binary_values_show_their_size_without_utf8_validation() {
         let input = [0xc4, 0x01, 0xe0];
         let got = String::from_utf8(format_msgpack(&input, false).unwrap()).unwrap();
-        assert_eq!(got, "\"4A==\"\n");
+        assert_eq!(got, "\"<1 byte>\"\n");
+
+        let mut input = vec![0x92, 0xc4, 0x00, 0xc5, 0x01, 0x00];
+        input.extend([0xff; 256]);
+        let got = String::from_utf8(format_msgpack(&input, false).unwrap()).unwrap();
+        assert_eq!(got, "[\n  \"<0 bytes>\",\n  \"<256 bytes>\"\n]\n");
+
+        let err = format_msgpack(&[0xc4, 0x02, 0x00], false).unwrap_err();
+        assert!(err.to_string().contains("unexpected EOF"), "{err}");
     }
 
     #[test]
@@ -0,0 +1,444 @@
+use std::fmt;
+
+use crate::core::{Printer, Sequence};
+
+const KEY_STYLE: &[Sequence] = &[Sequence::Blue, Sequence::Bold];
+const TABLE_STYLE: &[Sequence] = &[Sequence::Cyan, Sequence::Bold];
+const STRING_STYLE: &[Sequence] = &[Sequence::Green];
+const SCALAR_STYLE: &[Sequence] = &[Sequence::Yellow];
+const DATETIME_STYLE: &[Sequence] = &[Sequence::Magenta];
+const COMMENT_STYLE: &[Sequence] = &[Sequence::Dim];
+
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct TomlError(String);
+
+impl fmt::Display for TomlError {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str(&self.0)
+    }
+}
+
+impl std::error::Error for TomlError {}
+
+#[cfg(test)]
+pub(crate) fn format_toml(buf: &[u8], color: bool) -> Result<Vec<u8>, TomlError> {
+    let mut out = Printer::new(color);
+    format_toml_to(buf, &mut out)?;
+    Ok(out.into_bytes())
+}
+
+/// Colorizes a TOML document while preserving its exact text, so key
+/// ordering, whitespace, and comments are written back unchanged.
+pub fn format_toml_to(buf: &[u8], out: &mut Printer) -> Result<(), TomlError> {
+    let input = String::from_utf8_lossy(buf);
+    Formatter::new(&input, out).run()
+}
+
+#[derive(Clone, Copy, PartialEq, Eq)]
+enum Container {
+    Array,
+    InlineTable,
+}
+
+struct Formatter<'a, 'p> {
+    input: &'a str,
+    out: &'p mut Printer,
+    index: usize,
+    containers: Vec<Container>,
+    expect_key: bool,
+    line_start: bool,
+}
+
+impl<'a, 'p> Formatter<'a, 'p> {
+    fn new(input: &'a str, out: &'p mut Printer) -> Self {
+        Self {
+            input,
+            out,
+            index: 0,
+            containers: Vec::new(),
+            expect_key: true,
+            line_start: true,
+        }
+    }
+
+    fn run(mut self) -> Result<(), TomlError> {
+        while let Some(ch) = self.peek() {
+            match ch {
+                '\n' => {
+                    self.push_char(ch);
+                    if self.containers.is_empty() {
+                        self.expect_key = true;
+                    }
+                    self.line_start = true;
+                    continue;
+                }
+                // Any other whitespace ends a token without starting one, so
+                // pass it through here or the token paths would not advance.
+                _ if ch.is_whitespace() => {
+                    self.push_char(ch);
+                    continue;
+                }
+                _ => {}
+            }
+
+            let line_start = std::mem::replace(&mut self.line_start, false);
+            match ch {
+                '#' => {
+                    let end = self.line_end();
+                    self.write(end, COMMENT_STYLE);
+                }
+                '[' if line_start && self.containers.is_empty() && self.expect_key => {
+                    self.table_header()?;
+                }
+                '"' | '\'' => {
+                    let end = self.string_end(ch)?;
+                    let style = if self.expect_key {
+                        KEY_STYLE
+                    } else {
+                        STRING_STYLE
+                    };
+                    self.write(end, style);
+                }
+                '=' => {
+                    self.push_char(ch);
+                    self.expect_key = false;
+                }
+                '.' if self.expect_key => self.push_char(ch),
+                '{' => {
+                    self.push_char(ch);
+                    self.containers.push(Container::InlineTable);
+                    self.expect_key = true;
+                }
+                '[' => {
+                    self.push_char(ch);
+                    self.containers.push(Container::Array);
+                    self.expect_key = false;
+                }
+                '}' | ']' => {
+                    self.push_char(ch);
+                    self.containers.pop();
+                    self.expect_key = false;
+                }
+                ',' => {
+                    self.push_char(ch);
+                    self.expect_key = self.containers.last() == Some(&Container::InlineTable);
+                }
+                _ if self.expect_key => {
+                    let end = self.token_end(is_key_boundary);
+                    self.write(end, KEY_STYLE);
+                }
+                _ => {
+                    let end = self.value_end();
+                    let token = &self.input[self.index..end];
+                    if is_datetime(token) {
+                        self.write(end, DATETIME_STYLE);
+                    } else if is_number(token) || matches!(token, "true" | "false") {
+                        self.write(end, SCALAR_STYLE);
+                    } else {
+                        self.write(end, &[]);
+                    }
+                }
+            }
+        }
+        Ok(())
+    }
+
+    fn peek(&self) -> Option<char> {
+        self.input[self.index..].chars().next()
+    }
+
+    fn push_char(&mut self, ch: char) {
+        self.out.push(ch);
+        self.index += ch.len_utf8();
+    }
+
+    /// Writes the input up to `end` with `styles`, resetting the style at
+    /// each line break so multi-line strings render correctly in pagers.
+    fn write(&mut self, end: usize, styles: &[Sequence]) {
+        let text = &self.input[self.index..end];
+        for piece in text.split_inclusive('\n') {
+            let body = piece.strip_suffix('\n').unwrap_or(piece);
+            let body = body.strip_suffix('\r').unwrap_or(body);
+            if body.is_empty() || styles.is_empty() {
+                self.out.push_str(body);
+            } else {
+                self.out.write_styled(body, styles);
+            }
+            self.out.push_str(&piece[body.len()..]);
+        }
+        self.index = end;
+    }
+
+    fn line_end(&self) -> usize {
+        let rest = &self.input[self.index..];
+        let line = rest.find('\n').map_or(rest, |end| &rest[..end]);
+        self.index + line.strip_suffix('\r').unwrap_or(line).len()
+    }
+
+    fn table_header(&mut self) -> Result<(), TomlError> {
+        let start = self.index;
+        let close = if self.input[start..].starts_with("[[") {
+            "]]"
+        } else {
+            "]"
+        };
+        let line_end = self.line_end();
+        let mut index = start + close.len();
+        while index < line_end {
+            let ch = self.input[index..].chars().next().unwrap();
+            if ch == '"' || ch == '\'' {
+                index = parse_single_line_string(self.input, index, ch)?;
+                continue;
+            }
+            if self.input[index..].starts_with(close) {
+                self.write(index + close.len(), TABLE_STYLE);
+                self.expect_key = false;
+                return Ok(());
+            }
+            index += ch.len_utf8();
+        }
+        Err(TomlError(
+            "invalid toml: found unclosed table header".to_string(),
+        ))
+    }
+
+    fn string_end(&self, quote: char) -> Result<usize, TomlError> {
+        let triple = if quote == '"' { "\"\"\"" } else { "'''" };
+        if !self.input[self.index..].starts_with(triple) {
+            return parse_single_line_string(self.input, self.index, quote);
+        }
+
+        let mut index = self.index + triple.len();
+        while index < self.input.len() {
+            let ch = self.input[index..].chars().next().unwrap();
+            if quote == '"' && ch == '\\' {
+                index += ch.len_utf8();
+                if let Some(escaped) = self.input[index..].chars().next() {
+                    index += escaped.len_utf8();
+                }
+                continue;
+            }
+            if self.input[index..].starts_with(triple) {
+                // Up to two quotes may directly precede the closing delimiter.
+                let mut end = index + triple.len();
+                for _ in 0..2 {
+                    if self.input[end..].starts_with(quote) {
+                        end += quote.len_utf8();
+                    }
+                }
+                return Ok(end);
+            }
+            index += ch.len_utf8();
+        }
+        Err(TomlError(
+            "invalid toml: found unclosed multi-line string".to_string(),
+        ))
+    }
+
+    fn token_end(&self, is_boundary: fn(char) -> bool) -> usize {
+        self.input[self.index..]
+            .char_indices()
+            .find(|(_, ch)| ch.is_whitespace() || is_boundary(*ch))
+            .map_or(self.input.len(), |(offset, _)| self.index + offset)
+    }
+
+    fn value_end(&self) -> usize {
+        let end = self.token_end(is_value_boundary);
+        // A date and time may be separated by a single space.
+        let token = &self.input[self.index..end];
+        if is_date(token) && self.input[end..].starts_with(' ') {
+            let rest = &self.input[end + 1..];
+            let time_len = rest
+                .find(|ch: char| ch.is_whitespace() || is_value_boundary(ch))
+                .unwrap_or(rest.len());
+            if is_time(&rest[..time_len]) {
+                return end + 1 + time_len;
+            }
+        }
+        end
+    }
+}
+
+fn parse_single_line_string(input: &str, start: usize, quote: char) -> Result<usize, TomlError> {
+    let mut index = start + quote.len_utf8();
+    while index < input.len() {
+        let ch = input[index..].chars().next().unwrap();
+        index += ch.len_utf8();
+        match ch {
+            '\n' => break,
+            '\\' if quote == '"' => {
+                if let Some(escaped) = input[index..].chars().next() {
+                    if escaped == '\n' {
+                        break;
+                    }
+                    index += escaped.len_utf8();
+                }
+            }
+            _ if ch == quote => return Ok(index),
+            _ => {}
+        }
+    }
+    Err(TomlError("invalid toml: found unclosed quote".to_string()))
+}
+
+fn is_key_boundary(ch: char) -> bool {
+    matches!(
+        ch,
+        '=' | '.' | '#' | ',' | '[' | ']' | '{' | '}' | '"' | '\''
+    )
+}
+
+fn is_value_boundary(ch: char) -> bool {
+    matches!(ch, '=' | '#' | ',' | '[' | ']' | '{' | '}')
+}
+
+fn is_number(token: &str) -> bool {
+    let unsigned = token
+        .strip_prefix('+')
+        .or_else(|| token.strip_prefix('-'))
+        .unwrap_or(token);
+    if matches!(unsigned, "inf" | "nan") {
+        return true;
+    }
+    for (prefix, radix) in [("0x", 16), ("0o", 8), ("0b", 2)] {
+        if let Some(digits) = token.strip_prefix(prefix) {
+            return !digits.is_empty() && digits.chars().all(|ch| ch == '_' || ch.is_digit(radix));
+        }
+    }
+    if !unsigned.starts_with(|ch: char| ch.is_ascii_digit()) {
+        return false;
+    }
+    unsigned.replace('_', "").parse::<f64>().is_ok()
+}
+
+fn is_date(token: &str) -> bool {
+    let bytes = token.as_bytes();
+    bytes.len() == 10
+        && bytes[4] == b'-'
+        && bytes[7] == b'-'
+        && bytes
+            .iter()
+            .enumerate()
+            .all(|(index, byte)| index == 4 || index == 7 || byte.is_ascii_digit())
+}
+
+fn is_time(token: &str) -> bool {
+    let bytes = token.as_bytes();
+    bytes.len() >= 8
+        && bytes[2] == b':'
+        && bytes[5] == b':'
+        && [0, 1, 3, 4, 6, 7]
+            .iter()
+            .all(|index| bytes[*index].is_ascii_digit())
+        && bytes[8..].iter().all(|byte| {
+            byte.is_ascii_digit() || matches!(byte, b'.' | b'Z' | b'z' | b'+' | b'-' | b':')
+        })
+}
+
+fn is_datetime(token: &str) -> bool {
+    if is_date(token) || is_time(token) {
+        return true;
+    }
+    token.len() > 11
+        && is_date(&token[..10])
+        && matches!(token.as_bytes()[10], b'T' | b't' | b' ')
+        && is_time(&token[11..])
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_format_toml() {
+        let tests = [
+            ("empty input", "", false),
+            ("simple pair", "key = \"value\"\n", false),
+            ("table", "[server]\nhost = \"localhost\"\n", false),
+            ("array of tables", "[[items]]\nname = \"a\"\n", false),
+            ("dotted key", "a.b.c = 1\n", false),
+            ("quoted key", "\"a key\" = 1\n", false),
+            ("inline table", "point = { x = 1, y = 2 }\n", false),
+            ("array", "ports = [8000, 8001]\n", false),
+            (
+                "multi-line array",
+                "ports = [\n  8000,\n  8001,\n]\n",
+                false,
+            ),
+            ("basic multi-line", "text = \"\"\"\nline\n\"\"\"\n", false),
+            ("literal multi-line", "text = '''\nline\n'''\n", false),
+            ("literal string", "path = 'C:\\Users'\n", false),
+            ("escaped quote", "key = \"a \\\" b\"\n", false),
+            ("comment", "# comment\nkey = 1 # inline\n", false),
+            ("datetime", "at = 1979-05-27T07:32:00Z\n", false),
+            ("unclosed double quote", "key = \"value\n", true),
+            ("unclosed single quote", "key = 'value\n", true),
+            ("unclosed multi-line", "key = \"\"\"value\n", true),
+            ("unclosed header", "[server\nkey = 1\n", true),
+        ];
+
+        for (name, input, want_err) in tests {
+            let got_err = format_toml(input.as_bytes(), false).is_err();
+            assert_eq!(got_err, want_err, "{name}");
+        }
+    }
+
+    #[test]
+    fn test_format_toml_preserves_structure() {
+        let inputs = [
+            "z = 1\na = 2",
+            "[b]\nz = 1\n\n[a]\ny = 2\n",
+            "key = \"value\"\r\nother = 'x'\r\n",
+            "point = {x=1,y=2}  # origin\n",
+            "text = \"\"\"\none\ntwo\"\"\"\"\"\nnext = true\n",
+        ];
+
+        for input in inputs {
+            let output = String::from_utf8(format_toml(input.as_bytes(), false).unwrap()).unwrap();
+            assert_eq!(output, input, "{input:?}");
+        }
+    }
+
+    #[test]
+    fn passes_through_unicode_whitespace() {
+        let inputs = [
+            "a = 1\u{a0}\n",
+            "\u{c}b = 2\n",
+            "c\u{b}= 3\n",
+            "d =\u{2003}[1,\u{a0}2]\n",
+        ];
+
+        for input in inputs {
+            let output = String::from_utf8(format_toml(input.as_bytes(), false).unwrap()).unwrap();
+            assert_eq!(output, input, "{input:?}");
+        }
+    }
+
+    #[test]
+    fn formats_toml_with_color_when_requested() {
+        let input = "# config\n[server.http]\nhost = \"localhost\"\nport = 8_080\ndebug = false\n\
+                     started = 1979-05-27 07:32:00Z\n[[servers]]\npoint = { x = 1.5, \"y\" = 'b' }\n\
+                     tags = [\"a\", 2]\n";
+        let output = String::from_utf8(format_toml(input.as_bytes(), true).unwrap()).unwrap();
+        assert!(output.contains("\x1b[2m# config\x1b[0m"));
+        assert!(output.contains("\x1b[36m\x1b[1m[server.http]\x1b[0m"));
+        assert!(output.contains("\x1b[36m\x1b[1m[[servers]]\x1b[0m"));
+        assert!(output.contains("\x1b[34m\x1b[1mhost\x1b[0m = \x1b[32m\"localhost\"\x1b[0m"));
+        assert!(output.contains("\x1b[33m8_080\x1b[0m"));
+        assert!(output.contains("\x1b[33mfalse\x1b[0m"));
+        assert!(output.contains("\x1b[35m1979-05-27 07:32:00Z\x1b[0m"));
+        assert!(output.contains("{ \x1b[34m\x1b[1mx\x1b[0m = \x1b[33m1.5\x1b[0m, "));
+        assert!(output.contains("\x1b[34m\x1b[1m\"y\"\x1b[0m = \x1b[32m'b'\x1b[0m }"));
+        assert!(output.contains("[\x1b[32m\"a\"\x1b[0m, \x1b[33m2\x1b[0m]"));
+    }
+
+    #[test]
+    fn styles_each_line_of_multi_line_strings() {
+        let input = "text = \"\"\"\r\none\ntwo\"\"\"\n";
+        let output = String::from_utf8(format_toml(input.as_bytes(), true).unwrap()).unwrap();
+        assert_eq!(
+            output,
+            "\x1b[34m\x1b[1mtext\x1b[0m = \x1b[32m\"\"\"\x1b[0m\r\n\x1b[32mone\x1b[0m\n\x1b[32mtwo\"\"\"\x1b[0m\n"
+        );
+    }
+}
@@ -1,8 +1,10 @@
 use quick_xml::events::{BytesStart, Event};
 use quick_xml::{Reader, XmlVersion};
+use serde_json::{Map, Value};
 use std::fmt;
 use std::io::Cursor;
 
+use super::PartialFormatError;
 use crate::core::{Printer, Sequence};
 
 #[derive(Debug, Clone, PartialEq, Eq)]
@@ -24,6 +26,38 @@ pub(crate) fn format_xml(buf: &[u8], color: bool) -> Result<Vec<u8>, XmlError> {
 }
 
 pub fn format_xml_to(buf: &[u8], out: &mut Printer) -> Result<(), XmlError> {
+    format_xml_events_to(buf, out, &mut XmlCheckpoint::default())
+}
+
+/// Format XML up to the last complete event before a parse error, dropping
+/// any output written for the event that failed.
+pub fn format_xml_prefix_to(buf: &[u8], out: &mut Printer) -> Result<(), PartialFormatError> {
+    let mut checkpoint = XmlCheckpoint {
+        input: 0,
+        output: out.bytes().len(),
+    };
+    if format_xml_events_to(buf, out, &mut checkpoint).is_err() {
+        out.truncate(checkpoint.output);
+        return Err(PartialFormatError {
+            formatted: checkpoint.input,
+        });
+    }
+    Ok(())
+}
+
+/// The input and output positions after the last event whose input is fully
+/// reflected in the output.
+#[derive(Default)]
+struct XmlCheckpoint {
+    input: usize,
+    output: usize,
+}
+
+fn format_xml_events_to(
+    buf: &[u8],
+    out: &mut Printer,
+    checkpoint: &mut XmlCheckpoint,
+) -> Result<(), XmlError> {
     let mut reader = Reader::from_reader(Cursor::new(buf));
     reader.config_mut().trim_text(false);
 
@@ -112,10 +146,142 @@ pub fn format_xml_to(buf: &[u8], out: &mut Printer) -> Result<(), XmlError> {
                 return Ok(());
             }
         }
+        if pending_text.is_empty() {
+            *checkpoint = XmlCheckpoint {
+                input: usize::try_from(reader.buffer_position()).unwrap_or(buf.len()),
+                output: out.bytes().len(),
+            };
+        }
         scratch.clear();
     }
 }
 
+pub fn xml_to_json_value(buf: &[u8]) -> Result<Value, XmlError> {
+    let mut reader = Reader::from_reader(Cursor::new(buf));
+    reader.config_mut().trim_text(false);
+
+    let mut scratch = Vec::new();
+    let mut stack: Vec<(String, Map<String, Value>, String)> = Vec::new();
+    let mut root = Map::new();
+
+    loop {
+        match reader
+            .read_event_into(&mut scratch)
+            .map_err(|err| XmlError(err.to_string()))?
+        {
+            Event::Start(element) => {
+                let attrs = json_attributes(&element, &reader)?;
+                stack.push((local_name(element.name().as_ref()), attrs, String::new()));
+            }
+            Event::Empty(element) => {
+                let attrs = json_attributes(&element, &reader)?;
+                let name = local_name(element.name().as_ref());
+                let value = json_element_value(attrs, "");
+                match stack.last_mut() {
+                    Some((_, children, _)) => insert_json_child(children, name, value),
+                    None => insert_json_child(&mut root, name, value),
+                }
+            }
+            Event::End(_) => {
+                let Some((name, children, text)) = stack.pop() else {
+                    return Err(XmlError("unexpected closing tag".to_string()));
+                };
+                let value = json_element_value(children, &text);
+                match stack.last_mut() {
+                    Some((_, children, _)) => insert_json_child(children, name, value),
+                    None => insert_json_child(&mut root, name, value),
+                }
+            }
+            Event::Text(text) => {
+                if let Some((_, _, pending)) = stack.last_mut() {
+                    let decoded = text.decode().map_err(|err| XmlError(err.to_string()))?;
+                    let unescaped = quick_xml::escape::unescape(&decoded)
+                        .map_err(|err| XmlError(err.to_string()))?;
+                    pending.push_str(&unescaped);
+                }
+            }
+            Event::CData(text) => {
+                if let Some((_, _, pending)) = stack.last_mut() {
+                    let decoded = text.decode().map_err(|err| XmlError(err.to_string()))?;
+                    pending.push_str(&decoded);
+                }
+            }
+            Event::GeneralRef(reference) => {
+                if let Some((_, _, pending)) = stack.last_mut() {
+                    let decoded = reference
+                        .decode()
+                        .map_err(|err| XmlError(err.to_string()))?;
+                    let entity = format!("&{decoded};");
+                    let unescaped = quick_xml::escape::unescape(&entity)
+                        .map_err(|err| XmlError(err.to_string()))?;
+                    pending.push_str(&unescaped);
+                }
+            }
+            Event::Comment(_) | Event::Decl(_) | Event::PI(_) | Event::DocType(_) => {}
+            Event::Eof => {
+                if !stack.is_empty() {
+                    return Err(XmlError("unexpected end of document".to_string()));
+                }
+                if root.is_empty() {
+                    return Err(XmlError("document has no root element".to_string()));
+                }
+                return Ok(Value::Object(root));
+            }
+        }
+        scratch.clear();
+    }
+}
+
+fn json_attributes(
+    element: &BytesStart<'_>,
+    reader: &Reader<Cursor<&[u8]>>,
+) -> Result<Map<String, Value>, XmlError> {
+    let mut attrs = Map::new();
+    for attr in element.attributes() {
+        let attr = attr.map_err(|err| XmlError(err.to_string()))?;
+        let key = String::from_utf8_lossy(attr.key.as_ref());
+        if key == "xmlns" || key.starts_with("xmlns:") {
+            continue;
+        }
+        let value = attr
+            .decoded_and_normalized_value(XmlVersion::Implicit1_0, reader.decoder())
+            .map_err(|err| XmlError(err.to_string()))?;
+        attrs.insert(
+            format!("@{}", local_name(attr.key.as_ref())),
+            Value::String(value.into_owned()),
+        );
+    }
+    Ok(attrs)
+}
+
+fn json_element_value(mut children: Map<String, Value>, text: &str) -> Value {
+    let text = text.trim();
+    if children.is_empty() {
+        return if text.is_empty() {
+            Value::Null
+        } else {
+            Value::String(text.to_string())
+        };
+    }
+    if !text.is_empty() {
+        children.insert("#text".to_string(), Value::String(text.to_string()));
+    }
+    Value::Object(children)
+}
+
+fn insert_json_child(children: &mut Map<String, Value>, name: String, value: Value) {
+    match children.get_mut(&name) {
+        Some(Value::Array(values)) => values.push(value),
+        Some(existing) => {
+            let first = existing.take();
+            *existing = Value::Array(vec![first, value]);
+        }
+        None => {
+            children.insert(name, value);
+        }
+    }
+}
+
 fn flush_xml_text(out: &mut Printer, pending_text: &mut String) {
     let trimmed = pending_text.trim();
     if !trimmed.is_empty() {
@@ -364,6 +530,20 @@ mod tests {
         assert_eq!(output, "<root attr=\"foo &amp; bar\">a &lt; b</root>\n");
     }
 
+    #[test]
+    fn format_xml_prefix_drops_output_for_failed_event() {
+        let mut out = Printer::new(false);
+        out.push_str("before\n");
+
+        let err = format_xml_prefix_to(b"<root a=\"1\"><b c=></b></root>", &mut out).unwrap_err();
+
+        assert_eq!(err, PartialFormatError { formatted: 12 });
+        assert_eq!(
+            String::from_utf8(out.into_bytes()).unwrap(),
+            "before\n<root a=\"1\">"
+        );
+    }
+
     #[test]
     fn formats_xml_with_color_when_requested() {
         let output =
@@ -375,6 +555,40 @@ mod tests {
         assert!(output.contains("\x1b[32mtext\x1b[0m"));
     }
 
+    #[test]
+    fn converts_xml_to_json_with_attributes_text_and_repeated_elements() {
+        let value = xml_to_json_value(
+            br#"<?xml version="1.0"?>
+<!-- users -->
+<ns:users xmlns:ns="urn:users" count="2">
+  <user id="1"><name>Ann &amp; Bo</name><tag>a</tag><tag>b</tag></user>
+  <user id="2"><name><![CDATA[<Cy>]]></name><empty/></user>
+  <note lang="en">hello</note>
+</ns:users>"#,
+        )
+        .unwrap();
+        assert_eq!(
+            value,
+            serde_json::json!({
+                "users": {
+                    "@count": "2",
+                    "user": [
+                        {"@id": "1", "name": "Ann & Bo", "tag": ["a", "b"]},
+                        {"@id": "2", "name": "<Cy>", "empty": null},
+                    ],
+                    "note": {"@lang": "en", "#text": "hello"},
+                }
+            })
+        );
+    }
+
+    #[test]
+    fn xml_to_json_rejects_malformed_documents() {
+        for input in ["", "<!-- only -->", "<a><b></a>", "<a>"] {
+            assert!(xml_to_json_value(input.as_bytes()).is_err(), "{input:?}");
+        }
+    }
+
     #[test]
     fn test_escape_xml_string() {
         let tests = [
@@ -4,6 +4,10 @@ use std::fmt;
 
 use crate::core::{Printer, Sequence};
 
+mod convert;
+
+pub use convert::{json_to_yaml, yaml_to_json_value};
+
 #[derive(Debug, Clone, PartialEq, Eq)]
 pub struct YamlError(String);
 
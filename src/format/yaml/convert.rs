@@ -0,0 +1,889 @@
+use std::borrow::Cow;
+use std::fmt::Write as _;
+
+use serde_json::{Map, Number, Value};
+
+use super::{YamlError, is_plain_string_token};
+
+pub fn json_to_yaml(value: &Value) -> String {
+    let mut out = String::new();
+    write_yaml_node(&mut out, value, 0);
+    out
+}
+
+fn write_yaml_node(out: &mut String, value: &Value, indent: usize) {
+    match value {
+        Value::Object(map) if !map.is_empty() => {
+            for (index, (key, value)) in map.iter().enumerate() {
+                if index > 0 {
+                    push_indent(out, indent);
+                }
+                write_yaml_string(out, key);
+                out.push(':');
+                write_yaml_child(out, value, indent);
+            }
+        }
+        Value::Array(items) if !items.is_empty() => {
+            for (index, item) in items.iter().enumerate() {
+                if index > 0 {
+                    push_indent(out, indent);
+                }
+                out.push_str("- ");
+                write_yaml_node(out, item, indent + 2);
+            }
+        }
+        value => {
+            write_yaml_scalar(out, value);
+            out.push('\n');
+        }
+    }
+}
+
+fn write_yaml_child(out: &mut String, value: &Value, indent: usize) {
+    let nested = match value {
+        Value::Object(map) => !map.is_empty(),
+        Value::Array(items) => !items.is_empty(),
+        _ => false,
+    };
+    if nested {
+        out.push('\n');
+        push_indent(out, indent + 2);
+        write_yaml_node(out, value, indent + 2);
+    } else {
+        out.push(' ');
+        write_yaml_scalar(out, value);
+        out.push('\n');
+    }
+}
+
+fn write_yaml_scalar(out: &mut String, value: &Value) {
+    match value {
+        Value::Null => out.push_str("null"),
+        Value::Bool(value) => out.push_str(if *value { "true" } else { "false" }),
+        Value::Number(value) => write!(out, "{value}").expect("write to string cannot fail"),
+        Value::String(value) => write_yaml_string(out, value),
+        Value::Array(_) => out.push_str("[]"),
+        Value::Object(_) => out.push_str("{}"),
+    }
+}
+
+fn write_yaml_string(out: &mut String, value: &str) {
+    if is_safe_plain_scalar(value) {
+        out.push_str(value);
+    } else {
+        out.push_str(&Value::String(value.to_string()).to_string());
+    }
+}
+
+fn is_safe_plain_scalar(value: &str) -> bool {
+    is_plain_string_token(value)
+        && value.trim() == value
+        && !value.starts_with([
+            '-', '?', ':', ',', '[', ']', '{', '}', '#', '&', '*', '!', '|', '>', '\'', '"', '%',
+            '@', '`',
+        ])
+        && !value.ends_with(':')
+        && !value.contains(": ")
+        && !value.contains(" #")
+        && !value.chars().any(char::is_control)
+        && !resolves_to_non_string(value)
+}
+
+/// Report whether a YAML reader would resolve the plain scalar to a number,
+/// covering YAML 1.2 core schema forms such as `0x1F`, `0o17`, and `.inf`
+/// along with YAML 1.1 forms such as `0b101`, `1_000`, and `1:30`.
+fn resolves_to_non_string(value: &str) -> bool {
+    let lower = value.to_ascii_lowercase().replace('_', "");
+    let unsigned = lower.strip_prefix(['+', '-']).unwrap_or(&lower);
+    if matches!(unsigned, ".inf" | ".nan") {
+        return true;
+    }
+    let radix = [("0x", 16), ("0o", 8), ("0b", 2)]
+        .into_iter()
+        .find_map(|(prefix, radix)| unsigned.strip_prefix(prefix).map(|digits| (digits, radix)));
+    if let Some((digits, radix)) = radix {
+        return !digits.is_empty() && digits.chars().all(|ch| ch.is_digit(radix));
+    }
+    if unsigned.contains(':') {
+        return unsigned.split(':').enumerate().all(|(index, part)| {
+            !part.is_empty()
+                && part.bytes().all(|byte| byte.is_ascii_digit())
+                && (index == 0 || part.len() <= 2)
+        });
+    }
+    is_decimal_number(unsigned)
+}
+
+/// `(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?`
+fn is_decimal_number(value: &str) -> bool {
+    let (mantissa, exponent) = match value.split_once('e') {
+        Some((mantissa, exponent)) => (mantissa, Some(exponent)),
+        None => (value, None),
+    };
+    let (whole, fraction) = mantissa.split_once('.').unwrap_or((mantissa, ""));
+    let digits = |part: &str| part.bytes().all(|byte| byte.is_ascii_digit());
+    let mantissa_valid =
+        digits(whole) && digits(fraction) && (!whole.is_empty() || !fraction.is_empty());
+    let exponent_valid = exponent.is_none_or(|exponent| {
+        let exponent = exponent.strip_prefix(['+', '-']).unwrap_or(exponent);
+        !exponent.is_empty() && digits(exponent)
+    });
+    mantissa_valid && exponent_valid
+}
+
+fn push_indent(out: &mut String, indent: usize) {
+    out.extend(std::iter::repeat_n(' ', indent));
+}
+
+pub fn yaml_to_json_value(buf: &[u8]) -> Result<Value, YamlError> {
+    let input = String::from_utf8_lossy(buf);
+    let mut parser = YamlParser::new(&input)?;
+    parser.skip_directives();
+    let value = parser.parse_node(0)?;
+    parser.finish()?;
+    Ok(value)
+}
+
+struct Line<'a> {
+    number: usize,
+    indent: usize,
+    text: Cow<'a, str>,
+    raw: &'a str,
+}
+
+struct YamlParser<'a> {
+    lines: Vec<Line<'a>>,
+    pos: usize,
+}
+
+impl<'a> YamlParser<'a> {
+    fn new(input: &'a str) -> Result<Self, YamlError> {
+        let mut lines = Vec::new();
+        for (index, raw) in input.lines().enumerate() {
+            let raw = raw.strip_suffix('\r').unwrap_or(raw);
+            let indent = raw.len() - raw.trim_start_matches(' ').len();
+            let rest = &raw[indent..];
+            let text = strip_comment(rest).trim_end();
+            if rest.starts_with('\t') && !text.trim_start().is_empty() {
+                return Err(line_error(
+                    index + 1,
+                    "tab characters are not allowed in indentation",
+                ));
+            }
+            lines.push(Line {
+                number: index + 1,
+                indent,
+                text: Cow::Borrowed(text),
+                raw,
+            });
+        }
+        Ok(Self { lines, pos: 0 })
+    }
+
+    fn peek(&mut self) -> Option<&Line<'a>> {
+        while self
+            .lines
+            .get(self.pos)
+            .is_some_and(|line| line.text.is_empty())
+        {
+            self.pos += 1;
+        }
+        self.lines.get(self.pos)
+    }
+
+    fn skip_directives(&mut self) {
+        while let Some(line) = self.peek() {
+            if line.indent == 0 && line.text.starts_with('%') {
+                self.pos += 1;
+                continue;
+            }
+            if line.indent == 0 && line.text == "---" {
+                self.pos += 1;
+            } else if line.indent == 0
+                && let Some(rest) = line.text.strip_prefix("--- ")
+            {
+                let rest = rest.trim_start().to_string();
+                let line = &mut self.lines[self.pos];
+                line.indent = line.text.len() - rest.len();
+                line.text = Cow::Owned(rest);
+            }
+            return;
+        }
+    }
+
+    fn finish(&mut self) -> Result<(), YamlError> {
+        if self
+            .peek()
+            .is_some_and(|line| line.indent == 0 && line.text == "...")
+        {
+            self.pos += 1;
+        }
+        match self.peek() {
+            None => Ok(()),
+            Some(line) if is_document_marker(line) => Err(line_error(
+                line.number,
+                "multiple YAML documents are not supported",
+            )),
+            Some(line) => Err(line_error(line.number, "unexpected content")),
+        }
+    }
+
+    fn parse_node(&mut self, min_indent: usize) -> Result<Value, YamlError> {
+        let Some(line) = self.peek() else {
+            return Ok(Value::Null);
+        };
+        if line.indent < min_indent {
+            return Ok(Value::Null);
+        }
+        if is_document_marker(line) {
+            return Ok(Value::Null);
+        }
+        let indent = line.indent;
+        if is_sequence_entry(&line.text) {
+            return self.parse_sequence(indent);
+        }
+        if split_mapping_entry(&line.text).is_some() {
+            return self.parse_mapping(indent);
+        }
+        let number = line.number;
+        let text = line.text.to_string();
+        self.pos += 1;
+        if is_block_scalar_header(&text) {
+            return Ok(self.parse_block_scalar(indent.saturating_sub(1), &text));
+        }
+        parse_inline(&text, number)
+    }
+
+    fn parse_sequence(&mut self, indent: usize) -> Result<Value, YamlError> {
+        let mut items = Vec::new();
+        while let Some(line) = self.peek() {
+            if line.indent != indent || !is_sequence_entry(&line.text) {
+                self.check_dedent(indent)?;
+                break;
+            }
+            let rest = &line.text[1..];
+            let item = rest.trim_start();
+            if item.is_empty() {
+                self.pos += 1;
+                items.push(self.parse_node(indent + 1)?);
+            } else if is_block_scalar_header(item) {
+                let header = item.to_string();
+                self.pos += 1;
+                items.push(self.parse_block_scalar(indent, &header));
+            } else {
+                let offset = 1 + rest.len() - item.len();
+                let item = item.to_string();
+                let line = &mut self.lines[self.pos];
+                line.indent += offset;
+                line.text = Cow::Owned(item);
+                items.push(self.parse_node(indent + offset)?);
+            }
+        }
+        Ok(Value::Array(items))
+    }
+
+    fn parse_mapping(&mut self, indent: usize) -> Result<Value, YamlError> {
+        let mut map = Map::new();
+        while let Some(line) = self.peek() {
+            if line.indent != indent {
+                self.check_dedent(indent)?;
+                break;
+            }
+            if is_document_marker(line) {
+                break;
+            }
+            let number = line.number;
+            let Some((key, rest)) = split_mapping_entry(&line.text) else {
+                if is_sequence_entry(&line.text) {
+                    break;
+                }
+                return Err(line_error(number, "expected a mapping key"));
+            };
+            let key = parse_key(key, number)?;
+            let rest = rest.to_string();
+            self.pos += 1;
+            let value = if rest.is_empty() {
+                match self.peek() {
+                    Some(next) if next.indent == indent && is_sequence_entry(&next.text) => {
+                        self.parse_sequence(indent)?
+                    }
+                    Some(next) if next.indent > indent => {
+                        let next_indent = next.indent;
+                        self.parse_node(next_indent)?
+                    }
+                    _ => Value::Null,
+                }
+            } else if is_block_scalar_header(&rest) {
+                self.parse_block_scalar(indent, &rest)
+            } else {
+                parse_inline(&rest, number)?
+            };
+            map.insert(key, value);
+        }
+        Ok(Value::Object(map))
+    }
+
+    fn check_dedent(&mut self, indent: usize) -> Result<(), YamlError> {
+        match self.peek() {
+            Some(line) if line.indent > indent => {
+                Err(line_error(line.number, "unexpected indentation"))
+            }
+            _ => Ok(()),
+        }
+    }
+
+    fn parse_block_scalar(&mut self, parent_indent: usize, header: &str) -> Value {
+        let folded = header.starts_with('>');
+        let chomping = header[1..].chars().find(|&ch| matches!(ch, '-' | '+'));
+
+        let mut content = Vec::new();
+        let mut detected_indent = None;
+        while let Some(line) = self.lines.get(self.pos) {
+            let raw = line.raw;
+            if raw.trim().is_empty() {
+                content.push("");
+                self.pos += 1;
+                continue;
+            }
+            let indent = raw.len() - raw.trim_start_matches(' ').len();
+            let block_indent = *detected_indent.get_or_insert(indent);
+            if indent <= parent_indent || indent < block_indent {
+                break;
+            }
+            content.push(&raw[block_indent..]);
+            self.pos += 1;
+        }
+
+        let trailing = content
+            .iter()
+            .rev()
+            .take_while(|line| line.is_empty())
+            .count();
+        let body = &content[..content.len() - trailing];
+        let mut text = if folded {
+            fold_lines(body)
+        } else {
+            body.join("\n")
+        };
+        if !body.is_empty() {
+            match chomping {
+                Some('-') => {}
+                Some('+') => text.extend(std::iter::repeat_n('\n', trailing + 1)),
+                _ => text.push('\n'),
+            }
+        }
+        Value::String(text)
+    }
+}
+
+fn fold_lines(lines: &[&str]) -> String {
+    let mut out = String::new();
+    let mut previous_empty = true;
+    for line in lines {
+        if line.is_empty() {
+            out.push('\n');
+            previous_empty = true;
+            continue;
+        }
+        if !previous_empty {
+            out.push(' ');
+        }
+        out.push_str(line);
+        previous_empty = false;
+    }
+    out
+}
+
+fn strip_comment(text: &str) -> &str {
+    let mut quote = None;
+    let mut previous = ' ';
+    let mut chars = text.char_indices();
+    while let Some((index, ch)) = chars.next() {
+        match quote {
+            Some('"') if ch == '\\' => {
+                chars.next();
+            }
+            Some(open) if ch == open => quote = None,
+            Some(_) => {}
+            None if ch == '#' && previous.is_whitespace() => return &text[..index],
+            None if matches!(ch, '"' | '\'') && matches!(previous, ' ' | '[' | '{' | ',' | ':') => {
+                quote = Some(ch);
+            }
+            None => {}
+        }
+        previous = ch;
+    }
+    text
+}
+
+fn is_document_marker(line: &Line<'_>) -> bool {
+    line.indent == 0 && (line.text == "---" || line.text == "..." || line.text.starts_with("--- "))
+}
+
+fn is_sequence_entry(text: &str) -> bool {
+    text == "-" || text.starts_with("- ")
+}
+
+fn is_block_scalar_header(text: &str) -> bool {
+    text.starts_with(['|', '>'])
+        && text[1..]
+            .chars()
+            .all(|ch| matches!(ch, '-' | '+') || ch.is_ascii_digit())
+}
+
+fn split_mapping_entry(text: &str) -> Option<(&str, &str)> {
+    if text.starts_with(['[', '{']) || is_sequence_entry(text) {
+        return None;
+    }
+    let search_from = match text.chars().next() {
+        Some(quote @ ('"' | '\'')) => quoted_end(text, quote)?,
+        _ => 0,
+    };
+    let bytes = text.as_bytes();
+    (search_from..bytes.len())
+        .find(|&index| {
+            bytes[index] == b':' && bytes.get(index + 1).is_none_or(|next| *next == b' ')
+        })
+        .map(|index| (text[..index].trim_end(), text[index + 1..].trim()))
+}
+
+fn quoted_end(text: &str, quote: char) -> Option<usize> {
+    let mut chars = text.char_indices().skip(1);
+    while let Some((index, ch)) = chars.next() {
+        if quote == '"' && ch == '\\' {
+            chars.next();
+        } else if ch == quote {
+            if quote == '\'' && text[index + 1..].starts_with('\'') {
+                chars.next();
+                continue;
+            }
+            return Some(index + 1);
+        }
+    }
+    None
+}
+
+fn parse_key(key: &str, number: usize) -> Result<String, YamlError> {
+    match parse_inline(key, number)? {
+        Value::String(key) => Ok(key),
+        Value::Null if key.is_empty() => Err(line_error(number, "empty mapping key")),
+        _ => Ok(key.to_string()),
+    }
+}
+
+fn parse_inline(text: &str, number: usize) -> Result<Value, YamlError> {
+    let mut parser = FlowParser {
+        input: text,
+        pos: 0,
+        number,
+    };
+    let value = parser.parse_value(false)?;
+    parser.skip_spaces();
+    if parser.pos != text.len() {
+        return Err(line_error(number, "unexpected characters after value"));
+    }
+    Ok(value)
+}
+
+struct FlowParser<'a> {
+    input: &'a str,
+    pos: usize,
+    number: usize,
+}
+
+impl<'a> FlowParser<'a> {
+    fn rest(&self) -> &'a str {
+        &self.input[self.pos..]
+    }
+
+    fn skip_spaces(&mut self) {
+        let rest = self.rest();
+        self.pos += rest.len() - rest.trim_start().len();
+    }
+
+    fn error(&self, message: &str) -> YamlError {
+        line_error(self.number, message)
+    }
+
+    fn parse_value(&mut self, in_flow: bool) -> Result<Value, YamlError> {
+        self.skip_spaces();
+        match self.rest().chars().next() {
+            Some('[') => self.parse_flow_sequence(),
+            Some('{') => self.parse_flow_mapping(),
+            Some(quote @ ('"' | '\'')) => self.parse_quoted(quote).map(Value::String),
+            Some('&' | '*' | '!') => {
+                Err(self.error("YAML anchors, aliases, and tags are not supported"))
+            }
+            _ => {
+                let end = if in_flow {
+                    self.rest()
+                        .find([',', ']', '}'])
+                        .unwrap_or(self.rest().len())
+                } else {
+                    self.rest().len()
+                };
+                let plain = self.rest()[..end].trim();
+                self.pos += end;
+                Ok(resolve_plain(plain))
+            }
+        }
+    }
+
+    fn parse_flow_sequence(&mut self) -> Result<Value, YamlError> {
+        self.pos += 1;
+        let mut items = Vec::new();
+        loop {
+            self.skip_spaces();
+            if self.eat(']') {
+                return Ok(Value::Array(items));
+            }
+            items.push(self.parse_value(true)?);
+            self.skip_spaces();
+            if self.eat(']') {
+                return Ok(Value::Array(items));
+            }
+            if !self.eat(',') {
+                return Err(self.error("expected ',' or ']' in flow sequence"));
+            }
+        }
+    }
+
+    fn parse_flow_mapping(&mut self) -> Result<Value, YamlError> {
+        self.pos += 1;
+        let mut map = Map::new();
+        loop {
+            self.skip_spaces();
+            if self.eat('}') {
+                return Ok(Value::Object(map));
+            }
+            let key = match self.rest().chars().next() {
+                Some(quote @ ('"' | '\'')) => self.parse_quoted(quote)?,
+                _ => {
+                    let end = self
+                        .rest()
+                        .find([':', ',', '}'])
+                        .unwrap_or(self.rest().len());
+                    let key = self.rest()[..end].trim().to_string();
+                    self.pos += end;
+                    key
+                }
+            };
+            self.skip_spaces();
+            let value = if self.eat(':') {
+                self.parse_value(true)?
+            } else {
+                Value::Null
+            };
+            map.insert(key, value);
+            self.skip_spaces();
+            if self.eat('}') {
+                return Ok(Value::Object(map));
+            }
+            if !self.eat(',') {
+                return Err(self.error("expected ',' or '}' in flow mapping"));
+            }
+        }
+    }
+
+    fn parse_quoted(&mut self, quote: char) -> Result<String, YamlError> {
+        let Some(end) = quoted_end(self.rest(), quote) else {
+            return Err(self.error("unterminated quoted string"));
+        };
+        let inner = &self.rest()[1..end - 1];
+        let value = if quote == '\'' {
+            inner.replace("''", "'")
+        } else {
+            unescape_double_quoted(inner).ok_or_else(|| self.error("invalid escape sequence"))?
+        };
+        self.pos += end;
+        Ok(value)
+    }
+
+    fn eat(&mut self, ch: char) -> bool {
+        if self.rest().starts_with(ch) {
+            self.pos += ch.len_utf8();
+            true
+        } else {
+            false
+        }
+    }
+}
+
+fn unescape_double_quoted(value: &str) -> Option<String> {
+    let mut out = String::with_capacity(value.len());
+    let mut chars = value.chars();
+    while let Some(ch) = chars.next() {
+        if ch != '\\' {
+            out.push(ch);
+            continue;
+        }
+        let escaped = match chars.next()? {
+            '0' => '\0',
+            'a' => '\x07',
+            'b' => '\x08',
+            't' | '\t' => '\t',
+            'n' => '\n',
+            'v' => '\x0b',
+            'f' => '\x0c',
+            'r' => '\r',
+            'e' => '\x1b',
+            ' ' => ' ',
+            '"' => '"',
+            '/' => '/',
+            '\\' => '\\',
+            'N' => '\u{85}',
+            '_' => '\u{a0}',
+            'x' => hex_escape(&mut chars, 2)?,
+            'u' => hex_escape(&mut chars, 4)?,
+            'U' => hex_escape(&mut chars, 8)?,
+            _ => return None,
+        };
+        out.push(escaped);
+    }
+    Some(out)
+}
+
+fn hex_escape(chars: &mut std::str::Chars<'_>, len: usize) -> Option<char> {
+    let digits: String = chars.by_ref().take(len).collect();
+    if digits.len() != len {
+        return None;
+    }
+    char::from_u32(u32::from_str_radix(&digits, 16).ok()?)
+}
+
+fn resolve_plain(value: &str) -> Value {
+    match value {
+        "" | "~" | "null" | "Null" | "NULL" => return Value::Null,
+        "true" | "True" | "TRUE" => return Value::Bool(true),
+        "false" | "False" | "FALSE" => return Value::Bool(false),
+        _ => {}
+    }
+    let unsigned = value.strip_prefix('+').unwrap_or(value);
+    if let Ok(number) = serde_json::from_str::<Number>(unsigned) {
+        return Value::Number(number);
+    }
+    let radix = [("0x", 16), ("0o", 8)]
+        .into_iter()
+        .find_map(|(prefix, radix)| unsigned.strip_prefix(prefix).map(|digits| (digits, radix)));
+    if let Some((digits, radix)) = radix
+        && let Ok(number) = i64::from_str_radix(digits, radix)
+    {
+        return Value::Number(number.into());
+    }
+    Value::String(value.to_string())
+}
+
+fn line_error(number: usize, message: &str) -> YamlError {
+    YamlError(format!("line {number}: {message}"))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    use serde_json::json;
+
+    fn yaml(input: &str) -> Value {
+        yaml_to_json_value(input.as_bytes()).unwrap()
+    }
+
+    fn yaml_err(input: &str) -> String {
+        yaml_to_json_value(input.as_bytes())
+            .unwrap_err()
+            .to_string()
+    }
+
+    #[test]
+    fn json_to_yaml_writes_block_collections_and_quotes_ambiguous_strings() {
+        let value = json!({
+            "name": "fetch",
+            "version": 1.5,
+            "enabled": true,
+            "missing": null,
+            "tags": ["cli", "http"],
+            "nested": {"list": [{"a": 1, "b": [true]}, []], "empty": {}},
+            "quoted": ["yes", "123", "a: b", " padded", "-dash", "", "line\nbreak"],
+        });
+        assert_eq!(
+            json_to_yaml(&value),
+            "name: fetch
+version: 1.5
+enabled: true
+missing: null
+tags:
+  - cli
+  - http
+nested:
+  list:
+    - a: 1
+      b:
+        - true
+    - []
+  empty: {}
+quoted:
+  - \"yes\"
+  - \"123\"
+  - \"a: b\"
+  - \" padded\"
+  - \"-dash\"
+  - \"\"
+  - \"line\\nbreak\"
+"
+        );
+        assert_eq!(json_to_yaml(&json!("plain")), "plain\n");
+        assert_eq!(json_to_yaml(&json!([[1, 2], 3])), "- - 1\n  - 2\n- 3\n");
+    }
+
+    #[test]
+    fn yaml_to_json_parses_block_and_flow_collections() {
+        let value = yaml(
+            "%YAML 1.2
+---
+# comment
+name: fetch   # trailing comment
+version: 1.5
+count: +3
+hex: 0x1f
+enabled: yes
+off: false
+missing: ~
+empty:
+url: http://example.com/a#b
+tags:
+- cli
+- \"quoted # not comment\"
+nested:
+  list:
+    - a: 1
+      b: [true, 'it''s', {x: 1, y: [2]}]
+    - - inner
+  flow: {a: b, c: }
+\"quoted key\": 'value'
+...
+",
+        );
+        assert_eq!(
+            value,
+            json!({
+                "name": "fetch",
+                "version": 1.5,
+                "count": 3,
+                "hex": 31,
+                "enabled": "yes",
+                "off": false,
+                "missing": null,
+                "empty": null,
+                "url": "http://example.com/a#b",
+                "tags": ["cli", "quoted # not comment"],
+                "nested": {
+                    "list": [
+                        {"a": 1, "b": [true, "it's", {"x": 1, "y": [2]}]},
+                        ["inner"],
+                    ],
+                    "flow": {"a": "b", "c": null},
+                },
+                "quoted key": "value",
+            })
+        );
+    }
+
+    #[test]
+    fn yaml_to_json_parses_block_scalars_and_escapes() {
+        let value = yaml(
+            "literal: |
+  line one
+    indented
+
+folded: >-
+  folded
+  text
+
+  paragraph
+keep: |+
+  kept
+
+escaped: \"tab\\there \\u00e9\"
+",
+        );
+        assert_eq!(
+            value,
+            json!({
+                "literal": "line one\n  indented\n",
+                "folded": "folded text\nparagraph",
+                "keep": "kept\n\n",
+                "escaped": "tab\there é",
+            })
+        );
+        assert_eq!(yaml(""), Value::Null);
+        assert_eq!(yaml("--- 42\n"), json!(42));
+        assert_eq!(yaml("- |\n  text\n- b\n"), json!(["text\n", "b"]));
+    }
+
+    #[test]
+    fn yaml_to_json_rejects_unsupported_documents() {
+        let cases = [
+            (
+                "a: 1\n---\nb: 2\n",
+                "line 2: multiple YAML documents are not supported",
+            ),
+            (
+                "a: &anchor 1\n",
+                "line 1: YAML anchors, aliases, and tags are not supported",
+            ),
+            ("a: 1\n    b: 2\n", "line 2: unexpected indentation"),
+            ("a: 1\nplain\n", "line 2: expected a mapping key"),
+            ("a: [1, 2\n", "line 1: expected ',' or ']' in flow sequence"),
+            ("a: \"open\n", "line 1: unterminated quoted string"),
+            (
+                "a:\n\t- b\n",
+                "line 2: tab characters are not allowed in indentation",
+            ),
+            ("a: \"bad \\q\"\n", "line 1: invalid escape sequence"),
+        ];
+        for (input, expected) in cases {
+            assert_eq!(yaml_err(input), expected, "{input:?}");
+        }
+    }
+
+    #[test]
+    fn json_round_trips_through_yaml() {
+        let value = json!({
+            "users": [{"id": 1, "name": "Ann", "roles": ["admin"]}, {"id": 2, "name": "null"}],
+            "meta": {"next": null, "ratio": 0.25, "note": "x: y # z"},
+        });
+        assert_eq!(yaml(&json_to_yaml(&value)), value);
+    }
+
+    #[test]
+    fn json_to_yaml_quotes_strings_that_resolve_to_numbers() {
+        for value in [
+            "0x1F",
+            "0o17",
+            "0b101",
+            "-0x1f",
+            ".inf",
+            "-.Inf",
+            "+.INF",
+            ".nan",
+            ".NaN",
+            "1_000",
+            "1:30",
+            "190:20:30",
+            "1e5",
+            "1E+5",
+            ".5",
+            "5.",
+            "+12",
+        ] {
+            assert_eq!(
+                json_to_yaml(&json!(value)),
+                format!("\"{value}\"\n"),
+                "{value}"
+            );
+            assert_eq!(yaml(&json_to_yaml(&json!([value]))), json!([value]));
+        }
+        for value in ["0x", "0xZZ", "1.2.3", "e5", "12:345", "inf.", "v1.0"] {
+            assert_eq!(json_to_yaml(&json!(value)), format!("{value}\n"), "{value}");
+        }
+    }
+}
@@ -236,7 +236,7 @@ fn reflection_headers(cli: &Cli, url: &Url, request_body: &[u8]) -> Result<Heade
     );
     crate::http::apply_headers(&mut headers, &cli.headers)?;
     grpc_headers::apply_standard_headers(&mut headers);
-    if let Some(config) = aws_config(cli.aws_sigv4.as_deref())? {
+    if let Some(config) = aws_config(cli.aws_sigv4.as_deref(), url)? {
         let signed_body = Some(RequestBodyPayload::from_bytes(request_body.to_vec(), None));
         apply_aws_sigv4(cli, "POST", url, &mut headers, &signed_body, &config)?;
     }
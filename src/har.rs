@@ -13,6 +13,10 @@ use crate::timing::ResponseTiming;
 
 pub(crate) const CAPTURE_LIMIT: usize = 16 * 1024 * 1024;
 
+/// Headers servers commonly echo to tie a response to the request that
+/// caused it, checked in order.
+const CORRELATION_HEADERS: &[&str] = &["x-request-id", "x-correlation-id"];
+
 #[derive(Clone, Default)]
 pub(crate) struct Capture(Arc<Mutex<CaptureState>>);
 
@@ -81,6 +85,23 @@ struct RequestCapture {
     body: Capture,
 }
 
+/// A correlation ID echoed by the server, with the value fetch sent, if any.
+#[derive(Serialize)]
+pub(crate) struct Correlation {
+    pub header: &'static str,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub sent: Option<String>,
+    pub received: String,
+}
+
+impl Correlation {
+    pub(crate) fn is_mismatch(&self) -> bool {
+        self.sent
+            .as_deref()
+            .is_some_and(|sent| sent != self.received)
+    }
+}
+
 pub(crate) struct ResponseMeta {
     pub status: u16,
     pub status_text: String,
@@ -121,7 +142,26 @@ impl Recorder {
             .clone()
     }
 
+    /// Return the first correlation header the response carries, paired with
+    /// the value sent on the final request.
+    pub(crate) fn correlation(&self, response_headers: &HeaderMap) -> Option<Correlation> {
+        let state = self.0.lock().expect("HAR recorder lock poisoned");
+        CORRELATION_HEADERS.iter().find_map(|&header| {
+            let received = response_headers.get(header)?;
+            Some(Correlation {
+                header,
+                sent: state
+                    .request
+                    .as_ref()
+                    .and_then(|request| request.headers.get(header))
+                    .map(|value| String::from_utf8_lossy(value.as_bytes()).into_owned()),
+                received: String::from_utf8_lossy(received.as_bytes()).into_owned(),
+            })
+        })
+    }
+
     pub(crate) fn serialize(&self, meta: ResponseMeta) -> Result<Vec<u8>, FetchError> {
+        let correlation = self.correlation(&meta.headers);
         let state = self.0.lock().expect("HAR recorder lock poisoned");
         let request = state
             .request
@@ -215,6 +255,7 @@ impl Recorder {
                         ssl,
                     },
                     server_ip_address: meta.remote_ip,
+                    correlation,
                     comment: "fetch records only the final HTTP exchange",
                 }],
             },
@@ -265,6 +306,8 @@ struct Entry<'a> {
     timings: Timings,
     #[serde(rename = "serverIPAddress", skip_serializing_if = "Option::is_none")]
     server_ip_address: Option<String>,
+    #[serde(rename = "_correlation", skip_serializing_if = "Option::is_none")]
+    correlation: Option<Correlation>,
     comment: &'a str,
 }
 #[derive(Serialize)]
@@ -0,0 +1,209 @@
+use super::*;
+
+use futures_util::stream::{self, StreamExt};
+
+const CHECK_HOSTS_WORKERS: usize = 8;
+
+struct HostCheck {
+    url: String,
+    result: Result<(StatusCode, Duration), String>,
+}
+
+pub(super) async fn check_hosts(cli: &Cli) -> Result<i32, FetchError> {
+    let source = cli
+        .check_hosts
+        .as_deref()
+        .expect("check-hosts checked by app");
+    let urls = read_host_list(source)?;
+    if urls.is_empty() {
+        return Err(FetchError::Message(format!(
+            "no URLs found for '--check-hosts {source}'"
+        )));
+    }
+    let http_version = crate::cli::selected_http_version(cli).map_err(FetchError::Message)?;
+    let http_version = effective_http_version(cli, http_version);
+    crate::tls::install_default_crypto_provider();
+
+    let checks: Vec<HostCheck> = stream::iter(urls)
+        .map(|url| check_host(cli, http_version, url))
+        .buffered(CHECK_HOSTS_WORKERS)
+        .collect()
+        .await;
+
+    let stdio = core::stdio();
+    let mut printer = stdio.stdout_printer(cli.color.as_deref());
+    crate::format::markdown::format_markdown_to(
+        render_table(&checks).as_bytes(),
+        &mut printer,
+        true,
+    )
+    .map_err(|err| FetchError::Message(err.to_string()))?;
+    printer.flush_to(&mut std::io::stdout())?;
+
+    Ok(checks
+        .iter()
+        .map(|check| match &check.result {
+            Ok((status, _)) => exit_code(status.as_u16(), cli.ignore_status),
+            Err(_) => 1,
+        })
+        .find(|code| *code != 0)
+        .unwrap_or(0))
+}
+
+fn read_host_list(source: &str) -> Result<Vec<String>, FetchError> {
+    let path = source.strip_prefix('@').unwrap_or(source);
+    let contents = if path == "-" {
+        std::io::read_to_string(std::io::stdin())?
+    } else {
+        std::fs::read_to_string(crate::fileutil::expand_home(path)).map_err(|err| {
+            if err.kind() == ErrorKind::NotFound {
+                FetchError::FileDoesNotExist(path.to_string())
+            } else {
+                err.into()
+            }
+        })?
+    };
+    Ok(contents
+        .lines()
+        .map(str::trim)
+        .filter(|line| !line.is_empty() && !line.starts_with('#'))
+        .map(str::to_string)
+        .collect())
+}
+
+async fn check_host(cli: &Cli, http_version: Option<HttpVersion>, url: String) -> HostCheck {
+    let result = probe_host(cli, http_version, &url)
+        .await
+        .map_err(|err| err.to_string());
+    HostCheck { url, result }
+}
+
+async fn probe_host(
+    cli: &Cli,
+    http_version: Option<HttpVersion>,
+    raw_url: &str,
+) -> Result<(StatusCode, Duration), FetchError> {
+    let mut url = normalize_url(raw_url)?;
+    apply_query(&mut url, &cli.query);
+    let request_start = Instant::now();
+    let request_timeout = cli
+        .timeout
+        .map(|seconds| duration_from_seconds("timeout", seconds))
+        .transpose()?
+        .flatten();
+    let connect_timeout = cli
+        .connect_timeout
+        .map(|seconds| duration_from_seconds("connect-timeout", seconds))
+        .transpose()?
+        .flatten();
+    let client_build = client::ClientBuildContext {
+        mode: client::ClientMode::Request(http_version),
+        request_timeout,
+        connect_timeout,
+        request_start,
+        session: None,
+        connect_timing: None,
+        har: None,
+    };
+    let request_client = Box::pin(client::build_client_for_url(cli, &url, &client_build)).await?;
+
+    let method_name = effective_method(cli);
+    let method = Method::from_bytes(method_name.as_bytes())
+        .map_err(|err| FetchError::Message(format!("invalid method '{method_name}': {err}")))?;
+    let mut headers = HeaderMap::new();
+    headers.insert(
+        USER_AGENT,
+        HeaderValue::from_str(&core::user_agent()).expect("valid user agent"),
+    );
+    apply_headers(&mut headers, &cli.headers)?;
+    let req = build_request(
+        &request_client.client,
+        method,
+        url,
+        headers,
+        None,
+        cli,
+        RequestAuthorization::Cli,
+    )?;
+    let req = apply_request_timeout(req, request_timeout, request_start)?;
+    let response = Box::pin(req.send()).await.map_err(|err| {
+        FetchError::Runtime(
+            timeout_error_message(cli, &err)
+                .unwrap_or_else(|| transport_request_error_message(&err)),
+        )
+    })?;
+    let latency = request_start.elapsed();
+    let status = response.status();
+    drain_response_body_bounded(response).await;
+    Ok((status, latency))
+}
+
+fn render_table(checks: &[HostCheck]) -> String {
+    let mut out = String::from("| URL | STATUS | LATENCY |\n| --- | --- | ---: |\n");
+    for check in checks {
+        let (status, latency) = match &check.result {
+            Ok((status, latency)) => (
+                status.to_string(),
+                crate::timing::format_timing_duration(*latency),
+            ),
+            Err(err) => (format!("error: {err}"), String::new()),
+        };
+        out.push_str(&format!(
+            "| {} | {} | {latency} |\n",
+            table_code_cell(&check.url),
+            table_code_cell(&status)
+        ));
+    }
+    out
+}
+
+fn table_code_cell(value: &str) -> String {
+    format!("`{}`", value.replace('`', "'").replace('|', "%7C"))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn read_host_list_skips_blank_lines_and_comments() {
+        let dir = tempfile::tempdir().unwrap();
+        let path = dir.path().join("hosts.txt");
+        std::fs::write(
+            &path,
+            "# production\nexample.com\n\n  https://example.org/health  \n",
+        )
+        .unwrap();
+
+        let hosts = read_host_list(&format!("@{}", path.display())).unwrap();
+
+        assert_eq!(hosts, ["example.com", "https://example.org/health"]);
+        assert!(matches!(
+            read_host_list("@/definitely/missing/hosts.txt"),
+            Err(FetchError::FileDoesNotExist(_))
+        ));
+    }
+
+    #[test]
+    fn render_table_lists_status_and_latency_for_each_host() {
+        let checks = [
+            HostCheck {
+                url: "https://example.com/a_b_c".to_string(),
+                result: Ok((StatusCode::OK, Duration::from_millis(12))),
+            },
+            HostCheck {
+                url: "https://down.example".to_string(),
+                result: Err("connection refused".to_string()),
+            },
+        ];
+
+        let table = render_table(&checks);
+
+        assert_eq!(
+            table,
+            "| URL | STATUS | LATENCY |\n| --- | --- | ---: |\n\
+             | `https://example.com/a_b_c` | `200 OK` | 12.0 ms |\n\
+             | `https://down.example` | `error: connection refused` |  |\n"
+        );
+    }
+}
@@ -0,0 +1,509 @@
+use super::*;
+
+use md5::Md5;
+use sha1::Sha1;
+use sha2::{Digest as _, Sha256, Sha384, Sha512};
+
+const CHECKSUM_USAGE: &str = "must be ALGORITHM, ALGORITHM:HEX, or an SRI hash such as \
+     sha384-BASE64, where ALGORITHM is sha256, sha384, sha512, sha1, or md5";
+
+/// The digests from `--checksum` that a downloaded response body must match.
+/// A body passes when it matches any one of them. A bare algorithm name has
+/// no expected digest; the body's digest is reported instead.
+#[derive(Clone, Debug, PartialEq, Eq)]
+pub(crate) struct ExpectedChecksum {
+    digests: Vec<ExpectedDigest>,
+    report: Option<ChecksumAlgorithm>,
+}
+
+#[derive(Clone, Debug, PartialEq, Eq)]
+struct ExpectedDigest {
+    algorithm: ChecksumAlgorithm,
+    encoding: DigestEncoding,
+    bytes: Vec<u8>,
+}
+
+/// How a digest was written, so mismatches are reported in the same form.
+#[derive(Clone, Copy, Debug, PartialEq, Eq)]
+enum DigestEncoding {
+    /// `sha256:HEX`
+    Hex,
+    /// Subresource Integrity, `sha384-BASE64`
+    Sri,
+}
+
+#[derive(Clone, Copy, Debug, PartialEq, Eq)]
+enum ChecksumAlgorithm {
+    Md5,
+    Sha1,
+    Sha256,
+    Sha384,
+    Sha512,
+}
+
+impl ChecksumAlgorithm {
+    fn parse(name: &str) -> Option<Self> {
+        match name.to_ascii_lowercase().as_str() {
+            "md5" => Some(Self::Md5),
+            "sha1" | "sha-1" => Some(Self::Sha1),
+            "sha256" | "sha-256" => Some(Self::Sha256),
+            "sha384" | "sha-384" => Some(Self::Sha384),
+            "sha512" | "sha-512" => Some(Self::Sha512),
+            _ => None,
+        }
+    }
+
+    /// SRI only defines the SHA-2 family.
+    fn parse_sri(name: &str) -> Option<Self> {
+        match name.to_ascii_lowercase().as_str() {
+            "sha256" => Some(Self::Sha256),
+            "sha384" => Some(Self::Sha384),
+            "sha512" => Some(Self::Sha512),
+            _ => None,
+        }
+    }
+
+    fn name(self) -> &'static str {
+        match self {
+            Self::Md5 => "md5",
+            Self::Sha1 => "sha1",
+            Self::Sha256 => "sha256",
+            Self::Sha384 => "sha384",
+            Self::Sha512 => "sha512",
+        }
+    }
+
+    fn digest_len(self) -> usize {
+        match self {
+            Self::Md5 => 16,
+            Self::Sha1 => 20,
+            Self::Sha256 => 32,
+            Self::Sha384 => 48,
+            Self::Sha512 => 64,
+        }
+    }
+
+    fn hasher(self) -> AlgorithmHasher {
+        match self {
+            Self::Md5 => AlgorithmHasher::Md5(Md5::new()),
+            Self::Sha1 => AlgorithmHasher::Sha1(Sha1::new()),
+            Self::Sha256 => AlgorithmHasher::Sha256(Sha256::new()),
+            Self::Sha384 => AlgorithmHasher::Sha384(Sha384::new()),
+            Self::Sha512 => AlgorithmHasher::Sha512(Sha512::new()),
+        }
+    }
+}
+
+impl ExpectedDigest {
+    fn parse(value: &str, token: &str) -> Result<Self, FetchError> {
+        let invalid = |usage: String| FetchError::invalid_value("--checksum", value, usage);
+        if let Some((name, hex)) = token.split_once(':') {
+            let algorithm = ChecksumAlgorithm::parse(name)
+                .ok_or_else(|| invalid(CHECKSUM_USAGE.to_string()))?;
+            return Self::parse_hex("--checksum", value, algorithm, hex);
+        }
+
+        let (name, encoded) = token
+            .split_once('-')
+            .ok_or_else(|| invalid(CHECKSUM_USAGE.to_string()))?;
+        let algorithm = ChecksumAlgorithm::parse_sri(name)
+            .ok_or_else(|| invalid(CHECKSUM_USAGE.to_string()))?;
+        // SRI allows options after the digest, as in `sha384-BASE64?opt`.
+        let encoded = encoded
+            .split_once('?')
+            .map_or(encoded, |(encoded, _)| encoded);
+        base64::engine::general_purpose::STANDARD
+            .decode(encoded)
+            .ok()
+            .filter(|bytes| bytes.len() == algorithm.digest_len())
+            .map(|bytes| Self {
+                algorithm,
+                encoding: DigestEncoding::Sri,
+                bytes,
+            })
+            .ok_or_else(|| {
+                invalid(format!(
+                    "{} integrity hashes must be a base64 {}-byte digest",
+                    algorithm.name(),
+                    algorithm.digest_len()
+                ))
+            })
+    }
+
+    fn parse_hex(
+        flag: &str,
+        value: &str,
+        algorithm: ChecksumAlgorithm,
+        hex: &str,
+    ) -> Result<Self, FetchError> {
+        let hex_len = algorithm.digest_len() * 2;
+        if hex.len() != hex_len || !hex.bytes().all(|byte| byte.is_ascii_hexdigit()) {
+            return Err(FetchError::invalid_value(
+                flag,
+                value,
+                format!(
+                    "{} digests must be {hex_len} hex characters",
+                    algorithm.name()
+                ),
+            ));
+        }
+        let bytes = (0..hex.len())
+            .step_by(2)
+            .map(|index| u8::from_str_radix(&hex[index..index + 2], 16).unwrap())
+            .collect();
+        Ok(Self {
+            algorithm,
+            encoding: DigestEncoding::Hex,
+            bytes,
+        })
+    }
+
+    fn format(&self, bytes: &[u8]) -> String {
+        match self.encoding {
+            DigestEncoding::Hex => format!("{}:{}", self.algorithm.name(), hex_encode(bytes)),
+            DigestEncoding::Sri => format!(
+                "{}-{}",
+                self.algorithm.name(),
+                base64::engine::general_purpose::STANDARD.encode(bytes)
+            ),
+        }
+    }
+}
+
+impl ExpectedChecksum {
+    /// Parses one or more space-separated digests, each either
+    /// `ALGORITHM:HEX` or an SRI hash like `sha384-BASE64`. A bare algorithm
+    /// may be paired with the hex digest from `--checksum-verify`.
+    pub(crate) fn parse(value: &str, verify: Option<&str>) -> Result<Self, FetchError> {
+        if let Some(algorithm) = ChecksumAlgorithm::parse(value.trim()) {
+            let digests = match verify {
+                Some(hex) => vec![ExpectedDigest::parse_hex(
+                    "--checksum-verify",
+                    hex,
+                    algorithm,
+                    hex.trim(),
+                )?],
+                None => Vec::new(),
+            };
+            return Ok(Self {
+                digests,
+                report: Some(algorithm),
+            });
+        }
+        if let Some(hex) = verify {
+            return Err(FetchError::invalid_value(
+                "--checksum-verify",
+                hex,
+                "requires --checksum to be a bare algorithm such as sha256",
+            ));
+        }
+        let digests = value
+            .split_whitespace()
+            .map(|token| ExpectedDigest::parse(value, token))
+            .collect::<Result<Vec<_>, _>>()?;
+        if digests.is_empty() {
+            return Err(FetchError::invalid_value(
+                "--checksum",
+                value,
+                CHECKSUM_USAGE,
+            ));
+        }
+        Ok(Self {
+            digests,
+            report: None,
+        })
+    }
+
+    pub(crate) fn from_cli(cli: &Cli) -> Option<Self> {
+        cli.checksum
+            .as_deref()
+            .and_then(|value| Self::parse(value, cli.checksum_verify.as_deref()).ok())
+    }
+
+    pub(crate) fn hasher(&self) -> BodyHasher {
+        let mut hashers: Vec<(ChecksumAlgorithm, AlgorithmHasher)> = Vec::new();
+        let algorithms = self.digests.iter().map(|digest| digest.algorithm);
+        for algorithm in algorithms.chain(self.report) {
+            if !hashers.iter().any(|(existing, _)| *existing == algorithm) {
+                hashers.push((algorithm, algorithm.hasher()));
+            }
+        }
+        BodyHasher { hashers }
+    }
+
+    /// Compares the finished hashes against the expected digests, returning
+    /// a mismatch message that names the expected and actual digests. For a
+    /// bare algorithm, returns the body's digest as `ALGORITHM:HEX` to print.
+    pub(crate) fn verify(&self, hasher: BodyHasher) -> Result<Option<String>, String> {
+        let actual: Vec<(ChecksumAlgorithm, Vec<u8>)> = hasher
+            .hashers
+            .into_iter()
+            .map(|(algorithm, hasher)| (algorithm, hasher.finish()))
+            .collect();
+        let actual_for = |algorithm: ChecksumAlgorithm| {
+            actual
+                .iter()
+                .find(|(actual, _)| *actual == algorithm)
+                .map(|(_, bytes)| bytes.as_slice())
+                .unwrap_or_default()
+        };
+        let reported = self
+            .report
+            .map(|report| format!("{}:{}", report.name(), hex_encode(actual_for(report))));
+        if self.digests.is_empty()
+            || self
+                .digests
+                .iter()
+                .any(|digest| actual_for(digest.algorithm) == digest.bytes)
+        {
+            return Ok(reported);
+        }
+
+        let expected: Vec<String> = self
+            .digests
+            .iter()
+            .map(|digest| digest.format(&digest.bytes))
+            .collect();
+        let mut got: Vec<String> = Vec::new();
+        for digest in &self.digests {
+            let formatted = digest.format(actual_for(digest.algorithm));
+            if !got.contains(&formatted) {
+                got.push(formatted);
+            }
+        }
+        let one_of = if expected.len() > 1 { "one of " } else { "" };
+        Err(format!(
+            "response body checksum mismatch: expected {one_of}{}, got {}",
+            expected.join(" "),
+            got.join(" ")
+        ))
+    }
+
+    pub(crate) fn verify_file(&self, path: &Path) -> Result<Option<String>, FetchError> {
+        let mut file = std::fs::File::open(path)?;
+        let mut hasher = self.hasher();
+        let mut buf = vec![0; 64 * 1024];
+        loop {
+            let n = file.read(&mut buf)?;
+            if n == 0 {
+                break;
+            }
+            hasher.update(&buf[..n]);
+        }
+        self.verify(hasher).map_err(FetchError::Message)
+    }
+}
+
+/// Print a digest computed for `--checksum ALGORITHM` to stderr.
+pub(crate) fn print_digest(color: Option<&str>, digest: &str) {
+    let mut printer = core::Printer::stderr(color);
+    printer.write_info_prefix();
+    printer.write_styled("checksum", &[core::Sequence::Bold]);
+    printer.push_str(": ");
+    printer.push_str(digest);
+    printer.push('\n');
+    core::flush_stderr(printer);
+}
+
+/// Running digests of response body bytes for `--checksum`, one per
+/// distinct algorithm among the expected digests.
+pub(crate) struct BodyHasher {
+    hashers: Vec<(ChecksumAlgorithm, AlgorithmHasher)>,
+}
+
+impl BodyHasher {
+    pub(crate) fn update(&mut self, bytes: &[u8]) {
+        for (_, hasher) in &mut self.hashers {
+            hasher.update(bytes);
+        }
+    }
+}
+
+enum AlgorithmHasher {
+    Md5(Md5),
+    Sha1(Sha1),
+    Sha256(Sha256),
+    Sha384(Sha384),
+    Sha512(Sha512),
+}
+
+impl AlgorithmHasher {
+    fn update(&mut self, bytes: &[u8]) {
+        match self {
+            Self::Md5(hasher) => hasher.update(bytes),
+            Self::Sha1(hasher) => hasher.update(bytes),
+            Self::Sha256(hasher) => hasher.update(bytes),
+            Self::Sha384(hasher) => hasher.update(bytes),
+            Self::Sha512(hasher) => hasher.update(bytes),
+        }
+    }
+
+    fn finish(self) -> Vec<u8> {
+        match self {
+            Self::Md5(hasher) => hasher.finalize().to_vec(),
+            Self::Sha1(hasher) => hasher.finalize().to_vec(),
+            Self::Sha256(hasher) => hasher.finalize().to_vec(),
+            Self::Sha384(hasher) => hasher.finalize().to_vec(),
+            Self::Sha512(hasher) => hasher.finalize().to_vec(),
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    const SHA384_ABC_SRI: &str =
+        "sha384-ywB1P0WjXou1oD1pmsZQBycsMqsO3tFjGotgWkP/W+2AhgcroefMI1i67KE0yCWn";
+
+    fn digest(value: &str, body: &[u8]) -> Result<Option<String>, String> {
+        let checksum = ExpectedChecksum::parse(value, None).unwrap();
+        let mut hasher = checksum.hasher();
+        hasher.update(body);
+        checksum.verify(hasher)
+    }
+
+    #[test]
+    fn checksum_verifies_each_supported_algorithm() {
+        for value in [
+            "md5:900150983cd24fb0d6963f7d28e17f72",
+            "sha1:a9993e364706816aba3e25717850c26c9cd0d89d",
+            "SHA-256:BA7816BF8F01CFEA414140DE5DAE2223B00361A396177A9CB410FF61F20015AD",
+            "sha384:cb00753f45a35e8bb5a03d699ac65007272c32ab0eded1631a8b605a43ff5bed8086072ba1e7cc2358baeca134c825a7",
+            "sha512:ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f",
+            "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=",
+            SHA384_ABC_SRI,
+            "sha512-3a81oZNherrMQXNJriBBMRLm+k6JqX6iCp7u5ktV05ohkpkqJ0/BqDa6PCOj/uu9RU1EI2Q86A4qmslPpUyknw==?ct=text/plain",
+        ] {
+            let reported = digest(value, b"abc").unwrap_or_else(|err| panic!("{value}: {err}"));
+            assert_eq!(reported, None, "{value}");
+        }
+    }
+
+    #[test]
+    fn checksum_reports_digest_for_bare_algorithm() {
+        assert_eq!(
+            digest("sha256", b"abc").unwrap().as_deref(),
+            Some("sha256:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad")
+        );
+        assert_eq!(
+            digest(" MD5 ", b"abc").unwrap().as_deref(),
+            Some("md5:900150983cd24fb0d6963f7d28e17f72")
+        );
+        assert_eq!(
+            digest("sha-1", b"").unwrap().as_deref(),
+            Some("sha1:da39a3ee5e6b4b0d3255bfef95601890afd80709")
+        );
+    }
+
+    #[test]
+    fn checksum_passes_when_any_listed_digest_matches() {
+        let value = format!(
+            "sha256-AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=  {SHA384_ABC_SRI}\tmd5:00000000000000000000000000000000"
+        );
+        digest(&value, b"abc").unwrap();
+
+        let err = digest(&value, b"abd").unwrap_err();
+        assert!(
+            err.starts_with(
+                "response body checksum mismatch: expected one of \
+                 sha256-AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA= sha384-"
+            ),
+            "{err}"
+        );
+        assert!(err.contains(", got sha256-"), "{err}");
+        assert!(err.contains(" md5:"), "{err}");
+    }
+
+    #[test]
+    fn checksum_reports_expected_and_actual_digests() {
+        let err = digest(
+            "sha256:0000000000000000000000000000000000000000000000000000000000000000",
+            b"abc",
+        )
+        .unwrap_err();
+        assert_eq!(
+            err,
+            "response body checksum mismatch: expected \
+             sha256:0000000000000000000000000000000000000000000000000000000000000000, \
+             got sha256:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+        );
+
+        let err = digest(SHA384_ABC_SRI, b"abd").unwrap_err();
+        assert!(
+            err.starts_with(&format!(
+                "response body checksum mismatch: expected {SHA384_ABC_SRI}, got sha384-"
+            )),
+            "{err}"
+        );
+    }
+
+    #[test]
+    fn checksum_rejects_malformed_values() {
+        for (value, want) in [
+            ("", "must be ALGORITHM:HEX"),
+            ("abc", "must be ALGORITHM:HEX"),
+            ("crc32:deadbeef", "must be ALGORITHM:HEX"),
+            ("md5-AAAA", "must be ALGORITHM:HEX"),
+            ("sha256:abc", "sha256 digests must be 64 hex characters"),
+            (
+                "md5:zz0150983cd24fb0d6963f7d28e17f72",
+                "md5 digests must be 32 hex characters",
+            ),
+            (
+                "sha384-not*base64",
+                "sha384 integrity hashes must be a base64 48-byte digest",
+            ),
+            (
+                "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0= sha1:abc",
+                "sha1 digests must be 40 hex characters",
+            ),
+        ] {
+            let err = ExpectedChecksum::parse(value, None)
+                .unwrap_err()
+                .to_string();
+            assert!(err.contains("--checksum"), "{value}: {err}");
+            assert!(err.contains(want), "{value}: {err}");
+        }
+    }
+
+    #[test]
+    fn checksum_verify_checks_the_bare_algorithm_digest() {
+        let verify = |hex: &str, body: &[u8]| {
+            let checksum = ExpectedChecksum::parse("sha1", Some(hex)).unwrap();
+            let mut hasher = checksum.hasher();
+            hasher.update(body);
+            checksum.verify(hasher)
+        };
+        assert_eq!(
+            verify("A9993E364706816ABA3E25717850C26C9CD0D89D", b"abc")
+                .unwrap()
+                .as_deref(),
+            Some("sha1:a9993e364706816aba3e25717850c26c9cd0d89d")
+        );
+        assert_eq!(
+            verify("a9993e364706816aba3e25717850c26c9cd0d89d", b"abd").unwrap_err(),
+            "response body checksum mismatch: expected \
+             sha1:a9993e364706816aba3e25717850c26c9cd0d89d, \
+             got sha1:cb4cc28df0fdbe0ecf9d9662e294b118092a5735"
+        );
+
+        let err = ExpectedChecksum::parse("sha1", Some("abc"))
+            .unwrap_err()
+            .to_string();
+        assert!(err.contains("--checksum-verify"), "{err}");
+        assert!(
+            err.contains("sha1 digests must be 40 hex characters"),
+            "{err}"
+        );
+
+        let err = ExpectedChecksum::parse(
+            "sha1:a9993e364706816aba3e25717850c26c9cd0d89d",
+            Some("a9993e364706816aba3e25717850c26c9cd0d89d"),
+        )
+        .unwrap_err()
+        .to_string();
+        assert!(err.contains("--checksum-verify"), "{err}");
+        assert!(err.contains("bare algorithm"), "{err}");
+    }
+}
@@ -0,0 +1,413 @@
+use super::*;
+
+use std::ffi::OsString;
+use std::path::PathBuf;
+
+const PART_SUFFIX: &str = ".part";
+
+/// A download fetched with range requests. `--chunk-size` appends bounded
+/// ranges to `PATH.part` and renames it once complete; `--continue-at` appends
+/// one open-ended range directly to the output file.
+pub(super) struct ChunkedDownload {
+    path: PathBuf,
+    part_path: PathBuf,
+    clobber: bool,
+    chunk_size: Option<u64>,
+    offset: u64,
+    /// Bytes the output file held before a `--continue-at` run, restored if
+    /// the finished file fails `--checksum`.
+    initial_len: Option<u64>,
+    total: Option<u64>,
+    complete: bool,
+    respect_rate_limit: bool,
+    rate_limit_wait: Option<Duration>,
+    checksum: Option<ExpectedChecksum>,
+}
+
+#[derive(Debug, PartialEq, Eq)]
+struct ContentRange {
+    start: u64,
+    end: u64,
+    total: Option<u64>,
+}
+
+/// Run a ranged download. A `chunk_size` of `None` continues the output file in
+/// place for `--continue-at`.
+pub(super) async fn download_in_chunks(
+    cli: &Cli,
+    http_version: Option<HttpVersion>,
+    url: Url,
+    session: Option<&crate::session::Session>,
+    chunk_size: Option<u64>,
+) -> Result<i32, FetchError> {
+    let mut download = match chunk_size {
+        Some(chunk_size) => ChunkedDownload::open(cli, &url, chunk_size)?,
+        None => ChunkedDownload::open_in_place(cli, &url)?,
+    };
+    let max_rate_limit_wait = RetryPolicy::from_cli(cli)?.max_delay;
+    let request_timeout = cli
+        .timeout
+        .map(|seconds| duration_from_seconds("timeout", seconds))
+        .transpose()?
+        .flatten();
+    loop {
+        let offset = download.offset;
+        let code = Box::pin(execute_request(
+            cli,
+            http_version,
+            url.clone(),
+            None,
+            session,
+            Some(&mut download),
+        ))
+        .await?;
+        if code != 0 || cli.dry_run || cli.trace_headers_only {
+            return Ok(code);
+        }
+        if download.complete {
+            download.finish(cli)?;
+            return Ok(0);
+        }
+        if download.offset == offset {
+            return Err(FetchError::Message(format!(
+                "chunked download made no progress at byte {offset}"
+            )));
+        }
+        if let Some(wait) = download.rate_limit_wait.take() {
+            // Like a retry delay, the wait is capped by --timeout.
+            let requested = wait.min(max_rate_limit_wait);
+            let wait_start = Instant::now();
+            let wait = retry_delay_within_timeout(requested, request_timeout, wait_start)?;
+            write_warning(
+                cli,
+                &format!(
+                    "rate limit reached; waiting {} before the next range",
+                    format_delay(wait)
+                ),
+            );
+            tokio::time::sleep(wait).await;
+            ensure_retry_delay_completed(requested, wait, request_timeout, wait_start)?;
+        }
+    }
+}
+
+impl ChunkedDownload {
+    fn open(cli: &Cli, url: &Url, chunk_size: u64) -> Result<Self, FetchError> {
+        let path = download_path(cli, url)?;
+        if !cli.clobber && path.exists() {
+            let err = output::OutputError::FileExists(path.to_string_lossy().into_owned());
+            return Err(FetchError::Message(err.to_string()));
+        }
+
+        let mut part_path = OsString::from(path.as_os_str());
+        part_path.push(PART_SUFFIX);
+        let part_path = PathBuf::from(part_path);
+        let offset = match std::fs::metadata(&part_path) {
+            Ok(metadata) => metadata.len(),
+            Err(err) if err.kind() == ErrorKind::NotFound => 0,
+            Err(err) => return Err(err.into()),
+        };
+        if offset > 0 && !cli.dry_run && !cli.trace_headers_only {
+            write_warning(
+                cli,
+                &format!(
+                    "resuming download from '{}' at byte {offset}",
+                    part_path.display()
+                ),
+            );
+        }
+
+        Ok(Self {
+            path,
+            part_path,
+            clobber: cli.clobber,
+            chunk_size: Some(chunk_size),
+            offset,
+            initial_len: None,
+            total: None,
+            complete: false,
+            respect_rate_limit: cli.respect_rate_limit,
+            rate_limit_wait: None,
+            checksum: ExpectedChecksum::from_cli(cli),
+        })
+    }
+
+    /// Continue the output file itself, starting after the bytes it already
+    /// holds.
+    fn open_in_place(cli: &Cli, url: &Url) -> Result<Self, FetchError> {
+        let path = download_path(cli, url)?;
+        let initial_len = match std::fs::metadata(&path) {
+            Ok(metadata) => Some(metadata.len()),
+            Err(err) if err.kind() == ErrorKind::NotFound => None,
+            Err(err) => return Err(err.into()),
+        };
+        let offset = initial_len.unwrap_or(0);
+        if offset > 0 && !cli.dry_run && !cli.trace_headers_only {
+            write_warning(
+                cli,
+                &format!("resuming download of '{}' at byte {offset}", path.display()),
+            );
+        }
+
+        Ok(Self {
+            part_path: path.clone(),
+            path,
+            clobber: cli.clobber,
+            chunk_size: None,
+            offset,
+            initial_len,
+            total: None,
+            complete: false,
+            respect_rate_limit: cli.respect_rate_limit,
+            rate_limit_wait: None,
+            checksum: ExpectedChecksum::from_cli(cli),
+        })
+    }
+
+    fn in_place(&self) -> bool {
+        self.chunk_size.is_none()
+    }
+
+    pub(super) fn apply_range(&self, headers: &mut HeaderMap) {
+        let range = match self.chunk_size {
+            Some(chunk_size) => {
+                let mut end = self.offset.saturating_add(chunk_size - 1);
+                if let Some(total) = self.total {
+                    end = end.min(total.saturating_sub(1));
+                }
+                format!("bytes={}-{end}", self.offset)
+            }
+            None if self.offset == 0 => return,
+            None => format!("bytes={}-", self.offset),
+        };
+        headers.insert(
+            RANGE,
+            HeaderValue::from_str(&range).expect("range is a valid header value"),
+        );
+    }
+
+    /// Append a 200 or 206 response body to the partial file, rolling the
+    /// file back to the last completed chunk if the body is cut short. When
+    /// continuing in place, a 200 response replaces the file from the start.
+    pub(super) async fn append<R: AsyncRead + Unpin>(
+        &mut self,
+        status: StatusCode,
+        headers: &HeaderMap,
+        reader: &mut R,
+    ) -> Result<(), FetchError> {
+        let expected = if status == StatusCode::PARTIAL_CONTENT {
+            let range = headers
+                .get(CONTENT_RANGE)
+                .and_then(|value| value.to_str().ok())
+                .and_then(parse_content_range)
+                .ok_or_else(|| {
+                    FetchError::Message(
+                        "range response is missing a valid Content-Range header".into(),
+                    )
+                })?;
+            if range.start != self.offset {
+                return Err(FetchError::Message(format!(
+                    "range response starts at byte {}, expected byte {}",
+                    range.start, self.offset
+                )));
+            }
+            self.total = range.total.or(self.total);
+            Some(range.end - range.start + 1)
+        } else if self.offset == 0 {
+            None
+        } else if self.in_place() {
+            tokio::fs::File::create(&self.part_path).await?;
+            self.offset = 0;
+            // The old bytes are gone, so a mismatch can only empty the file.
+            self.initial_len = self.initial_len.map(|_| 0);
+            None
+        } else {
+            return Err(FetchError::Message(format!(
+                "server ignored the range request; remove '{}' to restart the download",
+                self.part_path.display()
+            )));
+        };
+
+        let mut file = tokio::fs::OpenOptions::new()
+            .create(true)
+            .append(true)
+            .open(&self.part_path)
+            .await?;
+        let written = match tokio::io::copy(reader, &mut file).await {
+            Ok(written) => written,
+            Err(err) => {
+                let _ = file.set_len(self.offset).await;
+                return Err(err.into());
+            }
+        };
+        if let Some(expected) = expected
+            && written != expected
+        {
+            let _ = file.set_len(self.offset).await;
+            return Err(FetchError::Message(format!(
+                "range response at byte {} is truncated: received {written} of {expected} bytes",
+                self.offset
+            )));
+        }
+        file.sync_all().await?;
+
+        self.offset += written;
+        if self.respect_rate_limit {
+            self.rate_limit_wait = parse_rate_limit_reset(headers);
+        }
+        self.complete = match (expected, self.total, self.chunk_size) {
+            (None, _, _) => true,
+            (Some(_), Some(total), _) => self.offset >= total,
+            (Some(_), None, None) => true,
+            (Some(expected), None, Some(chunk_size)) => expected < chunk_size,
+        };
+        if self.complete
+            && let Some(total) = self.total
+            && self.offset != total
+        {
+            return Err(FetchError::Message(format!(
+                "downloaded {} bytes, but Content-Range reports {total}",
+                self.offset
+            )));
+        }
+        Ok(())
+    }
+
+    /// Report whether a 416 response means the partial file already holds the
+    /// whole resource.
+    pub(super) fn range_not_satisfiable(&mut self, headers: &HeaderMap) -> bool {
+        if self.offset == 0 {
+            return false;
+        }
+        let total = headers
+            .get(CONTENT_RANGE)
+            .and_then(|value| value.to_str().ok())
+            .and_then(parse_unsatisfied_range_total);
+        self.complete = total.is_none_or(|total| total == self.offset);
+        self.complete
+    }
+
+    /// Undo a download that failed `--checksum`. An output file continued in
+    /// place is cut back to the bytes it held before this run rather than
+    /// removed, since fetch did not create it.
+    fn discard_mismatch(&self) {
+        match self.initial_len {
+            None => {
+                let _ = std::fs::remove_file(&self.part_path);
+            }
+            Some(len) => {
+                let _ = std::fs::OpenOptions::new()
+                    .write(true)
+                    .open(&self.part_path)
+                    .and_then(|file| file.set_len(len));
+            }
+        }
+    }
+
+    fn finish(self, cli: &Cli) -> Result<(), FetchError> {
+        // The ranges are only hashed once assembled, and a mismatched partial
+        // file is discarded so the next run starts over.
+        if let Some(checksum) = &self.checksum {
+            match checksum.verify_file(&self.part_path) {
+                Ok(Some(digest)) => print_digest(cli.color.as_deref(), &digest),
+                Ok(None) => {}
+                Err(err) => {
+                    self.discard_mismatch();
+                    return Err(err);
+                }
+            }
+        }
+        if self.in_place() {
+            return Ok(());
+        }
+        let result = if self.clobber {
+            crate::fileutil::atomic_replace_file(&self.part_path, &self.path)
+        } else {
+            crate::fileutil::atomic_write_new_file(&self.part_path, &self.path)
+        };
+        match result {
+            Ok(()) => Ok(()),
+            Err(err) if err.kind() == ErrorKind::AlreadyExists => {
+                let err = output::OutputError::FileExists(self.path.to_string_lossy().into_owned());
+                Err(FetchError::Message(err.to_string()))
+            }
+            Err(err) => Err(err.into()),
+        }
+    }
+}
+
+fn download_path(cli: &Cli, url: &Url) -> Result<PathBuf, FetchError> {
+    let resolved = output::resolve_output_path(
+        cli.output.as_deref(),
+        cli.remote_name,
+        false,
+        url,
+        &HeaderMap::new(),
+    )
+    .and_then(|resolved| output::apply_output_dir(resolved, cli.output_dir.as_deref()))
+    .map_err(|err| FetchError::Message(err.to_string()))?;
+    Ok(PathBuf::from(
+        resolved.path.expect("output path checked by app"),
+    ))
+}
+
+fn parse_content_range(value: &str) -> Option<ContentRange> {
+    let rest = value.trim().strip_prefix("bytes ")?;
+    let (range, total) = rest.split_once('/')?;
+    let total = match total.trim() {
+        "*" => None,
+        total => Some(total.parse().ok()?),
+    };
+    let (start, end) = range.trim().split_once('-')?;
+    let start: u64 = start.parse().ok()?;
+    let end: u64 = end.parse().ok()?;
+    if end < start || total.is_some_and(|total| end >= total) {
+        return None;
+    }
+    Some(ContentRange { start, end, total })
+}
+
+fn parse_unsatisfied_range_total(value: &str) -> Option<u64> {
+    value.trim().strip_prefix("bytes */")?.trim().parse().ok()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn parse_content_range_accepts_known_and_unknown_totals() {
+        assert_eq!(
+            parse_content_range("bytes 0-99/1000"),
+            Some(ContentRange {
+                start: 0,
+                end: 99,
+                total: Some(1000),
+            })
+        );
+        assert_eq!(
+            parse_content_range(" bytes 100-199/* "),
+            Some(ContentRange {
+                start: 100,
+                end: 199,
+                total: None,
+            })
+        );
+        for value in [
+            "bytes 10-5/100",
+            "bytes 0-100/100",
+            "bytes */100",
+            "items 0-1/2",
+            "bytes 0-/100",
+        ] {
+            assert_eq!(parse_content_range(value), None, "{value}");
+        }
+    }
+
+    #[test]
+    fn parse_unsatisfied_range_total_reads_complete_length() {
+        assert_eq!(parse_unsatisfied_range_total("bytes */1234"), Some(1234));
+        assert_eq!(parse_unsatisfied_range_total("bytes 0-1/2"), None);
+        assert_eq!(parse_unsatisfied_range_total("bytes */*"), None);
+    }
+}
@@ -14,9 +14,21 @@ use crate::dns::custom;
 use crate::dns::svcb::{HttpsRecordResolver, SvcbRecord};
 use crate::duration::{TimeoutBudget, request_timeout_message};
 use crate::error::FetchError;
+use crate::net::{
+    ConnectTo, InterfaceAddrs, LocalPortRange, ResolveOverride, TcpKeepalive, connect_to_target,
+    resolve_override_addrs,
+};
 use crate::timing::{DnsTiming, TransportTiming};
 use rustls::client::EchMode;
 
+/// Response header limit used when `--max-headers` is unset, matching the
+/// default of Go's `net/http`.
+pub(crate) const DEFAULT_MAX_RESPONSE_HEADER_BYTES: u32 = 1 << 20;
+
+pub(crate) fn max_response_header_bytes(cli: &Cli) -> u32 {
+    cli.max_headers.unwrap_or(DEFAULT_MAX_RESPONSE_HEADER_BYTES)
+}
+
 #[derive(Clone, Copy, Debug)]
 pub(crate) enum ClientMode {
     Request(Option<HttpVersion>),
@@ -105,7 +117,7 @@ pub(crate) async fn build_client_for_url(
     )?;
     let connect_timeout_message = connect_budget.timeout().map(|timeout| {
         if context.connect_timeout == Some(timeout) {
-            request_timeout_message(timeout)
+            connect_budget.timeout_message()
         } else {
             context
                 .request_timeout
@@ -115,13 +127,36 @@ pub(crate) async fn build_client_for_url(
     });
     let dns_timeout = connect_budget.remaining()?;
     let effective_proxy = effective_proxy_for_url(cli.proxy.as_deref(), http_version, url)?;
-    let auto_http3 = auto_http3_allowed(context.mode, url, cli.unix.as_deref(), effective_proxy);
-    let discovery = if dynamic_dns_for_client(cli, url, effective_proxy) {
-        let debug_dns = cli.timing || cli.har.is_some() || (cli.verbose >= 3 && !cli.silent);
+    let resolve_overrides = cli
+        .resolve
+        .iter()
+        .map(|value| ResolveOverride::parse(value))
+        .collect::<Result<Vec<_>, _>>()?;
+    let connect_to = cli
+        .connect_to
+        .iter()
+        .map(|value| ConnectTo::parse(value))
+        .collect::<Result<Vec<_>, _>>()?;
+    // A pinned address or a '--connect-to' reroute means the URL's host is
+    // not what gets dialed, so it is not resolved here.
+    let resolve_pinned =
+        resolve_pins_url(&resolve_overrides, url) || connect_to_reroutes_url(&connect_to, url);
+    // Binding a local port only applies to TCP, so skip racing QUIC, which
+    // would not bind the '--interface' address either. A pinned address also
+    // skips it, since HTTPS records would name other endpoints, and so does a
+    // '--ciphers' list, which QUIC may not be able to use.
+    let auto_http3 = cli.local_port.is_none()
+        && cli.interface.is_none()
+        && cli.ciphers.is_none()
+        && !resolve_pinned
+        && auto_http3_allowed(context.mode, url, cli.unix.as_deref(), effective_proxy);
+    let discovery = if resolve_pinned || dynamic_dns_for_client(cli, url, effective_proxy) {
+        let debug_dns =
+            cli.timing || cli.har.is_some() || cli.write_meta || (cli.verbose >= 3 && !cli.silent);
         ClientDnsDiscovery {
             dns_resolution: None,
             runtime_dns_resolution: debug_dns.then(DnsResolutionHandle::default),
-            dns_server: cli.dns_server.clone(),
+            dns_server: cli.dns_server(),
             auto_http3: None,
             auto_http3_discovery: auto_http3,
             ech_https_records: Vec::new(),
@@ -146,8 +181,39 @@ pub(crate) async fn build_client_for_url(
         .no_gzip()
         .no_zstd();
     builder = configure_http_version(builder, context.mode);
+    if let Some(value) = cli.local_port.as_deref() {
+        builder = builder.local_port(LocalPortRange::parse(value)?);
+    }
+    let interface = cli
+        .interface
+        .as_deref()
+        .map(InterfaceAddrs::resolve)
+        .transpose()?;
+    if let Some(interface) = interface {
+        builder = builder.interface(interface);
+    }
+    builder = builder.tcp_keepalive(TcpKeepalive::from_flags(cli.keepalive, cli.no_keepalive)?);
+    if cli.no_keepalive {
+        builder = builder.no_connection_reuse();
+    }
+    for entry in resolve_overrides {
+        builder = builder.resolve_override(entry);
+    }
+    for entry in connect_to {
+        builder = builder.connect_to(entry);
+    }
+    if let Some(size) = cli.h2_max_frame_size {
+        builder = builder.http2_max_frame_size(size);
+    }
+    if let Some(size) = cli.h2_initial_window_size {
+        builder = builder.http2_initial_window_size(size);
+    }
+    if let Some(max) = cli.h2_max_concurrent_streams {
+        builder = builder.http2_max_concurrent_streams(max);
+    }
+    builder = builder.max_response_header_bytes(max_response_header_bytes(cli));
     builder = configure_unix_socket(builder, cli.unix.as_deref())?;
-    builder = configure_http3_local_address(builder, http_version, url);
+    builder = configure_http3_local_address(builder, http_version, url, interface)?;
     if let Some(auto_http3) = auto_http3_config {
         builder = builder.auto_http3(auto_http3);
     }
@@ -168,7 +234,7 @@ pub(crate) async fn build_client_for_url(
     }
     builder = configure_dns_resolution(builder, url.host_str(), dns_resolution.as_ref());
     if let Some(connect_timing) = context.connect_timing
-        && (cli.timing || cli.har.is_some() || (cli.verbose >= 3 && !cli.silent))
+        && (cli.timing || cli.har.is_some() || cli.write_meta || (cli.verbose >= 2 && !cli.silent))
     {
         builder = builder.connection_timing(connect_timing.clone());
     }
@@ -287,11 +353,8 @@ async fn resolve_dns_for_client_inner(
                 .ok()
                 .flatten()
                 .unwrap_or(Duration::from_secs(5));
-            let https_records = if let Some(dns_server) = cli.dns_server.as_deref() {
-                lookup_ech_https_records(Some(dns_server), host, ech_timeout).await
-            } else {
-                lookup_ech_https_records(None, host, ech_timeout).await
-            };
+            let https_records =
+                lookup_ech_https_records(cli.dns_server().as_deref(), host, ech_timeout).await;
             return Ok(ClientDnsDiscovery {
                 dns_resolution: None,
                 runtime_dns_resolution: None,
@@ -316,7 +379,8 @@ async fn resolve_dns_for_client_inner(
         .then(|| AutoHttp3DiscoveryBudget::new(timeout))
         .flatten();
 
-    if let Some(dns_server) = cli.dns_server.as_deref() {
+    let dns_server = cli.dns_server();
+    if let Some(dns_server) = dns_server.as_deref() {
         let start = Instant::now();
         let (addrs, https_records) = if need_ech_svcb {
             // ECH requires HTTPS records; don't use the abort-early auto-H3
@@ -468,6 +532,18 @@ async fn resolve_dns_for_client_inner(
     })
 }
 
+fn resolve_pins_url(overrides: &[ResolveOverride], url: &Url) -> bool {
+    url.host_str()
+        .zip(url.port_or_known_default())
+        .is_some_and(|(host, port)| resolve_override_addrs(overrides, host, port).is_some())
+}
+
+fn connect_to_reroutes_url(entries: &[ConnectTo], url: &Url) -> bool {
+    url.host_str()
+        .zip(url.port_or_known_default())
+        .is_some_and(|(host, port)| connect_to_target(entries, host, port).is_some())
+}
+
 fn dynamic_dns_for_client(cli: &Cli, url: &Url, effective_proxy: Option<EffectiveProxy>) -> bool {
     url.host_str()
         .is_some_and(|host| host.parse::<IpAddr>().is_err())
@@ -774,14 +850,37 @@ fn configure_http3_local_address(
     builder: ClientBuilder,
     version: Option<HttpVersion>,
     url: &Url,
-) -> ClientBuilder {
+    interface: Option<InterfaceAddrs>,
+) -> Result<ClientBuilder, FetchError> {
     if !matches!(version, Some(HttpVersion::Http3)) {
-        return builder;
+        return Ok(builder);
     }
 
-    match http3_local_address(url) {
+    let addr = match interface {
+        Some(interface) => Some(http3_interface_address(url, interface)?),
+        None => http3_local_address(url),
+    };
+    Ok(match addr {
         Some(addr) => builder.local_address(addr),
         None => builder,
+    })
+}
+
+/// The '--interface' address to bind the HTTP/3 endpoint to. An IP literal
+/// needs an address of its family. Named hosts use the IPv6 address when
+/// there is one, like the default dual-stack bind, and IPv4 otherwise.
+fn http3_interface_address(url: &Url, interface: InterfaceAddrs) -> Result<IpAddr, FetchError> {
+    let destination_ip = url
+        .host_str()
+        .map(|host| host.trim_start_matches('[').trim_end_matches(']'))
+        .and_then(|host| host.parse::<IpAddr>().ok());
+    match destination_ip {
+        Some(ip) => interface.for_remote(ip),
+        None => interface
+            .ipv6
+            .map(IpAddr::V6)
+            .or(interface.ipv4.map(IpAddr::V4))
+            .ok_or_else(|| FetchError::Message("--interface has no usable address".to_string())),
     }
 }
 
@@ -865,13 +964,14 @@ pub(crate) fn configure_tls(
     }
     crate::tls::ensure_rustls_supported_range(min_tls_option, cli.max_tls.as_deref())?;
     builder = builder.tls_config(crate::tls::rustls_platform_client_config_with_options(
-        &cli.ca_cert,
+        &crate::tls::ca_certificate_paths(&cli.ca_cert, &cli.ca_path)?,
         cli.cert.as_deref(),
         cli.key.as_deref(),
         cli.insecure,
         min_tls_option,
         cli.max_tls.as_deref(),
         ech_mode,
+        cli.ciphers.as_deref(),
     )?);
     Ok(builder)
 }
@@ -889,7 +989,7 @@ async fn lookup_custom_ips_with_doh_tls(
     host: &str,
     timeout: TimeoutBudget,
 ) -> Result<Vec<IpAddr>, FetchError> {
-    if dns_server.starts_with("http://") || dns_server.starts_with("https://") {
+    if custom::dns_server_list(dns_server).any(custom::is_doh_dns_server) {
         return crate::net::resolve_host_with_doh_tls(
             host,
             Some(dns_server),
@@ -905,10 +1005,12 @@ async fn lookup_custom_ips_with_doh_tls(
 pub(crate) fn doh_tls_config_for_cli(
     cli: &Cli,
 ) -> Result<Option<rustls::ClientConfig>, FetchError> {
-    let Some(dns_server) = cli.dns_server.as_deref() else {
-        return Ok(None);
-    };
-    if !(dns_server.starts_with("http://") || dns_server.starts_with("https://")) {
+    if !cli
+        .dns_servers
+        .iter()
+        .flat_map(|value| custom::dns_server_list(value))
+        .any(custom::is_doh_dns_server)
+    {
         return Ok(None);
     }
     let min_tls = cli.min_tls.as_deref().or(cli.tls.as_deref());
@@ -924,13 +1026,14 @@ pub(crate) fn doh_tls_config_for_cli(
     // attach origin client certificates to the resolver.
     Ok(Some(
         crate::tls::rustls_platform_client_config_with_options(
-            &cli.ca_cert,
+            &crate::tls::ca_certificate_paths(&cli.ca_cert, &cli.ca_path)?,
             None,
             None,
             cli.insecure,
             min_tls_option,
             cli.max_tls.as_deref(),
             None,
+            cli.ciphers.as_deref(),
         )?,
     ))
 }
@@ -939,11 +1042,13 @@ fn should_configure_tls(cli: &Cli, url: &Url) -> bool {
     url.scheme() == "https"
         || cli.insecure
         || !cli.ca_cert.is_empty()
+        || !cli.ca_path.is_empty()
         || cli.cert.is_some()
         || cli.key.is_some()
         || cli.min_tls.is_some()
         || cli.max_tls.is_some()
         || cli.tls.is_some()
+        || cli.ciphers.is_some()
 }
 
 fn configure_proxy(
@@ -993,7 +1098,9 @@ fn effective_proxy_from_configs(proxy_configs: &[Proxy], url: &Url) -> Option<Ef
 
 fn proxy_configs(proxy: Option<&str>) -> Result<Vec<Proxy>, FetchError> {
     if let Some(proxy) = proxy {
-        let proxy_config = Proxy::all(proxy).map_err(|err| invalid_proxy_error(proxy, err))?;
+        let proxy_config = Proxy::all(proxy)
+            .map_err(|err| invalid_proxy_error(proxy, err))?
+            .no_proxy(NoProxy::from_env());
         return Ok(vec![proxy_config]);
     }
 
@@ -1517,6 +1624,31 @@ mod tests {
         );
     }
 
+    #[test]
+    fn http3_interface_address_matches_destination_family() {
+        let interface = InterfaceAddrs {
+            ipv4: Some(Ipv4Addr::new(10, 0, 0, 2)),
+            ipv6: None,
+        };
+        let url = Url::parse("https://127.0.0.1:3000/").unwrap();
+        assert_eq!(
+            http3_interface_address(&url, interface).unwrap(),
+            IpAddr::V4(Ipv4Addr::new(10, 0, 0, 2))
+        );
+        let url = Url::parse("https://example.com/").unwrap();
+        assert_eq!(
+            http3_interface_address(&url, interface).unwrap(),
+            IpAddr::V4(Ipv4Addr::new(10, 0, 0, 2))
+        );
+        let url = Url::parse("https://[::1]:3000/").unwrap();
+        assert_eq!(
+            http3_interface_address(&url, interface)
+                .unwrap_err()
+                .to_string(),
+            "--interface has no IPv6 address to connect to ::1"
+        );
+    }
+
     #[test]
     fn http3_local_address_uses_dual_stack_bind_for_named_hosts() {
         let url = Url::parse("https://localhost:3000/").unwrap();
@@ -0,0 +1,152 @@
+use super::*;
+
+use cookie::Cookie;
+use time::OffsetDateTime;
+use time::format_description::well_known::Rfc3339;
+
+const LABEL_STYLE: &[core::Sequence] = &[core::Sequence::Bold, core::Sequence::Yellow];
+const NAME_STYLE: &[core::Sequence] = &[core::Sequence::Bold, core::Sequence::Blue];
+const ATTRIBUTE_STYLE: &[core::Sequence] = &[core::Sequence::Cyan];
+
+/// Print one line per cookie in a `Cookie` request header.
+pub(super) fn write_sent_cookies(printer: &mut core::Printer, cookie_header: &str) {
+    for pair in cookie_header.split(';') {
+        let pair = pair.trim();
+        if pair.is_empty() {
+            continue;
+        }
+        let (name, value) = pair.split_once('=').unwrap_or((pair, ""));
+        printer.write_info_prefix();
+        printer.write_styled("Cookie sent", LABEL_STYLE);
+        printer.push_str(": ");
+        write_cookie_pair(printer, name, value);
+        printer.push('\n');
+    }
+}
+
+/// Print one line per `Set-Cookie` response header, with its attributes
+/// parsed. Values that are not valid cookies are skipped, since the raw
+/// header is already shown.
+pub(super) fn write_received_cookies(printer: &mut core::Printer, headers: &HeaderMap) {
+    write_received_cookies_at(printer, headers, OffsetDateTime::now_utc());
+}
+
+fn write_received_cookies_at(
+    printer: &mut core::Printer,
+    headers: &HeaderMap,
+    now: OffsetDateTime,
+) {
+    for value in headers.get_all(http::header::SET_COOKIE) {
+        let Some(cookie) = value
+            .to_str()
+            .ok()
+            .and_then(|value| Cookie::parse(value).ok())
+        else {
+            continue;
+        };
+        printer.write_info_prefix();
+        printer.write_styled("Cookie received", LABEL_STYLE);
+        printer.push_str(": ");
+        write_cookie_pair(printer, cookie.name(), cookie.value());
+        let attributes = cookie_attributes(&cookie, now);
+        if !attributes.is_empty() {
+            printer.push_str(" (");
+            for (index, (name, value)) in attributes.iter().enumerate() {
+                if index > 0 {
+                    printer.push_str(", ");
+                }
+                printer.write_styled(name, ATTRIBUTE_STYLE);
+                if let Some(value) = value {
+                    printer.push(' ');
+                    printer.push_str(value);
+                }
+            }
+            printer.push(')');
+        }
+        printer.push('\n');
+    }
+}
+
+fn write_cookie_pair(printer: &mut core::Printer, name: &str, value: &str) {
+    printer.write_styled(name, NAME_STYLE);
+    printer.push('=');
+    printer.push_str(value);
+}
+
+fn cookie_attributes(
+    cookie: &Cookie<'_>,
+    now: OffsetDateTime,
+) -> Vec<(&'static str, Option<String>)> {
+    let mut attributes = Vec::new();
+    if let Some(domain) = cookie.domain() {
+        attributes.push(("domain", Some(domain.to_string())));
+    }
+    if let Some(path) = cookie.path() {
+        attributes.push(("path", Some(path.to_string())));
+    }
+    if let Some(expires) = cookie.expires_datetime() {
+        let mut value = expires
+            .format(&Rfc3339)
+            .unwrap_or_else(|_| expires.to_string());
+        if expires <= now {
+            value.push_str(" (expired)");
+        }
+        attributes.push(("expires", Some(value)));
+    }
+    if let Some(max_age) = cookie.max_age() {
+        attributes.push(("max-age", Some(format!("{}s", max_age.whole_seconds()))));
+    }
+    if cookie.secure() == Some(true) {
+        attributes.push(("secure", None));
+    }
+    if cookie.http_only() == Some(true) {
+        attributes.push(("httponly", None));
+    }
+    if let Some(same_site) = cookie.same_site() {
+        attributes.push(("samesite", Some(same_site.to_string().to_ascii_lowercase())));
+    }
+    if cookie.partitioned() == Some(true) {
+        attributes.push(("partitioned", None));
+    }
+    attributes
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn sent_cookies_are_listed_one_per_line() {
+        let mut printer = core::Printer::new(false);
+        write_sent_cookies(&mut printer, "sid=abc; theme=dark;;flag");
+        assert_eq!(
+            printer.into_string().unwrap(),
+            "* Cookie sent: sid=abc\n* Cookie sent: theme=dark\n* Cookie sent: flag=\n"
+        );
+    }
+
+    #[test]
+    fn received_cookies_show_parsed_attributes() {
+        let mut headers = HeaderMap::new();
+        for value in [
+            "sid=abc123; Domain=example.com; Path=/; Expires=Sat, 17 Oct 2026 12:00:00 GMT; \
+             Secure; HttpOnly; SameSite=Lax",
+            "old=; Path=/; Max-Age=0; Expires=Thu, 01 Jan 1970 00:00:00 GMT",
+            "plain=1",
+            "=invalid",
+        ] {
+            headers.append(http::header::SET_COOKIE, HeaderValue::from_static(value));
+        }
+
+        let now = OffsetDateTime::from_unix_timestamp(1_791_000_000).unwrap();
+        let mut printer = core::Printer::new(false);
+        write_received_cookies_at(&mut printer, &headers, now);
+        assert_eq!(
+            printer.into_string().unwrap(),
+            "* Cookie received: sid=abc123 (domain example.com, path /, \
+             expires 2026-10-17T12:00:00Z, secure, httponly, samesite lax)\n\
+             * Cookie received: old= (path /, expires 1970-01-01T00:00:00Z (expired), max-age 0s)\n\
+             * Cookie received: plain=1\n"
+        );
+    }
+}
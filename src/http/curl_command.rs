@@ -0,0 +1,413 @@
+use super::*;
+
+use std::borrow::Cow;
+
+/// Render the assembled request as an equivalent `curl` command line.
+/// `headers` must already include authorization, so the command reproduces
+/// what fetch would send.
+pub(super) fn curl_command(
+    cli: &Cli,
+    method: &Method,
+    url: &Url,
+    headers: &HeaderMap,
+    body: &RequestBody,
+    http_version: Option<HttpVersion>,
+    compression: CompressionMode,
+) -> Result<String, FetchError> {
+    let mut args: Vec<Cow<'_, str>> = vec!["curl".into()];
+    if *method == Method::HEAD {
+        args.push("--head".into());
+    } else if *method != implied_curl_method(body) {
+        args.extend(["-X".into(), method.as_str().into()]);
+    }
+
+    let multipart = body
+        .as_ref()
+        .is_some_and(|body| matches!(body.source, RequestBodySource::Multipart(_)));
+    for (name, value) in headers {
+        if *name == ACCEPT_ENCODING && compression != CompressionMode::Off {
+            // curl only decodes the response when it negotiates compression
+            // itself. An Accept-Encoding the user set is passed through as is.
+            args.push("--compressed".into());
+            continue;
+        }
+        if multipart && *name == CONTENT_TYPE {
+            // curl picks its own multipart boundary.
+            continue;
+        }
+        let value = String::from_utf8_lossy(value.as_bytes());
+        args.extend(["-H".into(), format!("{}: {value}", name.as_str()).into()]);
+    }
+    if let Some(credentials) = cli.digest.as_deref() {
+        args.extend(["--digest".into(), "-u".into(), credentials.into()]);
+    }
+    if let Some(body) = body {
+        push_body_args(&mut args, cli, &body.source)?;
+    }
+
+    match cli.redirects {
+        Some(0) => {}
+        max => args.extend([
+            "-L".into(),
+            "--max-redirs".into(),
+            max.unwrap_or(10).to_string().into(),
+        ]),
+    }
+    if let Some(seconds) = cli.timeout.filter(|seconds| *seconds > 0.0) {
+        args.extend(["--max-time".into(), seconds.to_string().into()]);
+    }
+    if let Some(seconds) = cli.connect_timeout.filter(|seconds| *seconds > 0.0) {
+        args.extend(["--connect-timeout".into(), seconds.to_string().into()]);
+    }
+    match http_version {
+        Some(HttpVersion::Http1) => args.push("--http1.1".into()),
+        Some(HttpVersion::Http2) if cli.http2_prior_knowledge => {
+            args.push("--http2-prior-knowledge".into());
+        }
+        Some(HttpVersion::Http2) => args.push("--http2".into()),
+        Some(HttpVersion::Http3) => args.push("--http3".into()),
+        None => {}
+    }
+    if cli.insecure {
+        args.push("-k".into());
+    }
+    let (tls12_ciphers, tls13_ciphers) = match cli.ciphers.as_deref() {
+        Some(ciphers) => crate::tls::curl_cipher_lists(ciphers)?,
+        None => (String::new(), String::new()),
+    };
+    // fetch offers no TLS version without a listed suite, while curl keeps
+    // its default suites for a version whose list is missing.
+    let ciphers = cli.ciphers.is_some();
+    let min_tls = cli
+        .min_tls
+        .as_deref()
+        .or(cli.tls.as_deref())
+        .or_else(|| (ciphers && tls12_ciphers.is_empty()).then_some("1.3"));
+    let max_tls = cli
+        .max_tls
+        .as_deref()
+        .or_else(|| (ciphers && tls13_ciphers.is_empty()).then_some("1.2"));
+    if let Some(version) = min_tls {
+        args.push(format!("--tlsv{version}").into());
+    }
+    if let Some(version) = max_tls {
+        args.extend(["--tls-max".into(), version.into()]);
+    }
+    if !tls12_ciphers.is_empty() {
+        args.extend(["--ciphers".into(), tls12_ciphers.into()]);
+    }
+    if !tls13_ciphers.is_empty() {
+        args.extend(["--tls13-ciphers".into(), tls13_ciphers.into()]);
+    }
+    for path in &cli.ca_cert {
+        args.extend(["--cacert".into(), path.as_str().into()]);
+    }
+    for path in &cli.ca_path {
+        args.extend(["--capath".into(), path.as_str().into()]);
+    }
+    if let Some(path) = cli.cert.as_deref() {
+        args.extend(["--cert".into(), path.into()]);
+    }
+    if let Some(path) = cli.key.as_deref() {
+        args.extend(["--key".into(), path.into()]);
+    }
+    if let Some(proxy) = cli.proxy.as_deref() {
+        args.extend(["-x".into(), proxy.into()]);
+    }
+    if let Some(path) = cli.unix.as_deref() {
+        args.extend(["--unix-socket".into(), path.into()]);
+    }
+    for entry in &cli.resolve {
+        args.extend(["--resolve".into(), entry.as_str().into()]);
+    }
+    for entry in &cli.connect_to {
+        args.extend(["--connect-to".into(), entry.as_str().into()]);
+    }
+    if let Some(interface) = cli.interface.as_deref() {
+        args.extend(["--interface".into(), interface.into()]);
+    }
+    if let Some(range) = cli.local_port.as_deref() {
+        args.extend(["--local-port".into(), range.into()]);
+    }
+    match cli.keepalive {
+        Some(seconds) if seconds == 0.0 => args.push("--no-keepalive".into()),
+        // curl only takes whole seconds.
+        Some(seconds) => args.extend([
+            "--keepalive-time".into(),
+            (seconds.ceil() as u64).to_string().into(),
+        ]),
+        None if cli.no_keepalive => args.push("--no-keepalive".into()),
+        None => {}
+    }
+    args.push(url.as_str().into());
+
+    let quoted = args.iter().map(|arg| shell_quote(arg)).collect::<Vec<_>>();
+    Ok(quoted.join(" "))
+}
+
+/// Connection options set on `cli` that the rendered curl command cannot
+/// express, so the caller can warn that curl would behave differently.
+pub(super) fn unexported_curl_options(cli: &Cli) -> Vec<&'static str> {
+    [
+        ("--dns-server", !cli.dns_servers.is_empty()),
+        ("--ech", cli.ech.is_some()),
+        (
+            "--h2-initial-window-size",
+            cli.h2_initial_window_size.is_some(),
+        ),
+        (
+            "--h2-max-concurrent-streams",
+            cli.h2_max_concurrent_streams.is_some(),
+        ),
+        ("--h2-max-frame-size", cli.h2_max_frame_size.is_some()),
+        ("--max-headers", cli.max_headers.is_some()),
+    ]
+    .into_iter()
+    .filter_map(|(flag, set)| set.then_some(flag))
+    .collect()
+}
+
+/// The method curl uses when no `-X` is given.
+fn implied_curl_method(body: &RequestBody) -> Method {
+    if body.is_some() {
+        Method::POST
+    } else {
+        Method::GET
+    }
+}
+
+fn push_body_args<'a>(
+    args: &mut Vec<Cow<'a, str>>,
+    cli: &'a Cli,
+    source: &'a RequestBodySource,
+) -> Result<(), FetchError> {
+    match source {
+        RequestBodySource::Bytes(bytes) => {
+            let text = std::str::from_utf8(bytes).map_err(|_| {
+                FetchError::Message(
+                    "--curl cannot print a binary request body; send it from a file with '--data @FILE'"
+                        .to_string(),
+                )
+            })?;
+            // --data-raw, unlike --data-binary, never reads a leading '@' as a
+            // file name.
+            args.extend(["--data-raw".into(), text.into()]);
+        }
+        RequestBodySource::File { path, .. } => {
+            args.extend(["--data-binary".into(), format!("@{path}").into()]);
+        }
+        RequestBodySource::Stdin => {
+            args.extend(["--data-binary".into(), "@-".into()]);
+        }
+        RequestBodySource::Multipart(_) => {
+            for field in &cli.multipart {
+                args.extend(["-F".into(), field.as_str().into()]);
+            }
+        }
+        RequestBodySource::GrpcJsonStream { .. } => {
+            return Err(FetchError::Message(
+                "--curl cannot print gRPC requests".to_string(),
+            ));
+        }
+    }
+    Ok(())
+}
+
+/// Quote `arg` for a POSIX shell. Arguments made only of characters the shell
+/// leaves alone are returned as is; anything else is single-quoted.
+fn shell_quote(arg: &str) -> Cow<'_, str> {
+    let plain = !arg.is_empty()
+        && arg
+            .bytes()
+            .all(|byte| byte.is_ascii_alphanumeric() || b"-_./:@%+=,".contains(&byte));
+    if plain {
+        return Cow::Borrowed(arg);
+    }
+    Cow::Owned(format!("'{}'", arg.replace('\'', r"'\''")))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    use clap::Parser;
+
+    fn render(args: &[&str]) -> String {
+        let cli = Cli::try_parse_from(["fetch"].iter().chain(args)).unwrap();
+        let url = Url::parse(cli.url.as_deref().unwrap()).unwrap();
+        let method = Method::from_bytes(effective_method(&cli).as_bytes()).unwrap();
+        let body = request_body(&cli).unwrap();
+        let mut headers = HeaderMap::new();
+        apply_headers(&mut headers, &cli.headers).unwrap();
+        apply_body_content_type(&mut headers, &body);
+        apply_builder_authorization_headers(&mut headers, &cli, None).unwrap();
+        curl_command(
+            &cli,
+            &method,
+            &url,
+            &headers,
+            &body,
+            None,
+            CompressionMode::Off,
+        )
+        .unwrap()
+    }
+
+    #[test]
+    fn shell_quote_leaves_plain_words_and_escapes_quotes() {
+        assert_eq!(
+            shell_quote("https://example.com/a?b"),
+            "'https://example.com/a?b'"
+        );
+        assert_eq!(shell_quote("--max-time"), "--max-time");
+        assert_eq!(shell_quote("@data.json"), "@data.json");
+        assert_eq!(shell_quote(""), "''");
+        assert_eq!(shell_quote("it's"), r"'it'\''s'");
+        assert_eq!(shell_quote("a b$c"), "'a b$c'");
+    }
+
+    #[test]
+    fn curl_command_renders_method_headers_body_and_options() {
+        assert_eq!(
+            render(&["https://example.com/items"]),
+            "curl -L --max-redirs 10 https://example.com/items"
+        );
+        assert_eq!(
+            render(&[
+                "-m",
+                "PUT",
+                "-H",
+                "X-Note: it's",
+                "-j",
+                r#"{"a":1}"#,
+                "--bearer",
+                "tok",
+                "--redirects",
+                "0",
+                "--timeout",
+                "2.5",
+                "https://example.com/items/1",
+            ]),
+            r#"curl -X PUT -H 'x-note: it'\''s' -H 'content-type: application/json' -H 'authorization: Bearer tok' --data-raw '{"a":1}' --max-time 2.5 https://example.com/items/1"#
+        );
+        assert_eq!(
+            render(&["-m", "HEAD", "https://example.com/"]),
+            "curl --head -L --max-redirs 10 https://example.com/"
+        );
+        assert_eq!(
+            render(&["-F", "name=Ada", "-F", "note=a b", "https://example.com/"]),
+            "curl -F name=Ada -F 'note=a b' -L --max-redirs 10 https://example.com/"
+        );
+    }
+
+    #[test]
+    fn curl_command_keeps_literal_at_bodies_and_explicit_accept_encoding() {
+        let cli = Cli::try_parse_from(["fetch", "https://example.com/"]).unwrap();
+        let url = Url::parse("https://example.com/").unwrap();
+        let body = Some(RequestBodyPayload::from_bytes(b"@user".to_vec(), None));
+        assert_eq!(
+            curl_command(
+                &cli,
+                &Method::POST,
+                &url,
+                &HeaderMap::new(),
+                &body,
+                None,
+                CompressionMode::Off,
+            )
+            .unwrap(),
+            "curl --data-raw @user -L --max-redirs 10 https://example.com/"
+        );
+
+        let mut headers = HeaderMap::new();
+        headers.insert(ACCEPT_ENCODING, HeaderValue::from_static("identity"));
+        let compression = apply_accept_encoding(&mut headers, &cli, &Method::GET);
+        assert_eq!(
+            curl_command(&cli, &Method::GET, &url, &headers, &None, None, compression).unwrap(),
+            "curl -H 'accept-encoding: identity' -L --max-redirs 10 https://example.com/"
+        );
+
+        let mut headers = HeaderMap::new();
+        let compression = apply_accept_encoding(&mut headers, &cli, &Method::GET);
+        assert_eq!(
+            curl_command(&cli, &Method::GET, &url, &headers, &None, None, compression).unwrap(),
+            "curl --compressed -L --max-redirs 10 https://example.com/"
+        );
+    }
+
+    #[test]
+    fn curl_command_renders_connection_options() {
+        assert_eq!(
+            render(&[
+                "--min-tls",
+                "1.2",
+                "--max-tls",
+                "1.3",
+                "--interface",
+                "eth1",
+                "--local-port",
+                "4000-4100",
+                "--keepalive",
+                "1.5",
+                "https://example.com/",
+            ]),
+            "curl -L --max-redirs 10 --tlsv1.2 --tls-max 1.3 --interface eth1 --local-port 4000-4100 --keepalive-time 2 https://example.com/"
+        );
+        assert_eq!(
+            render(&["--no-keepalive", "https://example.com/"]),
+            "curl -L --max-redirs 10 --no-keepalive https://example.com/"
+        );
+        assert_eq!(
+            render(&["--keepalive", "0", "https://example.com/"]),
+            "curl -L --max-redirs 10 --no-keepalive https://example.com/"
+        );
+    }
+
+    #[test]
+    fn curl_command_splits_ciphers_by_tls_version() {
+        assert_eq!(
+            render(&[
+                "--ciphers",
+                "TLS_AES_128_GCM_SHA256:TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+                "https://example.com/",
+            ]),
+            "curl -L --max-redirs 10 --ciphers ECDHE-RSA-AES128-GCM-SHA256 --tls13-ciphers TLS_AES_128_GCM_SHA256 https://example.com/"
+        );
+        assert_eq!(
+            render(&[
+                "--ciphers",
+                "ECDHE-RSA-AES128-GCM-SHA256",
+                "https://example.com/",
+            ]),
+            "curl -L --max-redirs 10 --tls-max 1.2 --ciphers ECDHE-RSA-AES128-GCM-SHA256 https://example.com/"
+        );
+        assert_eq!(
+            render(&[
+                "--ciphers",
+                "TLS_AES_256_GCM_SHA384",
+                "https://example.com/",
+            ]),
+            "curl -L --max-redirs 10 --tlsv1.3 --tls13-ciphers TLS_AES_256_GCM_SHA384 https://example.com/"
+        );
+    }
+
+    #[test]
+    fn unexported_curl_options_lists_flags_curl_cannot_express() {
+        let cli = Cli::try_parse_from(["fetch", "https://example.com/"]).unwrap();
+        assert!(unexported_curl_options(&cli).is_empty());
+
+        let cli = Cli::try_parse_from([
+            "fetch",
+            "--dns-server",
+            "1.1.1.1",
+            "--h2-max-frame-size",
+            "65536",
+            "https://example.com/",
+        ])
+        .unwrap();
+        assert_eq!(
+            unexported_curl_options(&cli),
+            ["--dns-server", "--h2-max-frame-size"]
+        );
+    }
+}
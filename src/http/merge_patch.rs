@@ -0,0 +1,95 @@
+use serde_json::Value;
+
+/// Apply a JSON Merge Patch (RFC 7386) to `target` in place. Object members
+/// are merged recursively, `null` removes a member, and any other patch value
+/// replaces the target.
+pub(super) fn apply(target: &mut Value, patch: Value) {
+    let Value::Object(patch) = patch else {
+        *target = patch;
+        return;
+    };
+    if !target.is_object() {
+        *target = Value::Object(serde_json::Map::new());
+    }
+    let Value::Object(target) = target else {
+        unreachable!("target was just made an object");
+    };
+    for (key, value) in patch {
+        if value.is_null() {
+            target.shift_remove(&key);
+            continue;
+        }
+        apply(target.entry(key).or_insert(Value::Null), value);
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    use serde_json::json;
+
+    #[test]
+    fn apply_follows_rfc_7386_examples() {
+        let cases = [
+            (json!({"a": "b"}), json!({"a": "c"}), json!({"a": "c"})),
+            (
+                json!({"a": "b"}),
+                json!({"b": "c"}),
+                json!({"a": "b", "b": "c"}),
+            ),
+            (json!({"a": "b"}), json!({"a": null}), json!({})),
+            (
+                json!({"a": "b", "b": "c"}),
+                json!({"a": null}),
+                json!({"b": "c"}),
+            ),
+            (json!({"a": ["b"]}), json!({"a": "c"}), json!({"a": "c"})),
+            (json!({"a": "c"}), json!({"a": ["b"]}), json!({"a": ["b"]})),
+            (
+                json!({"a": {"b": "c"}}),
+                json!({"a": {"b": "d", "c": null}}),
+                json!({"a": {"b": "d"}}),
+            ),
+            (
+                json!({"a": [{"b": "c"}]}),
+                json!({"a": [1]}),
+                json!({"a": [1]}),
+            ),
+            (json!(["a", "b"]), json!(["c", "d"]), json!(["c", "d"])),
+            (json!({"a": "b"}), json!(["c"]), json!(["c"])),
+            (json!({"a": "foo"}), json!(null), json!(null)),
+            (json!({"a": "foo"}), json!("bar"), json!("bar")),
+            (
+                json!({"e": null}),
+                json!({"a": 1}),
+                json!({"e": null, "a": 1}),
+            ),
+            (
+                json!([1, 2]),
+                json!({"a": "b", "c": null}),
+                json!({"a": "b"}),
+            ),
+            (
+                json!({}),
+                json!({"a": {"bb": {"ccc": null}}}),
+                json!({"a": {"bb": {}}}),
+            ),
+        ];
+        for (mut target, patch, want) in cases {
+            let name = format!("{target} + {patch}");
+            apply(&mut target, patch);
+            assert_eq!(target, want, "{name}");
+        }
+    }
+
+    #[test]
+    fn apply_keeps_existing_member_order() {
+        let mut target = json!({"id": 1, "name": "old", "tags": ["a"]});
+        apply(&mut target, json!({"name": "new", "extra": true}));
+        assert_eq!(
+            target.to_string(),
+            r#"{"id":1,"name":"new","tags":["a"],"extra":true}"#
+        );
+    }
+}
@@ -78,6 +78,33 @@ pub(super) fn print_dns_debug(cli: &Cli, dns: &DnsTiming) {
     let _ = printer.flush_to(&mut std::io::stderr());
 }
 
+/// hyper's HTTP/2 client defaults, advertised when the flags are unset. hyper
+/// does not expose the SETTINGS the server sent back, so only the client's
+/// side of the exchange is printed.
+const DEFAULT_H2_MAX_FRAME_SIZE: u32 = 16_384;
+const DEFAULT_H2_INITIAL_WINDOW_SIZE: u32 = 2_097_152;
+
+pub(super) fn print_http2_settings_debug(cli: &Cli) {
+    let mut printer = core::stdio().stderr_printer(cli.color.as_deref());
+    printer.write_info_prefix();
+    printer.write_styled("HTTP/2", &[core::Sequence::Bold, core::Sequence::Yellow]);
+    printer.push_str(" client SETTINGS: ");
+    printer.push_str(&http2_settings_message(cli));
+    printer.push('\n');
+    let _ = printer.flush_to(&mut std::io::stderr());
+}
+
+fn http2_settings_message(cli: &Cli) -> String {
+    format!(
+        "max frame size {}, initial window size {}, max concurrent streams {}",
+        cli.h2_max_frame_size.unwrap_or(DEFAULT_H2_MAX_FRAME_SIZE),
+        cli.h2_initial_window_size
+            .unwrap_or(DEFAULT_H2_INITIAL_WINDOW_SIZE),
+        cli.h2_max_concurrent_streams
+            .map_or_else(|| "unlimited".to_string(), |max| max.to_string()),
+    )
+}
+
 pub(super) fn connect_debug_target(
     response: &Response,
     url: &Url,
@@ -107,6 +134,7 @@ pub(super) fn print_request_metadata(
     headers: &HeaderMap,
     body: &RequestBody,
     http_version: Option<HttpVersion>,
+    session: Option<&crate::session::Session>,
 ) -> Result<(), FetchError> {
     let mut printer = core::Printer::stderr(cli.color.as_deref());
     let debug = cli.verbose >= 2;
@@ -156,8 +184,20 @@ pub(super) fn print_request_metadata(
         printer.push_str(": ");
         printer.push_str(&value);
         printer.push_str("\n");
+        if cli.decode_jwt && name == "authorization" {
+            write_header_jwts(&mut printer, &value, debug);
+        }
     }
     if debug {
+        // Session cookies are only added when the request has no Cookie
+        // header, matching the transport.
+        let cookies = match headers.get(COOKIE) {
+            Some(value) => Some(value.clone()),
+            None => session.and_then(|session| session.cookies(url)),
+        };
+        if let Some(cookies) = cookies.as_ref().and_then(|value| value.to_str().ok()) {
+            write_sent_cookies(&mut printer, cookies);
+        }
         printer.write_request_prefix();
         printer.push_str("\n");
     }
@@ -165,6 +205,24 @@ pub(super) fn print_request_metadata(
     Ok(())
 }
 
+/// Print the decoded JWTs in a header value for `--decode-jwt`, indented
+/// under the header line.
+fn write_header_jwts(printer: &mut core::Printer, value: &str, debug: bool) {
+    for token in jwt::find_jwts(value) {
+        let mut block = core::Printer::new(printer.use_color());
+        jwt::write_jwt_to(&mut block, &token);
+        let block = String::from_utf8_lossy(block.bytes()).into_owned();
+        for line in block.lines() {
+            if debug {
+                printer.write_request_prefix();
+            }
+            printer.push_str("  ");
+            printer.push_str(line);
+            printer.push_str("\n");
+        }
+    }
+}
+
 pub(super) fn is_printable(bytes: &[u8]) -> bool {
     core::bytes_appear_printable(bytes)
 }
@@ -214,7 +272,7 @@ pub(super) fn validate_http_version_options(
 ) -> Result<(), FetchError> {
     match version {
         Some(HttpVersion::Http2) if url.scheme() == "http" && !allow_h2c => Err(
-            "plain HTTP/2 is only supported for gRPC h2c; use https://, --grpc, or --http 1."
+            "plain HTTP/2 requires h2c; use https://, --grpc, --http2-prior-knowledge, or --http 1."
                 .into(),
         ),
         Some(HttpVersion::Http3) if unix_socket.is_some() => {
@@ -300,6 +358,37 @@ fn normalize_schemeless_url(raw: &str) -> Result<Url, FetchError> {
     Url::parse(&format!("{scheme}://{raw}")).map_err(Into::into)
 }
 
+/// Report, at `-v`, which scheme was chosen for a URL given without one.
+pub(super) fn print_inferred_scheme(cli: &Cli, raw: &str, url: &Url) {
+    if cli.verbose < 1 || cli.silent || has_authority_scheme(raw) {
+        return;
+    }
+    let reason = if url.scheme() == "http" {
+        "loopback and IP address hosts default to http"
+    } else {
+        "hostnames default to https"
+    };
+    let mut printer = core::stdio().stderr_printer(cli.color.as_deref());
+    printer.write_info_prefix();
+    printer.write_styled("Scheme", &[core::Sequence::Bold, core::Sequence::Yellow]);
+    printer.push_str(&format!(": {} (no scheme given; {reason})", url.scheme()));
+    printer.push('\n');
+    let _ = printer.flush_to(&mut std::io::stderr());
+}
+
+pub(super) fn validate_require_https(cli: &Cli, raw: &str, url: &Url) -> Result<(), FetchError> {
+    if !cli.require_https || url.scheme() == "https" {
+        return Ok(());
+    }
+    let mut message = format!("'--require-https' refuses to send a request to {url}");
+    if !has_authority_scheme(raw) {
+        message.push_str(
+            "; http was inferred for this loopback or IP address host, so use an explicit https:// URL",
+        );
+    }
+    Err(FetchError::Message(message))
+}
+
 pub(super) fn schemeless_plaintext_hint_url(raw: &str, url: &Url) -> Option<String> {
     if has_authority_scheme(raw) || url.scheme() != "https" {
         return None;
@@ -410,6 +499,31 @@ mod tests {
 
     use clap::Parser;
 
+    #[test]
+    fn http2_settings_message_uses_defaults_when_unset() {
+        let cli = Cli::try_parse_from(["fetch", "example.com"]).unwrap();
+        assert_eq!(
+            http2_settings_message(&cli),
+            "max frame size 16384, initial window size 2097152, max concurrent streams unlimited"
+        );
+
+        let cli = Cli::try_parse_from([
+            "fetch",
+            "--h2-max-frame-size",
+            "65536",
+            "--h2-initial-window-size",
+            "1048576",
+            "--h2-max-concurrent-streams",
+            "10",
+            "example.com",
+        ])
+        .unwrap();
+        assert_eq!(
+            http2_settings_message(&cli),
+            "max frame size 65536, initial window size 1048576, max concurrent streams 10"
+        );
+    }
+
     #[test]
     fn default_scheme_loopback_is_http() {
         let url = normalize_url("localhost:3000/path").unwrap();
@@ -475,6 +589,34 @@ mod tests {
         );
     }
 
+    #[test]
+    fn require_https_rejects_explicit_and_inferred_http() {
+        let cli = Cli::try_parse_from(["fetch", "--require-https", "example.com"]).unwrap();
+        for raw in ["example.com", "https://127.0.0.1:8443"] {
+            let url = normalize_url(raw).unwrap();
+            assert!(
+                validate_require_https(&cli, raw, &url).is_ok(),
+                "raw URL {raw}"
+            );
+        }
+
+        let url = normalize_url("http://example.com").unwrap();
+        let err = validate_require_https(&cli, "http://example.com", &url)
+            .unwrap_err()
+            .to_string();
+        assert!(err.contains("http://example.com/"), "{err}");
+        assert!(!err.contains("inferred"), "{err}");
+
+        let url = normalize_url("10.0.0.1/path").unwrap();
+        let err = validate_require_https(&cli, "10.0.0.1/path", &url)
+            .unwrap_err()
+            .to_string();
+        assert!(err.contains("http was inferred"), "{err}");
+
+        let cli = Cli::try_parse_from(["fetch", "example.com"]).unwrap();
+        assert!(validate_require_https(&cli, "http://example.com", &url).is_ok());
+    }
+
     #[test]
     fn default_scheme_ip_literals_are_http() {
         let cases = [
@@ -635,7 +777,8 @@ mod tests {
             vec!["fetch", "--multipart", "a=b", "https://example.com"],
             vec!["fetch", "--edit", "https://example.com"],
         ] {
-            let cli = Cli::try_parse_from(args).unwrap();
+            let mut cli = Cli::try_parse_from(args).unwrap();
+            crate::app::apply_data_values(&mut cli).unwrap();
             assert_eq!(effective_method(&cli), "POST");
         }
     }
@@ -779,7 +922,7 @@ mod tests {
 
         assert_eq!(
             err.to_string(),
-            "plain HTTP/2 is only supported for gRPC h2c; use https://, --grpc, or --http 1."
+            "plain HTTP/2 requires h2c; use https://, --grpc, --http2-prior-knowledge, or --http 1."
         );
     }
 
@@ -18,9 +18,9 @@ use bytes::Bytes;
 #[cfg(test)]
 use flate2::read::GzDecoder;
 use http::header::{
-    ACCEPT, ACCEPT_ENCODING, AUTHORIZATION, CONTENT_LENGTH, CONTENT_TYPE, COOKIE, HOST, HeaderMap,
-    HeaderName, HeaderValue, LOCATION, PROXY_AUTHORIZATION, RANGE, RETRY_AFTER, TRANSFER_ENCODING,
-    USER_AGENT, WWW_AUTHENTICATE,
+    ACCEPT, ACCEPT_ENCODING, AUTHORIZATION, CONTENT_LENGTH, CONTENT_RANGE, CONTENT_TYPE, COOKIE,
+    HOST, HeaderMap, HeaderName, HeaderValue, LOCATION, PROXY_AUTHORIZATION, RANGE, RETRY_AFTER,
+    TRANSFER_ENCODING, USER_AGENT, WWW_AUTHENTICATE,
 };
 use http::{Method, StatusCode};
 use sha2::{Digest as _, Sha256};
@@ -32,7 +32,9 @@ use crate::auth::aws_sigv4;
 use crate::auth::digest;
 use crate::cli::{Cli, CompressionMode, HttpVersion};
 use crate::core;
-use crate::duration::{TimeoutBudget, duration_from_seconds, request_timeout_message};
+use crate::duration::{
+    TimeoutBudget, duration_from_seconds, is_connect_timeout_message, request_timeout_message,
+};
 use crate::error::{
     FetchError, write_error_with_color, write_warning_with_color,
     write_warning_with_separator_with_color,
@@ -40,13 +42,18 @@ use crate::error::{
 use crate::format::content_type::{self, ContentType};
 use crate::format::css;
 use crate::format::csv;
+use crate::format::extract::{self, JsonPath};
 use crate::format::grpc as grpc_format;
+use crate::format::hexdump;
 use crate::format::html;
 use crate::format::json;
+use crate::format::jwt;
+use crate::format::line_numbers;
 use crate::format::markdown;
 use crate::format::msgpack;
 use crate::format::protobuf;
 use crate::format::sse;
+use crate::format::toml;
 use crate::format::xml;
 use crate::format::yaml;
 use crate::grpc::encoding as grpc_encoding;
@@ -58,10 +65,16 @@ use crate::output::clipboard;
 use crate::proto;
 use crate::timing::{self, AttemptTiming, DnsTiming, ResponseTiming};
 
+mod check;
+mod checksum;
+mod chunked;
 pub(crate) mod client;
+mod cookies;
+mod curl_command;
 mod edit;
 mod encoding;
 mod http3_cache;
+mod merge_patch;
 mod metadata;
 pub mod multipart;
 mod request;
@@ -69,21 +82,25 @@ mod response;
 mod retry;
 pub(crate) mod transport;
 
+pub(crate) use checksum::ExpectedChecksum;
 pub(crate) use core::color_for_status;
 pub(crate) use metadata::{
     apply_headers, apply_query, has_authority_scheme, load_session, normalize_url, request_target,
     save_session, validate_ech_for_url,
 };
 pub(crate) use request::{
-    RequestBody, RequestBodyPayload, apply_aws_sigv4, apply_builder_authorization_headers,
-    aws_config, basic_header, request_body, request_body_into_bytes,
-    request_body_into_bytes_limited,
+    RequestBody, RequestBodyPayload, accept_fallbacks, apply_aws_sigv4,
+    apply_builder_authorization_headers, aws_config, basic_header, request_body,
+    request_body_into_bytes, request_body_into_bytes_limited,
 };
 #[cfg(test)]
 pub(crate) use request::{request_body_bytes, request_body_content_len, request_body_preview};
 pub(crate) use retry::{is_certificate_validation_message, total_attempts_for_retry};
 pub(crate) use transport::{basic_auth_header_value, extract_url_basic_auth};
 
+use checksum::*;
+use chunked::*;
+use cookies::*;
 use encoding::*;
 use metadata::*;
 use request::*;
@@ -99,13 +116,25 @@ pub fn execute(cli: &Cli) -> HttpFuture<'_> {
     Box::pin(execute_inner(cli))
 }
 
+pub fn check_hosts(cli: &Cli) -> HttpFuture<'_> {
+    Box::pin(check::check_hosts(cli))
+}
+
 async fn execute_inner(cli: &Cli) -> Result<i32, FetchError> {
     let http_version = crate::cli::selected_http_version(cli).map_err(FetchError::Message)?;
     let http_version = effective_http_version(cli, http_version);
-    let mut url = normalize_url(cli.url.as_deref().expect("URL checked by app"))?;
+    let raw_url = cli.url.as_deref().expect("URL checked by app");
+    let mut url = normalize_url(raw_url)?;
+    validate_require_https(cli, raw_url, &url)?;
+    print_inferred_scheme(cli, raw_url, &url);
     apply_query(&mut url, &cli.query);
     client::validate_proxy_for_http_version(cli.proxy.as_deref(), http_version)?;
-    validate_http_version_options(http_version, &url, cli.grpc, cli.unix.as_deref())?;
+    validate_http_version_options(
+        http_version,
+        &url,
+        cli.grpc || cli.http2_prior_knowledge,
+        cli.unix.as_deref(),
+    )?;
     validate_ech_for_url(cli, &url)?;
     let grpc_schema = if cli.grpc {
         proto::load_local_schema(cli)?
@@ -118,8 +147,16 @@ async fn execute_inner(cli: &Cli) -> Result<i32, FetchError> {
         None
     };
     let session = load_session(cli)?;
-    let result = execute_request(cli, http_version, url, grpc_method, session.as_ref()).await;
-    if !cli.dry_run {
+    let sends_request = !cli.dry_run && !cli.trace_headers_only && !cli.curl;
+    if cli.insecure && !cli.quiet_insecure && sends_request {
+        write_warning_before_output(cli, "TLS certificate verification is disabled");
+    }
+    let result = if cli.chunk_size.is_some() || cli.continue_at.is_some() {
+        download_in_chunks(cli, http_version, url, session.as_ref(), cli.chunk_size).await
+    } else {
+        execute_request(cli, http_version, url, grpc_method, session.as_ref(), None).await
+    };
+    if sends_request {
         save_session(cli, session.as_ref());
     }
     result
@@ -131,6 +168,7 @@ async fn execute_request(
     url: Url,
     mut grpc_method: Option<prost_reflect::MethodDescriptor>,
     session: Option<&crate::session::Session>,
+    mut chunk: Option<&mut ChunkedDownload>,
 ) -> Result<i32, FetchError> {
     let har_recorder = cli.har.as_ref().map(|_| crate::har::Recorder::new());
     let har_destination = cli
@@ -138,6 +176,7 @@ async fn execute_request(
         .as_deref()
         .map(|path| crate::har::Destination::reserve(path, cli.clobber))
         .transpose()?;
+    let body_options = ResponseBodyOptions::from_cli(cli)?;
     let request_start = Instant::now();
     let request_timeout = cli
         .timeout
@@ -205,7 +244,14 @@ async fn execute_request(
         apply_headers(&mut headers, &cli.headers)?;
     }
     apply_ranges(&mut headers, &cli.ranges);
-    let mut compression = apply_accept_encoding(&mut headers, cli, &method);
+    apply_idempotency_key(&mut headers, cli.idempotency_key.as_deref());
+    let mut compression = match chunk.as_deref() {
+        Some(download) => {
+            download.apply_range(&mut headers);
+            CompressionMode::Off
+        }
+        None => apply_accept_encoding(&mut headers, cli, &method),
+    };
     let mut body = request_body(cli)?;
     apply_body_content_type(&mut headers, &body);
     if cli.edit {
@@ -217,9 +263,9 @@ async fn execute_request(
     apply_body_content_type(&mut headers, &body);
 
     let digest_credentials = digest_credentials(cli.digest.as_deref())?;
-    let aws_config = aws_config(cli.aws_sigv4.as_deref())?;
+    let aws_config = aws_config(cli.aws_sigv4.as_deref(), &url)?;
 
-    if cli.dry_run {
+    if cli.dry_run || cli.trace_headers_only || cli.curl {
         let mut dry_run_headers = headers.clone();
         if let Some(config) = &aws_config {
             apply_aws_sigv4(
@@ -232,8 +278,41 @@ async fn execute_request(
             )?;
         }
         apply_builder_authorization_headers(&mut dry_run_headers, cli, None)?;
-        print_request_metadata(cli, &method, &url, &dry_run_headers, &body, http_version)?;
-        print_dry_run_body(cli, &body)?;
+        if cli.curl {
+            let unexported = curl_command::unexported_curl_options(cli);
+            if !unexported.is_empty() {
+                write_warning_before_output(
+                    cli,
+                    &format!(
+                        "the curl command does not include {}",
+                        unexported.join(", ")
+                    ),
+                );
+            }
+            let command = curl_command::curl_command(
+                cli,
+                &method,
+                &url,
+                &dry_run_headers,
+                &body,
+                http_version,
+                compression,
+            )?;
+            core::write_stdout(format!("{command}\n"))?;
+            return Ok(0);
+        }
+        print_request_metadata(
+            cli,
+            &method,
+            &url,
+            &dry_run_headers,
+            &body,
+            http_version,
+            session,
+        )?;
+        if cli.dry_run {
+            print_dry_run_body(cli, &body)?;
+        }
         return Ok(0);
     }
 
@@ -243,10 +322,17 @@ async fn execute_request(
     };
 
     let retry_count = cli.retry();
-    let retry_delay =
-        duration_from_seconds("retry-delay", cli.retry_delay())?.unwrap_or(Duration::ZERO);
+    let retry_policy = RetryPolicy::from_cli(cli)?;
     let total_attempts = total_attempts_for_retry(retry_count)?;
     let original_body_replayable = request_body_replayable(&body);
+    let mut fallback_accepts = cli
+        .accept_fallback
+        .as_deref()
+        .map(accept_fallbacks)
+        .transpose()?
+        .unwrap_or_default()
+        .into_iter();
+    let mut accepted_fallback = None;
     let mut attempt = 0;
     loop {
         let mut request_method = method.clone();
@@ -284,6 +370,7 @@ async fn execute_request(
                     &attempt_headers,
                     &request_body,
                     http_version,
+                    session,
                 )?;
             }
             let req = build_request(
@@ -363,8 +450,21 @@ async fn execute_request(
                     let dns_resolution = request_client.current_dns_resolution();
                     let connect_target =
                         connect_debug_target(&response, &request_url, dns_resolution.as_ref());
+                    if response.version() == http::Version::HTTP_2 {
+                        print_http2_settings_debug(cli);
+                    }
                     timing::print_debug_lines(&timing, &connect_target, cli.color.as_deref());
                 }
+                // Only a new connection has a handshake to report.
+                if cli.verbose >= 2
+                    && !cli.silent
+                    && let Some(tls_session) = response.tls_session()
+                    && connect_timing
+                        .timing()
+                        .is_some_and(|timing| timing.tls.is_some() || timing.quic.is_some())
+                {
+                    tls_session.print(cli.color.as_deref());
+                }
                 let digest_result = Box::pin(apply_digest_challenge(
                     response,
                     DigestRetryContext {
@@ -409,20 +509,38 @@ async fn execute_request(
                         ),
                     );
                 }
-                if attempt < retry_count && should_retry_status(status) {
+                if status == StatusCode::NOT_ACCEPTABLE
+                    && let Some(accept) = fallback_accepts.next()
+                {
+                    ensure_body_replayable(original_body_replayable, "Accept fallback")?;
+                    print_redirect_status(cli, &response);
+                    drain_response_body_bounded(response).await;
+                    headers.insert(ACCEPT, accept.clone());
+                    accepted_fallback = Some(accept);
+                    continue;
+                }
+                let mut retry_after = parse_retry_after(response.headers());
+                if cli.respect_rate_limit
+                    && let Some(reset) = parse_rate_limit_reset(response.headers())
+                {
+                    retry_after = retry_after.max(reset);
+                }
+                let requested_delay = compute_delay(&retry_policy, attempt, retry_after);
+                if attempt < retry_count
+                    && retry_policy.should_retry_status(status)
+                    && retry_policy.within_max_time(request_start, requested_delay)
+                {
                     ensure_body_replayable(original_body_replayable, "retry")?;
-                    let retry_after = parse_retry_after(response.headers());
-                    if retry_after > MAX_RETRY_DELAY {
+                    if retry_after > retry_policy.max_delay {
                         write_warning_before_output(
                             cli,
                             &format!(
                                 "Retry-After requested {}; limiting retry delay to {}",
                                 format_delay(retry_after),
-                                format_delay(MAX_RETRY_DELAY)
+                                format_delay(retry_policy.max_delay)
                             ),
                         );
                     }
-                    let requested_delay = compute_delay(retry_delay, attempt, retry_after);
                     drain_response_body_bounded(response).await;
                     let delay = retry_delay_within_timeout(
                         requested_delay,
@@ -446,6 +564,14 @@ async fn execute_request(
                     attempt += 1;
                     continue;
                 }
+                if status != StatusCode::NOT_ACCEPTABLE
+                    && let Some(accept) = &accepted_fallback
+                {
+                    print_accept_fallback(cli, accept);
+                }
+                if let Some(download) = chunk.as_deref_mut() {
+                    break finish_chunked_response(cli, response, download).await;
+                }
                 break finish_response(
                     cli,
                     response,
@@ -455,13 +581,17 @@ async fn execute_request(
                     har_recorder.as_ref(),
                     har_destination,
                     exchange_started,
+                    &body_options,
                 )
                 .await;
             }
             Err(err) => {
-                if attempt < retry_count && is_retryable_error(&err) {
+                let requested_delay = compute_delay(&retry_policy, attempt, Duration::ZERO);
+                if attempt < retry_count
+                    && is_retryable_error(&err, cli.retry_connrefused)
+                    && retry_policy.within_max_time(request_start, requested_delay)
+                {
                     ensure_body_replayable(original_body_replayable, "retry")?;
-                    let requested_delay = compute_delay(retry_delay, attempt, Duration::ZERO);
                     let delay = retry_delay_within_timeout(
                         requested_delay,
                         request_timeout,
@@ -481,6 +611,9 @@ async fn execute_request(
                 if let Some(message) = timeout_error_message(cli, &err) {
                     break Err(FetchError::Runtime(message));
                 }
+                if let Some(message) = response_headers_too_large_message(cli, &err) {
+                    break Err(FetchError::Runtime(message));
+                }
                 let mut message = transport_request_error_message(&err);
                 append_schemeless_plaintext_hint(&mut message, cli, &url, &request_url, &err);
                 if is_certificate_validation_error(&err) {
@@ -19,6 +19,8 @@ pub enum MultipartError {
     FileIsNotRegular(String),
     #[error("invalid multipart {kind}: value contains ASCII control character")]
     InvalidDispositionValue { kind: &'static str },
+    #[error("invalid multipart content type '{0}': must be in the form TYPE/SUBTYPE")]
+    InvalidContentType(String),
     #[error("multipart body is too large to compute Content-Length")]
     BodyTooLarge,
     #[error(transparent)]
@@ -60,9 +62,10 @@ impl Multipart {
             let (name, value) = raw.split_once('=').unwrap_or((raw, ""));
             let name = name.trim().to_string();
             validate_multipart_disposition_value("field name", &name)?;
-            let field = if let Some(path) = value.strip_prefix('@') {
+            let field = if let Some(value) = value.strip_prefix('@') {
+                let (path, options) = split_file_options(value);
                 let path = crate::fileutil::expand_home(path);
-                file_field(&name, path)?
+                file_field(&name, path, options)?
             } else {
                 text_field(&name, value)
             };
@@ -302,14 +305,54 @@ fn text_field(name: &str, value: &str) -> Field {
     }
 }
 
-fn file_field(name: &str, path: PathBuf) -> Result<Field, MultipartError> {
+/// Options given after a file field's path, as in curl's
+/// `-F "file=@photo.dat;type=image/png;filename=photo.png"`.
+#[derive(Debug, Default, PartialEq)]
+struct FileOptions<'a> {
+    content_type: Option<&'a str>,
+    filename: Option<&'a str>,
+}
+
+/// Split trailing `;type=` and `;filename=` options off a file field value.
+/// Any other `;` is left as part of the path.
+fn split_file_options(value: &str) -> (&str, FileOptions<'_>) {
+    let mut path = value;
+    let mut options = FileOptions::default();
+    while let Some((rest, option)) = path.rsplit_once(';') {
+        if let Some(content_type) = option.strip_prefix("type=")
+            && options.content_type.is_none()
+        {
+            options.content_type = Some(content_type);
+        } else if let Some(filename) = option.strip_prefix("filename=")
+            && options.filename.is_none()
+        {
+            options.filename = Some(filename);
+        } else {
+            break;
+        }
+        path = rest;
+    }
+    (path, options)
+}
+
+fn file_field(
+    name: &str,
+    path: PathBuf,
+    options: FileOptions<'_>,
+) -> Result<Field, MultipartError> {
     let metadata = validate_file_path(&path)?;
-    let filename = path
-        .file_name()
-        .map(|name| name.to_string_lossy().into_owned())
-        .unwrap_or_default();
+    let filename = match options.filename {
+        Some(filename) => filename.to_string(),
+        None => path
+            .file_name()
+            .map(|name| name.to_string_lossy().into_owned())
+            .unwrap_or_default(),
+    };
     validate_multipart_disposition_value("filename", &filename)?;
-    let content_type = detect_content_type(&path)?;
+    let content_type = match options.content_type {
+        Some(content_type) => validate_content_type(content_type)?,
+        None => detect_content_type(&path)?,
+    };
 
     Ok(Field {
         header: file_header(name, &filename, content_type),
@@ -339,6 +382,20 @@ fn validate_multipart_disposition_value(
     Ok(())
 }
 
+/// Accept a `TYPE/SUBTYPE` media type made of HTTP token characters.
+fn validate_content_type(value: &str) -> Result<&str, MultipartError> {
+    let is_token = |part: &str| {
+        !part.is_empty()
+            && part
+                .bytes()
+                .all(|byte| byte.is_ascii_alphanumeric() || b"!#$%&'*+-.^_`|~".contains(&byte))
+    };
+    match value.split_once('/') {
+        Some((kind, subtype)) if is_token(kind) && is_token(subtype) => Ok(value),
+        _ => Err(MultipartError::InvalidContentType(value.to_string())),
+    }
+}
+
 fn detect_content_type(path: &Path) -> Result<&'static str, MultipartError> {
     if let Some(content_type) = content_type::request_content_type_for_path(path) {
         return Ok(content_type);
@@ -525,6 +582,69 @@ mod tests {
         assert!(!body.contains("secret/report.pdf"));
     }
 
+    #[test]
+    fn multipart_file_options_set_content_type_and_filename() {
+        let dir = tempfile::tempdir().unwrap();
+        let path = dir.path().join("photo.dat");
+        std::fs::write(&path, b"not really a png").unwrap();
+        let multipart = Multipart::from_cli_fields(&[format!(
+            "file=@{};type=image/png;filename=upload.png",
+            path.display()
+        )])
+        .unwrap()
+        .unwrap();
+
+        let body = String::from_utf8(multipart.open().unwrap()).unwrap();
+
+        assert!(
+            body.contains("name=\"file\"; filename=\"upload.png\"\r\nContent-Type: image/png\r\n")
+        );
+        assert!(!body.contains("photo.dat"));
+    }
+
+    #[test]
+    fn split_file_options_only_takes_known_trailing_options() {
+        assert_eq!(
+            split_file_options("a.bin;filename=b.bin;type=text/csv"),
+            (
+                "a.bin",
+                FileOptions {
+                    content_type: Some("text/csv"),
+                    filename: Some("b.bin"),
+                }
+            )
+        );
+        assert_eq!(
+            split_file_options("dir;v2/a.txt"),
+            ("dir;v2/a.txt", FileOptions::default())
+        );
+        assert_eq!(
+            split_file_options("a.txt;type=x/y;type=text/plain").0,
+            "a.txt;type=x/y"
+        );
+    }
+
+    #[test]
+    fn multipart_rejects_malformed_content_types() {
+        let dir = tempfile::tempdir().unwrap();
+        let path = dir.path().join("payload.txt");
+        std::fs::write(&path, b"x").unwrap();
+
+        for content_type in ["text", "text/", "/plain", "text/plain/x", "text plain/x"] {
+            let err = Multipart::from_cli_fields(&[format!(
+                "file=@{};type={content_type}",
+                path.display()
+            )])
+            .unwrap_err();
+            assert_eq!(
+                err.to_string(),
+                format!(
+                    "invalid multipart content type '{content_type}': must be in the form TYPE/SUBTYPE"
+                )
+            );
+        }
+    }
+
     #[test]
     fn multipart_file_without_extension_is_sniffed() {
         let file = tempfile::NamedTempFile::new().unwrap();
@@ -398,9 +398,12 @@ pub(super) fn digest_credentials(
     Ok(Some((username.to_string(), password.to_string())))
 }
 
-pub(crate) fn aws_config(value: Option<&str>) -> Result<Option<aws_sigv4::Config>, FetchError> {
+pub(crate) fn aws_config(
+    value: Option<&str>,
+    url: &Url,
+) -> Result<Option<aws_sigv4::Config>, FetchError> {
     value
-        .map(aws_sigv4::parse_config)
+        .map(|value| aws_sigv4::config_for_url(value, url))
         .transpose()
         .map_err(|err| FetchError::Message(err.to_string()))
 }
@@ -955,6 +958,67 @@ pub(super) fn apply_ranges(headers: &mut HeaderMap, ranges: &[String]) {
     );
 }
 
+/// Set the `Idempotency-Key` header from `--idempotency-key`, generating a
+/// random UUID when the value is empty. Request headers are built once, so
+/// every retry and redirect reuses the same key.
+pub(super) fn apply_idempotency_key(headers: &mut HeaderMap, key: Option<&str>) {
+    let Some(key) = key else {
+        return;
+    };
+    let key = if key.is_empty() {
+        random_uuid()
+    } else {
+        key.to_string()
+    };
+    headers.insert(
+        HeaderName::from_static("idempotency-key"),
+        HeaderValue::from_str(&key).expect("idempotency key is a valid header value"),
+    );
+}
+
+/// Format a random version 4 UUID.
+fn random_uuid() -> String {
+    let mut bits = rand::random::<u128>();
+    bits = (bits & !(0xf << 76)) | (0x4 << 76);
+    bits = (bits & !(0x3 << 62)) | (0x2 << 62);
+    format!(
+        "{:08x}-{:04x}-{:04x}-{:04x}-{:012x}",
+        bits >> 96,
+        (bits >> 80) & 0xffff,
+        (bits >> 64) & 0xffff,
+        (bits >> 48) & 0xffff,
+        bits & 0xffff_ffff_ffff
+    )
+}
+
+/// Parse `--accept-fallback` into the Accept values to send, in order, after
+/// each `406 Not Acceptable`. Names such as `json` map to their media type, `*`
+/// means `*/*`, and anything containing a `/` is sent as given.
+pub(crate) fn accept_fallbacks(value: &str) -> Result<Vec<HeaderValue>, FetchError> {
+    let invalid = || {
+        FetchError::invalid_value(
+            "--accept-fallback",
+            value,
+            "must be a comma-separated list of media types or names such as json, xml, or *",
+        )
+    };
+    value
+        .split(',')
+        .map(str::trim)
+        .map(|entry| {
+            let media_type = match entry {
+                "" => return Err(invalid()),
+                "*" => "*/*",
+                _ if entry.contains('/') => entry,
+                _ => crate::format::content_type::request_content_type_for_extension(entry)
+                    .and_then(|content_type| content_type.split(';').next())
+                    .ok_or_else(invalid)?,
+            };
+            HeaderValue::from_str(media_type).map_err(|_| invalid())
+        })
+        .collect()
+}
+
 pub(crate) fn request_body(cli: &Cli) -> Result<RequestBody, FetchError> {
     if !cli.multipart.is_empty() {
         let multipart = multipart::Multipart::from_cli_fields(&cli.multipart)
@@ -989,7 +1053,10 @@ pub(crate) fn request_body(cli: &Cli) -> Result<RequestBody, FetchError> {
         }));
     }
     if let Some(value) = cli.json.as_deref() {
-        let (source, _) = body_value_source(value, false)?;
+        let (mut source, _) = body_value_source(value, false)?;
+        if let Some(path) = cli.json_merge.as_deref() {
+            source = json_merge_body(path, source)?;
+        }
         return Ok(Some(RequestBodyPayload {
             source,
             content_type: Some("application/json".to_string()),
@@ -1016,6 +1083,27 @@ pub(crate) fn request_body(cli: &Cli) -> Result<RequestBody, FetchError> {
     Ok(None)
 }
 
+/// Read the JSON document at `path` and apply the `--json` body to it as a
+/// JSON Merge Patch.
+fn json_merge_body(path: &str, patch: RequestBodySource) -> Result<RequestBodySource, FetchError> {
+    let expanded = crate::fileutil::expand_home(path);
+    let base = std::fs::read(&expanded).map_err(|err| {
+        if err.kind() == std::io::ErrorKind::NotFound {
+            FetchError::Message(format!("file '{path}' does not exist"))
+        } else {
+            err.into()
+        }
+    })?;
+    let mut document = serde_json::from_slice::<serde_json::Value>(&base)
+        .map_err(|err| FetchError::Message(format!("invalid JSON in file '{path}': {err}")))?;
+    let patch = request_body_source_to_bytes(patch)?;
+    let patch = serde_json::from_slice::<serde_json::Value>(&patch)
+        .map_err(|err| FetchError::Message(format!("invalid JSON merge patch: {err}")))?;
+    merge_patch::apply(&mut document, patch);
+    let body = serde_json::to_vec(&document).map_err(|err| FetchError::Message(err.to_string()))?;
+    Ok(RequestBodySource::Bytes(Bytes::from(body)))
+}
+
 pub(super) fn body_value_source(
     value: &str,
     detect_content_type: bool,
@@ -1196,9 +1284,48 @@ mod tests {
 
     use clap::Parser;
 
+    #[test]
+    fn idempotency_key_uses_value_or_random_uuid() {
+        let mut headers = HeaderMap::new();
+        apply_idempotency_key(&mut headers, None);
+        assert!(headers.is_empty());
+
+        apply_idempotency_key(&mut headers, Some("order-42"));
+        assert_eq!(headers["idempotency-key"], "order-42");
+
+        apply_idempotency_key(&mut headers, Some(""));
+        let key = headers["idempotency-key"].to_str().unwrap().to_string();
+        let groups = key.split('-').map(str::len).collect::<Vec<_>>();
+        assert_eq!(groups, [8, 4, 4, 4, 12], "{key}");
+        assert!(key.chars().all(|c| c == '-' || c.is_ascii_hexdigit()));
+        assert_eq!(&key[14..15], "4", "{key}");
+        assert!(matches!(&key[19..20], "8" | "9" | "a" | "b"), "{key}");
+        assert_ne!(random_uuid(), random_uuid());
+    }
+
+    #[test]
+    fn accept_fallbacks_expand_names_and_wildcards() {
+        let values = accept_fallbacks("json, xml,text/*;q=0.5,html,*").unwrap();
+        assert_eq!(
+            values,
+            [
+                "application/json",
+                "application/xml",
+                "text/*;q=0.5",
+                "text/html",
+                "*/*"
+            ]
+        );
+        for value in ["", "json,", "nope", "json,bad\nvalue/x"] {
+            assert!(accept_fallbacks(value).is_err(), "{value:?}");
+        }
+    }
+
     #[test]
     fn request_body_data_detects_go_style_content_type() {
-        let cli = Cli::try_parse_from(["fetch", "--data", "hello", "https://example.com"]).unwrap();
+        let mut cli =
+            Cli::try_parse_from(["fetch", "--data", "hello", "https://example.com"]).unwrap();
+        crate::app::apply_data_values(&mut cli).unwrap();
         let body = request_body_into_bytes(request_body(&cli).unwrap())
             .unwrap()
             .unwrap();
@@ -1208,13 +1335,14 @@ mod tests {
         let dir = tempfile::tempdir().unwrap();
         let path = dir.path().join("payload.json");
         std::fs::write(&path, br#"{"ok":true}"#).unwrap();
-        let cli = Cli::try_parse_from([
+        let mut cli = Cli::try_parse_from([
             "fetch",
             "--data",
             &format!("@{}", path.display()),
             "https://example.com",
         ])
         .unwrap();
+        crate::app::apply_data_values(&mut cli).unwrap();
         let body = request_body_into_bytes(request_body(&cli).unwrap())
             .unwrap()
             .unwrap();
@@ -1252,23 +1380,25 @@ mod tests {
     fn request_body_file_errors_match_go_cli_surface() {
         let dir = tempfile::tempdir().unwrap();
         let missing = dir.path().join("missing.txt");
-        let cli = Cli::try_parse_from([
+        let mut cli = Cli::try_parse_from([
             "fetch",
             "--data",
             &format!("@{}", missing.display()),
             "https://example.com",
         ])
         .unwrap();
+        crate::app::apply_data_values(&mut cli).unwrap();
         let err = request_body(&cli).unwrap_err().to_string();
         assert_eq!(err, format!("file '{}' does not exist", missing.display()));
 
-        let cli = Cli::try_parse_from([
+        let mut cli = Cli::try_parse_from([
             "fetch",
             "--data",
             &format!("@{}", dir.path().display()),
             "https://example.com",
         ])
         .unwrap();
+        crate::app::apply_data_values(&mut cli).unwrap();
         let err = request_body(&cli).unwrap_err().to_string();
         assert_eq!(
             err,
@@ -1301,8 +1431,9 @@ mod tests {
     #[cfg(unix)]
     #[test]
     fn request_body_rejects_non_regular_body_file() {
-        let cli =
+        let mut cli =
             Cli::try_parse_from(["fetch", "--data", "@/dev/null", "https://example.com"]).unwrap();
+        crate::app::apply_data_values(&mut cli).unwrap();
 
         let err = request_body(&cli).unwrap_err().to_string();
 
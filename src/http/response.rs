@@ -1,29 +1,39 @@
 use super::*;
 
+mod format_debug;
 mod formatters;
+mod meta_file;
 mod metadata;
+mod split;
 mod stdout;
 mod stream;
 
 pub(super) use formatters::{
     should_retry_sse_without_compression, should_retry_sse_without_compression_for_method,
 };
+pub(super) use metadata::exit_code;
 pub(super) use stream::{drain_response_body_bounded, response_body_exceeds_discard_bound};
 
+use format_debug::{FormatDecision, FormatSource, print_format_debug};
 use formatters::{
-    format_stdout_bytes, should_stream_formatted_grpc_stdout,
-    should_stream_formatted_ndjson_stdout, should_stream_formatted_sse_stdout,
-    stream_response_to_formatted_grpc_stdout, stream_response_to_formatted_ndjson_stdout,
-    stream_response_to_formatted_sse_stdout,
+    BodyConversion, convert_body, extract_stdout_body, format_stdout_bytes, minify_stdout_body,
+    should_stream_formatted_grpc_stdout, should_stream_formatted_ndjson_stdout,
+    should_stream_formatted_sse_stdout, stream_response_to_formatted_grpc_stdout,
+    stream_response_to_formatted_ndjson_stdout, stream_response_to_formatted_sse_stdout,
+    stream_response_to_hexdump_stdout,
 };
+use meta_file::{OutputMeta, write_meta_file};
 use metadata::{
-    body_duration, check_grpc_status, exit_code, finalize_streamed_response,
-    handle_clipboard_outcome, print_response_metadata, print_timing,
+    body_duration, body_duration_from_len, check_grpc_status, check_response_schema,
+    finalize_streamed_response, handle_clipboard_outcome, print_response_metadata,
+    print_response_trailers, print_timing,
 };
+use split::{split_json_values, write_split_output};
 use stdout::{StdoutBody, stdout_stream_target, write_stdout_bytes};
 use stream::{
-    read_decoded_article_body_limited, read_decoded_response_body_limited,
-    stream_response_to_discard, stream_response_to_output, stream_response_to_stdout,
+    BodyChecks, decoded_capturing_response_reader, read_decoded_article_body_limited,
+    read_decoded_response_body_limited, stream_response_to_discard, stream_response_to_output,
+    stream_response_to_stdout,
 };
 
 #[allow(clippy::too_many_arguments)]
@@ -36,6 +46,7 @@ pub(super) async fn finish_response(
     har_recorder: Option<&crate::har::Recorder>,
     har_destination: Option<crate::har::Destination>,
     har_started: SystemTime,
+    body_options: &ResponseBodyOptions,
 ) -> Result<i32, FetchError> {
     let response_timing = timing.and_then(AttemptTiming::response_timing);
     let status = response.status();
@@ -64,18 +75,64 @@ pub(super) async fn finish_response(
         response_timing,
         grpc_method,
         har_recorder.map(crate::har::Recorder::response_capture),
+        body_options,
     )
     .await;
     let code = result?;
     if let (Some(recorder), Some(destination), Some(meta)) =
         (har_recorder, har_destination, response_meta)
     {
+        if let Some(correlation) = recorder.correlation(&meta.headers)
+            && correlation.is_mismatch()
+        {
+            write_warning(
+                cli,
+                &format!(
+                    "response {} '{}' does not match the sent value '{}'",
+                    correlation.header,
+                    correlation.received,
+                    correlation.sent.as_deref().unwrap_or_default()
+                ),
+            );
+        }
         let bytes = recorder.serialize(meta)?;
         destination.commit(&bytes)?;
     }
     Ok(code)
 }
 
+pub(super) async fn finish_chunked_response(
+    cli: &Cli,
+    response: Response,
+    download: &mut ChunkedDownload,
+) -> Result<i32, FetchError> {
+    let status = response.status();
+    print_response_metadata(cli, &response);
+    let response_headers = response.headers().clone();
+    if status == StatusCode::RANGE_NOT_SATISFIABLE
+        && download.range_not_satisfiable(&response_headers)
+    {
+        drain_response_body_bounded(response).await;
+        return Ok(0);
+    }
+    if status != StatusCode::OK && status != StatusCode::PARTIAL_CONTENT {
+        drain_response_body_bounded(response).await;
+        return Ok(exit_code(status.as_u16(), cli.ignore_status));
+    }
+    let body_checks = BodyChecks::for_range(cli, &response);
+    let (mut reader, _) = decoded_capturing_response_reader(
+        response,
+        CompressionMode::Off,
+        &response_headers,
+        None,
+        body_checks,
+    )?;
+    download
+        .append(status, &response_headers, &mut reader)
+        .await?;
+    Ok(0)
+}
+
 async fn finish_response_output(
     cli: &Cli,
     response: Response,
@@ -83,6 +140,7 @@ async fn finish_response_output(
     response_timing: Option<ResponseTiming>,
     grpc_method: Option<&prost_reflect::MethodDescriptor>,
     har_capture: Option<crate::har::Capture>,
+    body_options: &ResponseBodyOptions,
 ) -> Result<i32, FetchError> {
     let status = response.status();
     print_response_metadata(cli, &response);
@@ -94,6 +152,7 @@ async fn finish_response_output(
     let output_progress_total =
         output_progress_total_bytes(compression, &response_headers, response_content_length);
     let method_is_head = cli.method().eq_ignore_ascii_case("HEAD");
+    let body_checks = BodyChecks::from_response(cli, &response, method_is_head, compression);
     let stdio = core::stdio();
 
     if cli.discard {
@@ -103,6 +162,7 @@ async fn finish_response_output(
             response_headers.clone(),
             compression,
             har_capture,
+            body_checks,
         )
         .await?;
         return Ok(finalize_streamed_response(
@@ -123,6 +183,7 @@ async fn finish_response_output(
         &response_url,
         &response_headers,
     )
+    .and_then(|resolved| output::apply_output_dir(resolved, cli.output_dir.as_deref()))
     .map_err(|err| FetchError::Message(err.to_string()))?;
     if let Some(warning) = &resolved_output.warning {
         write_warning(cli, warning);
@@ -147,6 +208,7 @@ async fn finish_response_output(
             method_is_head,
             resolved_output.path.as_deref(),
             har_capture,
+            body_checks,
         )
         .await;
     }
@@ -155,8 +217,10 @@ async fn finish_response_output(
             output::WriteProgress::disabled()
         } else {
             output::WriteProgress::stdio(cli.color.as_deref(), output_progress_total)
+                .with_mode(crate::cli::ProgressMode::from_cli(cli))
         };
         let body_start = Instant::now();
+        let meta_path = cli.write_meta.then(|| path.clone());
         let streamed = stream_response_to_output(
             response,
             response_headers.clone(),
@@ -166,8 +230,24 @@ async fn finish_response_output(
             progress,
             cli.copy,
             har_capture,
+            body_checks,
         )
         .await?;
+        if let Some(meta_path) = meta_path {
+            let timing = response_timing.map(|mut timing| {
+                timing.body =
+                    body_duration_from_len(method_is_head, streamed.bytes_written, body_start);
+                timing
+            });
+            let meta = OutputMeta::new(
+                &response_url,
+                status,
+                &response_headers,
+                streamed.bytes_written,
+                timing,
+            );
+            write_meta_file(cli, &meta_path, &meta).await?;
+        }
         return Ok(finalize_streamed_response(
             cli,
             status,
@@ -181,7 +261,45 @@ async fn finish_response_output(
 
     let body_start = Instant::now();
     let stdout_is_terminal = stdio.stdout_is_terminal();
-    if should_stream_formatted_sse_stdout(cli, &response_headers, stdout_is_terminal) {
+    let buffer_body = body_options.requires_buffered_body();
+    if cli.hex {
+        print_format_debug(
+            cli,
+            &response_headers,
+            FormatDecision::streamed(
+                stdout::response_header_content_type(&response_headers),
+                FormatSource::Flag("--hex"),
+            ),
+        );
+        let use_color = stdio.stdout_color(cli.color.as_deref());
+        let streamed = stream_response_to_hexdump_stdout(
+            response,
+            response_headers.clone(),
+            compression,
+            cli.copy,
+            use_color,
+            har_capture,
+            body_checks,
+        )
+        .await?;
+        return Ok(finalize_streamed_response(
+            cli,
+            status,
+            &response_headers,
+            response_timing,
+            method_is_head,
+            body_start,
+            streamed,
+        ));
+    }
+    if !buffer_body
+        && should_stream_formatted_sse_stdout(cli, &response_headers, stdout_is_terminal)
+    {
+        print_format_debug(
+            cli,
+            &response_headers,
+            FormatDecision::streamed(ContentType::Sse, FormatSource::Header),
+        );
         let use_color = stdio.stdout_color(cli.color.as_deref());
         let streamed = stream_response_to_formatted_sse_stdout(
             response,
@@ -190,6 +308,7 @@ async fn finish_response_output(
             cli.copy,
             use_color,
             har_capture,
+            body_checks,
         )
         .await?;
         return Ok(finalize_streamed_response(
@@ -202,7 +321,14 @@ async fn finish_response_output(
             streamed,
         ));
     }
-    if should_stream_formatted_ndjson_stdout(cli, &response_headers, stdout_is_terminal) {
+    if !buffer_body
+        && should_stream_formatted_ndjson_stdout(cli, &response_headers, stdout_is_terminal)
+    {
+        print_format_debug(
+            cli,
+            &response_headers,
+            FormatDecision::streamed(ContentType::Ndjson, FormatSource::Header),
+        );
         let use_color = stdio.stdout_color(cli.color.as_deref());
         let streamed = stream_response_to_formatted_ndjson_stdout(
             response,
@@ -211,6 +337,7 @@ async fn finish_response_output(
             cli.copy,
             use_color,
             har_capture,
+            body_checks,
         )
         .await?;
         return Ok(finalize_streamed_response(
@@ -223,7 +350,14 @@ async fn finish_response_output(
             streamed,
         ));
     }
-    if should_stream_formatted_grpc_stdout(cli, &response_headers, stdout_is_terminal) {
+    if !buffer_body
+        && should_stream_formatted_grpc_stdout(cli, &response_headers, stdout_is_terminal)
+    {
+        print_format_debug(
+            cli,
+            &response_headers,
+            FormatDecision::streamed(ContentType::Grpc, FormatSource::Header),
+        );
         let use_color = stdio.stdout_color(cli.color.as_deref());
         let streamed = stream_response_to_formatted_grpc_stdout(
             response,
@@ -233,6 +367,7 @@ async fn finish_response_output(
             grpc_method.map(|method| method.output()),
             use_color,
             har_capture,
+            body_checks,
         )
         .await?;
         return Ok(finalize_streamed_response(
@@ -245,7 +380,17 @@ async fn finish_response_output(
             streamed,
         ));
     }
-    if let Some(target) = stdout_stream_target(cli, &response_headers, stdout_is_terminal) {
+    if !buffer_body
+        && let Some(target) = stdout_stream_target(cli, &response_headers, stdout_is_terminal)
+    {
+        print_format_debug(
+            cli,
+            &response_headers,
+            FormatDecision::streamed(
+                stdout::response_header_content_type(&response_headers),
+                FormatSource::Disabled,
+            ),
+        );
         let streamed = stream_response_to_stdout(
             cli,
             response,
@@ -255,6 +400,7 @@ async fn finish_response_output(
             target,
             stdout_is_terminal,
             har_capture,
+            body_checks,
         )
         .await?;
         return Ok(finalize_streamed_response(
@@ -273,23 +419,122 @@ async fn finish_response_output(
         response_headers.clone(),
         compression,
         har_capture,
+        body_checks,
     )
     .await?;
     let body_duration = body_duration(method_is_head, bytes.as_ref(), body_start);
     if cli.copy {
         handle_clipboard_outcome(cli, clipboard::copy_bytes(&bytes));
     }
-    let stdout_body = format_stdout_bytes(
-        cli,
-        &response_headers,
-        &bytes,
-        grpc_method.map(|method| method.output()),
-    )?;
+    let code = exit_code(status.as_u16(), cli.ignore_status);
+    let (response_headers, bytes) = match body_options.conversion {
+        Some(target) if code == 0 => convert_body(response_headers, bytes, target)?,
+        _ => (response_headers, bytes),
+    };
+    if let Some(pattern) = body_options.split_output.as_deref()
+        && code == 0
+    {
+        let values = split_json_values(body_options.extract.as_ref(), cli.extract_all, &bytes)?;
+        write_split_output(cli, pattern, &values).await?;
+        print_response_trailers(cli, &trailers);
+        print_timing(cli, response_timing, body_duration);
+        return Ok(check_response_schema(
+            cli,
+            body_options.schema.as_ref(),
+            &bytes,
+            code,
+        ));
+    }
+    let minified = if body_options.minify && code == 0 {
+        minify_stdout_body(&response_headers, &bytes)
+    } else {
+        None
+    };
+    let stdout_body = match (&body_options.extract, minified) {
+        (Some(path), _) if code == 0 => {
+            print_format_debug(
+                cli,
+                &response_headers,
+                FormatDecision::buffered(ContentType::Json, FormatSource::Flag("--extract")),
+            );
+            extract_stdout_body(path, cli.extract_all, &bytes)?
+        }
+        (_, Some(minified)) => {
+            print_format_debug(
+                cli,
+                &response_headers,
+                FormatDecision::buffered(minified.content_type, FormatSource::Flag("--minify")),
+            );
+            minified
+        }
+        _ => format_stdout_bytes(
+            cli,
+            &response_headers,
+            &bytes,
+            grpc_method.map(|method| method.output()),
+        )?,
+    };
     write_stdout_bytes(cli, &stdout_body)?;
+    print_response_trailers(cli, &trailers);
     print_timing(cli, response_timing, body_duration);
 
-    let code = exit_code(status.as_u16(), cli.ignore_status);
-    Ok(check_grpc_status(cli, &response_headers, &trailers, code))
+    let code = check_grpc_status(cli, &response_headers, &trailers, code);
+    Ok(check_response_schema(
+        cli,
+        body_options.schema.as_ref(),
+        &bytes,
+        code,
+    ))
+}
+
+pub(super) struct ResponseBodyOptions {
+    schema: Option<crate::schema::Schema>,
+    extract: Option<JsonPath>,
+    conversion: Option<BodyConversion>,
+    minify: bool,
+    split_output: Option<String>,
+}
+
+impl ResponseBodyOptions {
+    pub(super) fn from_cli(cli: &Cli) -> Result<Self, FetchError> {
+        let schema = cli
+            .schema
+            .as_deref()
+            .map(crate::schema::Schema::load)
+            .transpose()?;
+        let extract = cli
+            .extract
+            .as_deref()
+            .map(|path| {
+                JsonPath::parse(path)
+                    .map_err(|usage| FetchError::invalid_value("--extract", path, usage))
+            })
+            .transpose()?;
+        if let Some(pattern) = cli.split_output.as_deref()
+            && (pattern.is_empty() || pattern == "-")
+        {
+            return Err(FetchError::invalid_value(
+                "--split-output",
+                pattern,
+                "must be a file path pattern",
+            ));
+        }
+        Ok(Self {
+            schema,
+            extract,
+            conversion: BodyConversion::from_cli(cli),
+            minify: cli.minify,
+            split_output: cli.split_output.clone(),
+        })
+    }
+
+    fn requires_buffered_body(&self) -> bool {
+        self.schema.is_some()
+            || self.extract.is_some()
+            || self.conversion.is_some()
+            || self.minify
+            || self.split_output.is_some()
+    }
 }
 
 #[allow(clippy::too_many_arguments)]
@@ -304,6 +549,7 @@ async fn finish_article_response(
     method_is_head: bool,
     output_path: Option<&str>,
     har_capture: Option<crate::har::Capture>,
+    body_checks: BodyChecks,
 ) -> Result<i32, FetchError> {
     let body_start = Instant::now();
     let (bytes, trailers) = read_decoded_article_body_limited(
@@ -311,6 +557,7 @@ async fn finish_article_response(
         response_headers.clone(),
         compression,
         har_capture,
+        body_checks,
     )
     .await?;
     let body_duration = body_duration(method_is_head, &bytes, body_start);
@@ -327,7 +574,12 @@ async fn finish_article_response(
         .get(CONTENT_TYPE)
         .and_then(|value| value.to_str().ok());
     let (_, charset) = content_type::get_content_type(raw_content_type);
-    let text = formatters::transcode_bytes(&bytes, &charset);
+    let text = match input_kind {
+        ArticleInputKind::Html => {
+            formatters::transcode_format_bytes(&bytes, &charset, ContentType::Html)
+        }
+        _ => formatters::transcode_bytes(&bytes, &charset),
+    };
     let text = String::from_utf8_lossy(&text);
     let article = match input_kind {
         ArticleInputKind::Html => {
@@ -352,6 +604,7 @@ async fn finish_article_response(
                 cli.color.as_deref(),
                 Some(i64::try_from(article.len()).unwrap_or(i64::MAX)),
             )
+            .with_mode(crate::cli::ProgressMode::from_cli(cli))
         };
         output::write_output_with_progress(path, &article, cli.clobber, progress)
             .await
@@ -361,7 +614,9 @@ async fn finish_article_response(
         let rendered = if core::format_enabled(cli.format.as_deref(), stdout_is_terminal) {
             let use_color = core::color_enabled(cli.color.as_deref(), stdout_is_terminal);
             let mut out = core::Printer::new(use_color);
-            if markdown::format_markdown_to(&article, &mut out).is_ok() {
+            if markdown::format_markdown_to(&article, &mut out, !cli.no_formatter_delegation)
+                .is_ok()
+            {
                 out.into_bytes()
             } else {
                 article.clone()
@@ -379,6 +634,7 @@ async fn finish_article_response(
         )?;
     }
 
+    print_response_trailers(cli, &trailers);
     print_timing(cli, response_timing, body_duration);
     let code = exit_code(status.as_u16(), cli.ignore_status);
     Ok(check_grpc_status(cli, &response_headers, &trailers, code))
@@ -0,0 +1,143 @@
+use super::*;
+
+use super::stdout::response_header_content_type_label;
+
+/// Why a response body was given its stdout formatter.
+#[derive(Clone, Copy, Debug, Eq, PartialEq)]
+pub(super) enum FormatSource {
+    /// The Content-Type header names a supported format.
+    Header,
+    /// The Content-Type header is missing or unknown, so the body was sniffed.
+    Sniffed,
+    /// Formatting is off, either from '--format' or because stdout is not a
+    /// terminal.
+    Disabled,
+    /// A flag such as '--extract' replaced the formatter.
+    Flag(&'static str),
+}
+
+#[derive(Clone, Copy, Debug, Eq, PartialEq)]
+pub(super) struct FormatDecision {
+    content_type: ContentType,
+    source: FormatSource,
+    streaming: bool,
+}
+
+impl FormatDecision {
+    pub(super) fn streamed(content_type: ContentType, source: FormatSource) -> Self {
+        Self {
+            content_type,
+            source,
+            streaming: true,
+        }
+    }
+
+    pub(super) fn buffered(content_type: ContentType, source: FormatSource) -> Self {
+        Self {
+            content_type,
+            source,
+            streaming: false,
+        }
+    }
+}
+
+/// Print the formatter decision for a response body at debug verbosity.
+pub(super) fn print_format_debug(cli: &Cli, headers: &HeaderMap, decision: FormatDecision) {
+    if cli.silent || cli.verbose < 3 {
+        return;
+    }
+    let mut printer = core::stdio().stderr_printer(cli.color.as_deref());
+    printer.write_info_prefix();
+    printer.write_styled("Format", &[core::Sequence::Bold, core::Sequence::Yellow]);
+    printer.push_str(": ");
+    printer.push_str(&format_decision_message(
+        decision,
+        &response_header_content_type_label(headers),
+    ));
+    printer.push('\n');
+    let _ = printer.flush_to(&mut std::io::stderr());
+}
+
+fn format_decision_message(decision: FormatDecision, content_type_label: &str) -> String {
+    let formatter = match decision.source {
+        FormatSource::Disabled => "raw",
+        FormatSource::Flag("--hex") => "hexdump",
+        _ => formatter_name(decision.content_type),
+    };
+    let reason = match decision.source {
+        FormatSource::Header => format!("content type '{content_type_label}'"),
+        FormatSource::Sniffed => format!("sniffed from body, content type '{content_type_label}'"),
+        FormatSource::Disabled => "formatting disabled".to_string(),
+        FormatSource::Flag(flag) => flag.to_string(),
+    };
+    let mode = if decision.streaming {
+        "streaming"
+    } else {
+        "buffered"
+    };
+    format!("{formatter} ({reason}; {mode})")
+}
+
+fn formatter_name(content_type: ContentType) -> &'static str {
+    match content_type {
+        ContentType::Unknown => "raw",
+        ContentType::Css => "css",
+        ContentType::Csv => "csv",
+        ContentType::Grpc => "grpc",
+        ContentType::Html => "html",
+        ContentType::Image => "image",
+        ContentType::Json => "json",
+        ContentType::Markdown => "markdown",
+        ContentType::MsgPack => "msgpack",
+        ContentType::Ndjson => "ndjson",
+        ContentType::Protobuf => "protobuf",
+        ContentType::Sse => "sse",
+        ContentType::Toml => "toml",
+        ContentType::Xml => "xml",
+        ContentType::Yaml => "yaml",
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn format_decision_message_explains_formatter_choice() {
+        assert_eq!(
+            format_decision_message(
+                FormatDecision::buffered(ContentType::Json, FormatSource::Header),
+                "application/json"
+            ),
+            "json (content type 'application/json'; buffered)"
+        );
+        assert_eq!(
+            format_decision_message(
+                FormatDecision::buffered(ContentType::Html, FormatSource::Sniffed),
+                "<none>"
+            ),
+            "html (sniffed from body, content type '<none>'; buffered)"
+        );
+        assert_eq!(
+            format_decision_message(
+                FormatDecision::streamed(ContentType::Sse, FormatSource::Disabled),
+                "text/event-stream"
+            ),
+            "raw (formatting disabled; streaming)"
+        );
+        assert_eq!(
+            format_decision_message(
+                FormatDecision::buffered(ContentType::Json, FormatSource::Flag("--extract")),
+                "application/json"
+            ),
+            "json (--extract; buffered)"
+        );
+        assert_eq!(
+            format_decision_message(
+                FormatDecision::streamed(ContentType::MsgPack, FormatSource::Flag("--hex")),
+                "application/msgpack"
+            ),
+            "hexdump (--hex; streaming)"
+        );
+    }
+}
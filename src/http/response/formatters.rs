@@ -1,7 +1,11 @@
 use super::*;
 
+use crate::format::PartialFormatError;
+
 use super::stdout::{StdoutBody, response_header_content_type, response_header_content_type_label};
-use super::stream::{MAX_BUFFERED_RESPONSE_BYTES, StdoutStreamFormatter, StreamedOutput};
+use super::stream::{
+    BodyChecks, MAX_BUFFERED_RESPONSE_BYTES, StdoutStreamFormatter, StreamedOutput,
+};
 
 pub(super) fn should_stream_formatted_sse_stdout(
     cli: &Cli,
@@ -54,6 +58,7 @@ pub(super) async fn stream_response_to_formatted_sse_stdout(
     copy: bool,
     use_color: bool,
     har_capture: Option<crate::har::Capture>,
+    body_checks: BodyChecks,
 ) -> Result<StreamedOutput, FetchError> {
     super::stream::stream_formatted_response_to_stdout(
         response,
@@ -62,6 +67,7 @@ pub(super) async fn stream_response_to_formatted_sse_stdout(
         copy,
         FormattedSseStream::new(use_color),
         har_capture,
+        body_checks,
     )
     .await
 }
@@ -73,6 +79,7 @@ pub(super) async fn stream_response_to_formatted_ndjson_stdout(
     copy: bool,
     use_color: bool,
     har_capture: Option<crate::har::Capture>,
+    body_checks: BodyChecks,
 ) -> Result<StreamedOutput, FetchError> {
     super::stream::stream_formatted_response_to_stdout(
         response,
@@ -81,6 +88,7 @@ pub(super) async fn stream_response_to_formatted_ndjson_stdout(
         copy,
         FormattedNdjsonStream::new(use_color),
         har_capture,
+        body_checks,
     )
     .await
 }
@@ -93,6 +101,7 @@ pub(super) async fn stream_response_to_formatted_grpc_stdout(
     grpc_response_desc: Option<prost_reflect::MessageDescriptor>,
     use_color: bool,
     har_capture: Option<crate::har::Capture>,
+    body_checks: BodyChecks,
 ) -> Result<StreamedOutput, FetchError> {
     let formatter = FormattedGrpcStream::new(&response_headers, grpc_response_desc, use_color);
     super::stream::stream_formatted_response_to_stdout(
@@ -102,6 +111,31 @@ pub(super) async fn stream_response_to_formatted_grpc_stdout(
         copy,
         formatter,
         har_capture,
+        body_checks,
+    )
+    .await
+}
+
+pub(super) async fn stream_response_to_hexdump_stdout(
+    response: Response,
+    response_headers: HeaderMap,
+    compression: CompressionMode,
+    copy: bool,
+    use_color: bool,
+    har_capture: Option<crate::har::Capture>,
+    body_checks: BodyChecks,
+) -> Result<StreamedOutput, FetchError> {
+    super::stream::stream_formatted_response_to_stdout(
+        response,
+        response_headers,
+        compression,
+        copy,
+        HexdumpStream {
+            formatter: hexdump::HexdumpFormatter::new(),
+            use_color,
+        },
+        har_capture,
+        body_checks,
     )
     .await
 }
@@ -314,6 +348,25 @@ impl StdoutStreamFormatter for FormattedGrpcStream {
     }
 }
 
+struct HexdumpStream {
+    formatter: hexdump::HexdumpFormatter,
+    use_color: bool,
+}
+
+impl StdoutStreamFormatter for HexdumpStream {
+    fn push_chunk(&mut self, chunk: &[u8]) -> Result<Vec<Vec<u8>>, FetchError> {
+        let mut out = core::Printer::new(self.use_color);
+        self.formatter.push(chunk, &mut out);
+        Ok(vec![out.into_bytes()])
+    }
+
+    fn finish(&mut self) -> Result<Vec<Vec<u8>>, FetchError> {
+        let mut out = core::Printer::new(self.use_color);
+        self.formatter.finish(&mut out);
+        Ok(vec![out.into_bytes()])
+    }
+}
+
 pub(super) fn push_sse_stream_bytes(
     pending: &mut Vec<u8>,
     formatter: &mut sse::EventStreamFormatter,
@@ -401,10 +454,17 @@ pub(super) fn format_stdout_bytes_with_terminal(
         .get(http::header::CONTENT_TYPE)
         .and_then(|value| value.to_str().ok());
     let (mut content_type, charset) = content_type::get_content_type(content_type);
+    let mut source = FormatSource::Header;
     if content_type == ContentType::Unknown {
         content_type = content_type::sniff_content_type(bytes);
+        source = FormatSource::Sniffed;
+    }
+    let format_enabled = core::format_enabled(cli.format.as_deref(), stdout_is_terminal);
+    if !format_enabled {
+        source = FormatSource::Disabled;
     }
-    if !core::format_enabled(cli.format.as_deref(), stdout_is_terminal) {
+    print_format_debug(cli, headers, FormatDecision::buffered(content_type, source));
+    if !format_enabled {
         return Ok(StdoutBody {
             bytes: bytes.to_vec(),
             content_type,
@@ -414,32 +474,43 @@ pub(super) fn format_stdout_bytes_with_terminal(
 
     let use_color = core::color_enabled(cli.color.as_deref(), stdout_is_terminal);
     let bytes = transcode_format_bytes(bytes, &charset, content_type);
-    let bytes = match content_type {
+    let mut formatted = match content_type {
         ContentType::Json => {
-            Ok(
-                format_printer_bytes(use_color, |out| json::format_json_to(&bytes, out))
-                    .unwrap_or_else(|_| bytes.to_vec()),
-            )
-        }
-        ContentType::Ndjson => {
-            Ok(
-                format_printer_bytes(use_color, |out| json::format_ndjson_to(&bytes, out))
-                    .unwrap_or_else(|_| bytes.to_vec()),
-            )
+            if cli.verbose > 0
+                && let Some(warning) = duplicate_keys_warning(&json::duplicate_keys(&bytes))
+            {
+                write_warning(cli, &warning);
+            }
+            Ok(format_printer_bytes_or_raw_tail(use_color, &bytes, |out| {
+                json::format_json_prefix_to(
+                    &bytes,
+                    out,
+                    json::JsonOptions {
+                        sort_keys: cli.sort_keys,
+                        unwrap_strings: cli.unwrap_json,
+                    },
+                )
+            }))
         }
+        ContentType::Ndjson => Ok(format_printer_bytes_or_raw_tail(use_color, &bytes, |out| {
+            json::format_ndjson_prefix_to(&bytes, out)
+        })),
         ContentType::Csv => Ok(format_printer_bytes(use_color, |out| {
             csv::format_csv_to_with_terminal_cols(&bytes, out, terminal_cols)
         })
         .unwrap_or_else(|_| bytes.to_vec())),
-        ContentType::Xml => {
+        ContentType::Xml => Ok(format_printer_bytes_or_raw_tail(use_color, &bytes, |out| {
+            xml::format_xml_prefix_to(&bytes, out)
+        })),
+        ContentType::Yaml => {
             Ok(
-                format_printer_bytes(use_color, |out| xml::format_xml_to(&bytes, out))
+                format_printer_bytes(use_color, |out| yaml::format_yaml_to(&bytes, out))
                     .unwrap_or_else(|_| bytes.to_vec()),
             )
         }
-        ContentType::Yaml => {
+        ContentType::Toml => {
             Ok(
-                format_printer_bytes(use_color, |out| yaml::format_yaml_to(&bytes, out))
+                format_printer_bytes(use_color, |out| toml::format_toml_to(&bytes, out))
                     .unwrap_or_else(|_| bytes.to_vec()),
             )
         }
@@ -455,12 +526,10 @@ pub(super) fn format_stdout_bytes_with_terminal(
                     .unwrap_or_else(|_| bytes.to_vec()),
             )
         }
-        ContentType::Markdown => {
-            Ok(
-                format_printer_bytes(use_color, |out| markdown::format_markdown_to(&bytes, out))
-                    .unwrap_or_else(|_| bytes.to_vec()),
-            )
-        }
+        ContentType::Markdown => Ok(format_printer_bytes(use_color, |out| {
+            markdown::format_markdown_to(&bytes, out, !cli.no_formatter_delegation)
+        })
+        .unwrap_or_else(|_| bytes.to_vec())),
         ContentType::MsgPack => {
             Ok(
                 format_printer_bytes(use_color, |out| msgpack::format_msgpack_to(&bytes, out))
@@ -520,7 +589,149 @@ pub(super) fn format_stdout_bytes_with_terminal(
         }
         _ => Ok(bytes.to_vec()),
     }?;
+    if cli.decode_jwt
+        && let Ok(text) = std::str::from_utf8(&bytes)
+    {
+        let mut out = core::Printer::new(use_color);
+        jwt::write_jwts_to(&mut out, text);
+        formatted.extend_from_slice(out.bytes());
+    }
+    if cli.line_numbers && !matches!(content_type, ContentType::Unknown | ContentType::Image) {
+        formatted = line_numbers::number_lines(&formatted, use_color);
+    }
+    Ok(StdoutBody {
+        bytes: formatted,
+        content_type,
+        content_type_label: response_header_content_type_label(headers),
+    })
+}
+
+#[derive(Clone, Copy, Debug, Eq, PartialEq)]
+pub(super) enum BodyConversion {
+    Json,
+    Yaml,
+}
+
+impl BodyConversion {
+    pub(super) fn from_cli(cli: &Cli) -> Option<Self> {
+        if cli.to_json {
+            Some(Self::Json)
+        } else if cli.to_yaml {
+            Some(Self::Yaml)
+        } else {
+            None
+        }
+    }
+
+    fn flag(self) -> &'static str {
+        match self {
+            Self::Json => "--to-json",
+            Self::Yaml => "--to-yaml",
+        }
+    }
+}
+
+pub(super) fn convert_body(
+    headers: HeaderMap,
+    bytes: Vec<u8>,
+    target: BodyConversion,
+) -> Result<(HeaderMap, Vec<u8>), FetchError> {
+    if bytes.is_empty() {
+        return Ok((headers, bytes));
+    }
+    let raw_content_type = headers
+        .get(CONTENT_TYPE)
+        .and_then(|value| value.to_str().ok());
+    let (mut content_type, charset) = content_type::get_content_type(raw_content_type);
+    if content_type == ContentType::Unknown {
+        content_type = content_type::sniff_content_type(&bytes);
+    }
+    let conversion_error = |source: &str, err: &dyn std::fmt::Display| {
+        let target = match target {
+            BodyConversion::Json => "JSON",
+            BodyConversion::Yaml => "YAML",
+        };
+        FetchError::Message(format!(
+            "cannot convert {source} response to {target}: {err}"
+        ))
+    };
+    let value = match (content_type, target) {
+        (ContentType::Json, BodyConversion::Json) | (ContentType::Yaml, BodyConversion::Yaml) => {
+            return Ok((headers, bytes));
+        }
+        (ContentType::Json, _) => serde_json::from_slice(&transcode_bytes(&bytes, &charset))
+            .map_err(|err| conversion_error("JSON", &err))?,
+        (ContentType::Xml, _) => xml::xml_to_json_value(&transcode_bytes(&bytes, &charset))
+            .map_err(|err| conversion_error("XML", &err))?,
+        (ContentType::Yaml, _) => yaml::yaml_to_json_value(&transcode_bytes(&bytes, &charset))
+            .map_err(|err| conversion_error("YAML", &err))?,
+        _ => {
+            return Err(FetchError::Message(format!(
+                "response content type '{}' cannot be converted with '{}'",
+                response_header_content_type_label(&headers),
+                target.flag()
+            )));
+        }
+    };
+    let (bytes, content_type) = match target {
+        BodyConversion::Json => (
+            serde_json::to_vec(&value).expect("JSON value serializes"),
+            "application/json",
+        ),
+        BodyConversion::Yaml => (yaml::json_to_yaml(&value).into_bytes(), "application/yaml"),
+    };
+    let mut headers = headers;
+    headers.insert(CONTENT_TYPE, HeaderValue::from_static(content_type));
+    Ok((headers, bytes))
+}
+
+pub(super) fn extract_stdout_body(
+    path: &JsonPath,
+    all: bool,
+    bytes: &[u8],
+) -> Result<StdoutBody, FetchError> {
+    let value: serde_json::Value = serde_json::from_slice(bytes).map_err(|err| {
+        FetchError::Message(format!(
+            "cannot extract '{}': response body is not valid JSON: {err}",
+            path.as_str()
+        ))
+    })?;
+    let matches = path.select(&value);
+    if matches.is_empty() {
+        return Err(FetchError::Message(format!(
+            "no value matches extract path '{}'",
+            path.as_str()
+        )));
+    }
+    if matches.len() > 1 && !all {
+        return Err(FetchError::Message(format!(
+            "extract path '{}' matches {} values; use '--extract-all' to print all of them",
+            path.as_str(),
+            matches.len()
+        )));
+    }
+    let mut out = Vec::new();
+    for value in matches {
+        extract::write_extracted_value(value, &mut out);
+    }
     Ok(StdoutBody {
+        bytes: out,
+        content_type: ContentType::Unknown,
+        content_type_label: "text/plain; charset=utf-8".to_string(),
+    })
+}
+
+pub(super) fn minify_stdout_body(headers: &HeaderMap, bytes: &[u8]) -> Option<StdoutBody> {
+    let mut content_type = response_header_content_type(headers);
+    if content_type == ContentType::Unknown {
+        content_type = content_type::sniff_content_type(bytes);
+    }
+    let bytes = match content_type {
+        ContentType::Html => html::minify_html(bytes),
+        ContentType::Css => css::minify_css(bytes),
+        _ => return None,
+    };
+    Some(StdoutBody {
         bytes,
         content_type,
         content_type_label: response_header_content_type_label(headers),
@@ -536,6 +747,50 @@ fn format_printer_bytes<E>(
     Ok(out.into_bytes())
 }
 
+fn duplicate_keys_warning(paths: &[String]) -> Option<String> {
+    const MAX_LISTED: usize = 5;
+    if paths.is_empty() {
+        return None;
+    }
+    let mut listed = paths
+        .iter()
+        .take(MAX_LISTED)
+        .map(|path| format!("'{path}'"))
+        .collect::<Vec<_>>()
+        .join(", ");
+    if paths.len() > MAX_LISTED {
+        listed.push_str(&format!(" and {} more", paths.len() - MAX_LISTED));
+    }
+    Some(format!(
+        "response JSON has duplicate object keys {listed}; only the last value of each is shown"
+    ))
+}
+
+/// Format a body with a formatter that reports how much of the input it
+/// handled, printing any input after a formatting error unformatted instead of
+/// discarding the formatted prefix.
+fn format_printer_bytes_or_raw_tail(
+    use_color: bool,
+    bytes: &[u8],
+    write: impl FnOnce(&mut core::Printer) -> Result<(), PartialFormatError>,
+) -> Vec<u8> {
+    let mut out = core::Printer::new(use_color);
+    let Err(err) = write(&mut out) else {
+        return out.into_bytes();
+    };
+    if err.formatted == 0 {
+        return bytes.to_vec();
+    }
+    let tail = &bytes[err.formatted..];
+    let tail_start = tail
+        .iter()
+        .position(|byte| !byte.is_ascii_whitespace())
+        .unwrap_or(tail.len());
+    let mut formatted = out.into_bytes();
+    formatted.extend_from_slice(&tail[tail_start..]);
+    formatted
+}
+
 pub(super) fn transcode_format_bytes(
     bytes: &[u8],
     charset: &str,
@@ -547,6 +802,12 @@ pub(super) fn transcode_format_bytes(
     ) {
         return bytes.to_vec();
     }
+    if content_type == ContentType::Html
+        && charset.trim().is_empty()
+        && let Some(charset) = html::meta_charset(bytes)
+    {
+        return transcode_bytes(bytes, charset);
+    }
     transcode_bytes(bytes, charset)
 }
 
@@ -869,6 +1130,31 @@ mod tests {
         assert!(charset_decoder("not-a-real-charset").is_none());
     }
 
+    #[test]
+    fn formatted_html_uses_meta_charset_without_header_charset() {
+        let cli = Cli::try_parse_from(["fetch", "--format", "on", "https://example.com"]).unwrap();
+        let mut body = b"<html><head><meta charset=\"windows-1252\"></head><body><p>".to_vec();
+        body.extend_from_slice(&[0x93, 0x63, 0x61, 0x66, 0xe9, 0x94, 0x20, 0x80]);
+        body.extend_from_slice(b"</p></body></html>");
+
+        let mut headers = HeaderMap::new();
+        headers.insert(CONTENT_TYPE, HeaderValue::from_static("text/html"));
+        let out = format_stdout_bytes_with_terminal(&cli, &headers, &body, None, false, 0).unwrap();
+        let out = String::from_utf8(out.bytes).unwrap();
+        assert!(out.contains("\u{201c}caf\u{e9}\u{201d} \u{20ac}"), "{out}");
+
+        headers.insert(
+            CONTENT_TYPE,
+            HeaderValue::from_static("text/html; charset=utf-8"),
+        );
+        let out = format_stdout_bytes_with_terminal(&cli, &headers, &body, None, false, 0).unwrap();
+        assert!(
+            !out.bytes
+                .windows(2)
+                .any(|window| window == "\u{e9}".as_bytes())
+        );
+    }
+
     #[test]
     fn transcode_bytes_matches_go_charset_cases() {
         let cases = [
@@ -954,6 +1240,56 @@ mod tests {
         }
     }
 
+    #[test]
+    fn formatted_stdout_prints_raw_tail_after_partial_format_error() {
+        let cli = Cli::try_parse_from(["fetch", "--format", "on", "https://example.com"]).unwrap();
+        let cases = [
+            (
+                "application/json",
+                &br#"{"ok":true} trailing"#[..],
+                "{\n  \"ok\": true\n}\ntrailing",
+            ),
+            (
+                "application/x-ndjson",
+                b"{\"a\":1}\n{bad\n",
+                "{ \"a\": 1 }\n{bad\n",
+            ),
+            (
+                "application/xml",
+                b"<root><a>1</a></b>",
+                "<root>\n  <a>1</a>\n</b>",
+            ),
+            ("application/json", b"{\"ok\":", "{\"ok\":"),
+        ];
+
+        for (content_type, body, expected) in cases {
+            let mut headers = HeaderMap::new();
+            headers.insert(CONTENT_TYPE, HeaderValue::from_static(content_type));
+            let out =
+                format_stdout_bytes_with_terminal(&cli, &headers, body, None, false, 0).unwrap();
+            assert_eq!(
+                String::from_utf8(out.bytes).unwrap(),
+                expected,
+                "{content_type}"
+            );
+        }
+    }
+
+    #[test]
+    fn duplicate_keys_warning_lists_a_bounded_number_of_paths() {
+        assert_eq!(duplicate_keys_warning(&[]), None);
+        assert_eq!(
+            duplicate_keys_warning(&["$.id".to_string()]).unwrap(),
+            "response JSON has duplicate object keys '$.id'; only the last value of each is shown"
+        );
+        let paths: Vec<String> = (0..7).map(|index| format!("$[{index}].id")).collect();
+        assert_eq!(
+            duplicate_keys_warning(&paths).unwrap(),
+            "response JSON has duplicate object keys '$[0].id', '$[1].id', '$[2].id', '$[3].id', \
+             '$[4].id' and 2 more; only the last value of each is shown"
+        );
+    }
+
     #[test]
     fn formatted_stdout_uses_go_color_auto_target_policy() {
         let mut headers = HeaderMap::new();
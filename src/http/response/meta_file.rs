@@ -0,0 +1,168 @@
+use super::*;
+
+use serde::Serialize;
+
+/// Return the path of the `--write-meta` sidecar for an output file.
+pub(super) fn meta_file_path(output_path: &str) -> String {
+    format!("{output_path}.meta.json")
+}
+
+/// What `--write-meta` records about a response saved to a file.
+#[derive(Serialize)]
+pub(super) struct OutputMeta<'a> {
+    url: &'a str,
+    status: u16,
+    content_type: Option<String>,
+    size: i64,
+    headers: Vec<MetaHeader>,
+    timing: Option<MetaTiming>,
+}
+
+#[derive(Serialize)]
+struct MetaHeader {
+    name: String,
+    value: String,
+}
+
+/// Phase durations in milliseconds. Connection phases are omitted when the
+/// request reused a connection or did not go through them.
+#[derive(Serialize)]
+struct MetaTiming {
+    #[serde(skip_serializing_if = "Option::is_none")]
+    dns_ms: Option<f64>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    tcp_ms: Option<f64>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    tls_ms: Option<f64>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    quic_ms: Option<f64>,
+    ttfb_ms: f64,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    body_ms: Option<f64>,
+    total_ms: f64,
+}
+
+impl<'a> OutputMeta<'a> {
+    pub(super) fn new(
+        url: &'a Url,
+        status: StatusCode,
+        headers: &HeaderMap,
+        size: i64,
+        timing: Option<ResponseTiming>,
+    ) -> Self {
+        Self {
+            url: url.as_str(),
+            status: status.as_u16(),
+            content_type: headers
+                .get(CONTENT_TYPE)
+                .map(|value| String::from_utf8_lossy(value.as_bytes()).into_owned()),
+            size,
+            headers: headers
+                .iter()
+                .map(|(name, value)| MetaHeader {
+                    name: name.as_str().to_string(),
+                    value: String::from_utf8_lossy(value.as_bytes()).into_owned(),
+                })
+                .collect(),
+            timing: timing.map(MetaTiming::from),
+        }
+    }
+
+    fn to_json(&self) -> Vec<u8> {
+        let mut bytes = serde_json::to_vec_pretty(self).expect("output metadata serializes");
+        bytes.push(b'\n');
+        bytes
+    }
+}
+
+impl From<ResponseTiming> for MetaTiming {
+    fn from(timing: ResponseTiming) -> Self {
+        let total = [timing.dns, timing.tcp, timing.tls, timing.quic, timing.body]
+            .into_iter()
+            .flatten()
+            .fold(timing.ttfb, |total, phase| total + phase);
+        Self {
+            dns_ms: timing.dns.map(duration_ms),
+            tcp_ms: timing.tcp.map(duration_ms),
+            tls_ms: timing.tls.map(duration_ms),
+            quic_ms: timing.quic.map(duration_ms),
+            ttfb_ms: duration_ms(timing.ttfb),
+            body_ms: timing.body.map(duration_ms),
+            total_ms: duration_ms(total),
+        }
+    }
+}
+
+fn duration_ms(value: Duration) -> f64 {
+    value.as_secs_f64() * 1000.0
+}
+
+/// Write the sidecar next to the output file. It follows the same clobber
+/// rules as the output itself.
+pub(super) async fn write_meta_file(
+    cli: &Cli,
+    output_path: &str,
+    meta: &OutputMeta<'_>,
+) -> Result<(), FetchError> {
+    output::write_output(&meta_file_path(output_path), &meta.to_json(), cli.clobber)
+        .await
+        .map_err(|err| FetchError::Message(err.to_string()))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    use serde_json::{Value, json};
+
+    #[test]
+    fn output_meta_records_response_details() {
+        let url = Url::parse("https://example.com/files/report.csv").unwrap();
+        let mut headers = HeaderMap::new();
+        headers.insert(CONTENT_TYPE, HeaderValue::from_static("text/csv"));
+        headers.append("x-tag", HeaderValue::from_static("a"));
+        headers.append("x-tag", HeaderValue::from_static("b"));
+        let timing = ResponseTiming {
+            dns: Some(Duration::from_millis(2)),
+            tcp: Some(Duration::from_millis(3)),
+            tls: None,
+            quic: None,
+            ttfb: Duration::from_millis(10),
+            body: Some(Duration::from_millis(5)),
+        };
+
+        let meta = OutputMeta::new(&url, StatusCode::OK, &headers, 42, Some(timing));
+        let value: Value = serde_json::from_slice(&meta.to_json()).unwrap();
+        assert_eq!(
+            value,
+            json!({
+                "url": "https://example.com/files/report.csv",
+                "status": 200,
+                "content_type": "text/csv",
+                "size": 42,
+                "headers": [
+                    {"name": "content-type", "value": "text/csv"},
+                    {"name": "x-tag", "value": "a"},
+                    {"name": "x-tag", "value": "b"},
+                ],
+                "timing": {
+                    "dns_ms": 2.0,
+                    "tcp_ms": 3.0,
+                    "ttfb_ms": 10.0,
+                    "body_ms": 5.0,
+                    "total_ms": 20.0,
+                },
+            })
+        );
+
+        let meta = OutputMeta::new(&url, StatusCode::NOT_FOUND, &HeaderMap::new(), 0, None);
+        let value: Value = serde_json::from_slice(&meta.to_json()).unwrap();
+        assert_eq!(value["content_type"], Value::Null);
+        assert_eq!(value["timing"], Value::Null);
+    }
+
+    #[test]
+    fn meta_file_path_appends_suffix() {
+        assert_eq!(meta_file_path("out/report.csv"), "out/report.csv.meta.json");
+    }
+}
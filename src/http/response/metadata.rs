@@ -13,6 +13,7 @@ pub(super) fn finalize_streamed_response(
 ) -> i32 {
     handle_optional_clipboard_outcome(cli, streamed.clipboard);
     let body_duration = body_duration_from_len(method_is_head, streamed.bytes_written, body_start);
+    print_response_trailers(cli, &streamed.trailers);
     print_timing(cli, response_timing, body_duration);
 
     let code = exit_code(status.as_u16(), cli.ignore_status);
@@ -47,7 +48,11 @@ pub(super) fn body_duration(
     )
 }
 
-fn body_duration_from_len(method_is_head: bool, len: i64, start: Instant) -> Option<Duration> {
+pub(super) fn body_duration_from_len(
+    method_is_head: bool,
+    len: i64,
+    start: Instant,
+) -> Option<Duration> {
     if method_is_head || len == 0 {
         None
     } else {
@@ -83,12 +88,34 @@ pub(super) fn check_grpc_status(
     if status.ok() {
         return exit_code;
     }
-    if !cli.silent {
+    if !cli.silent || cli.body_only {
         write_error_with_color(status, cli.color.as_deref());
     }
     if exit_code == 0 { 1 } else { exit_code }
 }
 
+pub(super) fn check_response_schema(
+    cli: &Cli,
+    schema: Option<&crate::schema::Schema>,
+    bytes: &[u8],
+    exit_code: i32,
+) -> i32 {
+    let Some(schema) = schema else {
+        return exit_code;
+    };
+    if exit_code != 0 {
+        return exit_code;
+    }
+    let violations = schema.validate_bytes(bytes);
+    if violations.is_empty() {
+        return exit_code;
+    }
+    if !cli.silent || cli.body_only {
+        write_error_with_color(schema.violations_message(&violations), cli.color.as_deref());
+    }
+    crate::schema::SCHEMA_VIOLATION_EXIT_CODE
+}
+
 pub(super) fn print_response_metadata(cli: &Cli, response: &Response) {
     if cli.silent {
         return;
@@ -114,18 +141,9 @@ pub(super) fn print_response_metadata(cli: &Cli, response: &Response) {
     printer.push_str("\n");
 
     if cli.verbose > 0 {
-        let mut lines = header_lines(response.headers());
-        if cli.sort_headers {
-            sort_header_lines(&mut lines);
-        }
-        for (name, value) in lines {
-            if cli.verbose >= 2 {
-                printer.write_response_prefix();
-            }
-            printer.write_styled(&name, &[core::Sequence::Bold, core::Sequence::Cyan]);
-            printer.push_str(": ");
-            printer.push_str(&value);
-            printer.push_str("\n");
+        write_response_header_lines(cli, &mut printer, response.headers());
+        if cli.verbose >= 2 {
+            write_received_cookies(&mut printer, response.headers());
         }
     }
     if cli.verbose >= 2 {
@@ -135,7 +153,48 @@ pub(super) fn print_response_metadata(cli: &Cli, response: &Response) {
     core::flush_stderr(printer);
 }
 
-pub(super) fn exit_code(status: u16, ignore_status: bool) -> i32 {
+/// Print the trailers that arrived after the response body, below a label so
+/// they are not mistaken for headers. Trailers are only known once the body
+/// has been read to the end.
+pub(super) fn print_response_trailers(cli: &Cli, trailers: &HeaderMap) {
+    if cli.verbose == 0 || cli.silent || trailers.is_empty() {
+        return;
+    }
+    let mut printer = core::Printer::stderr(cli.color.as_deref());
+    write_response_trailers(cli, &mut printer, trailers);
+    core::flush_stderr(printer);
+}
+
+fn write_response_trailers(cli: &Cli, printer: &mut core::Printer, trailers: &HeaderMap) {
+    if cli.verbose >= 2 {
+        printer.write_response_prefix();
+    }
+    printer.push('\n');
+    if cli.verbose >= 2 {
+        printer.write_response_prefix();
+    }
+    printer.write_styled("Trailers", &[core::Sequence::Bold, core::Sequence::Yellow]);
+    printer.push_str(":\n");
+    write_response_header_lines(cli, printer, trailers);
+}
+
+fn write_response_header_lines(cli: &Cli, printer: &mut core::Printer, headers: &HeaderMap) {
+    let mut lines = header_lines(headers);
+    if cli.sort_headers {
+        sort_header_lines(&mut lines);
+    }
+    for (name, value) in lines {
+        if cli.verbose >= 2 {
+            printer.write_response_prefix();
+        }
+        printer.write_styled(&name, &[core::Sequence::Bold, core::Sequence::Cyan]);
+        printer.push_str(": ");
+        printer.push_str(&value);
+        printer.push_str("\n");
+    }
+}
+
+pub(in crate::http) fn exit_code(status: u16, ignore_status: bool) -> i32 {
     if ignore_status || (200..400).contains(&status) {
         0
     } else if (400..500).contains(&status) {
@@ -184,6 +243,30 @@ mod tests {
         assert_eq!(code, 1);
     }
 
+    #[test]
+    fn response_trailers_are_labeled_after_the_body() {
+        let mut trailers = HeaderMap::new();
+        trailers.insert("grpc-status", HeaderValue::from_static("0"));
+        trailers.insert("grpc-message", HeaderValue::from_static("ok"));
+
+        let cli =
+            Cli::try_parse_from(["fetch", "-v", "--sort-headers", "https://example.com"]).unwrap();
+        let mut printer = core::Printer::new(false);
+        write_response_trailers(&cli, &mut printer, &trailers);
+        assert_eq!(
+            printer.into_string().unwrap(),
+            "\nTrailers:\ngrpc-message: ok\ngrpc-status: 0\n"
+        );
+
+        let cli = Cli::try_parse_from(["fetch", "-vv", "https://example.com"]).unwrap();
+        let mut printer = core::Printer::new(false);
+        write_response_trailers(&cli, &mut printer, &trailers);
+        assert_eq!(
+            printer.into_string().unwrap(),
+            "< \n< Trailers:\n< grpc-status: 0\n< grpc-message: ok\n"
+        );
+    }
+
     #[test]
     fn exit_code_maps_status_classes() {
         assert_eq!(exit_code(200, false), 0);
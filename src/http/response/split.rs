@@ -0,0 +1,137 @@
+use super::*;
+
+use serde_json::Value;
+
+/// Select the JSON values to write as separate files: the elements of the
+/// top-level array, or of the array matched by `--extract`.
+pub(super) fn split_json_values(
+    path: Option<&JsonPath>,
+    all: bool,
+    bytes: &[u8],
+) -> Result<Vec<Value>, FetchError> {
+    let value: Value = serde_json::from_slice(bytes).map_err(|err| {
+        FetchError::Message(format!(
+            "cannot split output: response body is not valid JSON: {err}"
+        ))
+    })?;
+    let Some(path) = path else {
+        return array_elements(value, "response body");
+    };
+
+    let mut matches = path.select(&value);
+    if matches.is_empty() {
+        return Err(FetchError::Message(format!(
+            "no value matches extract path '{}'",
+            path.as_str()
+        )));
+    }
+    if all {
+        return Ok(matches.into_iter().cloned().collect());
+    }
+    if matches.len() > 1 {
+        return Err(FetchError::Message(format!(
+            "extract path '{}' matches {} values; use '--extract-all' to split all of them",
+            path.as_str(),
+            matches.len()
+        )));
+    }
+    let value = matches.pop().expect("one match").clone();
+    array_elements(value, &format!("extract path '{}'", path.as_str()))
+}
+
+fn array_elements(value: Value, source: &str) -> Result<Vec<Value>, FetchError> {
+    match value {
+        Value::Array(items) => Ok(items),
+        _ => Err(FetchError::Message(format!(
+            "cannot split output: {source} is not a JSON array"
+        ))),
+    }
+}
+
+pub(super) async fn write_split_output(
+    cli: &Cli,
+    pattern: &str,
+    values: &[Value],
+) -> Result<(), FetchError> {
+    if values.is_empty() {
+        write_warning(cli, "response array is empty; no files were written");
+        return Ok(());
+    }
+    for (index, value) in values.iter().enumerate() {
+        let path = split_output_path(pattern, index);
+        let mut bytes = serde_json::to_vec_pretty(value).expect("JSON value serializes");
+        bytes.push(b'\n');
+        output::write_output(&path, &bytes, cli.clobber)
+            .await
+            .map_err(|err| FetchError::Message(err.to_string()))?;
+    }
+    Ok(())
+}
+
+/// Replace `{n}` in the pattern with the element index, or insert `-N` before
+/// the file extension when the pattern has no placeholder.
+pub(super) fn split_output_path(pattern: &str, index: usize) -> String {
+    if pattern.contains("{n}") {
+        return pattern.replace("{n}", &index.to_string());
+    }
+    let name_start = pattern
+        .rfind(std::path::is_separator)
+        .map_or(0, |separator| separator + 1);
+    let extension_start = pattern[name_start..]
+        .rfind('.')
+        .filter(|dot| *dot > 0)
+        .map_or(pattern.len(), |dot| name_start + dot);
+    format!(
+        "{}-{index}{}",
+        &pattern[..extension_start],
+        &pattern[extension_start..]
+    )
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    use serde_json::json;
+
+    #[test]
+    fn split_output_path_numbers_files() {
+        assert_eq!(split_output_path("out.json", 0), "out-0.json");
+        assert_eq!(split_output_path("records/{n}.json", 12), "records/12.json");
+        assert_eq!(split_output_path("dir.d/items", 3), "dir.d/items-3");
+        assert_eq!(split_output_path(".hidden", 1), ".hidden-1");
+        assert_eq!(split_output_path("a.tar.gz", 2), "a.tar-2.gz");
+    }
+
+    #[test]
+    fn split_json_values_selects_top_level_or_extracted_array() {
+        let body = br#"{"data":{"items":[{"id":1},{"id":2}]},"next":null}"#;
+
+        let path = JsonPath::parse("$.data.items").unwrap();
+        let values = split_json_values(Some(&path), false, body).unwrap();
+        assert_eq!(values, [json!({"id": 1}), json!({"id": 2})]);
+
+        let path = JsonPath::parse("$.data.items[*].id").unwrap();
+        let values = split_json_values(Some(&path), true, body).unwrap();
+        assert_eq!(values, [json!(1), json!(2)]);
+
+        let values = split_json_values(None, false, b"[1, \"two\"]").unwrap();
+        assert_eq!(values, [json!(1), json!("two")]);
+
+        let err = split_json_values(None, false, body)
+            .unwrap_err()
+            .to_string();
+        assert_eq!(
+            err,
+            "cannot split output: response body is not a JSON array"
+        );
+        let path = JsonPath::parse("$.next").unwrap();
+        let err = split_json_values(Some(&path), false, body)
+            .unwrap_err()
+            .to_string();
+        assert_eq!(
+            err,
+            "cannot split output: extract path '$.next' is not a JSON array"
+        );
+    }
+}
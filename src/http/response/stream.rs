@@ -1,5 +1,7 @@
 use super::*;
 
+use std::sync::atomic::{AtomicU64, Ordering};
+
 use super::stdout::{
     StdoutStreamTarget, binary_response_warning, response_header_content_type_label,
     terminal_binary_stdout_guard_enabled,
@@ -16,12 +18,14 @@ pub(super) async fn read_decoded_response_body_limited(
     response_headers: HeaderMap,
     compression: CompressionMode,
     har_capture: Option<crate::har::Capture>,
+    body_checks: BodyChecks,
 ) -> Result<(Vec<u8>, HeaderMap), FetchError> {
     read_decoded_response_body_with_limit_message(
         response,
         response_headers,
         compression,
         har_capture,
+        body_checks,
         "cannot be buffered; use '--format off' or write to a file to stream it",
     )
     .await
@@ -32,12 +36,14 @@ pub(super) async fn read_decoded_article_body_limited(
     response_headers: HeaderMap,
     compression: CompressionMode,
     har_capture: Option<crate::har::Capture>,
+    body_checks: BodyChecks,
 ) -> Result<(Vec<u8>, HeaderMap), FetchError> {
     read_decoded_response_body_with_limit_message(
         response,
         response_headers,
         compression,
         har_capture,
+        body_checks,
         "cannot be extracted as an article",
     )
     .await
@@ -48,10 +54,16 @@ async fn read_decoded_response_body_with_limit_message(
     response_headers: HeaderMap,
     compression: CompressionMode,
     har_capture: Option<crate::har::Capture>,
+    body_checks: BodyChecks,
     limit_message: &str,
 ) -> Result<(Vec<u8>, HeaderMap), FetchError> {
-    let (mut reader, trailers) =
-        decoded_capturing_response_reader(response, compression, &response_headers, har_capture)?;
+    let (mut reader, trailers) = decoded_capturing_response_reader(
+        response,
+        compression,
+        &response_headers,
+        har_capture,
+        body_checks,
+    )?;
     let mut bytes = Vec::new();
     let mut buf = vec![0; 16 * 1024];
     loop {
@@ -120,9 +132,15 @@ pub(super) async fn stream_response_to_discard(
     response_headers: HeaderMap,
     compression: CompressionMode,
     har_capture: Option<crate::har::Capture>,
+    body_checks: BodyChecks,
 ) -> Result<StreamedOutput, FetchError> {
-    let (mut reader, trailers) =
-        decoded_capturing_response_reader(response, compression, &response_headers, har_capture)?;
+    let (mut reader, trailers) = decoded_capturing_response_reader(
+        response,
+        compression,
+        &response_headers,
+        har_capture,
+        body_checks,
+    )?;
     let mut sink = tokio::io::sink();
     let bytes_written = copy_async_reader_to_writer(&mut reader, &mut sink, None).await?;
     let trailers = captured_trailers(&trailers);
@@ -143,9 +161,15 @@ pub(super) async fn stream_response_to_stdout(
     target: StdoutStreamTarget,
     stdout_is_terminal: bool,
     har_capture: Option<crate::har::Capture>,
+    body_checks: BodyChecks,
 ) -> Result<StreamedOutput, FetchError> {
-    let (mut reader, trailers) =
-        decoded_capturing_response_reader(response, compression, &response_headers, har_capture)?;
+    let (mut reader, trailers) = decoded_capturing_response_reader(
+        response,
+        compression,
+        &response_headers,
+        har_capture,
+        body_checks,
+    )?;
     let mut capture = copy.then(clipboard::Capture::default);
     let bytes_written = if terminal_binary_stdout_guard_enabled(cli, stdout_is_terminal) {
         stream_response_to_stdout_with_binary_check(
@@ -175,12 +199,18 @@ pub(super) async fn stream_formatted_response_to_stdout<F>(
     copy: bool,
     mut formatter: F,
     har_capture: Option<crate::har::Capture>,
+    body_checks: BodyChecks,
 ) -> Result<StreamedOutput, FetchError>
 where
     F: StdoutStreamFormatter,
 {
-    let (mut reader, trailers) =
-        decoded_capturing_response_reader(response, compression, &response_headers, har_capture)?;
+    let (mut reader, trailers) = decoded_capturing_response_reader(
+        response,
+        compression,
+        &response_headers,
+        har_capture,
+        body_checks,
+    )?;
     let mut stdout = tokio::io::stdout();
     let mut capture = copy.then(clipboard::Capture::default);
     let mut buf = vec![0; 16 * 1024];
@@ -274,9 +304,15 @@ pub(super) async fn stream_response_to_output(
     progress: output::WriteProgress,
     copy: bool,
     har_capture: Option<crate::har::Capture>,
+    body_checks: BodyChecks,
 ) -> Result<StreamedOutput, FetchError> {
-    let (mut reader, trailers) =
-        decoded_capturing_response_reader(response, compression, &response_headers, har_capture)?;
+    let (mut reader, trailers) = decoded_capturing_response_reader(
+        response,
+        compression,
+        &response_headers,
+        har_capture,
+        body_checks,
+    )?;
     let mut capture = copy.then(clipboard::Capture::default);
     let bytes_written = if let Some(capture) = capture.as_mut() {
         let mut reader = AsyncClipboardTeeReader { reader, capture };
@@ -331,7 +367,12 @@ fn async_response_reader(response: Response) -> (AsyncReadBox, ResponseTrailers)
                 let Some(frame) = transport::read_body_frame(&mut body, deadline.as_ref())
                     .await
                     .map_err(|err| {
-                        std::io::Error::other(transport_response_body_error_message(&err))
+                        let kind = if is_incomplete_body_error(&err) {
+                            ErrorKind::UnexpectedEof
+                        } else {
+                            ErrorKind::Other
+                        };
+                        std::io::Error::new(kind, transport_response_body_error_message(&err))
                     })?
                 else {
                     return Ok::<
@@ -360,14 +401,71 @@ fn async_response_reader(response: Response) -> (AsyncReadBox, ResponseTrailers)
     (Box::pin(StreamReader::new(stream)), trailers)
 }
 
-fn decoded_capturing_response_reader(
+pub(super) fn decoded_capturing_response_reader(
     response: Response,
     compression: CompressionMode,
     response_headers: &HeaderMap,
     capture: Option<crate::har::Capture>,
+    body_checks: BodyChecks,
 ) -> Result<(AsyncReadBox, ResponseTrailers), FetchError> {
     let (reader, trailers) = async_response_reader(response);
+    let wire_bytes = Arc::new(AtomicU64::new(0));
+    let reader: AsyncReadBox =
+        if body_checks.transfer.is_some() || body_checks.decompress_ratio.is_some() {
+            Box::pin(CountingReader {
+                reader,
+                count: wire_bytes.clone(),
+            })
+        } else {
+            reader
+        };
+    let reader: AsyncReadBox = match body_checks.length {
+        Some(check) => Box::pin(LengthCheckedReader {
+            reader,
+            check,
+            received: 0,
+            finished: false,
+        }),
+        None => reader,
+    };
     let reader = decoded_async_response_reader(reader, compression, response_headers)?;
+    let reader: AsyncReadBox = match body_checks.decompress_ratio {
+        Some(limit) => Box::pin(DecompressRatioReader {
+            reader,
+            limit,
+            wire_bytes: wire_bytes.clone(),
+            decoded_bytes: 0,
+        }),
+        None => reader,
+    };
+    let reader: AsyncReadBox = if body_checks.fail_on_empty {
+        Box::pin(EmptyCheckedReader {
+            reader,
+            received: false,
+            finished: false,
+        })
+    } else {
+        reader
+    };
+    let reader: AsyncReadBox = match body_checks.checksum {
+        Some(expected) => Box::pin(ChecksumReader {
+            reader,
+            hasher: Some(expected.hasher()),
+            expected,
+            color: body_checks.color,
+        }),
+        None => reader,
+    };
+    let reader: AsyncReadBox = match body_checks.transfer {
+        Some(stats) => Box::pin(TransferStatsReader {
+            reader,
+            stats,
+            wire_bytes,
+            decoded_bytes: 0,
+            finished: false,
+        }),
+        None => reader,
+    };
     let reader: AsyncReadBox = match capture {
         Some(capture) => Box::pin(AsyncHarTeeReader {
             reader,
@@ -379,6 +477,381 @@ fn decoded_capturing_response_reader(
     Ok((reader, trailers))
 }
 
+#[derive(Clone, Debug, Default)]
+pub(super) struct BodyChecks {
+    length: Option<BodyLengthCheck>,
+    transfer: Option<TransferStats>,
+    decompress_ratio: Option<DecompressRatioLimit>,
+    fail_on_empty: bool,
+    checksum: Option<ExpectedChecksum>,
+    color: Option<String>,
+}
+
+impl BodyChecks {
+    pub(super) fn from_response(
+        cli: &Cli,
+        response: &Response,
+        method_is_head: bool,
+        compression: CompressionMode,
+    ) -> Self {
+        Self {
+            length: BodyLengthCheck::from_response(cli, response, method_is_head),
+            transfer: TransferStats::from_response(cli, response, compression),
+            decompress_ratio: DecompressRatioLimit::from_response(cli, response, compression),
+            fail_on_empty: cli.fail_on_empty && response.status().is_success() && !method_is_head,
+            checksum: ExpectedChecksum::from_cli(cli)
+                .filter(|_| response.status().is_success() && !method_is_head),
+            color: cli.color.clone(),
+        }
+    }
+
+    /// Checks for one range of a `--chunk-size` or `--continue-at` download,
+    /// which verifies each range's length and the whole file's `--checksum`
+    /// itself.
+    pub(super) fn for_range(cli: &Cli, response: &Response) -> Self {
+        Self {
+            fail_on_empty: cli.fail_on_empty && response.status().is_success(),
+            color: cli.color.clone(),
+            ..Self::default()
+        }
+    }
+}
+
+#[derive(Clone, Debug)]
+struct BodyLengthCheck {
+    expected: u64,
+    fail: bool,
+    silent: bool,
+    color: Option<String>,
+}
+
+impl BodyLengthCheck {
+    fn from_response(cli: &Cli, response: &Response, method_is_head: bool) -> Option<Self> {
+        let status = response.status();
+        if method_is_head
+            || status.is_informational()
+            || status == StatusCode::NO_CONTENT
+            || status == StatusCode::NOT_MODIFIED
+            || response.headers().contains_key(TRANSFER_ENCODING)
+        {
+            return None;
+        }
+        let expected = response.content_length().filter(|len| *len > 0)?;
+        Some(Self {
+            expected,
+            fail: cli.fail_on_truncation,
+            silent: cli.silent,
+            color: cli.color.clone(),
+        })
+    }
+
+    fn finish(&self, received: u64) -> std::io::Result<()> {
+        if received >= self.expected {
+            return Ok(());
+        }
+        let message = format!(
+            "response body is truncated: received {received} of {} bytes",
+            self.expected
+        );
+        if self.fail {
+            return Err(std::io::Error::other(message));
+        }
+        if !self.silent {
+            write_warning_with_color(&message, self.color.as_deref());
+        }
+        Ok(())
+    }
+}
+
+/// Counts body bytes against `Content-Length`. The connection closing early
+/// surfaces as an unexpected EOF from the transport, which is reported as a
+/// truncated body: a warning that ends the body, or an error with
+/// `--fail-on-truncation`.
+struct LengthCheckedReader {
+    reader: AsyncReadBox,
+    check: BodyLengthCheck,
+    received: u64,
+    finished: bool,
+}
+
+impl AsyncRead for LengthCheckedReader {
+    fn poll_read(
+        mut self: Pin<&mut Self>,
+        cx: &mut Context<'_>,
+        buf: &mut ReadBuf<'_>,
+    ) -> Poll<std::io::Result<()>> {
+        let before = buf.filled().len();
+        let has_capacity = buf.remaining() > 0;
+        match self.reader.as_mut().poll_read(cx, buf) {
+            Poll::Ready(Ok(())) => {
+                let n = buf.filled().len() - before;
+                if n > 0 {
+                    self.received = self.received.saturating_add(n as u64);
+                } else if has_capacity && !self.finished {
+                    self.finished = true;
+                    self.check.finish(self.received)?;
+                }
+                Poll::Ready(Ok(()))
+            }
+            Poll::Ready(Err(err))
+                if err.kind() == ErrorKind::UnexpectedEof
+                    && !self.finished
+                    && self.received < self.check.expected =>
+            {
+                self.finished = true;
+                self.check.finish(self.received)?;
+                Poll::Ready(Ok(()))
+            }
+            other => other,
+        }
+    }
+}
+
+/// Fails at end of stream when a successful response decoded to no bytes, for
+/// `--fail-on-empty`.
+struct EmptyCheckedReader {
+    reader: AsyncReadBox,
+    received: bool,
+    finished: bool,
+}
+
+impl AsyncRead for EmptyCheckedReader {
+    fn poll_read(
+        mut self: Pin<&mut Self>,
+        cx: &mut Context<'_>,
+        buf: &mut ReadBuf<'_>,
+    ) -> Poll<std::io::Result<()>> {
+        let before = buf.filled().len();
+        let has_capacity = buf.remaining() > 0;
+        match self.reader.as_mut().poll_read(cx, buf) {
+            Poll::Ready(Ok(())) => {
+                if buf.filled().len() > before {
+                    self.received = true;
+                } else if has_capacity && !self.finished {
+                    self.finished = true;
+                    if !self.received {
+                        return Poll::Ready(Err(std::io::Error::other("response body is empty")));
+                    }
+                }
+                Poll::Ready(Ok(()))
+            }
+            other => other,
+        }
+    }
+}
+
+/// Hashes the decoded body and fails at end of stream when it does not match
+/// the `--checksum` digest, so `--output` never installs a mismatched file.
+/// A bare `--checksum ALGORITHM` prints the digest at end of stream instead.
+struct ChecksumReader {
+    reader: AsyncReadBox,
+    expected: ExpectedChecksum,
+    hasher: Option<BodyHasher>,
+    color: Option<String>,
+}
+
+impl AsyncRead for ChecksumReader {
+    fn poll_read(
+        mut self: Pin<&mut Self>,
+        cx: &mut Context<'_>,
+        buf: &mut ReadBuf<'_>,
+    ) -> Poll<std::io::Result<()>> {
+        let before = buf.filled().len();
+        let has_capacity = buf.remaining() > 0;
+        match self.reader.as_mut().poll_read(cx, buf) {
+            Poll::Ready(Ok(())) => {
+                let n = buf.filled().len() - before;
+                if n > 0 {
+                    if let Some(hasher) = self.hasher.as_mut() {
+                        hasher.update(&buf.filled()[before..]);
+                    }
+                } else if has_capacity && let Some(hasher) = self.hasher.take() {
+                    let digest = self
+                        .expected
+                        .verify(hasher)
+                        .map_err(std::io::Error::other)?;
+                    if let Some(digest) = digest {
+                        print_digest(self.color.as_deref(), &digest);
+                    }
+                }
+                Poll::Ready(Ok(()))
+            }
+            other => other,
+        }
+    }
+}
+
+/// Verbose report of how much a content-encoded body shrank on the wire.
+#[derive(Clone, Debug)]
+struct TransferStats {
+    encodings: String,
+    verbose: u8,
+    color: Option<String>,
+}
+
+impl TransferStats {
+    fn from_response(cli: &Cli, response: &Response, compression: CompressionMode) -> Option<Self> {
+        if cli.silent || cli.verbose == 0 || compression == CompressionMode::Off {
+            return None;
+        }
+        let decoders = content_encoding_decoders(response.headers(), compression)?;
+        if decoders.iter().all(|encoding| encoding == "aws-chunked") {
+            return None;
+        }
+        Some(Self {
+            encodings: content_encodings(response.headers()).join(", "),
+            verbose: cli.verbose,
+            color: cli.color.clone(),
+        })
+    }
+
+    fn finish(&self, wire_bytes: u64, decoded_bytes: u64) {
+        if wire_bytes == 0 {
+            return;
+        }
+        let mut printer = core::Printer::stderr(self.color.as_deref());
+        if self.verbose >= 2 {
+            printer.write_info_prefix();
+        }
+        core::write_status_line_no_flush(
+            &mut printer,
+            transfer_stats_message(&self.encodings, wire_bytes, decoded_bytes),
+        );
+        core::flush_stderr(printer);
+    }
+}
+
+fn transfer_stats_message(encodings: &str, wire_bytes: u64, decoded_bytes: u64) -> String {
+    let size = |bytes: u64| crate::output::progress::format_size(bytes as i64);
+    format!(
+        "compressed transfer ({encodings}): {} received, {} decoded ({:.1}x)",
+        size(wire_bytes),
+        size(decoded_bytes),
+        decoded_bytes as f64 / wire_bytes as f64
+    )
+}
+
+/// Default for `--max-decompress-ratio`.
+const DEFAULT_MAX_DECOMPRESS_RATIO: u32 = 100;
+
+/// Decoded bytes allowed before `--max-decompress-ratio` is enforced, so small
+/// bodies that compress very well are never rejected.
+const DECOMPRESS_RATIO_MIN_BYTES: u64 = 1 << 20;
+
+/// Limit on how far a content-encoded body may expand while it is decoded,
+/// which guards against decompression bombs from untrusted servers.
+#[derive(Clone, Copy, Debug)]
+struct DecompressRatioLimit {
+    ratio: u32,
+}
+
+impl DecompressRatioLimit {
+    fn from_response(cli: &Cli, response: &Response, compression: CompressionMode) -> Option<Self> {
+        let ratio = cli
+            .max_decompress_ratio
+            .unwrap_or(DEFAULT_MAX_DECOMPRESS_RATIO);
+        if ratio == 0 || compression == CompressionMode::Off {
+            return None;
+        }
+        let decoders = content_encoding_decoders(response.headers(), compression)?;
+        if decoders.iter().all(|encoding| encoding == "aws-chunked") {
+            return None;
+        }
+        Some(Self { ratio })
+    }
+
+    fn check(self, wire_bytes: u64, decoded_bytes: u64) -> std::io::Result<()> {
+        if decoded_bytes <= DECOMPRESS_RATIO_MIN_BYTES
+            || decoded_bytes <= wire_bytes.saturating_mul(u64::from(self.ratio))
+        {
+            return Ok(());
+        }
+        Err(std::io::Error::other(format!(
+            "response body expanded more than {}x while decoding; raise the limit with --max-decompress-ratio",
+            self.ratio
+        )))
+    }
+}
+
+struct DecompressRatioReader {
+    reader: AsyncReadBox,
+    limit: DecompressRatioLimit,
+    wire_bytes: Arc<AtomicU64>,
+    decoded_bytes: u64,
+}
+
+impl AsyncRead for DecompressRatioReader {
+    fn poll_read(
+        mut self: Pin<&mut Self>,
+        cx: &mut Context<'_>,
+        buf: &mut ReadBuf<'_>,
+    ) -> Poll<std::io::Result<()>> {
+        let before = buf.filled().len();
+        match self.reader.as_mut().poll_read(cx, buf) {
+            Poll::Ready(Ok(())) => {
+                let n = (buf.filled().len() - before) as u64;
+                self.decoded_bytes = self.decoded_bytes.saturating_add(n);
+                let wire_bytes = self.wire_bytes.load(Ordering::Relaxed);
+                self.limit.check(wire_bytes, self.decoded_bytes)?;
+                Poll::Ready(Ok(()))
+            }
+            other => other,
+        }
+    }
+}
+
+struct CountingReader {
+    reader: AsyncReadBox,
+    count: Arc<AtomicU64>,
+}
+
+impl AsyncRead for CountingReader {
+    fn poll_read(
+        mut self: Pin<&mut Self>,
+        cx: &mut Context<'_>,
+        buf: &mut ReadBuf<'_>,
+    ) -> Poll<std::io::Result<()>> {
+        let before = buf.filled().len();
+        let result = self.reader.as_mut().poll_read(cx, buf);
+        if let Poll::Ready(Ok(())) = result {
+            let n = (buf.filled().len() - before) as u64;
+            self.count.fetch_add(n, Ordering::Relaxed);
+        }
+        result
+    }
+}
+
+struct TransferStatsReader {
+    reader: AsyncReadBox,
+    stats: TransferStats,
+    wire_bytes: Arc<AtomicU64>,
+    decoded_bytes: u64,
+    finished: bool,
+}
+
+impl AsyncRead for TransferStatsReader {
+    fn poll_read(
+        mut self: Pin<&mut Self>,
+        cx: &mut Context<'_>,
+        buf: &mut ReadBuf<'_>,
+    ) -> Poll<std::io::Result<()>> {
+        let before = buf.filled().len();
+        let has_capacity = buf.remaining() > 0;
+        let result = self.reader.as_mut().poll_read(cx, buf);
+        if let Poll::Ready(Ok(())) = result {
+            let n = buf.filled().len() - before;
+            if n > 0 {
+                self.decoded_bytes = self.decoded_bytes.saturating_add(n as u64);
+            } else if has_capacity && !self.finished {
+                self.finished = true;
+                let wire_bytes = self.wire_bytes.load(Ordering::Relaxed);
+                self.stats.finish(wire_bytes, self.decoded_bytes);
+            }
+        }
+        result
+    }
+}
+
 struct AsyncHarTeeReader {
     reader: AsyncReadBox,
     capture: crate::har::Capture,
@@ -947,6 +1420,164 @@ mod tests {
         assert_eq!(writer.flushes, 1);
     }
 
+    fn length_checked_reader(body: &[u8], expected: u64, fail: bool) -> LengthCheckedReader {
+        LengthCheckedReader {
+            reader: Box::pin(std::io::Cursor::new(body.to_vec())),
+            check: BodyLengthCheck {
+                expected,
+                fail,
+                silent: true,
+                color: None,
+            },
+            received: 0,
+            finished: false,
+        }
+    }
+
+    #[tokio::test]
+    async fn length_checked_reader_compares_body_against_content_length() {
+        let mut out = Vec::new();
+        length_checked_reader(b"hello", 5, true)
+            .read_to_end(&mut out)
+            .await
+            .unwrap();
+        assert_eq!(out, b"hello");
+
+        let mut out = Vec::new();
+        length_checked_reader(b"hel", 5, false)
+            .read_to_end(&mut out)
+            .await
+            .unwrap();
+        assert_eq!(out, b"hel");
+
+        let mut out = Vec::new();
+        let err = length_checked_reader(b"hel", 5, true)
+            .read_to_end(&mut out)
+            .await
+            .unwrap_err();
+        assert_eq!(
+            err.to_string(),
+            "response body is truncated: received 3 of 5 bytes"
+        );
+        assert_eq!(out, b"hel");
+    }
+
+    #[tokio::test]
+    async fn length_checked_reader_reports_early_close_as_truncation() {
+        let closed_early = |fail: bool| {
+            let stream = futures_util::stream::iter([
+                Ok(Bytes::from_static(b"hel")),
+                Err(std::io::Error::new(
+                    ErrorKind::UnexpectedEof,
+                    "response body error: end of file before message length reached",
+                )),
+            ]);
+            let mut reader = length_checked_reader(b"", 5, fail);
+            reader.reader = Box::pin(StreamReader::new(stream));
+            reader
+        };
+
+        let mut out = Vec::new();
+        closed_early(false).read_to_end(&mut out).await.unwrap();
+        assert_eq!(out, b"hel");
+
+        let err = closed_early(true)
+            .read_to_end(&mut Vec::new())
+            .await
+            .unwrap_err();
+        assert_eq!(
+            err.to_string(),
+            "response body is truncated: received 3 of 5 bytes"
+        );
+    }
+
+    #[tokio::test]
+    async fn empty_checked_reader_fails_only_without_body_bytes() {
+        let empty_checked = |body: &[u8]| EmptyCheckedReader {
+            reader: Box::pin(std::io::Cursor::new(body.to_vec())),
+            received: false,
+            finished: false,
+        };
+
+        let mut out = Vec::new();
+        empty_checked(b"{}").read_to_end(&mut out).await.unwrap();
+        assert_eq!(out, b"{}");
+
+        let err = empty_checked(b"")
+            .read_to_end(&mut Vec::new())
+            .await
+            .unwrap_err();
+        assert_eq!(err.to_string(), "response body is empty");
+    }
+
+    #[tokio::test]
+    async fn checksum_reader_fails_when_digest_differs() {
+        let checksum_reader = |body: &[u8]| {
+            let expected =
+                ExpectedChecksum::parse("md5:900150983cd24fb0d6963f7d28e17f72", None).unwrap();
+            ChecksumReader {
+                reader: Box::pin(std::io::Cursor::new(body.to_vec())),
+                hasher: Some(expected.hasher()),
+                expected,
+                color: None,
+            }
+        };
+
+        let mut out = Vec::new();
+        checksum_reader(b"abc").read_to_end(&mut out).await.unwrap();
+        assert_eq!(out, b"abc");
+
+        let err = checksum_reader(b"abd")
+            .read_to_end(&mut Vec::new())
+            .await
+            .unwrap_err();
+        assert!(
+            err.to_string()
+                .starts_with("response body checksum mismatch: expected md5:9001"),
+            "{err}"
+        );
+    }
+
+    #[tokio::test]
+    async fn counting_reader_tracks_wire_bytes() {
+        let count = Arc::new(AtomicU64::new(0));
+        let mut reader = CountingReader {
+            reader: Box::pin(std::io::Cursor::new(vec![b'z'; 4096])),
+            count: count.clone(),
+        };
+        let mut out = Vec::new();
+        reader.read_to_end(&mut out).await.unwrap();
+
+        assert_eq!(out.len(), 4096);
+        assert_eq!(count.load(Ordering::Relaxed), 4096);
+    }
+
+    #[test]
+    fn decompress_ratio_limit_allows_small_bodies_and_rejects_bombs() {
+        let limit = DecompressRatioLimit { ratio: 100 };
+        limit.check(10, DECOMPRESS_RATIO_MIN_BYTES).unwrap();
+        limit.check(20_000, 2_000_000).unwrap();
+
+        let err = limit.check(10_000, 2_000_000).unwrap_err();
+        assert_eq!(
+            err.to_string(),
+            "response body expanded more than 100x while decoding; \
+             raise the limit with --max-decompress-ratio"
+        );
+    }
+
+    #[test]
+    fn transfer_stats_message_reports_sizes_and_ratio() {
+        assert_eq!(
+            transfer_stats_message("gzip", 2048, 10 * 1024),
+            "compressed transfer (gzip): 2.0KB received, 10.0KB decoded (5.0x)"
+        );
+        assert_eq!(
+            transfer_stats_message("gzip, br", 512, 512),
+            "compressed transfer (gzip, br): 512B received, 512B decoded (1.0x)"
+        );
+    }
+
     #[tokio::test]
     async fn async_copy_with_prefix_flushes_once_after_streaming_body() {
         let prefix = b"first chunk";
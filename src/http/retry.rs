@@ -1,7 +1,148 @@
 use super::*;
 
+use std::ops::RangeInclusive;
+
 pub(super) const MAX_PROTOCOL_NACK_RETRIES: usize = 2;
 pub(super) const MAX_RETRY_DELAY: Duration = Duration::from_secs(30);
+pub(super) const DEFAULT_RETRY_JITTER: f64 = 0.25;
+pub(super) const DEFAULT_RETRY_BACKOFF: f64 = 2.0;
+
+#[derive(Clone, Copy, Debug, PartialEq)]
+pub(super) enum RetryBackoff {
+    Exponential(f64),
+    Linear,
+}
+
+#[derive(Clone, Debug, PartialEq)]
+pub(super) struct RetryPolicy {
+    pub(super) initial_delay: Duration,
+    pub(super) max_delay: Duration,
+    pub(super) jitter: f64,
+    pub(super) backoff: RetryBackoff,
+    pub(super) max_time: Option<Duration>,
+    /// Status codes from `--retry-on-status`, retried in addition to the
+    /// defaults.
+    pub(super) extra_statuses: Vec<RangeInclusive<u16>>,
+}
+
+impl Default for RetryPolicy {
+    fn default() -> Self {
+        Self {
+            initial_delay: Duration::from_secs(1),
+            max_delay: MAX_RETRY_DELAY,
+            jitter: DEFAULT_RETRY_JITTER,
+            backoff: RetryBackoff::Exponential(DEFAULT_RETRY_BACKOFF),
+            max_time: None,
+            extra_statuses: Vec::new(),
+        }
+    }
+}
+
+impl RetryPolicy {
+    pub(super) fn from_cli(cli: &Cli) -> Result<Self, FetchError> {
+        let initial_delay =
+            duration_from_seconds("retry-delay", cli.retry_delay())?.unwrap_or(Duration::ZERO);
+        let max_delay = match cli.retry_max_delay {
+            Some(seconds) => {
+                duration_from_seconds("retry-max-delay", seconds)?.unwrap_or(Duration::ZERO)
+            }
+            None => MAX_RETRY_DELAY,
+        };
+        let jitter = cli.retry_jitter.unwrap_or(DEFAULT_RETRY_JITTER);
+        if !(0.0..=1.0).contains(&jitter) {
+            return Err(FetchError::invalid_value(
+                "--retry-jitter",
+                jitter.to_string(),
+                "must be a number between 0 and 1",
+            ));
+        }
+        let backoff = match cli.retry_backoff.as_deref() {
+            Some(value) => parse_retry_backoff(value)?,
+            None => RetryBackoff::Exponential(DEFAULT_RETRY_BACKOFF),
+        };
+        let max_time = cli
+            .retry_max_time
+            .map(|seconds| duration_from_seconds("retry-max-time", seconds))
+            .transpose()?
+            .flatten();
+        let extra_statuses = match cli.retry_on_status.as_deref() {
+            Some(value) => parse_retry_statuses(value)?,
+            None => Vec::new(),
+        };
+        Ok(Self {
+            initial_delay,
+            max_delay,
+            jitter,
+            backoff,
+            max_time,
+            extra_statuses,
+        })
+    }
+
+    pub(super) fn should_retry_status(&self, status: StatusCode) -> bool {
+        should_retry_status(status)
+            || self
+                .extra_statuses
+                .iter()
+                .any(|range| range.contains(&status.as_u16()))
+    }
+
+    pub(super) fn within_max_time(&self, started: Instant, delay: Duration) -> bool {
+        self.max_time
+            .is_none_or(|max_time| started.elapsed().saturating_add(delay) <= max_time)
+    }
+}
+
+pub(super) fn parse_retry_backoff(value: &str) -> Result<RetryBackoff, FetchError> {
+    if value.eq_ignore_ascii_case("linear") {
+        return Ok(RetryBackoff::Linear);
+    }
+    match value.parse::<f64>() {
+        Ok(multiplier) if multiplier.is_finite() && multiplier >= 1.0 => {
+            Ok(RetryBackoff::Exponential(multiplier))
+        }
+        _ => Err(FetchError::invalid_value(
+            "--retry-backoff",
+            value,
+            "must be 'linear' or a multiplier of at least 1",
+        )),
+    }
+}
+
+/// Parse a `--retry-on-status` list of status codes and ranges, such as
+/// `500,520-527`.
+pub(super) fn parse_retry_statuses(value: &str) -> Result<Vec<RangeInclusive<u16>>, FetchError> {
+    let invalid = || {
+        FetchError::invalid_value(
+            "--retry-on-status",
+            value,
+            "must be comma-separated status codes or ranges like 500-504",
+        )
+    };
+    let parse_code = |code: &str| {
+        code.trim()
+            .parse::<u16>()
+            .ok()
+            .filter(|code| (100..=599).contains(code))
+            .ok_or_else(invalid)
+    };
+    value
+        .split(',')
+        .map(|entry| {
+            let range = match entry.split_once('-') {
+                Some((start, end)) => parse_code(start)?..=parse_code(end)?,
+                None => {
+                    let code = parse_code(entry)?;
+                    code..=code
+                }
+            };
+            if range.is_empty() {
+                return Err(invalid());
+            }
+            Ok(range)
+        })
+        .collect()
+}
 
 pub(super) fn redirect_requires_client_refresh(
     cli: &Cli,
@@ -22,7 +163,7 @@ pub(super) fn redirect_requires_client_refresh(
     {
         return false;
     }
-    cli.dns_server.is_some()
+    !cli.dns_servers.is_empty()
         || matches!(http_version, Some(HttpVersion::Http3))
         || cli.timing
         || (cli.verbose >= 3 && !cli.silent)
@@ -64,11 +205,94 @@ pub(super) fn parse_retry_after(headers: &HeaderMap) -> Duration {
         .unwrap_or(Duration::ZERO)
 }
 
-pub(super) fn is_retryable_error(err: &transport::Error) -> bool {
+const RATE_LIMIT_REMAINING_HEADERS: &[&str] = &[
+    "ratelimit-remaining",
+    "x-ratelimit-remaining",
+    "x-rate-limit-remaining",
+];
+const RATE_LIMIT_RESET_HEADERS: &[&str] =
+    &["ratelimit-reset", "x-ratelimit-reset", "x-rate-limit-reset"];
+// Reset values at or above this are Unix timestamps rather than delta seconds.
+const RATE_LIMIT_RESET_EPOCH_THRESHOLD: f64 = 1_000_000_000.0;
+
+/// Return how long to wait for the rate limit window to reset when the
+/// response reports no remaining requests.
+pub(super) fn parse_rate_limit_reset(headers: &HeaderMap) -> Option<Duration> {
+    let header = |names: &[&str]| {
+        names
+            .iter()
+            .find_map(|name| headers.get(*name))
+            .and_then(|value| value.to_str().ok())
+            .map(str::trim)
+    };
+    let remaining: u64 = header(RATE_LIMIT_REMAINING_HEADERS)?.parse().ok()?;
+    if remaining > 0 {
+        return None;
+    }
+    let reset: f64 = header(RATE_LIMIT_RESET_HEADERS)?.parse().ok()?;
+    if !reset.is_finite() || reset < 0.0 {
+        return None;
+    }
+    if reset < RATE_LIMIT_RESET_EPOCH_THRESHOLD {
+        return Some(Duration::from_secs_f64(reset));
+    }
+    let reset = std::time::UNIX_EPOCH + Duration::from_secs_f64(reset);
+    Some(
+        reset
+            .duration_since(SystemTime::now())
+            .unwrap_or(Duration::ZERO),
+    )
+}
+
+pub(super) fn is_retryable_error(err: &transport::Error, retry_connrefused: bool) -> bool {
     if is_certificate_validation_error(err) {
         return false;
     }
-    err.is_timeout() || err.is_connect()
+    err.is_timeout() || err.is_connect() || (retry_connrefused && is_connection_refused_error(err))
+}
+
+/// Report whether a refused connection caused `err`. Connect errors match on
+/// their message as well; other errors only match a refused `io::Error`
+/// source, such as a refused connection surfaced while sending the request.
+pub(super) fn is_connection_refused_error(err: &transport::Error) -> bool {
+    if err.is_connect() && is_connection_refused_message(&err.to_string()) {
+        return true;
+    }
+
+    let mut source = err.source();
+    while let Some(source_err) = source {
+        if source_err
+            .downcast_ref::<std::io::Error>()
+            .is_some_and(|io_err| io_err.kind() == ErrorKind::ConnectionRefused)
+            || (err.is_connect() && is_connection_refused_message(&source_err.to_string()))
+        {
+            return true;
+        }
+        source = source_err.source();
+    }
+
+    false
+}
+
+/// Report whether the connection closed before the response body reached its
+/// declared `Content-Length`.
+pub(super) fn is_incomplete_body_error(err: &transport::Error) -> bool {
+    let mut source = err.source();
+    while let Some(source_err) = source {
+        if source_err
+            .downcast_ref::<std::io::Error>()
+            .is_some_and(|io_err| io_err.kind() == ErrorKind::UnexpectedEof)
+        {
+            return true;
+        }
+        source = source_err.source();
+    }
+    false
+}
+
+fn is_connection_refused_message(message: &str) -> bool {
+    let lower = message.to_ascii_lowercase();
+    lower.contains("connection refused") || lower.contains("actively refused")
 }
 
 pub(super) fn is_protocol_nack_error(err: &transport::Error) -> bool {
@@ -141,6 +365,11 @@ pub(super) fn redirect_target(
             url.scheme()
         )));
     }
+    if cli.require_https && url.scheme() != "https" {
+        return Err(FetchError::Runtime(format!(
+            "'--require-https' refuses to follow a redirect to {url}"
+        )));
+    }
     Ok(Some(url))
 }
 
@@ -274,15 +503,67 @@ pub(super) fn print_redirect_status(cli: &Cli, response: &Response) {
     core::flush_stderr(printer);
 }
 
+/// Report the `--accept-fallback` value that the server finally accepted.
+pub(super) fn print_accept_fallback(cli: &Cli, accept: &HeaderValue) {
+    if cli.verbose < 1 || cli.silent {
+        return;
+    }
+    let mut printer = core::Printer::stderr(cli.color.as_deref());
+    printer.write_info_prefix();
+    printer.write_styled("Accept", &[core::Sequence::Bold, core::Sequence::Yellow]);
+    printer.push_str(": ");
+    printer.push_str(&format!(
+        "{} (after 406 Not Acceptable)\n",
+        String::from_utf8_lossy(accept.as_bytes())
+    ));
+    core::flush_stderr(printer);
+}
+
 pub(super) fn timeout_error_message(cli: &Cli, err: &transport::Error) -> Option<String> {
     if !err.is_timeout() {
         return None;
     }
+    // A connect timeout keeps its own message, which names the phase that
+    // ran out rather than the overall request timeout.
+    let message = err.to_string();
+    if is_connect_timeout_message(&message) {
+        return Some(message);
+    }
     let seconds = cli.timeout?;
     let duration = duration_from_seconds("timeout", seconds).ok().flatten()?;
     Some(request_timeout_message(duration))
 }
 
+/// Describe a response whose header block was larger than the configured
+/// limit. HTTP/1.1 reports this as a head that does not fit the read buffer;
+/// HTTP/2 refuses the stream locally.
+pub(super) fn response_headers_too_large_message(
+    cli: &Cli,
+    err: &transport::Error,
+) -> Option<String> {
+    let mut source = err.source();
+    while let Some(err) = source {
+        if err
+            .downcast_ref::<hyper::Error>()
+            .is_some_and(hyper::Error::is_parse_too_large)
+            || err
+                .downcast_ref::<h2::Error>()
+                .is_some_and(is_h2_header_list_too_large)
+        {
+            return Some(format!(
+                "response headers exceed {} bytes; raise the limit with --max-headers",
+                client::max_response_header_bytes(cli)
+            ));
+        }
+        source = err.source();
+    }
+    None
+}
+
+fn is_h2_header_list_too_large(err: &h2::Error) -> bool {
+    err.is_reset() && err.is_library() && err.reason() == Some(h2::Reason::REFUSED_STREAM)
+}
+
 pub(super) fn transport_request_error_message(err: &transport::Error) -> String {
     let mut message = err.to_string();
     let mut source = err.source();
@@ -403,31 +684,36 @@ pub(super) fn retry_reason(status: StatusCode) -> String {
 }
 
 pub(super) fn compute_delay(
-    initial_delay: Duration,
+    policy: &RetryPolicy,
     attempt: usize,
     retry_after: Duration,
 ) -> Duration {
-    let mut delay = if initial_delay.is_zero() {
-        Duration::from_secs(1)
+    let initial = if policy.initial_delay.is_zero() {
+        1.0
     } else {
-        initial_delay
+        policy.initial_delay.as_secs_f64()
     };
-
-    for _ in 0..attempt {
-        delay = delay.saturating_mul(2);
-        if delay > MAX_RETRY_DELAY {
-            delay = MAX_RETRY_DELAY;
+    let max = policy.max_delay.as_secs_f64();
+
+    let mut delay = initial;
+    for step in 0..attempt {
+        delay = match policy.backoff {
+            RetryBackoff::Exponential(multiplier) => delay * multiplier,
+            RetryBackoff::Linear => initial * (step + 2) as f64,
+        };
+        if delay >= max {
+            delay = max;
             break;
         }
     }
 
-    let jitter = delay.as_secs_f64() * 0.25;
-    let jittered = delay.as_secs_f64() + rand::random_range(-jitter..=jitter);
-    let delay = Duration::from_secs_f64(jittered.max(0.0));
+    if policy.jitter > 0.0 {
+        let jitter = delay * policy.jitter;
+        delay += rand::random_range(-jitter..=jitter);
+    }
+    let delay = Duration::from_secs_f64(delay.clamp(0.0, max));
 
-    delay
-        .min(MAX_RETRY_DELAY)
-        .max(retry_after.min(MAX_RETRY_DELAY))
+    delay.max(retry_after.min(policy.max_delay))
 }
 
 pub(super) fn retry_delay_within_timeout(
@@ -521,6 +807,13 @@ pub(crate) fn total_attempts_for_retry(retry_count: usize) -> Result<usize, Fetc
 mod tests {
     use super::*;
 
+    fn retry_policy(initial_delay: Duration) -> RetryPolicy {
+        RetryPolicy {
+            initial_delay,
+            ..RetryPolicy::default()
+        }
+    }
+
     fn test_body() -> RequestBody {
         Some(RequestBodyPayload::from_bytes(
             b"payload".to_vec(),
@@ -625,7 +918,11 @@ mod tests {
     #[test]
     fn compute_delay_matches_go_backoff_bounds() {
         for attempt in 0..5 {
-            let delay = compute_delay(Duration::from_secs(1), attempt, Duration::ZERO);
+            let delay = compute_delay(
+                &retry_policy(Duration::from_secs(1)),
+                attempt,
+                Duration::ZERO,
+            );
             let base = Duration::from_secs(1_u64 << attempt).min(Duration::from_secs(30));
             let min = base.mul_f64(0.75);
             let max = base.mul_f64(1.25);
@@ -635,25 +932,130 @@ mod tests {
             );
         }
 
-        let delay = compute_delay(Duration::from_secs(1), 10, Duration::ZERO);
+        let delay = compute_delay(&retry_policy(Duration::from_secs(1)), 10, Duration::ZERO);
         assert!(delay <= MAX_RETRY_DELAY);
 
         let retry_after = Duration::from_secs(60);
-        let delay = compute_delay(Duration::from_secs(1), 0, retry_after);
+        let delay = compute_delay(&retry_policy(Duration::from_secs(1)), 0, retry_after);
         assert_eq!(delay, MAX_RETRY_DELAY);
 
-        let delay = compute_delay(Duration::from_secs(10), 1, Duration::from_secs(5));
+        let delay = compute_delay(
+            &retry_policy(Duration::from_secs(10)),
+            1,
+            Duration::from_secs(5),
+        );
         assert!(delay >= Duration::from_secs(15));
         assert!(delay <= Duration::from_secs(25));
 
-        let delay = compute_delay(Duration::from_secs(1), 0, Duration::from_secs(20));
+        let delay = compute_delay(
+            &retry_policy(Duration::from_secs(1)),
+            0,
+            Duration::from_secs(20),
+        );
         assert_eq!(delay, Duration::from_secs(20));
 
-        let delay = compute_delay(Duration::ZERO, 0, Duration::ZERO);
+        let delay = compute_delay(&retry_policy(Duration::ZERO), 0, Duration::ZERO);
         assert!(delay >= Duration::from_millis(750));
         assert!(delay <= Duration::from_millis(1250));
     }
 
+    #[test]
+    fn compute_delay_uses_custom_backoff_multiplier() {
+        let policy = RetryPolicy {
+            initial_delay: Duration::from_secs(1),
+            max_delay: Duration::from_secs(100),
+            jitter: 0.0,
+            backoff: RetryBackoff::Exponential(3.0),
+            max_time: None,
+            extra_statuses: Vec::new(),
+        };
+        assert_eq!(
+            compute_delay(&policy, 0, Duration::ZERO),
+            Duration::from_secs(1)
+        );
+        assert_eq!(
+            compute_delay(&policy, 2, Duration::ZERO),
+            Duration::from_secs(9)
+        );
+        assert_eq!(
+            compute_delay(&policy, 5, Duration::ZERO),
+            Duration::from_secs(100)
+        );
+
+        let policy = RetryPolicy {
+            backoff: RetryBackoff::Linear,
+            ..policy
+        };
+        assert_eq!(
+            compute_delay(&policy, 0, Duration::ZERO),
+            Duration::from_secs(1)
+        );
+        assert_eq!(
+            compute_delay(&policy, 3, Duration::ZERO),
+            Duration::from_secs(4)
+        );
+    }
+
+    #[test]
+    fn compute_delay_without_jitter_is_deterministic() {
+        let policy = RetryPolicy {
+            initial_delay: Duration::from_millis(500),
+            max_delay: Duration::from_secs(3),
+            jitter: 0.0,
+            backoff: RetryBackoff::Exponential(DEFAULT_RETRY_BACKOFF),
+            max_time: None,
+            extra_statuses: Vec::new(),
+        };
+        for _ in 0..10 {
+            assert_eq!(
+                compute_delay(&policy, 1, Duration::ZERO),
+                Duration::from_secs(1)
+            );
+        }
+        assert_eq!(
+            compute_delay(&policy, 10, Duration::ZERO),
+            Duration::from_secs(3)
+        );
+        assert_eq!(
+            compute_delay(&policy, 0, Duration::from_secs(60)),
+            Duration::from_secs(3)
+        );
+    }
+
+    #[test]
+    fn retry_max_time_bounds_elapsed_time_and_next_delay() {
+        let started = Instant::now();
+        assert!(RetryPolicy::default().within_max_time(started, Duration::from_secs(3600)));
+
+        let policy = RetryPolicy {
+            max_time: Some(Duration::from_secs(10)),
+            ..RetryPolicy::default()
+        };
+        assert!(policy.within_max_time(started, Duration::from_secs(1)));
+        assert!(!policy.within_max_time(started, Duration::from_secs(11)));
+
+        let started = Instant::now() - Duration::from_secs(9);
+        assert!(!policy.within_max_time(started, Duration::from_secs(2)));
+    }
+
+    #[test]
+    fn parse_retry_backoff_accepts_linear_and_multipliers() {
+        assert_eq!(parse_retry_backoff("linear").unwrap(), RetryBackoff::Linear);
+        assert_eq!(
+            parse_retry_backoff("1.5").unwrap(),
+            RetryBackoff::Exponential(1.5)
+        );
+        for value in ["0.5", "-2", "inf", "NaN", "fast"] {
+            let err = parse_retry_backoff(value).unwrap_err();
+            assert_eq!(
+                err.to_string(),
+                format!(
+                    "invalid value '{value}' for option '--retry-backoff': must be 'linear' or a multiplier of at least 1"
+                )
+            );
+        }
+    }
+
     #[test]
     fn format_delay_matches_go_retry_output() {
         assert_eq!(format_delay(Duration::from_micros(500)), "0s");
@@ -713,6 +1115,43 @@ mod tests {
         );
     }
 
+    #[test]
+    fn parse_rate_limit_reset_waits_only_when_exhausted() {
+        let mut headers = HeaderMap::new();
+        headers.insert("x-ratelimit-remaining", HeaderValue::from_static("0"));
+        headers.insert("x-ratelimit-reset", HeaderValue::from_static("12"));
+        assert_eq!(
+            parse_rate_limit_reset(&headers),
+            Some(Duration::from_secs(12))
+        );
+
+        headers.insert("x-ratelimit-remaining", HeaderValue::from_static("3"));
+        assert_eq!(parse_rate_limit_reset(&headers), None);
+
+        let mut headers = HeaderMap::new();
+        headers.insert("ratelimit-remaining", HeaderValue::from_static("0"));
+        assert_eq!(parse_rate_limit_reset(&headers), None);
+        headers.insert("ratelimit-reset", HeaderValue::from_static("soon"));
+        assert_eq!(parse_rate_limit_reset(&headers), None);
+
+        let reset = SystemTime::now()
+            .duration_since(std::time::UNIX_EPOCH)
+            .unwrap()
+            .as_secs()
+            + 30;
+        let mut headers = HeaderMap::new();
+        headers.insert("x-rate-limit-remaining", HeaderValue::from_static("0"));
+        headers.insert(
+            "x-rate-limit-reset",
+            HeaderValue::from_str(&reset.to_string()).unwrap(),
+        );
+        let delay = parse_rate_limit_reset(&headers).unwrap();
+        assert!(delay > Duration::from_secs(28) && delay <= Duration::from_secs(30));
+
+        headers.insert("x-rate-limit-reset", HeaderValue::from_static("1000000000"));
+        assert_eq!(parse_rate_limit_reset(&headers), Some(Duration::ZERO));
+    }
+
     #[test]
     fn parse_retry_after_matches_go_integer_and_date_cases() {
         let mut headers = HeaderMap::new();
@@ -734,7 +1173,11 @@ mod tests {
             Duration::from_secs(2_147_483_647)
         );
         assert_eq!(
-            compute_delay(Duration::from_secs(1), 0, parse_retry_after(&headers)),
+            compute_delay(
+                &retry_policy(Duration::from_secs(1)),
+                0,
+                parse_retry_after(&headers)
+            ),
             MAX_RETRY_DELAY
         );
 
@@ -744,7 +1187,11 @@ mod tests {
         );
         assert!(parse_retry_after(&headers) > MAX_RETRY_DELAY);
         assert_eq!(
-            compute_delay(Duration::from_secs(1), 0, parse_retry_after(&headers)),
+            compute_delay(
+                &retry_policy(Duration::from_secs(1)),
+                0,
+                parse_retry_after(&headers)
+            ),
             MAX_RETRY_DELAY
         );
 
@@ -781,6 +1228,85 @@ mod tests {
         }
     }
 
+    #[test]
+    fn retry_policy_adds_extra_statuses_to_defaults() {
+        let policy = RetryPolicy {
+            extra_statuses: parse_retry_statuses("500, 520-522").unwrap(),
+            ..RetryPolicy::default()
+        };
+        for code in [429, 500, 502, 503, 504, 520, 521, 522] {
+            let status = StatusCode::from_u16(code).unwrap();
+            assert!(
+                policy.should_retry_status(status),
+                "{status} should be retryable"
+            );
+        }
+        for code in [200, 404, 501, 519, 523] {
+            let status = StatusCode::from_u16(code).unwrap();
+            assert!(
+                !policy.should_retry_status(status),
+                "{status} should not be retryable"
+            );
+        }
+        assert!(!RetryPolicy::default().should_retry_status(StatusCode::INTERNAL_SERVER_ERROR));
+    }
+
+    #[test]
+    fn parse_retry_statuses_rejects_invalid_entries() {
+        assert_eq!(parse_retry_statuses("500").unwrap(), vec![500..=500]);
+        assert_eq!(
+            parse_retry_statuses("408,500-504").unwrap(),
+            vec![408..=408, 500..=504]
+        );
+        for value in [
+            "", "500,", "abc", "99", "600", "504-500", "500-", "-500", "5xx",
+        ] {
+            let err = parse_retry_statuses(value).unwrap_err();
+            assert!(
+                err.to_string().contains("--retry-on-status"),
+                "{value}: {err}"
+            );
+        }
+    }
+
+    #[test]
+    fn connection_refused_is_retryable_by_default_and_with_flag() {
+        let refused =
+            transport::Error::connect("tcp connect error: Connection refused (os error 111)");
+        assert!(is_connection_refused_error(&refused));
+        assert!(is_retryable_error(&refused, false));
+        assert!(is_retryable_error(&refused, true));
+
+        let refused_source = transport::Error::with_source(
+            transport::ErrorKind::Connect,
+            "dial failed",
+            std::io::Error::from(ErrorKind::ConnectionRefused),
+        );
+        assert!(is_connection_refused_error(&refused_source));
+        assert!(is_retryable_error(&refused_source, false));
+        assert!(is_retryable_error(&refused_source, true));
+
+        let reset = transport::Error::connect("tcp connect error: Connection reset by peer");
+        assert!(!is_connection_refused_error(&reset));
+        assert!(is_retryable_error(&reset, false));
+
+        let timeout = transport::Error::timeout("request timed out after 1s");
+        assert!(is_retryable_error(&timeout, false));
+
+        let refused_request = transport::Error::with_source(
+            transport::ErrorKind::Request,
+            "send request",
+            std::io::Error::from(ErrorKind::ConnectionRefused),
+        );
+        assert!(is_connection_refused_error(&refused_request));
+        assert!(!is_retryable_error(&refused_request, false));
+        assert!(is_retryable_error(&refused_request, true));
+
+        let request = transport::Error::request("connection refused by policy");
+        assert!(!is_connection_refused_error(&request));
+        assert!(!is_retryable_error(&request, true));
+    }
+
     #[test]
     fn transport_tls_source_messages_keep_go_style_tls_hint() {
         assert_eq!(
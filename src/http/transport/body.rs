@@ -19,6 +19,7 @@ use url::Url;
 use super::{Error, ErrorKind};
 #[cfg(test)]
 use crate::duration::request_timeout_message;
+use crate::tls::inspect::TlsSession;
 
 #[derive(Clone, Debug)]
 pub(crate) struct BodyDeadline {
@@ -158,6 +159,7 @@ pub(crate) struct Response {
     body: Body,
     body_deadline: Option<BodyDeadline>,
     remote_addr: Option<SocketAddr>,
+    tls_session: Option<TlsSession>,
 }
 
 impl Response {
@@ -181,6 +183,7 @@ impl Response {
             .get::<PeerAddr>()
             .map(|addr| addr.0)
             .or(fallback_remote_addr);
+        let tls_session = parts.extensions.get::<TlsSession>().cloned();
         Self {
             url,
             status: parts.status,
@@ -189,6 +192,7 @@ impl Response {
             body: Body::map_incoming(body),
             body_deadline,
             remote_addr,
+            tls_session,
         }
     }
 
@@ -219,9 +223,19 @@ impl Response {
             }),
             body_deadline,
             remote_addr: Some(remote_addr),
+            tls_session: None,
         }
     }
 
+    /// Attach the TLS session of a connection made outside the pool, whose
+    /// responses do not carry it as an extension.
+    pub(super) fn with_tls_session(mut self, tls_session: Option<TlsSession>) -> Self {
+        if tls_session.is_some() {
+            self.tls_session = tls_session;
+        }
+        self
+    }
+
     pub(crate) fn status(&self) -> http::StatusCode {
         self.status
     }
@@ -249,6 +263,10 @@ impl Response {
         self.remote_addr
     }
 
+    pub(crate) fn tls_session(&self) -> Option<&TlsSession> {
+        self.tls_session.as_ref()
+    }
+
     pub(in crate::http) fn keep_client_alive(&mut self, client: super::client::Client) {
         self.body.keep_client_alive(client);
     }
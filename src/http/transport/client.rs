@@ -30,10 +30,13 @@ use super::h3::{AutoHttp3Config, H3PooledClient};
 use super::proxy::{Proxy, dial_stream_for_config, proxy_for_config};
 use super::{Error, ErrorKind};
 use crate::cli::HttpVersion;
-use crate::duration::{TimeoutBudget, request_timeout_message};
+use crate::duration::{
+    TimeoutBudget, connect_timeout_message, is_timeout_message, request_timeout_message,
+};
 use crate::error::FetchError;
 use crate::http::http3_cache::Http3Cache;
 use crate::timing::{DnsTiming, TransportTiming};
+use crate::tls::inspect::TlsSession;
 
 #[derive(Clone)]
 pub struct Client {
@@ -54,6 +57,8 @@ pub(super) struct ClientConfig {
     pub(super) mode: Option<HttpVersion>,
     pub(super) unix_socket: Option<String>,
     pub(super) dns_overrides: HashMap<String, Vec<SocketAddr>>,
+    pub(super) resolve_overrides: Vec<crate::net::ResolveOverride>,
+    pub(super) connect_to: Vec<crate::net::ConnectTo>,
     pub(super) proxies: Vec<Proxy>,
     pub(super) tls_config: Option<rustls::ClientConfig>,
     pub(super) doh_tls_config: Option<rustls::ClientConfig>,
@@ -66,6 +71,12 @@ pub(super) struct ClientConfig {
     pub(super) dns_resolution: Option<crate::http::client::DnsResolutionHandle>,
     pub(super) dns_server: Option<String>,
     pub(super) local_address: Option<IpAddr>,
+    pub(super) tcp_options: crate::net::TcpOptions,
+    pub(super) reuse_connections: bool,
+    pub(super) http2_max_frame_size: Option<u32>,
+    pub(super) http2_initial_window_size: Option<u32>,
+    pub(super) http2_max_concurrent_streams: Option<u32>,
+    pub(super) max_response_header_bytes: Option<u32>,
     pub(super) auto_http3: Option<AutoHttp3Config>,
     pub(super) auto_http3_discovery: bool,
     pub(super) http3_cache: Option<Arc<Http3Cache>>,
@@ -74,6 +85,37 @@ pub(super) struct ClientConfig {
     pub(super) har: Option<crate::har::Recorder>,
 }
 
+impl ClientConfig {
+    /// Addresses to dial for `host` and `port` without a DNS lookup, either
+    /// pinned with `--resolve` or resolved before the client was built.
+    pub(super) fn override_addrs(&self, host: &str, port: u16) -> Option<Vec<SocketAddr>> {
+        if let Some(addrs) = crate::net::resolve_override_addrs(&self.resolve_overrides, host, port)
+        {
+            return Some(addrs);
+        }
+        let mut addrs = self.dns_overrides.get(host)?.clone();
+        for addr in &mut addrs {
+            addr.set_port(port);
+        }
+        Some(addrs)
+    }
+
+    /// The URL to dial for `url`, with its host and port rerouted by
+    /// `--connect-to`. The request, TLS server name, and certificate checks
+    /// still use `url`.
+    pub(super) fn dial_url(&self, url: &Url) -> Url {
+        let mut dial_url = url.clone();
+        if let Some((host, port)) = url.host_str().zip(url.port_or_known_default())
+            && let Some((connect_host, connect_port)) =
+                crate::net::connect_to_target(&self.connect_to, host, port)
+        {
+            let _ = dial_url.set_host(Some(&connect_host));
+            let _ = dial_url.set_port(Some(connect_port));
+        }
+        dial_url
+    }
+}
+
 pub(crate) struct ClientBuilder {
     pub(super) config: ClientConfig,
 }
@@ -91,6 +133,8 @@ impl Client {
                 mode: None,
                 unix_socket: None,
                 dns_overrides: HashMap::new(),
+                resolve_overrides: Vec::new(),
+                connect_to: Vec::new(),
                 proxies: Vec::new(),
                 tls_config: None,
                 doh_tls_config: None,
@@ -103,6 +147,12 @@ impl Client {
                 dns_resolution: None,
                 dns_server: None,
                 local_address: None,
+                tcp_options: crate::net::TcpOptions::default(),
+                reuse_connections: true,
+                http2_max_frame_size: None,
+                http2_initial_window_size: None,
+                http2_max_concurrent_streams: None,
+                max_response_header_bytes: None,
                 auto_http3: None,
                 auto_http3_discovery: false,
                 http3_cache: None,
@@ -287,6 +337,16 @@ impl Client {
         version: Option<Version>,
         body_deadline: Option<BodyDeadline>,
     ) -> Result<Response, Error> {
+        if self.config.http2_max_concurrent_streams.is_some()
+            && !matches!(self.config.mode, Some(HttpVersion::Http1))
+        {
+            // hyper-util's pool cannot advertise SETTINGS_MAX_CONCURRENT_STREAMS,
+            // so open a connection and run the handshake here instead.
+            let connection = self.connect_unpooled(&url).await?;
+            return self
+                .send_tcp_one_shot(method, url, headers, body, body_deadline, connection)
+                .await;
+        }
         let body = body.unwrap_or_else(|| Body::from(Bytes::new()));
         let request_version = version.unwrap_or(Version::HTTP_11);
         let request = build_request(method, absolute_uri(&url)?, request_version, headers, body)
@@ -314,22 +374,33 @@ impl Client {
         } else {
             Version::HTTP_11
         };
-        let uri = if connection.negotiated_h2 {
+        let uri = if connection.negotiated_h2 || connection.stream.proxied {
             absolute_uri(&url)?
         } else {
             origin_form_uri(&url)?
         };
         let remote_addr = connection.remote_addr;
+        let tls_session = connection.stream.tls_session.clone();
         let request = build_request(method, uri, version, headers, body).map_err(Error::request)?;
         let io = TokioIo::new(connection.stream);
         let response = if connection.negotiated_h2 {
-            let (mut sender, conn) = hyper::client::conn::http2::Builder::new(TokioExecutor::new())
-                .timer(TokioTimer::new())
-                .handshake(io)
-                .await
-                .map_err(|err| {
-                    Error::with_source(ErrorKind::Connect, format!("http2 handshake: {err}"), err)
-                })?;
+            let mut builder = hyper::client::conn::http2::Builder::new(TokioExecutor::new());
+            builder.timer(TokioTimer::new());
+            if let Some(size) = self.config.http2_max_frame_size {
+                builder.max_frame_size(size);
+            }
+            if let Some(size) = self.config.http2_initial_window_size {
+                builder.initial_stream_window_size(size);
+            }
+            if let Some(max) = self.config.http2_max_concurrent_streams {
+                builder.max_concurrent_streams(max);
+            }
+            if let Some(size) = self.config.max_response_header_bytes {
+                builder.max_header_list_size(size);
+            }
+            let (mut sender, conn) = builder.handshake(io).await.map_err(|err| {
+                Error::with_source(ErrorKind::Connect, format!("http2 handshake: {err}"), err)
+            })?;
             tokio::spawn(async move {
                 let _ = conn.await;
             });
@@ -338,12 +409,13 @@ impl Client {
                 .await
                 .map_err(|err| Error::with_source(ErrorKind::Request, err.to_string(), err))?
         } else {
-            let (mut sender, conn) = hyper::client::conn::http1::Builder::new()
-                .handshake(io)
-                .await
-                .map_err(|err| {
-                    Error::with_source(ErrorKind::Connect, format!("http1 handshake: {err}"), err)
-                })?;
+            let mut builder = hyper::client::conn::http1::Builder::new();
+            if let Some(size) = self.config.max_response_header_bytes {
+                builder.max_buf_size(size as usize);
+            }
+            let (mut sender, conn) = builder.handshake(io).await.map_err(|err| {
+                Error::with_source(ErrorKind::Connect, format!("http1 handshake: {err}"), err)
+            })?;
             tokio::spawn(async move {
                 let _ = conn.await;
             });
@@ -352,12 +424,25 @@ impl Client {
                 .await
                 .map_err(|err| Error::with_source(ErrorKind::Request, err.to_string(), err))?
         };
-        Ok(Response::from_hyper_with_remote(
-            url,
-            response,
-            body_deadline,
-            remote_addr,
-        ))
+        Ok(
+            Response::from_hyper_with_remote(url, response, body_deadline, remote_addr)
+                .with_tls_session(tls_session),
+        )
+    }
+
+    async fn connect_unpooled(&self, url: &Url) -> Result<AutoTcpConnection, Error> {
+        let mut stream = connect_pooled(self.config.clone(), absolute_uri(url)?)
+            .await?
+            .into_inner();
+        if matches!(self.config.mode, Some(HttpVersion::Http2)) {
+            stream.negotiated_h2 = true;
+        }
+        Ok(AutoTcpConnection {
+            negotiated_h2: stream.negotiated_h2,
+            remote_addr: stream.remote_addr,
+            stream,
+            timing: TransportTiming::default(),
+        })
     }
 
     pub(super) async fn connect_auto_tcp_tls(
@@ -365,14 +450,15 @@ impl Client {
         url: &Url,
         timeout: TimeoutBudget,
     ) -> Result<AutoTcpConnection, Error> {
-        let trace = connect_direct_tcp_config(&self.config, url, timeout)
+        let dial_url = self.config.dial_url(url);
+        let trace = connect_direct_tcp_config(&self.config, &dial_url, timeout)
             .await
             .map_err(|err| Error::from_fetch(ErrorKind::Connect, err))?;
-        record_dns_trace(&self.config, url, &trace);
+        record_dns_trace(&self.config, &dial_url, &trace);
         let remote_addr = trace.stream.peer_addr().ok();
         let tcp = trace.tcp_duration;
         let tls_start = std::time::Instant::now();
-        let (stream, negotiated_h2) = tls_stream_for_config(
+        let (stream, negotiated_h2, tls_session) = tls_stream_for_config(
             &self.config,
             url,
             Box::pin(trace.stream) as crate::net::DialStream,
@@ -386,6 +472,7 @@ impl Client {
                 negotiated_h2,
                 proxied: false,
                 remote_addr,
+                tls_session: Some(tls_session),
             },
             negotiated_h2,
             remote_addr,
@@ -406,9 +493,24 @@ impl ClientBuilder {
         };
         let mut builder = HyperClient::builder(TokioExecutor::new());
         builder.pool_timer(TokioTimer::new());
+        if !config.reuse_connections {
+            // Like Go without keep-alive, open a new connection for every
+            // request rather than pooling idle ones.
+            builder.pool_max_idle_per_host(0);
+        }
         if matches!(config.mode, Some(HttpVersion::Http2)) {
             builder.http2_only(true);
         }
+        if let Some(size) = config.http2_max_frame_size {
+            builder.http2_max_frame_size(size);
+        }
+        if let Some(size) = config.http2_initial_window_size {
+            builder.http2_initial_stream_window_size(size);
+        }
+        if let Some(size) = config.max_response_header_bytes {
+            builder.http1_max_buf_size(size as usize);
+            builder.http2_max_header_list_size(size);
+        }
         Ok(Client {
             config,
             pooled: builder.build(connector),
@@ -442,6 +544,28 @@ impl ClientBuilder {
         self
     }
 
+    pub(crate) fn http2_max_frame_size(mut self, size: u32) -> Self {
+        self.config.http2_max_frame_size = Some(size);
+        self
+    }
+
+    pub(crate) fn http2_initial_window_size(mut self, size: u32) -> Self {
+        self.config.http2_initial_window_size = Some(size);
+        self
+    }
+
+    pub(crate) fn http2_max_concurrent_streams(mut self, max: u32) -> Self {
+        self.config.http2_max_concurrent_streams = Some(max);
+        self
+    }
+
+    /// Limit the size of a response header block. HTTP/1.1 enforces this
+    /// through the connection read buffer, which must hold the whole head.
+    pub(crate) fn max_response_header_bytes(mut self, size: u32) -> Self {
+        self.config.max_response_header_bytes = Some(size);
+        self
+    }
+
     pub(crate) fn http3_prior_knowledge(mut self) -> Self {
         self.config.mode = Some(HttpVersion::Http3);
         self
@@ -458,6 +582,27 @@ impl ClientBuilder {
         self
     }
 
+    pub(crate) fn local_port(mut self, range: crate::net::LocalPortRange) -> Self {
+        self.config.tcp_options.port = Some(range);
+        self
+    }
+
+    pub(crate) fn interface(mut self, addrs: crate::net::InterfaceAddrs) -> Self {
+        self.config.tcp_options.interface = Some(addrs);
+        self
+    }
+
+    pub(crate) fn tcp_keepalive(mut self, keepalive: crate::net::TcpKeepalive) -> Self {
+        self.config.tcp_options.keepalive = keepalive;
+        self
+    }
+
+    /// Open a new connection for every request, for `--no-keepalive`.
+    pub(crate) fn no_connection_reuse(mut self) -> Self {
+        self.config.reuse_connections = false;
+        self
+    }
+
     pub(crate) fn auto_http3(mut self, config: AutoHttp3Config) -> Self {
         self.config.auto_http3 = Some(config);
         self
@@ -494,6 +639,16 @@ impl ClientBuilder {
         self
     }
 
+    pub(crate) fn resolve_override(mut self, entry: crate::net::ResolveOverride) -> Self {
+        self.config.resolve_overrides.push(entry);
+        self
+    }
+
+    pub(crate) fn connect_to(mut self, entry: crate::net::ConnectTo) -> Self {
+        self.config.connect_to.push(entry);
+        self
+    }
+
     pub(crate) fn tls_config(mut self, config: rustls::ClientConfig) -> Self {
         self.config.tls_config = Some(config);
         self
@@ -505,7 +660,7 @@ impl ClientBuilder {
     }
 
     pub(crate) fn connect_timeout(self, timeout: Duration) -> Self {
-        self.connect_timeout_with_message(timeout, request_timeout_message(timeout))
+        self.connect_timeout_with_message(timeout, connect_timeout_message(timeout))
     }
 
     pub(crate) fn connect_timeout_with_message(
@@ -594,22 +749,24 @@ async fn connect_pooled(
     let timeout = TimeoutBudget::new(config.connect_timeout);
     let tcp_start = std::time::Instant::now();
     let (mut stream, proxied, uses_tcp, remote_addr, tcp_duration) =
-        dial_stream_for_config(&config, &url, proxy.as_ref(), timeout).await?;
+        dial_stream_for_config(&config, &config.dial_url(&url), proxy.as_ref(), timeout).await?;
     let mut timing = TransportTiming {
         tcp: uses_tcp.then_some(tcp_duration.unwrap_or_else(|| tcp_start.elapsed())),
         tls: None,
         quic: None,
     };
     let mut negotiated_h2 = false;
+    let mut tls_session = None;
 
     if url.scheme() == "https" {
         let tls_start = std::time::Instant::now();
-        let (tls, h2) =
+        let (tls, h2, session) =
             tls_stream_for_config(&config, &url, stream, &alpn_for_config(&config), timeout)
                 .await?;
         timing.tls = Some(tls_start.elapsed());
         stream = tls;
         negotiated_h2 = h2;
+        tls_session = Some(session);
     }
 
     if let Some(connection_timing) = &config.connection_timing {
@@ -621,6 +778,7 @@ async fn connect_pooled(
         negotiated_h2,
         proxied,
         remote_addr,
+        tls_session,
     }))
 }
 
@@ -635,14 +793,14 @@ pub(super) async fn connect_direct_tcp_config(
     let port = url
         .port_or_known_default()
         .ok_or_else(|| FetchError::Message("URL port is required".to_string()))?;
-    if let Some(addrs) = config.dns_overrides.get(host) {
-        let mut addrs = addrs.clone();
-        for addr in &mut addrs {
-            addr.set_port(port);
-        }
+    if let Some(addrs) = config.override_addrs(host, port) {
         let tcp_start = std::time::Instant::now();
         let stream = timeout
-            .run(crate::net::connect_first(addrs.clone(), timeout))
+            .run(crate::net::connect_first(
+                addrs.clone(),
+                config.tcp_options,
+                timeout,
+            ))
             .await?;
         return Ok(crate::net::TcpConnectTrace {
             stream,
@@ -655,6 +813,7 @@ pub(super) async fn connect_direct_tcp_config(
         url,
         config.dns_server.as_deref(),
         config.doh_tls_config.clone(),
+        config.tcp_options,
         timeout,
     )
     .await
@@ -718,7 +877,7 @@ pub(super) async fn tls_stream_for_config(
     stream: crate::net::DialStream,
     alpn: &[Vec<u8>],
     timeout: TimeoutBudget,
-) -> Result<(crate::net::DialStream, bool), Error> {
+) -> Result<(crate::net::DialStream, bool, TlsSession), Error> {
     let host = url
         .host_str()
         .ok_or_else(|| Error::request("URL host is required"))?;
@@ -736,7 +895,7 @@ pub(super) async fn tls_stream_for_config(
         })
         .await
         .map_err(|err| Error::from_fetch(ErrorKind::Connect, err))?;
-    let negotiated_h2 = {
+    let (negotiated_h2, tls_session) = {
         let (_, conn) = stream.get_ref();
         if ech_hard_fail {
             let ech_status = conn.ech_status();
@@ -746,9 +905,12 @@ pub(super) async fn tls_stream_for_config(
                 )));
             }
         }
-        matches!(conn.alpn_protocol(), Some(b"h2"))
+        (
+            matches!(conn.alpn_protocol(), Some(b"h2")),
+            TlsSession::from_tls_connection(conn),
+        )
     };
-    Ok((Box::pin(stream), negotiated_h2))
+    Ok((Box::pin(stream), negotiated_h2, tls_session))
 }
 
 fn map_pooled_client_error(err: hyper_util::client::legacy::Error) -> Error {
@@ -756,7 +918,7 @@ fn map_pooled_client_error(err: hyper_util::client::legacy::Error) -> Error {
         .source()
         .map(ToString::to_string)
         .unwrap_or_else(|| err.to_string());
-    let kind = if message.starts_with("request timed out after ") {
+    let kind = if is_timeout_message(&message) {
         ErrorKind::Timeout
     } else if err.is_connect() {
         ErrorKind::Connect
@@ -771,6 +933,7 @@ struct PooledStream {
     negotiated_h2: bool,
     proxied: bool,
     remote_addr: Option<SocketAddr>,
+    tls_session: Option<TlsSession>,
 }
 
 impl Connection for PooledStream {
@@ -779,6 +942,9 @@ impl Connection for PooledStream {
         if let Some(remote_addr) = self.remote_addr {
             connected = connected.extra(PeerAddr(remote_addr));
         }
+        if let Some(tls_session) = &self.tls_session {
+            connected = connected.extra(tls_session.clone());
+        }
         if self.negotiated_h2 {
             connected.negotiated_h2()
         } else {
@@ -22,6 +22,7 @@ use crate::duration::TimeoutBudget;
 use crate::error::FetchError;
 use crate::http::http3_cache::Http3CacheCandidate;
 use crate::timing::TransportTiming;
+use crate::tls::inspect::TlsSession;
 
 type H3SendRequest = h3::client::SendRequest<h3_quinn::OpenStreams, Bytes>;
 
@@ -35,6 +36,7 @@ pub(super) struct H3PooledClient {
     pub(super) origin: String,
     sender: H3SendRequest,
     remote_addr: SocketAddr,
+    tls_session: TlsSession,
 }
 
 struct Http3ConnectResult {
@@ -727,7 +729,8 @@ impl Client {
             upload_task,
             body_deadline,
             pooled.remote_addr,
-        ))
+        )
+        .with_tls_session(Some(pooled.tls_session)))
     }
 
     async fn http3_client(&self, url: &Url) -> Result<H3PooledClient, Error> {
@@ -845,18 +848,15 @@ impl Client {
         url: &Url,
         origin: String,
     ) -> Result<Http3ConnectResult, Error> {
-        let host = url
+        let dial_url = self.config.dial_url(url);
+        let host = dial_url
             .host_str()
             .ok_or_else(|| Error::request("URL host is required"))?;
-        let port = url
+        let port = dial_url
             .port_or_known_default()
             .ok_or_else(|| Error::request("URL port is required"))?;
         let timeout = TimeoutBudget::new(self.config.connect_timeout);
-        let (addrs, dns_duration) = if let Some(addrs) = self.config.dns_overrides.get(host) {
-            let mut addrs = addrs.clone();
-            for addr in &mut addrs {
-                addr.set_port(port);
-            }
+        let (addrs, dns_duration) = if let Some(addrs) = self.config.override_addrs(host, port) {
             (addrs, None)
         } else {
             let dns_start = std::time::Instant::now();
@@ -877,7 +877,7 @@ impl Client {
             (addrs, Some(dns_start.elapsed()))
         };
         if let Some(duration) = dns_duration {
-            record_dns_addrs_trace(&self.config, url, &addrs, duration);
+            record_dns_addrs_trace(&self.config, &dial_url, &addrs, duration);
         }
         self.connect_http3_client_with_addrs(url, origin, addrs, timeout)
             .await
@@ -912,13 +912,19 @@ impl Client {
             .await
             .map_err(|err| Error::from_fetch(ErrorKind::Connect, err))?;
         let remote_addr = connection.remote_address();
+        let tls_session = TlsSession::from_quic_connection(&connection);
         let timing = TransportTiming {
             tcp: None,
             tls: None,
             quic: Some(start.elapsed()),
         };
         let h3_connection = h3_quinn::Connection::new(connection);
-        let (mut driver, sender) = h3::client::new(h3_connection)
+        let mut builder = h3::client::builder();
+        if let Some(size) = self.config.max_response_header_bytes {
+            builder.max_field_section_size(size.into());
+        }
+        let (mut driver, sender) = builder
+            .build(h3_connection)
             .await
             .map_err(|err| Error::connect(format!("http3 handshake: {err}")))?;
         tokio::spawn(async move {
@@ -929,6 +935,7 @@ impl Client {
                 origin,
                 sender,
                 remote_addr,
+                tls_session,
             },
             timing,
         })
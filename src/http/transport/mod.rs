@@ -1,6 +1,7 @@
 use std::error::Error as StdError;
 use std::fmt;
 
+use crate::duration::is_timeout_message;
 use crate::error::FetchError;
 
 mod body;
@@ -75,7 +76,7 @@ impl Error {
 
     pub(super) fn from_fetch(kind: ErrorKind, err: FetchError) -> Self {
         let message = err.to_string();
-        let kind = if message.starts_with("request timed out after ") {
+        let kind = if is_timeout_message(&message) {
             ErrorKind::Timeout
         } else {
             kind
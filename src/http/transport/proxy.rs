@@ -129,10 +129,7 @@ pub(super) fn proxy_for_config(config: &ClientConfig, url: &Url) -> Option<Proxy
 fn target_override_addrs(config: &ClientConfig, url: &Url) -> Option<Vec<SocketAddr>> {
     let host = url.host_str()?;
     let port = url.port_or_known_default()?;
-    let mut addrs = config.dns_overrides.get(host)?.clone();
-    for addr in &mut addrs {
-        addr.set_port(port);
-    }
+    let addrs = config.override_addrs(host, port)?;
     (!addrs.is_empty()).then_some(addrs)
 }
 
@@ -221,9 +218,11 @@ impl Proxy {
     }
 
     fn applies_to(&self, url: &Url) -> bool {
-        if self.no_proxy.as_ref().is_some_and(|no_proxy| {
-            crate::http::client::no_proxy_matches_url(url, no_proxy.0.as_deref())
-        }) {
+        if self
+            .no_proxy
+            .as_ref()
+            .is_some_and(|no_proxy| no_proxy.matches(url))
+        {
             return false;
         }
         match &self.kind {
@@ -292,4 +291,9 @@ impl NoProxy {
                 .ok(),
         )
     }
+
+    /// Report whether `url` should bypass the proxy.
+    pub(crate) fn matches(&self, url: &Url) -> bool {
+        crate::http::client::no_proxy_matches_url(url, self.0.as_deref())
+    }
 }
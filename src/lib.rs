@@ -17,6 +17,7 @@ pub(crate) mod inspection;
 pub(crate) mod net;
 pub mod output;
 pub mod proto;
+pub(crate) mod schema;
 pub mod session;
 pub mod skill;
 pub mod timing;
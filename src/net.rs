@@ -1,8 +1,8 @@
 use std::collections::VecDeque;
 use std::future::Future;
-use std::net::{IpAddr, SocketAddr};
+use std::net::{IpAddr, Ipv4Addr, Ipv6Addr, SocketAddr};
 #[cfg(unix)]
-use std::net::{Ipv4Addr, Ipv6Addr, SocketAddrV4, SocketAddrV6};
+use std::net::{SocketAddrV4, SocketAddrV6};
 use std::pin::Pin;
 use std::sync::Arc;
 use std::task::{Context, Poll};
@@ -21,7 +21,7 @@ use tokio::task::JoinHandle;
 use url::{Host, Url};
 
 use crate::core;
-use crate::duration::TimeoutBudget;
+use crate::duration::{TimeoutBudget, duration_from_seconds};
 use crate::error::FetchError;
 
 pub(crate) trait AsyncIo: AsyncRead + AsyncWrite + Send + Unpin {}
@@ -44,6 +44,389 @@ pub(crate) enum AddressFamily {
     Ipv6,
 }
 
+/// An inclusive range of local ports to bind outgoing TCP connections to.
+#[derive(Clone, Copy, Debug, Eq, PartialEq)]
+pub(crate) struct LocalPortRange {
+    pub(crate) low: u16,
+    pub(crate) high: u16,
+}
+
+impl LocalPortRange {
+    /// Parse a `--local-port` value: a single port or a `LOW-HIGH` range.
+    pub(crate) fn parse(value: &str) -> Result<Self, FetchError> {
+        let invalid = || {
+            FetchError::invalid_value(
+                "--local-port",
+                value,
+                "must be PORT or LOW-HIGH, with ports from 1 to 65535",
+            )
+        };
+        let (low, high) = value.split_once('-').unwrap_or((value, value));
+        let low = low.trim().parse::<u16>().map_err(|_| invalid())?;
+        let high = high.trim().parse::<u16>().map_err(|_| invalid())?;
+        if low == 0 || low > high {
+            return Err(invalid());
+        }
+        Ok(Self { low, high })
+    }
+}
+
+impl std::fmt::Display for LocalPortRange {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        if self.low == self.high {
+            write!(f, "{}", self.low)
+        } else {
+            write!(f, "{}-{}", self.low, self.high)
+        }
+    }
+}
+
+/// Socket options for outgoing TCP connections: where they are bound, from
+/// `--interface` and `--local-port`, and their keep-alive. The default binds
+/// neither and uses the built-in keep-alive.
+#[derive(Clone, Copy, Debug, Default, Eq, PartialEq)]
+pub(crate) struct TcpOptions {
+    pub(crate) interface: Option<InterfaceAddrs>,
+    pub(crate) port: Option<LocalPortRange>,
+    pub(crate) keepalive: TcpKeepalive,
+}
+
+/// TCP keep-alive probing, set by `--keepalive` and `--no-keepalive`.
+#[derive(Clone, Copy, Debug, Default, Eq, PartialEq)]
+pub(crate) enum TcpKeepalive {
+    /// Probe idle connections on the built-in schedule.
+    #[default]
+    Default,
+    /// Probe after this much idle time, and at this interval after that.
+    Idle(Duration),
+    /// Send no probes.
+    Disabled,
+}
+
+impl TcpKeepalive {
+    /// Resolve the keep-alive flags. `--keepalive 0` disables keep-alive,
+    /// and times below a second are raised to one, the smallest value
+    /// systems accept.
+    pub(crate) fn from_flags(seconds: Option<f64>, disabled: bool) -> Result<Self, FetchError> {
+        if disabled {
+            return Ok(Self::Disabled);
+        }
+        let Some(seconds) = seconds else {
+            return Ok(Self::Default);
+        };
+        Ok(match duration_from_seconds("keepalive", seconds)? {
+            Some(idle) => Self::Idle(idle.max(Duration::from_secs(1))),
+            None => Self::Disabled,
+        })
+    }
+
+    pub(crate) fn is_disabled(self) -> bool {
+        self == Self::Disabled
+    }
+
+    /// The idle time before the first probe and the interval between
+    /// probes, or `None` when keep-alive is disabled.
+    fn schedule(self) -> Option<(Duration, Duration)> {
+        match self {
+            Self::Default => Some((TCP_KEEPALIVE_IDLE, TCP_KEEPALIVE_INTERVAL)),
+            Self::Idle(idle) => Some((idle, idle)),
+            Self::Disabled => None,
+        }
+    }
+}
+
+/// The local addresses an `--interface` value binds to, at most one for each
+/// address family.
+#[derive(Clone, Copy, Debug, Default, Eq, PartialEq)]
+pub(crate) struct InterfaceAddrs {
+    pub(crate) ipv4: Option<Ipv4Addr>,
+    pub(crate) ipv6: Option<Ipv6Addr>,
+}
+
+impl InterfaceAddrs {
+    /// Resolve an `--interface` value: a local IP address, or the name of a
+    /// network interface, which uses its first address of each family.
+    pub(crate) fn resolve(value: &str) -> Result<Self, FetchError> {
+        match value.parse::<IpAddr>() {
+            Ok(IpAddr::V4(ip)) => {
+                return Ok(Self {
+                    ipv4: Some(ip),
+                    ipv6: None,
+                });
+            }
+            Ok(IpAddr::V6(ip)) => {
+                return Ok(Self {
+                    ipv4: None,
+                    ipv6: Some(ip),
+                });
+            }
+            Err(_) => {}
+        }
+        let Some(addrs) = interface_ip_addrs(value)? else {
+            return Err(FetchError::Message(format!(
+                "network interface '{value}' does not exist"
+            )));
+        };
+        let resolved = Self::from_addrs(addrs);
+        if resolved.ipv4.is_none() && resolved.ipv6.is_none() {
+            return Err(FetchError::Message(format!(
+                "network interface '{value}' has no usable IP address"
+            )));
+        }
+        Ok(resolved)
+    }
+
+    /// Keep the first address of each family. IPv6 link-local addresses are
+    /// skipped, since they cannot reach other networks.
+    fn from_addrs(addrs: impl IntoIterator<Item = IpAddr>) -> Self {
+        let mut resolved = Self::default();
+        for addr in addrs {
+            match addr {
+                IpAddr::V4(ip) => {
+                    resolved.ipv4.get_or_insert(ip);
+                }
+                IpAddr::V6(ip) if !ip.is_unicast_link_local() => {
+                    resolved.ipv6.get_or_insert(ip);
+                }
+                IpAddr::V6(_) => {}
+            }
+        }
+        resolved
+    }
+
+    /// The address to bind for a connection to `remote`, which must be of a
+    /// family the interface has.
+    pub(crate) fn for_remote(self, remote: IpAddr) -> Result<IpAddr, FetchError> {
+        let local = match remote {
+            IpAddr::V4(_) => self.ipv4.map(IpAddr::V4),
+            IpAddr::V6(_) => self.ipv6.map(IpAddr::V6),
+        };
+        local.ok_or_else(|| {
+            let family = if remote.is_ipv4() { "IPv4" } else { "IPv6" };
+            FetchError::Runtime(format!(
+                "--interface has no {family} address to connect to {remote}"
+            ))
+        })
+    }
+}
+
+/// The addresses of the network interface `name`, or `None` when no
+/// interface has that name.
+#[cfg(unix)]
+fn interface_ip_addrs(name: &str) -> Result<Option<Vec<IpAddr>>, FetchError> {
+    let mut list = ptr::null_mut();
+    if unsafe { libc::getifaddrs(&mut list) } != 0 {
+        return Err(FetchError::Runtime(format!(
+            "list network interfaces: {}",
+            std::io::Error::last_os_error()
+        )));
+    }
+
+    let mut found = false;
+    let mut addrs = Vec::new();
+    let mut current = list;
+    while !current.is_null() {
+        let entry = unsafe { &*current };
+        current = entry.ifa_next;
+        if entry.ifa_name.is_null()
+            || unsafe { CStr::from_ptr(entry.ifa_name) }.to_bytes() != name.as_bytes()
+        {
+            continue;
+        }
+        found = true;
+        if entry.ifa_addr.is_null() {
+            continue;
+        }
+        let family = i32::from(unsafe { (*entry.ifa_addr).sa_family });
+        if family == libc::AF_INET {
+            let sockaddr = unsafe { &*(entry.ifa_addr as *const libc::sockaddr_in) };
+            addrs.push(socket_addr_from_sockaddr_in(sockaddr).ip());
+        } else if family == libc::AF_INET6 {
+            let sockaddr = unsafe { &*(entry.ifa_addr as *const libc::sockaddr_in6) };
+            addrs.push(socket_addr_from_sockaddr_in6(sockaddr).ip());
+        }
+    }
+    unsafe { libc::freeifaddrs(list) };
+    Ok(found.then_some(addrs))
+}
+
+#[cfg(not(unix))]
+fn interface_ip_addrs(name: &str) -> Result<Option<Vec<IpAddr>>, FetchError> {
+    Err(FetchError::Message(format!(
+        "cannot look up network interface '{name}' on this platform; use an IP address"
+    )))
+}
+
+/// A `--resolve` entry that pins a host and port to fixed addresses.
+#[derive(Clone, Debug, Eq, PartialEq)]
+pub(crate) struct ResolveOverride {
+    pub(crate) host: String,
+    pub(crate) port: u16,
+    pub(crate) addrs: Vec<IpAddr>,
+}
+
+impl ResolveOverride {
+    /// Parse a `--resolve` value in the form `HOST:PORT:ADDR[,ADDR...]`. IPv6
+    /// hosts and addresses may be wrapped in brackets.
+    pub(crate) fn parse(value: &str) -> Result<Self, FetchError> {
+        let invalid = || {
+            FetchError::invalid_value(
+                "--resolve",
+                value,
+                "must be HOST:PORT:ADDR, with one or more comma-separated IP addresses",
+            )
+        };
+        let (host, rest) = if let Some(bracketed) = value.strip_prefix('[') {
+            let (host, rest) = bracketed.split_once(']').ok_or_else(invalid)?;
+            (
+                format!("[{host}]"),
+                rest.strip_prefix(':').ok_or_else(invalid)?,
+            )
+        } else {
+            let (host, rest) = value.split_once(':').ok_or_else(invalid)?;
+            (host.to_string(), rest)
+        };
+        let (port, addrs) = rest.split_once(':').ok_or_else(invalid)?;
+        let port = port.parse::<u16>().map_err(|_| invalid())?;
+        let addrs = addrs
+            .split(',')
+            .map(|addr| {
+                let addr = addr.trim();
+                let addr = addr
+                    .strip_prefix('[')
+                    .and_then(|addr| addr.strip_suffix(']'))
+                    .unwrap_or(addr);
+                addr.parse::<IpAddr>().map_err(|_| invalid())
+            })
+            .collect::<Result<Vec<_>, _>>()?;
+        if host.is_empty() || port == 0 {
+            return Err(invalid());
+        }
+        Ok(Self {
+            host: host.to_ascii_lowercase(),
+            port,
+            addrs,
+        })
+    }
+}
+
+/// Return the addresses pinned for `host` and `port`, if any. The first
+/// matching entry wins.
+pub(crate) fn resolve_override_addrs(
+    overrides: &[ResolveOverride],
+    host: &str,
+    port: u16,
+) -> Option<Vec<SocketAddr>> {
+    let entry = overrides
+        .iter()
+        .find(|entry| entry.port == port && entry.host.eq_ignore_ascii_case(host))?;
+    Some(
+        entry
+            .addrs
+            .iter()
+            .map(|addr| SocketAddr::new(*addr, port))
+            .collect(),
+    )
+}
+
+/// A `--connect-to` entry that sends connections for a host and port to
+/// another host and port. A `None` host or port matches any, and a `None`
+/// connect host or port keeps the original.
+#[derive(Clone, Debug, Default, Eq, PartialEq)]
+pub(crate) struct ConnectTo {
+    pub(crate) host: Option<String>,
+    pub(crate) port: Option<u16>,
+    pub(crate) connect_host: Option<String>,
+    pub(crate) connect_port: Option<u16>,
+}
+
+impl ConnectTo {
+    /// Parse a `--connect-to` value in the form
+    /// `HOST:PORT:CONNECT_HOST:CONNECT_PORT`, where any field may be empty.
+    /// IPv6 hosts must be wrapped in brackets.
+    pub(crate) fn parse(value: &str) -> Result<Self, FetchError> {
+        let invalid = || {
+            FetchError::invalid_value(
+                "--connect-to",
+                value,
+                "must be HOST:PORT:HOST2:PORT2, with IPv6 hosts in brackets",
+            )
+        };
+        let [host, port, connect_host, connect_port] =
+            split_connect_to_fields(value).ok_or_else(invalid)?;
+        let parse_host = |field: &str| -> Result<Option<String>, FetchError> {
+            if field.is_empty() {
+                return Ok(None);
+            }
+            Host::parse(field).map_err(|_| invalid())?;
+            Ok(Some(field.to_ascii_lowercase()))
+        };
+        let parse_port = |field: &str| -> Result<Option<u16>, FetchError> {
+            if field.is_empty() {
+                return Ok(None);
+            }
+            match field.parse::<u16>() {
+                Ok(port) if port > 0 => Ok(Some(port)),
+                _ => Err(invalid()),
+            }
+        };
+        Ok(Self {
+            host: parse_host(host)?,
+            port: parse_port(port)?,
+            connect_host: parse_host(connect_host)?,
+            connect_port: parse_port(connect_port)?,
+        })
+    }
+
+    fn matches(&self, host: &str, port: u16) -> bool {
+        self.host
+            .as_deref()
+            .is_none_or(|want| want.eq_ignore_ascii_case(host))
+            && self.port.is_none_or(|want| want == port)
+    }
+}
+
+/// Split a `--connect-to` value into its four colon-separated fields. Colons
+/// inside brackets belong to an IPv6 address.
+fn split_connect_to_fields(value: &str) -> Option<[&str; 4]> {
+    let mut fields = Vec::with_capacity(4);
+    let mut start = 0;
+    let mut in_brackets = false;
+    for (index, ch) in value.char_indices() {
+        match ch {
+            '[' if !in_brackets && index == start => in_brackets = true,
+            ']' if in_brackets => in_brackets = false,
+            ':' if !in_brackets => {
+                fields.push(&value[start..index]);
+                start = index + 1;
+            }
+            _ => {}
+        }
+    }
+    if in_brackets {
+        return None;
+    }
+    fields.push(&value[start..]);
+    fields.try_into().ok()
+}
+
+/// Return the host and port to connect to for `host` and `port` under
+/// `--connect-to`, if an entry matches. The first matching entry wins.
+pub(crate) fn connect_to_target(
+    entries: &[ConnectTo],
+    host: &str,
+    port: u16,
+) -> Option<(String, u16)> {
+    let entry = entries.iter().find(|entry| entry.matches(host, port))?;
+    Some((
+        entry
+            .connect_host
+            .clone()
+            .unwrap_or_else(|| host.to_string()),
+        entry.connect_port.unwrap_or(port),
+    ))
+}
+
 pub(crate) struct TcpConnectTrace {
     pub(crate) stream: TcpStream,
     pub(crate) resolved_addrs: Vec<SocketAddr>,
@@ -76,15 +459,22 @@ pub(crate) async fn connect_tcp_with_doh_tls(
     doh_tls_config: Option<rustls::ClientConfig>,
     timeout: TimeoutBudget,
 ) -> Result<TcpStream, FetchError> {
-    connect_tcp_traced_with_doh_tls(url, dns_server, doh_tls_config, timeout)
-        .await
-        .map(|trace| trace.stream)
+    connect_tcp_traced_with_doh_tls(
+        url,
+        dns_server,
+        doh_tls_config,
+        TcpOptions::default(),
+        timeout,
+    )
+    .await
+    .map(|trace| trace.stream)
 }
 
 pub(crate) async fn connect_tcp_traced_with_doh_tls(
     url: &Url,
     dns_server: Option<&str>,
     doh_tls_config: Option<rustls::ClientConfig>,
+    tcp_options: TcpOptions,
     timeout: TimeoutBudget,
 ) -> Result<TcpConnectTrace, FetchError> {
     let host = url
@@ -98,7 +488,7 @@ pub(crate) async fn connect_tcp_traced_with_doh_tls(
     if let Ok(ip) = host.parse::<IpAddr>() {
         return timeout_fetch(
             timeout,
-            connect_addr_timed(SocketAddr::new(ip, port), timeout),
+            connect_addr_timed(SocketAddr::new(ip, port), tcp_options, timeout),
         )
         .await
         .map(|outcome| TcpConnectTrace {
@@ -111,7 +501,14 @@ pub(crate) async fn connect_tcp_traced_with_doh_tls(
 
     timeout_fetch(
         timeout,
-        connect_host_happy_eyeballs_traced(host, port, dns_server, doh_tls_config, timeout),
+        connect_host_happy_eyeballs_traced(
+            host,
+            port,
+            dns_server,
+            doh_tls_config,
+            tcp_options,
+            timeout,
+        ),
     )
     .await
 }
@@ -132,18 +529,30 @@ pub(crate) async fn resolve_host_with_doh_tls(
             .map_err(|err| FetchError::Runtime(format!("lookup {host}: {err}")));
     };
 
-    let addrs = if is_doh_dns_server(dns_server) {
-        let shared_doh = shared_doh_resolver(dns_server, host, timeout, doh_tls_config.as_ref())?;
-        resolve_doh_ips(host, dns_server, Some(&shared_doh), timeout).await?
-    } else {
-        crate::dns::custom::lookup_ips(dns_server, host, timeout.remaining()?).await?
-    };
+    let addrs = crate::dns::custom::with_failover(dns_server, |server| {
+        resolve_server_ips(host, server, doh_tls_config.as_ref(), timeout)
+    })
+    .await?;
     Ok(addrs
         .into_iter()
         .map(|addr| SocketAddr::new(addr, 0))
         .collect())
 }
 
+async fn resolve_server_ips(
+    host: &str,
+    dns_server: &str,
+    doh_tls_config: Option<&rustls::ClientConfig>,
+    timeout: TimeoutBudget,
+) -> Result<Vec<IpAddr>, FetchError> {
+    if crate::dns::custom::is_doh_dns_server(dns_server) {
+        let shared_doh = shared_doh_resolver(dns_server, host, timeout, doh_tls_config)?;
+        resolve_doh_ips(host, dns_server, Some(&shared_doh), timeout).await
+    } else {
+        crate::dns::custom::lookup_ips(dns_server, host, timeout.remaining()?).await
+    }
+}
+
 async fn resolve_host_family(
     host: &str,
     port: u16,
@@ -163,8 +572,10 @@ async fn resolve_host_family(
 
     match dns_server {
         Some(dns_server) => {
-            let addrs =
-                resolve_custom_host_family(host, dns_server, shared_doh, family, timeout).await?;
+            let addrs = crate::dns::custom::with_failover(dns_server, |server| {
+                resolve_custom_host_family(host, server, shared_doh, family, timeout)
+            })
+            .await?;
             Ok(socket_addrs_with_port(addrs, port))
         }
         None => resolve_system_host_family(host, port, family).await,
@@ -259,10 +670,6 @@ fn shared_doh_resolver(
     Ok(SharedDohResolver { server_url, client })
 }
 
-fn is_doh_dns_server(dns_server: &str) -> bool {
-    dns_server.starts_with("http://") || dns_server.starts_with("https://")
-}
-
 fn parse_doh_dns_server(dns_server: &str) -> Result<Url, FetchError> {
     Url::parse(dns_server)
         .map_err(|err| FetchError::Message(format!("invalid dns-server '{dns_server}': {err}")))
@@ -472,9 +879,10 @@ fn dedupe_socket_addrs(addrs: &mut Vec<SocketAddr>) {
 
 pub(crate) async fn connect_first(
     addrs: Vec<SocketAddr>,
+    tcp_options: TcpOptions,
     timeout: TimeoutBudget,
 ) -> Result<TcpStream, FetchError> {
-    connect_staggered(interleave_socket_addrs(addrs)?, timeout).await
+    connect_staggered(interleave_socket_addrs(addrs)?, tcp_options, timeout).await
 }
 
 #[cfg(test)]
@@ -532,9 +940,15 @@ pub(crate) async fn connect_host_happy_eyeballs_traced(
     port: u16,
     dns_server: Option<&str>,
     doh_tls_config: Option<rustls::ClientConfig>,
+    tcp_options: TcpOptions,
     timeout: TimeoutBudget,
 ) -> Result<TcpConnectTrace, FetchError> {
-    let shared_doh = match dns_server.filter(|s| is_doh_dns_server(s)) {
+    // One DoH client is shared by the A and AAAA lookups. Several resolvers
+    // each get their own client as they are tried.
+    let shared_doh = match dns_server
+        .and_then(crate::dns::custom::single_dns_server)
+        .filter(|server| crate::dns::custom::is_doh_dns_server(server))
+    {
         Some(server) => Some(shared_doh_resolver(
             server,
             host,
@@ -594,7 +1008,7 @@ pub(crate) async fn connect_host_happy_eyeballs_traced(
             && !held_ipv4_until_resolution_delay
             && !connection_delay_running
         {
-            start_next_tcp_connect(timeout, &mut pending, &mut active);
+            start_next_tcp_connect(tcp_options, timeout, &mut pending, &mut active);
             connection_delay
                 .as_mut()
                 .reset(tokio::time::Instant::now() + HAPPY_EYEBALLS_FALLBACK_DELAY);
@@ -696,7 +1110,7 @@ pub(crate) async fn connect_host_happy_eyeballs_traced(
                     Some(Err(err)) => {
                         last_err = Some(err);
                         if !pending.is_empty() {
-                            start_next_tcp_connect(timeout, &mut pending, &mut active);
+                            start_next_tcp_connect(tcp_options, timeout, &mut pending, &mut active);
                             connection_delay.as_mut().reset(tokio::time::Instant::now() + HAPPY_EYEBALLS_FALLBACK_DELAY);
                             connection_delay_running = true;
                         } else if active.is_empty() {
@@ -709,7 +1123,7 @@ pub(crate) async fn connect_host_happy_eyeballs_traced(
                 }
             }
             _ = &mut connection_delay, if connection_delay_running && !pending.is_empty() => {
-                start_next_tcp_connect(timeout, &mut pending, &mut active);
+                start_next_tcp_connect(tcp_options, timeout, &mut pending, &mut active);
                 if pending.is_empty() {
                     connection_delay_running = false;
                 } else {
@@ -767,6 +1181,7 @@ pub(crate) fn interleave_socket_addr_families(
 
 async fn connect_staggered(
     addrs: Vec<SocketAddr>,
+    tcp_options: TcpOptions,
     timeout: TimeoutBudget,
 ) -> Result<TcpStream, FetchError> {
     race_staggered(
@@ -774,7 +1189,7 @@ async fn connect_staggered(
         HAPPY_EYEBALLS_FALLBACK_DELAY,
         "lookup returned no addresses",
         "connect",
-        move |addr| connect_addr_timed(addr, timeout),
+        move |addr| connect_addr_timed(addr, tcp_options, timeout),
     )
     .await
     .map(|outcome| outcome.stream)
@@ -848,13 +1263,14 @@ fn start_next_staggered<I, T, Start, Fut>(
 }
 
 fn start_next_tcp_connect(
+    tcp_options: TcpOptions,
     timeout: TimeoutBudget,
     pending: &mut VecDeque<SocketAddr>,
     active: &mut FuturesUnordered<AbortOnDropJoin<TimedTcpStream>>,
 ) {
     if let Some(addr) = pending.pop_front() {
         active.push(AbortOnDropJoin::new(
-            connect_addr_timed(addr, timeout),
+            connect_addr_timed(addr, tcp_options, timeout),
             "connect",
         ));
     }
@@ -862,10 +1278,11 @@ fn start_next_tcp_connect(
 
 async fn connect_addr_timed(
     addr: SocketAddr,
+    tcp_options: TcpOptions,
     timeout: TimeoutBudget,
 ) -> Result<TimedTcpStream, FetchError> {
     let start = Instant::now();
-    let stream = timeout.run(connect_addr(addr)).await?;
+    let stream = timeout.run(connect_addr(addr, tcp_options)).await?;
     Ok(TimedTcpStream {
         stream,
         duration: start.elapsed(),
@@ -929,27 +1346,69 @@ fn per_address_timeout(addrs_len: usize, timeout: TimeoutBudget) -> Option<Durat
         .and_then(|timeout| timeout.checked_div(addrs_len))
 }
 
-async fn connect_addr(addr: SocketAddr) -> Result<TcpStream, FetchError> {
+async fn connect_addr(addr: SocketAddr, tcp_options: TcpOptions) -> Result<TcpStream, FetchError> {
     let socket = if addr.is_ipv4() {
         TcpSocket::new_v4()
     } else {
         TcpSocket::new_v6()
     }?;
     socket.set_nodelay(true)?;
-    let _ = socket.set_keepalive(true);
+    let _ = socket.set_keepalive(!tcp_options.keepalive.is_disabled());
+    let local_ip = tcp_options
+        .interface
+        .map(|interface| interface.for_remote(addr.ip()))
+        .transpose()?;
+    if let Some(range) = tcp_options.port {
+        bind_local_port(&socket, addr, local_ip, range)?;
+    } else if let Some(ip) = local_ip {
+        socket
+            .bind(SocketAddr::new(ip, 0))
+            .map_err(|err| FetchError::Runtime(format!("bind local address {ip}: {err}")))?;
+    }
     let stream = socket.connect(addr).await?;
-    configure_tcp_stream(&stream);
+    configure_tcp_stream(&stream, tcp_options.keepalive);
     Ok(stream)
 }
 
-fn configure_tcp_stream(stream: &TcpStream) {
+/// Bind `socket` to the first free port in `range`, on `local_ip` or else
+/// the unspecified address of the remote address's family.
+fn bind_local_port(
+    socket: &TcpSocket,
+    remote: SocketAddr,
+    local_ip: Option<IpAddr>,
+    range: LocalPortRange,
+) -> Result<(), FetchError> {
+    let ip = local_ip.unwrap_or(if remote.is_ipv4() {
+        IpAddr::V4(Ipv4Addr::UNSPECIFIED)
+    } else {
+        IpAddr::V6(Ipv6Addr::UNSPECIFIED)
+    });
+    for port in range.low..=range.high {
+        match socket.bind(SocketAddr::new(ip, port)) {
+            Ok(()) => return Ok(()),
+            Err(err) if err.kind() == std::io::ErrorKind::AddrInUse => {}
+            Err(err) => {
+                return Err(FetchError::Runtime(format!(
+                    "bind local port {port}: {err}"
+                )));
+            }
+        }
+    }
+    Err(FetchError::Runtime(format!(
+        "no free local port in range {range}"
+    )))
+}
+
+fn configure_tcp_stream(stream: &TcpStream, keepalive: TcpKeepalive) {
     let _ = stream.set_nodelay(true);
     let socket = socket2::SockRef::from(stream);
-    let keepalive = socket2::TcpKeepalive::new()
-        .with_time(TCP_KEEPALIVE_IDLE)
-        .with_interval(TCP_KEEPALIVE_INTERVAL)
-        .with_retries(TCP_KEEPALIVE_RETRIES);
-    let _ = socket.set_tcp_keepalive(&keepalive);
+    if let Some((idle, interval)) = keepalive.schedule() {
+        let keepalive = socket2::TcpKeepalive::new()
+            .with_time(idle)
+            .with_interval(interval)
+            .with_retries(TCP_KEEPALIVE_RETRIES);
+        let _ = socket.set_tcp_keepalive(&keepalive);
+    }
     #[cfg(any(target_os = "android", target_os = "fuchsia", target_os = "linux"))]
     let _ = socket.set_tcp_user_timeout(Some(TCP_USER_TIMEOUT));
 }
@@ -1129,7 +1588,7 @@ pub(crate) async fn connect_proxy_tcp(
             .await
             .map_err(|err| FetchError::Runtime(format!("lookup {host}: {err}")))?
             .collect();
-        connect_first(addrs, timeout).await
+        connect_first(addrs, TcpOptions::default(), timeout).await
     })
     .await
 }
@@ -1612,4 +2071,297 @@ mod tests {
 
         assert_eq!(err.to_string(), "fallback failed");
     }
+
+    #[test]
+    fn local_port_range_parses_single_ports_and_ranges() {
+        assert_eq!(
+            LocalPortRange::parse("4000").unwrap(),
+            LocalPortRange {
+                low: 4000,
+                high: 4000
+            }
+        );
+        assert_eq!(
+            LocalPortRange::parse("4000-4010").unwrap(),
+            LocalPortRange {
+                low: 4000,
+                high: 4010
+            }
+        );
+        for value in ["", "0", "0-10", "10-5", "abc", "1-", "-1", "1-70000"] {
+            let err = LocalPortRange::parse(value).unwrap_err();
+            assert!(
+                err.to_string()
+                    .contains("must be PORT or LOW-HIGH, with ports from 1 to 65535"),
+                "{value}: {err}"
+            );
+        }
+    }
+
+    #[test]
+    fn resolve_override_parses_hosts_and_addresses() {
+        assert_eq!(
+            ResolveOverride::parse("API.example.com:443:10.0.0.1,[2001:db8::1]").unwrap(),
+            ResolveOverride {
+                host: "api.example.com".to_string(),
+                port: 443,
+                addrs: vec!["10.0.0.1".parse().unwrap(), "2001:db8::1".parse().unwrap()],
+            }
+        );
+        assert_eq!(
+            ResolveOverride::parse("[::1]:8443:::1").unwrap(),
+            ResolveOverride {
+                host: "[::1]".to_string(),
+                port: 8443,
+                addrs: vec!["::1".parse().unwrap()],
+            }
+        );
+        for value in [
+            "",
+            "example.com",
+            "example.com:443",
+            "example.com:443:",
+            "example.com:0:10.0.0.1",
+            "example.com:https:10.0.0.1",
+            ":443:10.0.0.1",
+            "example.com:443:not-an-ip",
+            "[::1:443:::1",
+        ] {
+            let err = ResolveOverride::parse(value).unwrap_err();
+            assert!(
+                err.to_string().contains("must be HOST:PORT:ADDR"),
+                "{value}: {err}"
+            );
+        }
+    }
+
+    #[test]
+    fn resolve_override_addrs_match_host_and_port() {
+        let overrides = [
+            ResolveOverride::parse("example.com:443:10.0.0.1").unwrap(),
+            ResolveOverride::parse("example.com:8443:10.0.0.2,10.0.0.3").unwrap(),
+        ];
+        assert_eq!(
+            resolve_override_addrs(&overrides, "Example.COM", 443),
+            Some(vec!["10.0.0.1:443".parse().unwrap()])
+        );
+        assert_eq!(
+            resolve_override_addrs(&overrides, "example.com", 8443),
+            Some(vec![
+                "10.0.0.2:8443".parse().unwrap(),
+                "10.0.0.3:8443".parse().unwrap()
+            ])
+        );
+        assert_eq!(resolve_override_addrs(&overrides, "example.com", 80), None);
+        assert_eq!(resolve_override_addrs(&overrides, "other.com", 443), None);
+    }
+
+    #[test]
+    fn connect_to_parses_fields() {
+        assert_eq!(
+            ConnectTo::parse("Example.com:443:edge.example.net:8443").unwrap(),
+            ConnectTo {
+                host: Some("example.com".to_string()),
+                port: Some(443),
+                connect_host: Some("edge.example.net".to_string()),
+                connect_port: Some(8443),
+            }
+        );
+        assert_eq!(
+            ConnectTo::parse("[::1]:443:[2001:db8::1]:").unwrap(),
+            ConnectTo {
+                host: Some("[::1]".to_string()),
+                port: Some(443),
+                connect_host: Some("[2001:db8::1]".to_string()),
+                connect_port: None,
+            }
+        );
+        assert_eq!(
+            ConnectTo::parse("::127.0.0.1:").unwrap(),
+            ConnectTo {
+                connect_host: Some("127.0.0.1".to_string()),
+                ..ConnectTo::default()
+            }
+        );
+        for value in [
+            "",
+            "example.com:443:127.0.0.1",
+            "example.com:443:127.0.0.1:80:90",
+            "example.com:0:127.0.0.1:80",
+            "example.com:https:127.0.0.1:80",
+            "example.com:443:127.0.0.1:65536",
+            "example.com:443:[::1:80",
+            "exa mple.com:443:127.0.0.1:80",
+        ] {
+            let err = ConnectTo::parse(value).unwrap_err();
+            assert!(
+                err.to_string().contains("must be HOST:PORT:HOST2:PORT2"),
+                "{value}: {err}"
+            );
+        }
+    }
+
+    #[test]
+    fn connect_to_target_matches_host_and_port() {
+        let entries = [
+            ConnectTo::parse("example.com:443:edge.example.net:").unwrap(),
+            ConnectTo::parse(":80::8080").unwrap(),
+        ];
+        assert_eq!(
+            connect_to_target(&entries, "EXAMPLE.com", 443),
+            Some(("edge.example.net".to_string(), 443))
+        );
+        assert_eq!(
+            connect_to_target(&entries, "other.com", 80),
+            Some(("other.com".to_string(), 8080))
+        );
+        assert_eq!(connect_to_target(&entries, "example.com", 8443), None);
+        assert_eq!(connect_to_target(&entries, "other.com", 443), None);
+    }
+
+    #[tokio::test]
+    async fn connect_addr_binds_the_first_free_local_port_in_range() {
+        let server = tokio::net::TcpListener::bind("127.0.0.1:0").await.unwrap();
+        let addr = server.local_addr().unwrap();
+        let busy = std::net::TcpListener::bind("0.0.0.0:0").unwrap();
+        let busy_port = busy.local_addr().unwrap().port();
+        let range = LocalPortRange {
+            low: busy_port,
+            high: busy_port.saturating_add(20),
+        };
+
+        let tcp_options = TcpOptions {
+            port: Some(range),
+            ..TcpOptions::default()
+        };
+        let stream = connect_addr(addr, tcp_options).await.unwrap();
+        let local_port = stream.local_addr().unwrap().port();
+        assert!(
+            local_port > busy_port && local_port <= range.high,
+            "{local_port}"
+        );
+
+        let tcp_options = TcpOptions {
+            port: Some(LocalPortRange {
+                low: busy_port,
+                high: busy_port,
+            }),
+            ..TcpOptions::default()
+        };
+        let err = connect_addr(addr, tcp_options).await.unwrap_err();
+        assert_eq!(
+            err.to_string(),
+            format!("no free local port in range {busy_port}")
+        );
+    }
+
+    #[test]
+    fn interface_addrs_resolve_ip_literals_and_pick_one_per_family() {
+        let addrs = InterfaceAddrs::resolve("127.0.0.1").unwrap();
+        assert_eq!(addrs.ipv4, Some(Ipv4Addr::LOCALHOST));
+        assert_eq!(addrs.ipv6, None);
+        assert_eq!(
+            addrs
+                .for_remote(IpAddr::V4(Ipv4Addr::new(192, 0, 2, 1)))
+                .unwrap(),
+            IpAddr::V4(Ipv4Addr::LOCALHOST)
+        );
+        assert_eq!(
+            addrs
+                .for_remote(IpAddr::V6(Ipv6Addr::LOCALHOST))
+                .unwrap_err()
+                .to_string(),
+            "--interface has no IPv6 address to connect to ::1"
+        );
+
+        let addrs = InterfaceAddrs::from_addrs([
+            "fe80::1".parse().unwrap(),
+            "10.0.0.2".parse().unwrap(),
+            "2001:db8::2".parse().unwrap(),
+            "10.0.0.3".parse().unwrap(),
+        ]);
+        assert_eq!(addrs.ipv4, Some(Ipv4Addr::new(10, 0, 0, 2)));
+        assert_eq!(addrs.ipv6, Some("2001:db8::2".parse().unwrap()));
+    }
+
+    #[cfg(unix)]
+    #[test]
+    fn interface_addrs_reject_unknown_interface_names() {
+        let err = InterfaceAddrs::resolve("fetch-no-such-if0").unwrap_err();
+        assert_eq!(
+            err.to_string(),
+            "network interface 'fetch-no-such-if0' does not exist"
+        );
+    }
+
+    #[tokio::test]
+    async fn connect_addr_binds_the_interface_address() {
+        let server = tokio::net::TcpListener::bind("127.0.0.1:0").await.unwrap();
+        let addr = server.local_addr().unwrap();
+        let tcp_options = TcpOptions {
+            interface: Some(InterfaceAddrs::resolve("127.0.0.1").unwrap()),
+            ..TcpOptions::default()
+        };
+
+        let stream = connect_addr(addr, tcp_options).await.unwrap();
+        assert_eq!(
+            stream.local_addr().unwrap().ip(),
+            IpAddr::V4(Ipv4Addr::LOCALHOST)
+        );
+    }
+
+    #[test]
+    fn tcp_keepalive_from_flags() {
+        assert_eq!(
+            TcpKeepalive::from_flags(None, false).unwrap(),
+            TcpKeepalive::Default
+        );
+        assert_eq!(
+            TcpKeepalive::from_flags(Some(60.0), false).unwrap(),
+            TcpKeepalive::Idle(Duration::from_secs(60))
+        );
+        assert_eq!(
+            TcpKeepalive::from_flags(Some(0.25), false).unwrap(),
+            TcpKeepalive::Idle(Duration::from_secs(1))
+        );
+        assert_eq!(
+            TcpKeepalive::from_flags(Some(0.0), false).unwrap(),
+            TcpKeepalive::Disabled
+        );
+        assert_eq!(
+            TcpKeepalive::from_flags(None, true).unwrap(),
+            TcpKeepalive::Disabled
+        );
+        let err = TcpKeepalive::from_flags(Some(-1.0), false).unwrap_err();
+        assert!(
+            err.to_string().contains("must be a non-negative number"),
+            "{err}"
+        );
+    }
+
+    #[tokio::test]
+    async fn connect_addr_applies_keepalive_options() {
+        let server = tokio::net::TcpListener::bind("127.0.0.1:0").await.unwrap();
+        let addr = server.local_addr().unwrap();
+
+        let tcp_options = TcpOptions {
+            keepalive: TcpKeepalive::Idle(Duration::from_secs(42)),
+            ..TcpOptions::default()
+        };
+        let stream = connect_addr(addr, tcp_options).await.unwrap();
+        let socket = socket2::SockRef::from(&stream);
+        assert!(socket.keepalive().unwrap());
+        #[cfg(target_os = "linux")]
+        assert_eq!(
+            socket.tcp_keepalive_time().unwrap(),
+            Duration::from_secs(42)
+        );
+
+        let tcp_options = TcpOptions {
+            keepalive: TcpKeepalive::Disabled,
+            ..TcpOptions::default()
+        };
+        let stream = connect_addr(addr, tcp_options).await.unwrap();
+        assert!(!socket2::SockRef::from(&stream).keepalive().unwrap());
+    }
 }
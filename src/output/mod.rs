@@ -9,6 +9,7 @@ use thiserror::Error;
 use tokio::io::{AsyncRead, AsyncReadExt, AsyncWrite, AsyncWriteExt};
 use url::Url;
 
+use crate::cli::ProgressMode;
 use crate::core;
 use crate::fileutil;
 use crate::output::progress::{
@@ -53,6 +54,7 @@ pub struct WriteProgress {
     stderr_is_terminal: bool,
     stdout_is_terminal: bool,
     total_bytes: Option<i64>,
+    mode: ProgressMode,
 }
 
 impl WriteProgress {
@@ -62,6 +64,7 @@ impl WriteProgress {
             stderr_is_terminal: false,
             stdout_is_terminal: false,
             total_bytes: None,
+            mode: ProgressMode::Auto,
         }
     }
 
@@ -86,8 +89,22 @@ impl WriteProgress {
             stderr_is_terminal,
             stdout_is_terminal,
             total_bytes,
+            mode: ProgressMode::Auto,
         }
     }
+
+    pub fn with_mode(mut self, mode: ProgressMode) -> Self {
+        self.mode = mode;
+        self
+    }
+
+    fn renders_live(&self) -> bool {
+        self.stderr_is_terminal && self.mode != ProgressMode::Off
+    }
+
+    fn uses_bar(&self) -> bool {
+        self.mode != ProgressMode::Spinner && self.total_bytes.unwrap_or(-1) > 0
+    }
 }
 
 pub fn resolve_output_path(
@@ -154,6 +171,30 @@ pub fn resolve_output_path(
     Err(OutputError::UnableToInferFileName)
 }
 
+/// Place a file name inferred by `--remote-name` under `--output-dir`,
+/// creating the directory if it does not exist yet. The name must be a single
+/// path component so that it cannot escape the directory.
+pub fn apply_output_dir(
+    resolved: ResolvedOutputPath,
+    output_dir: Option<&str>,
+) -> Result<ResolvedOutputPath, OutputError> {
+    let (Some(dir), Some(filename)) = (output_dir, resolved.path.as_deref()) else {
+        return Ok(resolved);
+    };
+    let mut components = Path::new(filename).components();
+    if !matches!(
+        (components.next(), components.next()),
+        (Some(std::path::Component::Normal(_)), None)
+    ) {
+        return Err(OutputError::InvalidFilename(filename.to_string()));
+    }
+    std::fs::create_dir_all(dir)?;
+    Ok(ResolvedOutputPath {
+        path: Some(Path::new(dir).join(filename).to_string_lossy().into_owned()),
+        warning: resolved.warning,
+    })
+}
+
 /// Return whether two output spellings identify the same destination.
 ///
 /// Existing targets are canonicalized so symlink aliases compare equal. For a
@@ -531,7 +572,7 @@ fn write_temp_body<R: Read>(
     };
 
     let start = Instant::now();
-    let summary = if progress.stderr_is_terminal {
+    let summary = if progress.renders_live() {
         write_with_terminal_progress(file, reader, progress, &printer, display_path)?
     } else {
         let bytes_written = std::io::copy(reader, file)?;
@@ -569,7 +610,7 @@ async fn write_temp_body_async<R: AsyncRead + Unpin>(
     };
 
     let start = Instant::now();
-    let summary = if progress.stderr_is_terminal {
+    let summary = if progress.renders_live() {
         write_with_terminal_progress_async(file, reader, progress, &printer, display_path).await?
     } else {
         let bytes_written = tokio::io::copy(reader, file).await?;
@@ -627,7 +668,7 @@ async fn write_with_terminal_progress_async<R: AsyncRead + Unpin>(
     printer: &ProgressPrinter,
     display_path: &str,
 ) -> Result<ProgressSummary, OutputError> {
-    if progress.total_bytes.unwrap_or(-1) > 0 {
+    if progress.uses_bar() {
         let native_printer = printer.clone();
         let stdout_is_terminal = progress.stdout_is_terminal;
         let mut counter = BarCounter::new_with_on_render(
@@ -645,7 +686,7 @@ async fn write_with_terminal_progress_async<R: AsyncRead + Unpin>(
             printer: printer.clone(),
             bytes_read,
             elapsed,
-            to_clear: 32,
+            to_clear: 42,
             display_path: display_path.to_string(),
             clear_native: progress.stdout_is_terminal,
         })
@@ -703,7 +744,7 @@ fn write_with_terminal_progress<R: Read>(
     printer: &ProgressPrinter,
     display_path: &str,
 ) -> Result<ProgressSummary, OutputError> {
-    if progress.total_bytes.unwrap_or(-1) > 0 {
+    if progress.uses_bar() {
         let native_printer = printer.clone();
         let stdout_is_terminal = progress.stdout_is_terminal;
         let mut reader = Bar::new_with_on_render(
@@ -722,7 +763,7 @@ fn write_with_terminal_progress<R: Read>(
             printer: printer.clone(),
             bytes_read,
             elapsed,
-            to_clear: 32,
+            to_clear: 42,
             display_path: display_path.to_string(),
             clear_native: progress.stdout_is_terminal,
         })
@@ -1250,6 +1291,37 @@ mod tests {
         );
     }
 
+    #[test]
+    fn output_dir_joins_inferred_filename() {
+        let dir = tempfile::tempdir().unwrap();
+        let base = dir.path().join("downloads/nested");
+        let base = base.to_str().unwrap();
+        let resolved = ResolvedOutputPath {
+            path: Some("report.csv".to_string()),
+            warning: None,
+        };
+        let resolved = apply_output_dir(resolved, Some(base)).unwrap();
+        assert_eq!(
+            resolved.path.as_deref().map(Path::new),
+            Some(Path::new(base).join("report.csv").as_path())
+        );
+        assert!(Path::new(base).is_dir());
+
+        for filename in ["../escape", "..", "a/b", "/abs"] {
+            let resolved = ResolvedOutputPath {
+                path: Some(filename.to_string()),
+                warning: None,
+            };
+            assert!(
+                matches!(
+                    apply_output_dir(resolved, Some(base)),
+                    Err(OutputError::InvalidFilename(_))
+                ),
+                "{filename}"
+            );
+        }
+    }
+
     #[test]
     fn remote_name_falls_back_to_hostname() {
         let url = Url::parse("http://example.com/").unwrap();
@@ -1411,6 +1483,37 @@ mod tests {
         );
     }
 
+    #[tokio::test]
+    async fn write_output_skips_live_progress_when_mode_is_off() {
+        let dir = tempfile::tempdir().unwrap();
+        let path = dir.path().join("download.txt");
+        let buffer = Arc::new(Mutex::new(Vec::new()));
+        let printer = ProgressPrinter::new(SharedBuffer(buffer.clone()), false);
+        let progress =
+            WriteProgress::with_printer(printer, true, false, Some(3)).with_mode(ProgressMode::Off);
+
+        write_output_with_progress(path.to_str().unwrap(), b"new", false, progress)
+            .await
+            .unwrap();
+
+        let output = String::from_utf8(buffer.lock().unwrap().clone()).unwrap();
+        assert!(output.starts_with("Downloaded 3B in "), "{output:?}");
+        assert!(!output.contains('\r'), "{output:?}");
+    }
+
+    #[test]
+    fn write_progress_uses_bar_only_for_known_sizes_unless_spinner() {
+        let progress = |total, mode| {
+            WriteProgress::with_printer(ProgressPrinter::new(Vec::new(), false), true, true, total)
+                .with_mode(mode)
+        };
+        assert!(progress(Some(3), ProgressMode::Auto).uses_bar());
+        assert!(progress(Some(3), ProgressMode::Bar).uses_bar());
+        assert!(!progress(None, ProgressMode::Bar).uses_bar());
+        assert!(!progress(Some(3), ProgressMode::Spinner).uses_bar());
+        assert!(!progress(Some(3), ProgressMode::Off).renders_live());
+    }
+
     #[tokio::test]
     async fn write_output_async_reader_removes_temp_when_cancelled() {
         let dir = tempfile::tempdir().unwrap();
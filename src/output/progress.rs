@@ -218,24 +218,30 @@ impl BarCounter {
         let (stop_tx, stop_rx) = mpsc::channel();
         let mut on_render =
             on_render.map(|callback| Box::new(callback) as Box<dyn FnMut(i64) + Send>);
+        let start = Instant::now();
 
         let handle = thread::spawn(move || {
             loop {
-                match stop_rx.recv_timeout(Duration::from_millis(100)) {
-                    Ok(()) | Err(mpsc::RecvTimeoutError::Disconnected) => {
-                        render_bar(&printer, &thread_bytes_read, total_bytes, &mut on_render);
-                        return;
-                    }
-                    Err(mpsc::RecvTimeoutError::Timeout) => {
-                        render_bar(&printer, &thread_bytes_read, total_bytes, &mut on_render);
-                    }
+                let stopped = match stop_rx.recv_timeout(Duration::from_millis(100)) {
+                    Ok(()) | Err(mpsc::RecvTimeoutError::Disconnected) => true,
+                    Err(mpsc::RecvTimeoutError::Timeout) => false,
+                };
+                render_bar(
+                    &printer,
+                    &thread_bytes_read,
+                    total_bytes,
+                    start.elapsed(),
+                    &mut on_render,
+                );
+                if stopped {
+                    return;
                 }
             }
         });
 
         Self {
             bytes_read,
-            start: Instant::now(),
+            start,
             stop_tx: Some(stop_tx),
             handle: Some(handle),
         }
@@ -330,6 +336,7 @@ impl SpinnerCounter {
         let thread_bytes_read = bytes_read.clone();
         let (stop_tx, stop_rx) = mpsc::channel();
         let mut on_start = on_start.map(|callback| Box::new(callback) as Box<dyn FnOnce() + Send>);
+        let start = Instant::now();
 
         let handle = thread::spawn(move || {
             if let Some(callback) = on_start.take() {
@@ -340,11 +347,11 @@ impl SpinnerCounter {
             loop {
                 match stop_rx.recv_timeout(Duration::from_millis(50)) {
                     Ok(()) | Err(mpsc::RecvTimeoutError::Disconnected) => {
-                        render_spinner(&printer, &thread_bytes_read, position);
+                        render_spinner(&printer, &thread_bytes_read, start.elapsed(), position);
                         return;
                     }
                     Err(mpsc::RecvTimeoutError::Timeout) => {
-                        render_spinner(&printer, &thread_bytes_read, position);
+                        render_spinner(&printer, &thread_bytes_read, start.elapsed(), position);
                         position += 1;
                     }
                 }
@@ -353,7 +360,7 @@ impl SpinnerCounter {
 
         Self {
             bytes_read,
-            start: Instant::now(),
+            start,
             stop_tx: Some(stop_tx),
             handle: Some(handle),
         }
@@ -393,6 +400,7 @@ fn render_bar(
     printer: &ProgressPrinter,
     bytes_read: &AtomicI64,
     total_bytes: i64,
+    elapsed: Duration,
     on_render: &mut Option<Box<dyn FnMut(i64) + Send>>,
 ) {
     const BAR_WIDTH: i64 = 30;
@@ -439,10 +447,24 @@ fn render_bar(
     out.push_str(&format_size(total_bytes));
     out.push(')');
 
+    if let Some(rate) = transfer_rate(bytes_read, elapsed) {
+        push_rate(&mut out, rate);
+        let remaining = total_bytes.saturating_sub(bytes_read).max(0);
+        out.push_str(" ETA ");
+        let eta = format_eta(remaining as f64 / rate);
+        push_repeat(&mut out, ' ', 6usize.saturating_sub(eta.len()));
+        out.push_str(&eta);
+    }
+
     printer.render_printer(out);
 }
 
-fn render_spinner(printer: &ProgressPrinter, bytes_read: &AtomicI64, position: i64) {
+fn render_spinner(
+    printer: &ProgressPrinter,
+    bytes_read: &AtomicI64,
+    elapsed: Duration,
+    position: i64,
+) {
     const WIDTH: i64 = 20;
 
     let position = position % (WIDTH * 2);
@@ -466,13 +488,45 @@ fn render_spinner(printer: &ProgressPrinter, bytes_read: &AtomicI64, position: i
     out.reset();
 
     out.push(' ');
-    let size = format_size(bytes_read.load(Ordering::Relaxed));
+    let bytes_read = bytes_read.load(Ordering::Relaxed);
+    let size = format_size(bytes_read);
     push_repeat(&mut out, ' ', 7usize.saturating_sub(size.len()));
     out.push_str(&size);
 
+    if let Some(rate) = transfer_rate(bytes_read, elapsed) {
+        push_rate(&mut out, rate);
+    }
+
     printer.render_printer(out);
 }
 
+/// Bytes per second so far, once there is enough of a sample to be useful.
+fn transfer_rate(bytes_read: i64, elapsed: Duration) -> Option<f64> {
+    let seconds = elapsed.as_secs_f64();
+    (bytes_read > 0 && seconds >= 0.5).then(|| bytes_read as f64 / seconds)
+}
+
+/// Write the rate padded to a fixed width, so the line does not jitter as it
+/// is redrawn in place.
+fn push_rate(out: &mut Printer, rate: f64) {
+    let rate = format!("{}/s", format_size(rate as i64));
+    out.push(' ');
+    push_repeat(out, ' ', 9usize.saturating_sub(rate.len()));
+    out.push_str(&rate);
+}
+
+/// Format a remaining time compactly, such as `45s`, `3m07s`, or `2h05m`.
+fn format_eta(seconds: f64) -> String {
+    let seconds = seconds.ceil().min(u32::MAX as f64) as u64;
+    if seconds < 60 {
+        format!("{seconds}s")
+    } else if seconds < 60 * 60 {
+        format!("{}m{:02}s", seconds / 60, seconds % 60)
+    } else {
+        format!("{}h{:02}m", seconds / 3600, seconds % 3600 / 60)
+    }
+}
+
 fn push_repeat(out: &mut Printer, ch: char, count: usize) {
     for _ in 0..count {
         out.push(ch);
@@ -680,7 +734,7 @@ mod tests {
     fn render_shapes_match_go_without_color() {
         let (printer, buffer) = ProgressPrinter::memory(false);
         let bytes_read = AtomicI64::new(13);
-        render_bar(&printer, &bytes_read, 13, &mut None);
+        render_bar(&printer, &bytes_read, 13, Duration::ZERO, &mut None);
         assert_eq!(
             String::from_utf8(buffer.lock().unwrap().clone()).unwrap(),
             "\r[==============================] 100% (    13B / 13B)"
@@ -688,18 +742,51 @@ mod tests {
 
         let (printer, buffer) = ProgressPrinter::memory(false);
         let bytes_read = AtomicI64::new(17);
-        render_spinner(&printer, &bytes_read, 0);
+        render_spinner(&printer, &bytes_read, Duration::ZERO, 0);
         assert_eq!(
             String::from_utf8(buffer.lock().unwrap().clone()).unwrap(),
             "\r[=>                   ]     17B"
         );
     }
 
+    #[test]
+    fn render_shapes_include_rate_and_eta_once_timed() {
+        let (printer, buffer) = ProgressPrinter::memory(false);
+        let bytes_read = AtomicI64::new(2048);
+        render_bar(
+            &printer,
+            &bytes_read,
+            10240,
+            Duration::from_secs(2),
+            &mut None,
+        );
+        assert_eq!(
+            String::from_utf8(buffer.lock().unwrap().clone()).unwrap(),
+            "\r[======                        ]  20% (  2.0KB / 10.0KB)   1.0KB/s ETA     8s"
+        );
+
+        let (printer, buffer) = ProgressPrinter::memory(false);
+        let bytes_read = AtomicI64::new(3000);
+        render_spinner(&printer, &bytes_read, Duration::from_secs(3), 0);
+        assert_eq!(
+            String::from_utf8(buffer.lock().unwrap().clone()).unwrap(),
+            "\r[=>                   ]   2.9KB   1000B/s"
+        );
+    }
+
+    #[test]
+    fn format_eta_uses_largest_units() {
+        assert_eq!(format_eta(0.0), "0s");
+        assert_eq!(format_eta(44.2), "45s");
+        assert_eq!(format_eta(187.0), "3m07s");
+        assert_eq!(format_eta(7500.0), "2h05m");
+    }
+
     #[test]
     fn render_shapes_use_core_sequences_with_color() {
         let (printer, buffer) = ProgressPrinter::memory(true);
         let bytes_read = AtomicI64::new(13);
-        render_bar(&printer, &bytes_read, 13, &mut None);
+        render_bar(&printer, &bytes_read, 13, Duration::ZERO, &mut None);
         let output = String::from_utf8(buffer.lock().unwrap().clone()).unwrap();
 
         assert!(output.contains(&Sequence::Bold.ansi()), "{output:?}");
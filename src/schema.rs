@@ -0,0 +1,1042 @@
+use std::cell::RefCell;
+use std::collections::HashMap;
+use std::fmt::Write as _;
+
+use regex::Regex;
+use serde_json::{Map, Value};
+
+use crate::error::FetchError;
+
+pub(crate) const SCHEMA_VIOLATION_EXIT_CODE: i32 = 7;
+
+const MAX_SCHEMA_DEPTH: usize = 64;
+const MAX_REPORTED_VIOLATIONS: usize = 20;
+
+/// Keywords that constrain a document but are not implemented. A schema that
+/// reaches one fails validation rather than passing documents unchecked.
+const UNSUPPORTED_KEYWORDS: &[&str] = &[
+    "unevaluatedProperties",
+    "unevaluatedItems",
+    "$dynamicRef",
+    "$recursiveRef",
+];
+
+pub(crate) struct Schema {
+    path: String,
+    root: Value,
+    regexes: RefCell<HashMap<String, Result<Regex, String>>>,
+}
+
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub(crate) struct Violation {
+    pub path: String,
+    pub message: String,
+}
+
+impl Schema {
+    pub(crate) fn load(path: &str) -> Result<Self, FetchError> {
+        let bytes = std::fs::read(path).map_err(|err| {
+            if err.kind() == std::io::ErrorKind::NotFound {
+                FetchError::FileDoesNotExist(path.to_string())
+            } else {
+                FetchError::invalid_value("--schema", path, err.to_string())
+            }
+        })?;
+        let root: Value = serde_json::from_slice(&bytes).map_err(|err| {
+            FetchError::invalid_value("--schema", path, format!("invalid JSON: {err}"))
+        })?;
+        Self::from_value(path, root)
+    }
+
+    pub(crate) fn from_value(path: &str, root: Value) -> Result<Self, FetchError> {
+        if !matches!(root, Value::Object(_) | Value::Bool(_)) {
+            return Err(FetchError::invalid_value(
+                "--schema",
+                path,
+                "schema must be a JSON object or boolean",
+            ));
+        }
+        Ok(Self {
+            path: path.to_string(),
+            root,
+            regexes: RefCell::default(),
+        })
+    }
+
+    pub(crate) fn validate(&self, instance: &Value) -> Vec<Violation> {
+        let mut violations = Vec::new();
+        self.validate_at(&self.root, instance, "$", 0, &mut violations);
+        violations
+    }
+
+    pub(crate) fn validate_bytes(&self, bytes: &[u8]) -> Vec<Violation> {
+        match serde_json::from_slice::<Value>(bytes) {
+            Ok(value) => self.validate(&value),
+            Err(err) => vec![Violation {
+                path: "$".to_string(),
+                message: format!("response body is not valid JSON: {err}"),
+            }],
+        }
+    }
+
+    pub(crate) fn violations_message(&self, violations: &[Violation]) -> String {
+        let mut message = format!("response body does not match schema '{}'", self.path);
+        for violation in violations.iter().take(MAX_REPORTED_VIOLATIONS) {
+            let _ = write!(message, "\n  {}: {}", violation.path, violation.message);
+        }
+        if violations.len() > MAX_REPORTED_VIOLATIONS {
+            let _ = write!(
+                message,
+                "\n  ... and {} more",
+                violations.len() - MAX_REPORTED_VIOLATIONS
+            );
+        }
+        message
+    }
+
+    fn validate_at(
+        &self,
+        schema: &Value,
+        instance: &Value,
+        path: &str,
+        depth: usize,
+        violations: &mut Vec<Violation>,
+    ) {
+        if depth > MAX_SCHEMA_DEPTH {
+            push(violations, path, "schema nesting is too deep".to_string());
+            return;
+        }
+        let schema = match schema {
+            Value::Bool(true) => return,
+            Value::Bool(false) => {
+                push(violations, path, "no value is allowed here".to_string());
+                return;
+            }
+            Value::Object(schema) => schema,
+            _ => return,
+        };
+        for keyword in UNSUPPORTED_KEYWORDS {
+            if schema.contains_key(*keyword) {
+                push(
+                    violations,
+                    path,
+                    format!("unsupported schema keyword '{keyword}'"),
+                );
+            }
+        }
+
+        if let Some(reference) = schema.get("$ref").and_then(Value::as_str) {
+            match self.resolve_ref(reference) {
+                Some(target) => self.validate_at(target, instance, path, depth + 1, violations),
+                None => push(
+                    violations,
+                    path,
+                    format!("unresolvable schema reference '{reference}'"),
+                ),
+            }
+        }
+
+        if let Some(expected) = schema.get("type")
+            && !matches_type(expected, instance)
+        {
+            push(
+                violations,
+                path,
+                format!(
+                    "expected {}, got {}",
+                    describe_types(expected),
+                    type_name(instance)
+                ),
+            );
+            return;
+        }
+        if let Some(expected) = schema.get("const")
+            && expected != instance
+        {
+            push(violations, path, format!("expected constant {expected}"));
+        }
+        if let Some(Value::Array(allowed)) = schema.get("enum")
+            && !allowed.contains(instance)
+        {
+            push(
+                violations,
+                path,
+                format!("value is not one of {}", Value::Array(allowed.clone())),
+            );
+        }
+
+        match instance {
+            Value::Object(object) => self.validate_object(schema, object, path, depth, violations),
+            Value::Array(items) => self.validate_array(schema, items, path, depth, violations),
+            Value::String(value) => self.validate_string(schema, value, path, violations),
+            Value::Number(_) => validate_number(schema, instance, path, violations),
+            Value::Null | Value::Bool(_) => {}
+        }
+
+        if let Some(Value::Array(all)) = schema.get("allOf") {
+            for subschema in all {
+                self.validate_at(subschema, instance, path, depth + 1, violations);
+            }
+        }
+        if let Some(Value::Array(any)) = schema.get("anyOf")
+            && !any
+                .iter()
+                .any(|subschema| self.is_valid(subschema, instance, depth + 1))
+        {
+            push(
+                violations,
+                path,
+                "value does not match any schema in 'anyOf'".to_string(),
+            );
+        }
+        if let Some(Value::Array(one)) = schema.get("oneOf") {
+            let matched = one
+                .iter()
+                .filter(|subschema| self.is_valid(subschema, instance, depth + 1))
+                .count();
+            if matched != 1 {
+                push(
+                    violations,
+                    path,
+                    format!("value matches {matched} schemas in 'oneOf', expected exactly 1"),
+                );
+            }
+        }
+        if let Some(not) = schema.get("not")
+            && self.is_valid(not, instance, depth + 1)
+        {
+            push(
+                violations,
+                path,
+                "value must not match the schema in 'not'".to_string(),
+            );
+        }
+        if let Some(condition) = schema.get("if") {
+            let branch = if self.is_valid(condition, instance, depth + 1) {
+                schema.get("then")
+            } else {
+                schema.get("else")
+            };
+            if let Some(branch) = branch {
+                self.validate_at(branch, instance, path, depth + 1, violations);
+            }
+        }
+        if let (Some(Value::Object(dependents)), Value::Object(object)) =
+            (schema.get("dependentSchemas"), instance)
+        {
+            for (name, subschema) in dependents {
+                if object.contains_key(name) {
+                    self.validate_at(subschema, instance, path, depth + 1, violations);
+                }
+            }
+        }
+    }
+
+    fn validate_object(
+        &self,
+        schema: &Map<String, Value>,
+        object: &Map<String, Value>,
+        path: &str,
+        depth: usize,
+        violations: &mut Vec<Violation>,
+    ) {
+        if let Some(Value::Array(required)) = schema.get("required") {
+            for name in required.iter().filter_map(Value::as_str) {
+                if !object.contains_key(name) {
+                    push(
+                        violations,
+                        path,
+                        format!("missing required property '{name}'"),
+                    );
+                }
+            }
+        }
+        if let Some(min) = schema.get("minProperties").and_then(Value::as_u64)
+            && (object.len() as u64) < min
+        {
+            push(
+                violations,
+                path,
+                format!("expected at least {min} properties, got {}", object.len()),
+            );
+        }
+        if let Some(max) = schema.get("maxProperties").and_then(Value::as_u64)
+            && (object.len() as u64) > max
+        {
+            push(
+                violations,
+                path,
+                format!("expected at most {max} properties, got {}", object.len()),
+            );
+        }
+
+        if let Some(Value::Object(dependents)) = schema.get("dependentRequired") {
+            for (name, required) in dependents {
+                if !object.contains_key(name) {
+                    continue;
+                }
+                let required = required.as_array().map_or(&[][..], Vec::as_slice);
+                for other in required.iter().filter_map(Value::as_str) {
+                    if !object.contains_key(other) {
+                        push(
+                            violations,
+                            path,
+                            format!("property '{name}' requires property '{other}'"),
+                        );
+                    }
+                }
+            }
+        }
+
+        let mut patterns = Vec::new();
+        if let Some(Value::Object(pattern_properties)) = schema.get("patternProperties") {
+            for (pattern, subschema) in pattern_properties {
+                match self.regex(pattern) {
+                    Ok(regex) => patterns.push((regex, subschema)),
+                    Err(message) => push(violations, path, message),
+                }
+            }
+        }
+        let names = schema.get("propertyNames");
+        let properties = schema.get("properties").and_then(Value::as_object);
+        let additional = schema.get("additionalProperties");
+        for (name, value) in object {
+            let child = property_path(path, name);
+            if let Some(names) = names {
+                let name_value = Value::String(name.clone());
+                self.validate_at(names, &name_value, &child, depth + 1, violations);
+            }
+            let mut matched = false;
+            if let Some(subschema) = properties.and_then(|properties| properties.get(name)) {
+                self.validate_at(subschema, value, &child, depth + 1, violations);
+                matched = true;
+            }
+            for (regex, subschema) in &patterns {
+                if regex.is_match(name) {
+                    self.validate_at(subschema, value, &child, depth + 1, violations);
+                    matched = true;
+                }
+            }
+            if matched {
+                continue;
+            }
+            match additional {
+                Some(Value::Bool(false)) => {
+                    push(violations, path, format!("unexpected property '{name}'"))
+                }
+                Some(subschema) => {
+                    self.validate_at(subschema, value, &child, depth + 1, violations);
+                }
+                None => {}
+            }
+        }
+    }
+
+    fn validate_array(
+        &self,
+        schema: &Map<String, Value>,
+        items: &[Value],
+        path: &str,
+        depth: usize,
+        violations: &mut Vec<Violation>,
+    ) {
+        if let Some(min) = schema.get("minItems").and_then(Value::as_u64)
+            && (items.len() as u64) < min
+        {
+            push(
+                violations,
+                path,
+                format!("expected at least {min} items, got {}", items.len()),
+            );
+        }
+        if let Some(max) = schema.get("maxItems").and_then(Value::as_u64)
+            && (items.len() as u64) > max
+        {
+            push(
+                violations,
+                path,
+                format!("expected at most {max} items, got {}", items.len()),
+            );
+        }
+        if schema.get("uniqueItems") == Some(&Value::Bool(true)) {
+            for (index, item) in items.iter().enumerate() {
+                if items[..index].contains(item) {
+                    push(
+                        violations,
+                        &index_path(path, index),
+                        "duplicate item in array with 'uniqueItems'".to_string(),
+                    );
+                }
+            }
+        }
+
+        let prefix = match schema.get("prefixItems") {
+            Some(Value::Array(prefix)) => prefix.as_slice(),
+            _ => &[],
+        };
+        for (index, item) in items.iter().enumerate() {
+            let subschema = prefix.get(index).or_else(|| schema.get("items"));
+            if let Some(subschema) = subschema {
+                self.validate_at(
+                    subschema,
+                    item,
+                    &index_path(path, index),
+                    depth + 1,
+                    violations,
+                );
+            }
+        }
+        if let Some(contains) = schema.get("contains") {
+            let matched = items
+                .iter()
+                .filter(|item| self.is_valid(contains, item, depth + 1))
+                .count() as u64;
+            let min = schema
+                .get("minContains")
+                .and_then(Value::as_u64)
+                .unwrap_or(1);
+            let max = schema.get("maxContains").and_then(Value::as_u64);
+            if matched == 0 && min > 0 {
+                push(
+                    violations,
+                    path,
+                    "no item matches the schema in 'contains'".to_string(),
+                );
+            } else if matched < min {
+                push(
+                    violations,
+                    path,
+                    format!("expected at least {min} items matching 'contains', got {matched}"),
+                );
+            }
+            if let Some(max) = max
+                && matched > max
+            {
+                push(
+                    violations,
+                    path,
+                    format!("expected at most {max} items matching 'contains', got {matched}"),
+                );
+            }
+        }
+    }
+
+    fn validate_string(
+        &self,
+        schema: &Map<String, Value>,
+        value: &str,
+        path: &str,
+        violations: &mut Vec<Violation>,
+    ) {
+        let len = value.chars().count() as u64;
+        if let Some(min) = schema.get("minLength").and_then(Value::as_u64)
+            && len < min
+        {
+            push(
+                violations,
+                path,
+                format!("expected at least {min} characters, got {len}"),
+            );
+        }
+        if let Some(max) = schema.get("maxLength").and_then(Value::as_u64)
+            && len > max
+        {
+            push(
+                violations,
+                path,
+                format!("expected at most {max} characters, got {len}"),
+            );
+        }
+        if let Some(pattern) = schema.get("pattern").and_then(Value::as_str) {
+            match self.regex(pattern) {
+                Ok(regex) if regex.is_match(value) => {}
+                Ok(_) => push(
+                    violations,
+                    path,
+                    format!("value does not match pattern '{pattern}'"),
+                ),
+                Err(message) => push(violations, path, message),
+            }
+        }
+        if let Some(format) = schema.get("format").and_then(Value::as_str) {
+            match matches_format(format, value) {
+                Some(true) => {}
+                Some(false) => push(violations, path, format!("value is not a valid '{format}'")),
+                None => push(
+                    violations,
+                    path,
+                    format!("unsupported schema format '{format}'"),
+                ),
+            }
+        }
+    }
+
+    /// Compile a `pattern` or `patternProperties` regex once per schema.
+    fn regex(&self, pattern: &str) -> Result<Regex, String> {
+        self.regexes
+            .borrow_mut()
+            .entry(pattern.to_string())
+            .or_insert_with(|| {
+                Regex::new(pattern).map_err(|_| format!("unsupported schema pattern '{pattern}'"))
+            })
+            .clone()
+    }
+
+    fn is_valid(&self, schema: &Value, instance: &Value, depth: usize) -> bool {
+        let mut violations = Vec::new();
+        self.validate_at(schema, instance, "$", depth, &mut violations);
+        violations.is_empty()
+    }
+
+    fn resolve_ref(&self, reference: &str) -> Option<&Value> {
+        let pointer = reference.strip_prefix('#')?;
+        if pointer.is_empty() {
+            return Some(&self.root);
+        }
+        let pointer = percent_encoding::percent_decode_str(pointer)
+            .decode_utf8()
+            .ok()?;
+        self.root.pointer(&pointer)
+    }
+}
+
+fn validate_number(
+    schema: &Map<String, Value>,
+    instance: &Value,
+    path: &str,
+    violations: &mut Vec<Violation>,
+) {
+    let Some(value) = instance.as_f64() else {
+        return;
+    };
+    let bound = |keyword: &str| schema.get(keyword).and_then(Value::as_f64);
+    if let Some(min) = bound("minimum")
+        && value < min
+    {
+        push(violations, path, format!("{instance} is less than {min}"));
+    }
+    if let Some(max) = bound("maximum")
+        && value > max
+    {
+        push(
+            violations,
+            path,
+            format!("{instance} is greater than {max}"),
+        );
+    }
+    if let Some(min) = bound("exclusiveMinimum")
+        && value <= min
+    {
+        push(
+            violations,
+            path,
+            format!("{instance} is not greater than {min}"),
+        );
+    }
+    if let Some(max) = bound("exclusiveMaximum")
+        && value >= max
+    {
+        push(
+            violations,
+            path,
+            format!("{instance} is not less than {max}"),
+        );
+    }
+    if let Some(divisor) = bound("multipleOf")
+        && divisor > 0.0
+    {
+        let quotient = value / divisor;
+        if (quotient - quotient.round()).abs() > 1e-9 {
+            push(
+                violations,
+                path,
+                format!("{instance} is not a multiple of {divisor}"),
+            );
+        }
+    }
+}
+
+/// Check a string against a `format`, returning `None` for formats that are
+/// not implemented.
+fn matches_format(format: &str, value: &str) -> Option<bool> {
+    let valid = match format {
+        "date-time" => value
+            .split_once(['T', 't'])
+            .is_some_and(|(date, time)| is_date(date) && is_time(time)),
+        "date" => is_date(value),
+        "time" => is_time(value),
+        "email" => value.split_once('@').is_some_and(|(local, domain)| {
+            !local.is_empty() && !local.contains(char::is_whitespace) && is_hostname(domain)
+        }),
+        "hostname" => is_hostname(value),
+        "ipv4" => value.parse::<std::net::Ipv4Addr>().is_ok(),
+        "ipv6" => value.parse::<std::net::Ipv6Addr>().is_ok(),
+        "uri" => url::Url::parse(value).is_ok(),
+        "uuid" => {
+            let groups: Vec<&str> = value.split('-').collect();
+            groups.iter().map(|group| group.len()).eq([8, 4, 4, 4, 12])
+                && groups
+                    .iter()
+                    .all(|group| group.bytes().all(|byte| byte.is_ascii_hexdigit()))
+        }
+        "regex" => Regex::new(value).is_ok(),
+        _ => return None,
+    };
+    Some(valid)
+}
+
+/// An RFC 3339 `full-date`, such as `2024-02-29`.
+fn is_date(value: &str) -> bool {
+    let Some([year, month, day]) = digit_fields(value, '-', [4, 2, 2]) else {
+        return false;
+    };
+    let leap = year % 4 == 0 && (year % 100 != 0 || year % 400 == 0);
+    let days = match month {
+        1 | 3 | 5 | 7 | 8 | 10 | 12 => 31,
+        4 | 6 | 9 | 11 => 30,
+        2 if leap => 29,
+        2 => 28,
+        _ => return false,
+    };
+    (1..=days).contains(&day)
+}
+
+/// An RFC 3339 `full-time`, such as `23:59:60.5+01:00`.
+fn is_time(value: &str) -> bool {
+    let Some(index) = value.find(['Z', 'z', '+', '-']) else {
+        return false;
+    };
+    let (time, offset) = value.split_at(index);
+    let offset_valid = match offset {
+        "Z" | "z" => true,
+        _ => matches!(
+            digit_fields(&offset[1..], ':', [2, 2]),
+            Some([hour, minute]) if hour < 24 && minute < 60
+        ),
+    };
+    let (time, fraction) = time.split_once('.').unwrap_or((time, "0"));
+    offset_valid
+        && !fraction.is_empty()
+        && fraction.bytes().all(|byte| byte.is_ascii_digit())
+        && matches!(
+            digit_fields(time, ':', [2, 2, 2]),
+            Some([hour, minute, second]) if hour < 24 && minute < 60 && second <= 60
+        )
+}
+
+/// Split `value` on `separator` into fields of exactly the given digit
+/// counts.
+fn digit_fields<const N: usize>(
+    value: &str,
+    separator: char,
+    widths: [usize; N],
+) -> Option<[u32; N]> {
+    let mut fields = value.split(separator);
+    let mut parsed = [0; N];
+    for (slot, width) in parsed.iter_mut().zip(widths) {
+        let field = fields.next()?;
+        if field.len() != width || !field.bytes().all(|byte| byte.is_ascii_digit()) {
+            return None;
+        }
+        *slot = field.parse().ok()?;
+    }
+    fields.next().is_none().then_some(parsed)
+}
+
+fn is_hostname(value: &str) -> bool {
+    let value = value.strip_suffix('.').unwrap_or(value);
+    !value.is_empty()
+        && value.len() <= 253
+        && value.split('.').all(|label| {
+            !label.is_empty()
+                && label.len() <= 63
+                && !label.starts_with('-')
+                && !label.ends_with('-')
+                && label
+                    .bytes()
+                    .all(|byte| byte.is_ascii_alphanumeric() || byte == b'-')
+        })
+}
+
+fn matches_type(expected: &Value, instance: &Value) -> bool {
+    match expected {
+        Value::String(name) => matches_type_name(name, instance),
+        Value::Array(names) => names
+            .iter()
+            .filter_map(Value::as_str)
+            .any(|name| matches_type_name(name, instance)),
+        _ => true,
+    }
+}
+
+fn matches_type_name(name: &str, instance: &Value) -> bool {
+    match name {
+        "null" => instance.is_null(),
+        "boolean" => instance.is_boolean(),
+        "object" => instance.is_object(),
+        "array" => instance.is_array(),
+        "string" => instance.is_string(),
+        "number" => instance.is_number(),
+        "integer" => is_integer(instance),
+        _ => false,
+    }
+}
+
+fn is_integer(instance: &Value) -> bool {
+    let Value::Number(number) = instance else {
+        return false;
+    };
+    number.is_i64()
+        || number.is_u64()
+        || number
+            .as_f64()
+            .is_some_and(|value| value.is_finite() && value.fract() == 0.0)
+}
+
+fn describe_types(expected: &Value) -> String {
+    match expected {
+        Value::Array(names) => names
+            .iter()
+            .filter_map(Value::as_str)
+            .collect::<Vec<_>>()
+            .join(" or "),
+        Value::String(name) => name.clone(),
+        other => other.to_string(),
+    }
+}
+
+fn type_name(instance: &Value) -> &'static str {
+    match instance {
+        Value::Null => "null",
+        Value::Bool(_) => "boolean",
+        Value::Number(_) if is_integer(instance) => "integer",
+        Value::Number(_) => "number",
+        Value::String(_) => "string",
+        Value::Array(_) => "array",
+        Value::Object(_) => "object",
+    }
+}
+
+fn property_path(path: &str, name: &str) -> String {
+    let simple = !name.is_empty()
+        && !name.starts_with(|ch: char| ch.is_ascii_digit())
+        && name
+            .chars()
+            .all(|ch| ch.is_ascii_alphanumeric() || ch == '_');
+    if simple {
+        format!("{path}.{name}")
+    } else {
+        format!("{path}[{}]", Value::String(name.to_string()))
+    }
+}
+
+fn index_path(path: &str, index: usize) -> String {
+    format!("{path}[{index}]")
+}
+
+fn push(violations: &mut Vec<Violation>, path: &str, message: String) {
+    violations.push(Violation {
+        path: path.to_string(),
+        message,
+    });
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    use serde_json::json;
+
+    fn schema(value: Value) -> Schema {
+        Schema::from_value("schema.json", value).unwrap()
+    }
+
+    fn messages(schema: &Schema, instance: Value) -> Vec<String> {
+        schema
+            .validate(&instance)
+            .into_iter()
+            .map(|violation| format!("{}: {}", violation.path, violation.message))
+            .collect()
+    }
+
+    #[test]
+    fn validates_types_required_and_nested_properties() {
+        let schema = schema(json!({
+            "type": "object",
+            "required": ["id", "name"],
+            "properties": {
+                "id": {"type": "integer", "minimum": 1},
+                "name": {"type": "string", "minLength": 1},
+                "tags": {"type": "array", "items": {"type": "string"}},
+            },
+            "additionalProperties": false,
+        }));
+
+        assert!(messages(&schema, json!({"id": 1, "name": "a", "tags": ["x"]})).is_empty());
+        assert_eq!(
+            messages(&schema, json!({"id": "1", "tags": ["x", 2], "extra": true})),
+            vec![
+                "$: missing required property 'name'",
+                "$.id: expected integer, got string",
+                "$.tags[1]: expected string, got integer",
+                "$: unexpected property 'extra'",
+            ]
+        );
+        assert_eq!(
+            messages(&schema, json!([])),
+            vec!["$: expected object, got array"]
+        );
+    }
+
+    #[test]
+    fn validates_numbers_enums_and_array_bounds() {
+        let schema = schema(json!({
+            "type": "array",
+            "minItems": 1,
+            "maxItems": 3,
+            "uniqueItems": true,
+            "items": {"enum": [1, 2.5, "three"]},
+        }));
+        assert!(messages(&schema, json!([1, "three"])).is_empty());
+        assert_eq!(
+            messages(&schema, json!([])),
+            vec!["$: expected at least 1 items, got 0"]
+        );
+        assert_eq!(
+            messages(&schema, json!([1, 1, 4])),
+            vec![
+                "$[1]: duplicate item in array with 'uniqueItems'",
+                "$[2]: value is not one of [1,2.5,\"three\"]",
+            ]
+        );
+
+        let schema = self::schema(json!({
+            "type": ["number", "null"],
+            "exclusiveMinimum": 0,
+            "maximum": 10,
+            "multipleOf": 0.5,
+        }));
+        assert!(messages(&schema, json!(null)).is_empty());
+        assert!(messages(&schema, json!(2.5)).is_empty());
+        assert_eq!(
+            messages(&schema, json!(0)),
+            vec!["$: 0 is not greater than 0"]
+        );
+        assert_eq!(
+            messages(&schema, json!(10.25)),
+            vec![
+                "$: 10.25 is greater than 10",
+                "$: 10.25 is not a multiple of 0.5"
+            ]
+        );
+        assert_eq!(
+            messages(&schema, json!("5")),
+            vec!["$: expected number or null, got string"]
+        );
+    }
+
+    #[test]
+    fn resolves_local_refs_and_combinators() {
+        let schema = schema(json!({
+            "$defs": {
+                "id": {"type": "integer"},
+                "named": {"required": ["name"]},
+            },
+            "type": "object",
+            "properties": {
+                "id": {"$ref": "#/$defs/id"},
+                "kind": {"oneOf": [{"const": "a"}, {"const": "b"}]},
+                "value": {"anyOf": [{"type": "string"}, {"type": "boolean"}]},
+                "other": {"not": {"type": "null"}},
+            },
+            "allOf": [{"$ref": "#/$defs/named"}],
+        }));
+        assert!(messages(&schema, json!({"id": 1, "name": "x", "kind": "a"})).is_empty());
+        assert_eq!(
+            messages(
+                &schema,
+                json!({"id": 1.5, "kind": "c", "value": 1, "other": null})
+            ),
+            vec![
+                "$.id: expected integer, got number",
+                "$.kind: value matches 0 schemas in 'oneOf', expected exactly 1",
+                "$.value: value does not match any schema in 'anyOf'",
+                "$.other: value must not match the schema in 'not'",
+                "$: missing required property 'name'",
+            ]
+        );
+
+        let schema = self::schema(json!({"$ref": "#/missing"}));
+        assert_eq!(
+            messages(&schema, json!({})),
+            vec!["$: unresolvable schema reference '#/missing'"]
+        );
+    }
+
+    #[test]
+    fn pattern_properties_are_not_additional_properties() {
+        let schema = schema(json!({
+            "type": "object",
+            "properties": {"id": {"type": "integer"}},
+            "patternProperties": {"^x-": {"type": "string"}},
+            "propertyNames": {"maxLength": 5},
+            "additionalProperties": false,
+        }));
+        assert!(messages(&schema, json!({"id": 1, "x-a": "b"})).is_empty());
+        assert_eq!(
+            messages(&schema, json!({"x-a": 1, "others": true})),
+            vec![
+                "$[\"x-a\"]: expected string, got integer",
+                "$.others: expected at most 5 characters, got 6",
+                "$: unexpected property 'others'",
+            ]
+        );
+    }
+
+    #[test]
+    fn validates_patterns_formats_and_conditionals() {
+        let schema = schema(json!({
+            "properties": {
+                "code": {"pattern": "^[A-Z]{3}$"},
+                "when": {"format": "date-time"},
+                "ip": {"format": "ipv4"},
+                "id": {"format": "uuid"},
+            },
+            "dependentRequired": {"card": ["cvv"]},
+            "if": {"properties": {"kind": {"const": "a"}}, "required": ["kind"]},
+            "then": {"required": ["a"]},
+            "else": {"required": ["b"]},
+        }));
+        assert!(
+            messages(
+                &schema,
+                json!({
+                    "code": "ABC",
+                    "when": "2024-02-29T23:59:60.5+01:00",
+                    "ip": "10.0.0.1",
+                    "id": "123e4567-e89b-12d3-a456-426614174000",
+                    "kind": "a",
+                    "a": 1,
+                })
+            )
+            .is_empty()
+        );
+        assert_eq!(
+            messages(
+                &schema,
+                json!({
+                    "code": "abcd",
+                    "when": "2023-02-29T00:00:00Z",
+                    "ip": "10.0.0",
+                    "id": "not-a-uuid",
+                    "card": "4111",
+                })
+            ),
+            vec![
+                "$: property 'card' requires property 'cvv'",
+                "$.code: value does not match pattern '^[A-Z]{3}$'",
+                "$.when: value is not a valid 'date-time'",
+                "$.ip: value is not a valid 'ipv4'",
+                "$.id: value is not a valid 'uuid'",
+                "$: missing required property 'b'",
+            ]
+        );
+    }
+
+    #[test]
+    fn unsupported_keywords_formats_and_patterns_fail() {
+        let schema = schema(json!({
+            "unevaluatedProperties": false,
+            "properties": {
+                "a": {"format": "iri"},
+                "b": {"pattern": "(?=x)"},
+            },
+        }));
+        assert_eq!(
+            messages(&schema, json!({"a": "x", "b": "x"})),
+            vec![
+                "$: unsupported schema keyword 'unevaluatedProperties'",
+                "$.a: unsupported schema format 'iri'",
+                "$.b: unsupported schema pattern '(?=x)'",
+            ]
+        );
+    }
+
+    #[test]
+    fn contains_honors_min_and_max_contains() {
+        let schema = schema(json!({
+            "contains": {"type": "string"},
+            "minContains": 2,
+            "maxContains": 3,
+        }));
+        assert!(messages(&schema, json!(["a", 1, "b"])).is_empty());
+        assert_eq!(
+            messages(&schema, json!(["a", 1])),
+            vec!["$: expected at least 2 items matching 'contains', got 1"]
+        );
+        assert_eq!(
+            messages(&schema, json!(["a", "b", "c", "d"])),
+            vec!["$: expected at most 3 items matching 'contains', got 4"]
+        );
+    }
+
+    #[test]
+    fn recursive_refs_stop_at_depth_limit() {
+        let schema = schema(json!({"$ref": "#"}));
+        assert_eq!(
+            messages(&schema, json!(1)),
+            vec!["$: schema nesting is too deep"]
+        );
+    }
+
+    #[test]
+    fn violations_message_lists_paths_and_truncates() {
+        let schema = schema(json!({"items": {"type": "string"}}));
+        let violations = schema.validate(&json!([{"a b": 1}]));
+        assert_eq!(
+            schema.violations_message(&violations),
+            "response body does not match schema 'schema.json'\n  $[0]: expected string, got object"
+        );
+
+        let violations = schema.validate(&Value::Array(vec![json!(1); 25]));
+        let message = schema.violations_message(&violations);
+        assert_eq!(message.lines().count(), 22);
+        assert!(message.ends_with("\n  ... and 5 more"));
+
+        let violations = schema.validate_bytes(b"{not json");
+        assert_eq!(violations.len(), 1);
+        assert!(
+            violations[0]
+                .message
+                .starts_with("response body is not valid JSON: ")
+        );
+    }
+
+    #[test]
+    fn property_paths_quote_non_identifier_names() {
+        assert_eq!(property_path("$", "name"), "$.name");
+        assert_eq!(property_path("$", "a b"), "$[\"a b\"]");
+        assert_eq!(property_path("$", "1st"), "$[\"1st\"]");
+    }
+
+    #[test]
+    fn load_rejects_missing_and_invalid_schema_files() {
+        let dir = tempfile::TempDir::new().unwrap();
+        let missing = dir.path().join("missing.json");
+        let err = Schema::load(missing.to_str().unwrap())
+            .err()
+            .expect("missing schema file");
+        assert!(err.to_string().contains("does not exist"), "{err}");
+
+        let invalid = dir.path().join("invalid.json");
+        std::fs::write(&invalid, "[1, 2]").unwrap();
+        let err = Schema::load(invalid.to_str().unwrap())
+            .err()
+            .expect("array schema");
+        assert!(
+            err.to_string()
+                .ends_with("schema must be a JSON object or boolean"),
+            "{err}"
+        );
+    }
+}
@@ -143,6 +143,7 @@ pub(crate) fn validate_cli(cli: &Cli) -> Result<(), FetchError> {
         (cli.inspect_dns, "--inspect-dns"),
         (cli.inspect_tls, "--inspect-tls"),
         (cli.silent, "--silent"),
+        (cli.theme.is_some(), "--theme"),
         (cli.update, "--update"),
         (cli.verbose > 0, "--verbose"),
         (cli.version, "--version"),
@@ -135,7 +135,7 @@ async fn inspect_tcp(
     // Resolve ECH configuration from DNS.
     let ech_mode = resolve_inspect_ech_mode(cli, &host, timeout).await?;
 
-    let ca_certs = load_ca_certs(&cli.ca_cert)?;
+    let ca_certs = load_ca_certs(&super::ca_certificate_paths(&cli.ca_cert, &cli.ca_path)?)?;
     let native_roots = load_native_root_certs();
     let trusted_roots = trusted_root_certs(&ca_certs, &native_roots);
     let ocsp_capture = OcspCapture::default();
@@ -155,7 +155,7 @@ async fn inspect_tcp(
         .map_err(|_| FetchError::Message(format!("invalid server name '{host}'")))?;
     let stream = crate::net::connect_tcp_with_doh_tls(
         url,
-        cli.dns_server.as_deref(),
+        cli.dns_server().as_deref(),
         crate::http::client::doh_tls_config_for_cli(cli)?,
         timeout,
     )
@@ -179,25 +179,13 @@ async fn inspect_tcp(
         )));
     }
 
-    let mut peer_chain = Vec::new();
-    if let Some(certs) = conn.peer_certificates() {
-        peer_chain.extend(
-            certs
-                .iter()
-                .filter_map(|cert| ParsedCert::parse(cert.as_ref())),
-        );
-    }
-    let chain = certificate_chain_for_display(peer_chain, &trusted_roots, !cli.insecure);
+    let chain =
+        certificate_chain_for_display(tls_peer_certificates(conn), &trusted_roots, !cli.insecure);
 
     Ok(Inspection {
-        version: conn.protocol_version(),
-        cipher_suite: conn.negotiated_cipher_suite(),
-        alpn: conn
-            .alpn_protocol()
-            .map(|protocol| String::from_utf8_lossy(protocol).into_owned()),
-        ech_status,
         chain,
         ocsp_response: ocsp_capture.get(),
+        ..Inspection::from_tls_connection(conn)
     })
 }
 
@@ -229,8 +217,8 @@ async fn lookup_inspect_ech_candidates(
     host: &str,
     timeout: TimeoutBudget,
 ) -> Vec<Vec<u8>> {
-    let resolver = cli
-        .dns_server
+    let dns_server = cli.dns_server();
+    let resolver = dns_server
         .as_deref()
         .map(HttpsRecordResolver::Custom)
         .unwrap_or(HttpsRecordResolver::System);
@@ -274,7 +262,7 @@ async fn inspect_quic(
     let port = url.port_or_known_default().unwrap_or(443);
     let addrs = crate::net::resolve_host_with_doh_tls(
         &host,
-        cli.dns_server.as_deref(),
+        cli.dns_server().as_deref(),
         crate::http::client::doh_tls_config_for_cli(cli)?,
         timeout,
     )
@@ -287,7 +275,7 @@ async fn inspect_quic(
     .collect();
     let addrs = crate::net::interleave_socket_addrs(addrs)?;
 
-    let ca_certs = load_ca_certs(&cli.ca_cert)?;
+    let ca_certs = load_ca_certs(&super::ca_certificate_paths(&cli.ca_cert, &cli.ca_path)?)?;
     let native_roots = load_native_root_certs();
     let trusted_roots = trusted_root_certs(&ca_certs, &native_roots);
     // Each raced connection needs its own capture: a handshake completing after
@@ -416,6 +404,14 @@ fn quic_alpn(connection: &quinn::Connection) -> Option<String> {
         .map(|protocol| String::from_utf8_lossy(protocol).into_owned())
 }
 
+fn tls_peer_certificates(conn: &rustls::ClientConnection) -> Vec<ParsedCert> {
+    conn.peer_certificates()
+        .unwrap_or_default()
+        .iter()
+        .filter_map(|cert| ParsedCert::parse(cert.as_ref()))
+        .collect()
+}
+
 fn quic_peer_certificates(connection: &quinn::Connection) -> Vec<ParsedCert> {
     let Some(certs) = connection
         .peer_identity()
@@ -445,12 +441,8 @@ fn build_client_config(
     ocsp_capture: OcspCapture,
     ech_mode: Option<EchMode>,
 ) -> Result<rustls::ClientConfig, FetchError> {
-    super::install_default_crypto_provider();
-
-    let provider = rustls::crypto::CryptoProvider::get_default()
-        .cloned()
-        .unwrap_or_else(|| std::sync::Arc::new(rustls::crypto::aws_lc_rs::default_provider()));
-    let versions_builder = rustls::ClientConfig::builder_with_provider(provider);
+    let provider = super::crypto_provider(cli.ciphers.as_deref())?;
+    let versions_builder = rustls::ClientConfig::builder_with_provider(provider.clone());
     let versions = inspection_protocol_versions(cli)?;
     let builder = if let Some(ech_mode) = ech_mode {
         if !versions
@@ -472,10 +464,12 @@ fn build_client_config(
                 cli.max_tls.as_deref(),
             ));
         }
+        super::ensure_cipher_suites_for_versions(&provider, &[&rustls::version::TLS13])?;
         versions_builder
             .with_ech(ech_mode)
             .map_err(|err| FetchError::Message(format!("invalid ECH configuration: {err}")))?
     } else {
+        super::ensure_cipher_suites_for_versions(&provider, &versions)?;
         versions_builder
             .with_protocol_versions(&versions)
             .map_err(|_| FetchError::Message("invalid TLS versions".to_string()))?
@@ -498,9 +492,9 @@ fn build_client_config(
 
     if let Some((certs, key)) = super::rustls_client_auth(cli.cert.as_deref(), cli.key.as_deref())?
     {
-        builder
-            .with_client_auth_cert(certs, key)
-            .map_err(|err| FetchError::Message(err.to_string()))
+        builder.with_client_auth_cert(certs, key).map_err(|err| {
+            super::client_auth_cert_error(cli.cert.as_deref(), cli.key.as_deref(), err)
+        })
     } else {
         Ok(builder.with_no_client_auth())
     }
@@ -579,6 +573,54 @@ struct Inspection {
     ocsp_response: Vec<u8>,
 }
 
+impl Inspection {
+    /// The negotiated parameters of a TCP handshake, with the certificates
+    /// exactly as the server sent them and no stapled OCSP response.
+    fn from_tls_connection(conn: &rustls::ClientConnection) -> Self {
+        Self {
+            version: conn.protocol_version(),
+            cipher_suite: conn.negotiated_cipher_suite(),
+            alpn: conn
+                .alpn_protocol()
+                .map(|protocol| String::from_utf8_lossy(protocol).into_owned()),
+            ech_status: conn.ech_status(),
+            chain: tls_peer_certificates(conn),
+            ocsp_response: Vec::new(),
+        }
+    }
+}
+
+/// What the TLS handshake of a request's connection negotiated. At `-vv` it
+/// is printed in the same form as `--inspect-tls`.
+#[derive(Clone)]
+pub(crate) struct TlsSession(Inspection);
+
+impl TlsSession {
+    pub(crate) fn from_tls_connection(conn: &rustls::ClientConnection) -> Self {
+        Self(Inspection::from_tls_connection(conn))
+    }
+
+    /// QUIC always uses TLS 1.3, and quinn does not report the cipher suite.
+    pub(crate) fn from_quic_connection(connection: &quinn::Connection) -> Self {
+        Self(Inspection {
+            version: Some(ProtocolVersion::TLSv1_3),
+            cipher_suite: None,
+            alpn: quic_alpn(connection),
+            ech_status: EchStatus::NotOffered,
+            chain: quic_peer_certificates(connection),
+            ocsp_response: Vec::new(),
+        })
+    }
+
+    pub(crate) fn print(&self, color: Option<&str>) {
+        let mut printer = core::stdio().stderr_printer(color);
+        render_to(&self.0, &mut printer);
+        printer.write_info_prefix();
+        printer.push('\n');
+        let _ = printer.flush_to(&mut std::io::stderr());
+    }
+}
+
 #[derive(Debug)]
 struct CapturingServerVerifier {
     inner: Arc<dyn ServerCertVerifier>,
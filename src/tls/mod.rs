@@ -3,8 +3,9 @@ use std::sync::Arc;
 
 use rustls::client::EchMode;
 use rustls::client::danger::{HandshakeSignatureValid, ServerCertVerified, ServerCertVerifier};
+use rustls::crypto::CryptoProvider;
 use rustls::pki_types::{CertificateDer, PrivateKeyDer, ServerName, UnixTime};
-use rustls::{DigitallySignedStruct, SignatureScheme, SupportedProtocolVersion};
+use rustls::{CipherSuite, DigitallySignedStruct, SignatureScheme, SupportedProtocolVersion};
 
 use crate::error::FetchError;
 
@@ -49,6 +50,204 @@ pub fn install_default_crypto_provider() {
     let _ = rustls::crypto::aws_lc_rs::default_provider().install_default();
 }
 
+/// The cipher suites `--ciphers` accepts, by IANA name and then OpenSSL name.
+/// TLS 1.3 suites have the same name in both.
+const CIPHER_SUITE_NAMES: &[(CipherSuite, &str, &str)] = &[
+    (
+        CipherSuite::TLS13_AES_128_GCM_SHA256,
+        "TLS_AES_128_GCM_SHA256",
+        "TLS_AES_128_GCM_SHA256",
+    ),
+    (
+        CipherSuite::TLS13_AES_256_GCM_SHA384,
+        "TLS_AES_256_GCM_SHA384",
+        "TLS_AES_256_GCM_SHA384",
+    ),
+    (
+        CipherSuite::TLS13_CHACHA20_POLY1305_SHA256,
+        "TLS_CHACHA20_POLY1305_SHA256",
+        "TLS_CHACHA20_POLY1305_SHA256",
+    ),
+    (
+        CipherSuite::TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+        "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+        "ECDHE-ECDSA-AES128-GCM-SHA256",
+    ),
+    (
+        CipherSuite::TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+        "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+        "ECDHE-ECDSA-AES256-GCM-SHA384",
+    ),
+    (
+        CipherSuite::TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+        "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256",
+        "ECDHE-ECDSA-CHACHA20-POLY1305",
+    ),
+    (
+        CipherSuite::TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+        "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+        "ECDHE-RSA-AES128-GCM-SHA256",
+    ),
+    (
+        CipherSuite::TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+        "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+        "ECDHE-RSA-AES256-GCM-SHA384",
+    ),
+    (
+        CipherSuite::TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+        "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+        "ECDHE-RSA-CHACHA20-POLY1305",
+    ),
+];
+
+/// Parse a colon- or comma-separated `--ciphers` list, keeping its order of
+/// preference. Names match case-insensitively.
+pub(crate) fn cipher_suites(value: &str) -> Result<Vec<CipherSuite>, FetchError> {
+    let mut suites = Vec::new();
+    for name in value.split([':', ',']).map(str::trim) {
+        if name.is_empty() {
+            continue;
+        }
+        let Some((suite, _, _)) = CIPHER_SUITE_NAMES.iter().find(|(_, iana, openssl)| {
+            name.eq_ignore_ascii_case(iana) || name.eq_ignore_ascii_case(openssl)
+        }) else {
+            let supported = CIPHER_SUITE_NAMES
+                .iter()
+                .flat_map(|(_, iana, openssl)| {
+                    std::iter::once(*iana).chain((iana != openssl).then_some(*openssl))
+                })
+                .collect::<Vec<_>>()
+                .join(", ");
+            return Err(FetchError::invalid_value(
+                "--ciphers",
+                value,
+                format!("unknown cipher suite '{name}'; supported values: {supported}"),
+            ));
+        };
+        if !suites.contains(suite) {
+            suites.push(*suite);
+        }
+    }
+    if suites.is_empty() {
+        return Err(FetchError::invalid_value(
+            "--ciphers",
+            value,
+            "must list at least one cipher suite",
+        ));
+    }
+    Ok(suites)
+}
+
+fn is_tls13_suite(suite: CipherSuite) -> bool {
+    matches!(
+        suite,
+        CipherSuite::TLS13_AES_128_GCM_SHA256
+            | CipherSuite::TLS13_AES_256_GCM_SHA384
+            | CipherSuite::TLS13_CHACHA20_POLY1305_SHA256
+    )
+}
+
+/// Split a `--ciphers` list into curl's `--ciphers` list of TLS 1.2 suites and
+/// its `--tls13-ciphers` list, both colon-separated OpenSSL names. Either is
+/// empty when the list has no suite for that version.
+pub(crate) fn curl_cipher_lists(value: &str) -> Result<(String, String), FetchError> {
+    let (tls13, tls12): (Vec<_>, Vec<_>) = cipher_suites(value)?
+        .into_iter()
+        .partition(|suite| is_tls13_suite(*suite));
+    let names = |suites: Vec<CipherSuite>| {
+        suites
+            .into_iter()
+            .filter_map(|suite| {
+                CIPHER_SUITE_NAMES
+                    .iter()
+                    .find(|(known, _, _)| *known == suite)
+                    .map(|(_, _, openssl)| *openssl)
+            })
+            .collect::<Vec<_>>()
+            .join(":")
+    };
+    Ok((names(tls12), names(tls13)))
+}
+
+/// Build a `--ciphers` list from curl's `--ciphers` and `--tls13-ciphers`.
+/// curl applies each to one TLS version and leaves a version without a list
+/// unrestricted, so that version keeps every supported suite.
+pub(crate) fn cipher_list_from_curl(ciphers: &str, tls13_ciphers: &str) -> String {
+    let all = |tls13: bool| {
+        CIPHER_SUITE_NAMES
+            .iter()
+            .filter(|(suite, _, _)| is_tls13_suite(*suite) == tls13)
+            .map(|(_, _, openssl)| *openssl)
+            .collect::<Vec<_>>()
+            .join(":")
+    };
+    let tls13 = if tls13_ciphers.is_empty() {
+        all(true)
+    } else {
+        tls13_ciphers.to_string()
+    };
+    let tls12 = if ciphers.is_empty() {
+        all(false)
+    } else {
+        ciphers.to_string()
+    };
+    format!("{tls13}:{tls12}")
+}
+
+/// The process crypto provider, limited to the `--ciphers` suites when given.
+pub(crate) fn crypto_provider(ciphers: Option<&str>) -> Result<Arc<CryptoProvider>, FetchError> {
+    install_default_crypto_provider();
+
+    let provider = CryptoProvider::get_default()
+        .cloned()
+        .unwrap_or_else(|| Arc::new(rustls::crypto::aws_lc_rs::default_provider()));
+    let Some(ciphers) = ciphers else {
+        return Ok(provider);
+    };
+    let cipher_suites = cipher_suites(ciphers)?
+        .into_iter()
+        .filter_map(|suite| {
+            provider
+                .cipher_suites
+                .iter()
+                .find(|supported| supported.suite() == suite)
+                .copied()
+        })
+        .collect();
+    Ok(Arc::new(CryptoProvider {
+        cipher_suites,
+        ..(*provider).clone()
+    }))
+}
+
+/// Reject a `--ciphers` list that leaves no suite for any enabled version,
+/// which rustls would otherwise report as an opaque configuration error.
+pub(crate) fn ensure_cipher_suites_for_versions(
+    provider: &CryptoProvider,
+    versions: &[&'static SupportedProtocolVersion],
+) -> Result<(), FetchError> {
+    if provider.cipher_suites.iter().any(|suite| {
+        versions
+            .iter()
+            .any(|version| version.version == suite.version().version)
+    }) {
+        return Ok(());
+    }
+    let mut labels = versions
+        .iter()
+        .map(|version| match version.version {
+            rustls::ProtocolVersion::TLSv1_3 => "1.3",
+            _ => "1.2",
+        })
+        .collect::<Vec<_>>();
+    labels.sort_unstable();
+    Err(format!(
+        "--ciphers does not include a cipher suite for TLS {}",
+        labels.join(" or ")
+    )
+    .into())
+}
+
 pub fn default_min_tls_version() -> Version {
     Version::TLS_1_2
 }
@@ -114,9 +313,10 @@ pub fn rustls_protocol_versions(
 }
 
 pub fn rustls_platform_client_config() -> Result<rustls::ClientConfig, FetchError> {
-    rustls_platform_client_config_with_options(&[], None, None, false, None, None, None)
+    rustls_platform_client_config_with_options(&[], None, None, false, None, None, None, None)
 }
 
+#[allow(clippy::too_many_arguments)]
 pub fn rustls_platform_client_config_with_options(
     ca_cert_paths: &[String],
     cert_path: Option<&str>,
@@ -125,12 +325,9 @@ pub fn rustls_platform_client_config_with_options(
     min_tls: Option<(&str, &str)>,
     max_tls: Option<&str>,
     ech_mode: Option<EchMode>,
+    ciphers: Option<&str>,
 ) -> Result<rustls::ClientConfig, FetchError> {
-    install_default_crypto_provider();
-
-    let provider = rustls::crypto::CryptoProvider::get_default()
-        .cloned()
-        .unwrap_or_else(|| Arc::new(rustls::crypto::aws_lc_rs::default_provider()));
+    let provider = crypto_provider(ciphers)?;
     let versions_builder = rustls::ClientConfig::builder_with_provider(provider.clone());
     let versions = rustls_protocol_versions(min_tls, max_tls)?;
     let builder = if let Some(ech_mode) = ech_mode {
@@ -140,10 +337,12 @@ pub fn rustls_platform_client_config_with_options(
         {
             return Err(ech_tls_version_error(min_tls, max_tls));
         }
+        ensure_cipher_suites_for_versions(&provider, &[&rustls::version::TLS13])?;
         versions_builder
             .with_ech(ech_mode)
             .map_err(|err| FetchError::Message(format!("invalid ECH configuration: {err}")))?
     } else {
+        ensure_cipher_suites_for_versions(&provider, &versions)?;
         versions_builder
             .with_protocol_versions(&versions)
             .map_err(|_| FetchError::Message("invalid TLS versions".to_string()))?
@@ -168,7 +367,7 @@ pub fn rustls_platform_client_config_with_options(
     if let Some((certs, key)) = rustls_client_auth(cert_path, key_path)? {
         builder
             .with_client_auth_cert(certs, key)
-            .map_err(|err| FetchError::Message(err.to_string()))
+            .map_err(|err| client_auth_cert_error(cert_path, key_path, err))
     } else {
         Ok(builder.with_no_client_auth())
     }
@@ -254,6 +453,56 @@ pub fn ca_certificates(paths: &[String]) -> Result<Vec<Certificate>, FetchError>
     Ok(certs)
 }
 
+/// The `--ca-cert` files followed by the certificate files in each
+/// `--ca-path` directory, in name order.
+pub(crate) fn ca_certificate_paths(
+    files: &[String],
+    dirs: &[String],
+) -> Result<Vec<String>, FetchError> {
+    let mut paths = files.to_vec();
+    for dir in dirs {
+        paths.extend(ca_directory_files(dir)?);
+    }
+    Ok(paths)
+}
+
+/// List the `.pem`, `.crt`, and `.cer` files in a CA directory. Other files,
+/// such as the hashed links OpenSSL keeps next to them, are skipped.
+fn ca_directory_files(dir: &str) -> Result<Vec<String>, FetchError> {
+    let read_error = |err: std::io::Error| {
+        FetchError::Message(format!("unable to read CA directory '{dir}': {err}"))
+    };
+    let entries = match std::fs::read_dir(dir) {
+        Ok(entries) => entries,
+        Err(err) if err.kind() == std::io::ErrorKind::NotFound => {
+            return Err(format!("directory '{dir}' does not exist").into());
+        }
+        Err(err) => return Err(read_error(err)),
+    };
+    let mut files = Vec::new();
+    for entry in entries {
+        let path = entry.map_err(read_error)?.path();
+        let is_certificate = path
+            .extension()
+            .and_then(|ext| ext.to_str())
+            .is_some_and(|ext| {
+                ["pem", "crt", "cer"]
+                    .iter()
+                    .any(|known| ext.eq_ignore_ascii_case(known))
+            });
+        if is_certificate && path.is_file() {
+            files.push(path.to_string_lossy().into_owned());
+        }
+    }
+    if files.is_empty() {
+        return Err(
+            format!("invalid CA directory '{dir}': no .pem, .crt, or .cer files found").into(),
+        );
+    }
+    files.sort();
+    Ok(files)
+}
+
 pub fn validate_ca_certificate_file(path: &str) -> Result<(), FetchError> {
     let data = read_pem_file(path)?;
     if !has_certificate_block(&data) {
@@ -380,6 +629,31 @@ fn rustls_client_auth(
     )))
 }
 
+/// Explain a rustls client-certificate error, naming the files when the
+/// private key does not belong to the certificate.
+pub(crate) fn client_auth_cert_error(
+    cert_path: Option<&str>,
+    key_path: Option<&str>,
+    err: rustls::Error,
+) -> FetchError {
+    if !matches!(
+        err,
+        rustls::Error::InconsistentKeys(rustls::InconsistentKeys::KeyMismatch)
+    ) {
+        return FetchError::Message(err.to_string());
+    }
+    let cert_path = cert_path.unwrap_or_default();
+    match key_path {
+        Some(key_path) => {
+            format!("client certificate '{cert_path}' does not match key '{key_path}'").into()
+        }
+        None => format!(
+            "client certificate '{cert_path}' does not match the private key in the same file"
+        )
+        .into(),
+    }
+}
+
 fn pem_certificates(data: &[u8]) -> Result<Vec<Vec<u8>>, String> {
     let mut cursor = Cursor::new(data);
     let mut certs = Vec::new();
@@ -641,4 +915,127 @@ mod tests {
             "invalid value '1.1' for option '--max-tls': must be one of [1.2, 1.3]"
         );
     }
+
+    #[test]
+    fn cipher_suites_accept_iana_and_openssl_names() {
+        assert_eq!(
+            cipher_suites(
+                "ecdhe-rsa-aes128-gcm-sha256:TLS_AES_256_GCM_SHA384, ECDHE-RSA-AES128-GCM-SHA256"
+            )
+            .unwrap(),
+            [
+                CipherSuite::TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+                CipherSuite::TLS13_AES_256_GCM_SHA384,
+            ]
+        );
+
+        let err = cipher_suites("TLS_AES_128_GCM_SHA256:RC4-MD5").unwrap_err();
+        let err = err.to_string();
+        assert!(err.contains("unknown cipher suite 'RC4-MD5'"), "{err}");
+        assert!(err.contains("ECDHE-RSA-AES128-GCM-SHA256"), "{err}");
+
+        let err = cipher_suites(" : ").unwrap_err();
+        assert!(
+            err.to_string()
+                .contains("must list at least one cipher suite")
+        );
+    }
+
+    #[test]
+    fn curl_cipher_lists_split_suites_by_tls_version() {
+        assert_eq!(
+            curl_cipher_lists(
+                "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256:TLS_AES_256_GCM_SHA384:ECDHE-ECDSA-AES256-GCM-SHA384"
+            )
+            .unwrap(),
+            (
+                "ECDHE-RSA-AES128-GCM-SHA256:ECDHE-ECDSA-AES256-GCM-SHA384".to_string(),
+                "TLS_AES_256_GCM_SHA384".to_string()
+            )
+        );
+        assert_eq!(
+            curl_cipher_lists("ECDHE-RSA-AES128-GCM-SHA256").unwrap(),
+            ("ECDHE-RSA-AES128-GCM-SHA256".to_string(), String::new())
+        );
+    }
+
+    #[test]
+    fn cipher_list_from_curl_leaves_unlisted_versions_unrestricted() {
+        let list = cipher_list_from_curl("ECDHE-RSA-AES128-GCM-SHA256", "");
+        assert_eq!(
+            cipher_suites(&list).unwrap(),
+            [
+                CipherSuite::TLS13_AES_128_GCM_SHA256,
+                CipherSuite::TLS13_AES_256_GCM_SHA384,
+                CipherSuite::TLS13_CHACHA20_POLY1305_SHA256,
+                CipherSuite::TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+            ]
+        );
+
+        let list = cipher_list_from_curl("", "TLS_AES_128_GCM_SHA256");
+        let suites = cipher_suites(&list).unwrap();
+        assert_eq!(suites[0], CipherSuite::TLS13_AES_128_GCM_SHA256);
+        assert_eq!(suites.len(), 1 + CIPHER_SUITE_NAMES.len() - 3);
+        assert!(suites[1..].iter().all(|suite| !is_tls13_suite(*suite)));
+
+        assert_eq!(
+            cipher_list_from_curl("ECDHE-RSA-AES128-GCM-SHA256", "TLS_AES_128_GCM_SHA256"),
+            "TLS_AES_128_GCM_SHA256:ECDHE-RSA-AES128-GCM-SHA256"
+        );
+    }
+
+    #[test]
+    fn crypto_provider_limits_cipher_suites_to_usable_versions() {
+        let provider = crypto_provider(Some("TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384")).unwrap();
+        assert_eq!(provider.cipher_suites.len(), 1);
+        assert!(ensure_cipher_suites_for_versions(&provider, &[&rustls::version::TLS12]).is_ok());
+
+        let err =
+            ensure_cipher_suites_for_versions(&provider, &[&rustls::version::TLS13]).unwrap_err();
+        assert_eq!(
+            err.to_string(),
+            "--ciphers does not include a cipher suite for TLS 1.3"
+        );
+
+        let provider = crypto_provider(None).unwrap();
+        assert!(provider.cipher_suites.len() > 1);
+    }
+
+    #[test]
+    fn ca_certificate_paths_list_certificate_files_in_directories() {
+        let dir = tempfile::tempdir().unwrap();
+        for name in ["b.crt", "a.pem", "c.CER", "5a3f0b1c.0", "notes.txt"] {
+            std::fs::write(dir.path().join(name), b"").unwrap();
+        }
+        std::fs::create_dir(dir.path().join("nested.pem")).unwrap();
+        let dir_path = dir.path().to_string_lossy().into_owned();
+
+        let paths = ca_certificate_paths(&["extra.pem".to_string()], &[dir_path.clone()]).unwrap();
+        let names = paths
+            .iter()
+            .map(|path| {
+                std::path::Path::new(path)
+                    .file_name()
+                    .unwrap()
+                    .to_string_lossy()
+                    .into_owned()
+            })
+            .collect::<Vec<_>>();
+        assert_eq!(names, ["extra.pem", "a.pem", "b.crt", "c.CER"]);
+
+        let empty = tempfile::tempdir().unwrap();
+        let empty_path = empty.path().to_string_lossy().into_owned();
+        let err = ca_certificate_paths(&[], &[empty_path.clone()]).unwrap_err();
+        assert_eq!(
+            err.to_string(),
+            format!("invalid CA directory '{empty_path}': no .pem, .crt, or .cer files found")
+        );
+
+        let missing = format!("{dir_path}/missing");
+        let err = ca_certificate_paths(&[], &[missing.clone()]).unwrap_err();
+        assert_eq!(
+            err.to_string(),
+            format!("directory '{missing}' does not exist")
+        );
+    }
 }
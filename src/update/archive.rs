@@ -293,11 +293,11 @@ impl UpdateDownloadProgress {
         match self {
             Self::Bar { counter, printer } => {
                 counter.stop();
-                progress::clear_line(printer, 60);
+                progress::clear_line(printer, 80);
             }
             Self::Spinner { counter, printer } => {
                 counter.stop();
-                progress::clear_line(printer, 40);
+                progress::clear_line(printer, 45);
             }
             Self::None => {}
         }
@@ -244,7 +244,7 @@ fn sanitized_update_cli(cli: &Cli) -> Cli {
     sanitized.ca_cert.clone_from(&cli.ca_cert);
     sanitized.color.clone_from(&cli.color);
     sanitized.connect_timeout = cli.connect_timeout;
-    sanitized.dns_server.clone_from(&cli.dns_server);
+    sanitized.dns_servers.clone_from(&cli.dns_servers);
     sanitized.proxy.clone_from(&cli.proxy);
     sanitized.silent = cli.silent;
     sanitized.timeout = cli.timeout;
@@ -467,7 +467,7 @@ mod tests {
             sanitized.proxy.as_deref(),
             Some("http://proxy.example:8080")
         );
-        assert_eq!(sanitized.dns_server.as_deref(), Some("1.1.1.1"));
+        assert_eq!(sanitized.dns_servers, ["1.1.1.1"]);
         assert_eq!(sanitized.ca_cert, ["/tmp/enterprise-ca.pem"]);
         assert_eq!(sanitized.connect_timeout, Some(2.0));
         assert_eq!(sanitized.timeout, Some(5.0));
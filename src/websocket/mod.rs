@@ -29,7 +29,7 @@ use url::Url;
 use crate::auth::aws_sigv4;
 use crate::cli::Cli;
 use crate::core;
-use crate::duration::{TimeoutBudget, duration_from_seconds};
+use crate::duration::{TimeoutBudget, duration_from_seconds, find_timeout_message};
 use crate::error::{
     FetchError, write_warning_with_color, write_warnings_with_separator_with_color,
 };
@@ -77,7 +77,7 @@ pub async fn execute(cli: &Cli) -> Result<i32, FetchError> {
     let interactive = should_use_interactive(cli)?;
 
     let request = build_handshake_request(cli, &url, session.as_ref())?;
-    if cli.dry_run {
+    if cli.dry_run || cli.trace_headers_only {
         let _connector = websocket_connector(cli, &url, None)?;
         print_request_metadata(cli, method, &url, Some(request.headers()));
         return Ok(0);
@@ -177,13 +177,14 @@ fn websocket_connector(
         }
     });
     let config = crate::tls::rustls_platform_client_config_with_options(
-        &cli.ca_cert,
+        &crate::tls::ca_certificate_paths(&cli.ca_cert, &cli.ca_path)?,
         cli.cert.as_deref(),
         cli.key.as_deref(),
         cli.insecure,
         min_tls,
         cli.max_tls.as_deref(),
         ech_mode,
+        cli.ciphers.as_deref(),
     )?;
     Ok(Some(Connector::Rustls(Arc::new(config))))
 }
@@ -224,10 +225,15 @@ async fn dial_websocket(
     url: &Url,
     timeout: TimeoutBudget,
 ) -> Result<DialStream, FetchError> {
+    // An explicit proxy still honors NO_PROXY, as it does for HTTP requests.
+    let proxy = cli
+        .proxy
+        .as_deref()
+        .filter(|_| !crate::http::transport::NoProxy::from_env().matches(url));
     Box::pin(crate::net::dial_url(
         url,
-        cli.proxy.as_deref(),
-        cli.dns_server.as_deref(),
+        proxy,
+        cli.dns_server().as_deref(),
         websocket_doh_tls_config(cli)?,
         timeout,
     ))
@@ -530,9 +536,9 @@ fn handshake_headers(
         );
     }
     if let Some(value) = cli.aws_sigv4.as_deref() {
-        let config =
-            aws_sigv4::parse_config(value).map_err(|err| FetchError::Message(err.to_string()))?;
         let sign_url = websocket_signing_url(url)?;
+        let config = aws_sigv4::config_for_url(value, &sign_url)
+            .map_err(|err| FetchError::Message(err.to_string()))?;
         aws_sigv4::sign(
             "GET",
             &sign_url,
@@ -828,7 +834,7 @@ fn websocket_error(err: WsError) -> FetchError {
     if websocket_certificate_validation_error(&err, &message) {
         return FetchError::CertificateValidation(message);
     }
-    if let Some(start) = message.find("request timed out after ") {
+    if let Some(start) = find_timeout_message(&message) {
         return FetchError::Runtime(message[start..].to_string());
     }
     match err {
@@ -1169,7 +1175,8 @@ mod tests {
         file.set_len(WEBSOCKET_MAX_MESSAGE_BYTES as u64 + 1)
             .unwrap();
         let body = format!("@{}", path.display());
-        let cli = Cli::try_parse_from(["fetch", "-d", &body, "ws://example.com"]).unwrap();
+        let mut cli = Cli::try_parse_from(["fetch", "-d", &body, "ws://example.com"]).unwrap();
+        crate::app::apply_data_values(&mut cli).unwrap();
 
         let err = websocket_initial_message(&cli).unwrap_err();
 
@@ -295,6 +295,121 @@ fn dry_run_prints_effective_request_without_network() {
     assert!(res.stderr.contains("POST / HTTP/1.1\n"));
 }
 
+#[test]
+fn trace_headers_only_prints_headers_without_body() {
+    let res = run_fetch(&[
+        "-j",
+        r#"{"key":"val1"}"#,
+        "--bearer",
+        "token",
+        "localhost:3000",
+        "--trace-headers-only",
+    ]);
+    assert_exit(&res, 0);
+    assert!(res.stdout.is_empty());
+    assert!(res.stderr.contains("POST / HTTP/1.1\n"));
+    assert!(res.stderr.contains("authorization: Bearer token\n"));
+    assert!(res.stderr.contains("content-length: 14\n"));
+    assert!(res.stderr.contains("content-type: application/json\n"));
+    assert!(res.stderr.contains("host: localhost:3000\n"));
+    assert!(!res.stderr.contains("url: "));
+    assert!(!res.stderr.contains("{\"key\":\"val1\"}"));
+
+    let res = run_fetch(&["localhost:3000", "--trace-headers-only", "--dry-run"]);
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("cannot be used together"));
+}
+
+#[test]
+fn curl_prints_equivalent_command_without_network() {
+    let server = TestServer::start(|_| TestResponse::ok("unexpected"));
+    let res = run_fetch(&[
+        "--curl",
+        "-m",
+        "PUT",
+        "-j",
+        r#"{"name":"it's"}"#,
+        "--bearer",
+        "token",
+        "--timeout",
+        "5",
+        &format!("{}/items/1", server.url),
+    ]);
+    assert_exit(&res, 0);
+    assert!(res.stderr.is_empty(), "{}", res.stderr);
+    let command = res.stdout.trim_end();
+    assert!(
+        command.starts_with("curl -X PUT -H 'user-agent: fetch/"),
+        "{command}"
+    );
+    assert!(command.contains(" -H 'accept: application/json, */*;q=0.5' "));
+    assert!(command.contains(" --compressed "));
+    assert!(!command.contains("accept-encoding"));
+    assert!(command.contains(" -H 'content-type: application/json' "));
+    assert!(command.contains(" -H 'authorization: Bearer token' "));
+    assert!(command.contains(r#" --data-raw '{"name":"it'\''s"}' "#));
+    assert!(command.ends_with(&format!(
+        " -L --max-redirs 10 --max-time 5 {}/items/1",
+        server.url
+    )));
+
+    let dir = TempDir::new().unwrap();
+    let body = dir.path().join("body.txt");
+    fs::write(&body, "hello").unwrap();
+    let res = run_fetch(&[
+        "--curl",
+        "--redirects",
+        "0",
+        "-d",
+        &format!("@{}", body.display()),
+        &server.url,
+    ]);
+    assert_exit(&res, 0);
+    assert!(
+        res.stdout.contains(&format!(
+            " --data-binary @{} {}",
+            body.display(),
+            server.url
+        )),
+        "{}",
+        res.stdout
+    );
+    assert!(!res.stdout.contains(" -X "));
+    assert!(!res.stdout.contains(" -L "));
+
+    let res = run_fetch(&["--curl", "-H", "Accept-Encoding: identity", &server.url]);
+    assert_exit(&res, 0);
+    assert!(
+        res.stdout.contains(" -H 'accept-encoding: identity' "),
+        "{}",
+        res.stdout
+    );
+    assert!(!res.stdout.contains("--compressed"), "{}", res.stdout);
+
+    let res = run_fetch(&[
+        "--curl",
+        "--from-curl",
+        &format!("curl -X DELETE {}/items/1", server.url),
+    ]);
+    assert_exit(&res, 0);
+    assert!(res.stdout.starts_with("curl -X DELETE "), "{}", res.stdout);
+    assert!(server.requests().is_empty());
+
+    let res = run_fetch(&["--curl", "--h2-max-frame-size", "65536", &server.url]);
+    assert_exit(&res, 0);
+    assert!(res.stdout.starts_with("curl "), "{}", res.stdout);
+    assert!(
+        res.stderr
+            .contains("the curl command does not include --h2-max-frame-size"),
+        "{}",
+        res.stderr
+    );
+
+    let res = run_fetch(&[&server.url, "--curl", "--dry-run"]);
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("cannot be used together"));
+}
+
 #[test]
 fn dry_run_truncates_large_file_body_preview() {
     let dir = TempDir::new().unwrap();
@@ -393,6 +508,49 @@ fn default_scheme_loopback_and_presentation_flags() {
     assert_eq!(res.stdout, r#"{"ok":"yes"}"#);
 }
 
+#[test]
+fn theme_remaps_output_colors() {
+    let server = TestServer::start(|_| {
+        TestResponse::ok(r#"{"ok":"yes"}"#).header("Content-Type", "application/json")
+    });
+    let args = [server.url.as_str(), "--format", "on", "--color", "on"];
+
+    let res = run_fetch(&args);
+    assert_exit(&res, 0);
+    assert!(
+        res.stdout.contains("\x1b[34m\x1b[1m\"ok\"\x1b[0m"),
+        "{:?}",
+        res.stdout
+    );
+
+    let res = run_fetch(&[&args[..], &["--theme", "monochrome"][..]].concat());
+    assert_exit(&res, 0);
+    assert!(
+        res.stdout.contains("\x1b[1m\"ok\"\x1b[0m"),
+        "{:?}",
+        res.stdout
+    );
+    assert!(!res.stdout.contains("\x1b[3"), "{:?}", res.stdout);
+
+    let res = run_fetch(&[&args[..], &["--theme", "solarized"][..]].concat());
+    assert_exit(&res, 0);
+    assert!(
+        res.stdout
+            .contains("\x1b[38;2;38;139;210m\x1b[1m\"ok\"\x1b[0m"),
+        "{:?}",
+        res.stdout
+    );
+
+    let res = run_fetch(&[&args[..], &["--theme", "neon"][..]].concat());
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("invalid value 'neon' for option '--theme'"),
+        "{}",
+        res.stderr
+    );
+}
+
 #[test]
 fn config_error_and_metadata_edges() {
     let dir = TempDir::new().unwrap();
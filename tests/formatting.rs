@@ -30,6 +30,8 @@ fn response_formatting_json_ndjson_xml_yaml_html_csv_css_and_sniffing() {
         "/html" => TestResponse::ok("<html><body><h1>Hello</h1></body></html>")
             .header("Content-Type", "text/html"),
         "/csv" => TestResponse::ok("name,value\none,1\n").header("Content-Type", "text/csv"),
+        "/tsv" => TestResponse::ok("name\tvalue\none\t\"a\nb\"\n")
+            .header("Content-Type", "text/tab-separated-values"),
         "/css" => TestResponse::ok("body{color:red}").header("Content-Type", "text/css"),
         "/sse" => TestResponse::ok("data: {\"one\":1}\n\nevent: done\ndata: two\n\n")
             .header("Content-Type", "text/event-stream"),
@@ -49,6 +51,7 @@ fn response_formatting_json_ndjson_xml_yaml_html_csv_css_and_sniffing() {
             "<html>\n  <body>\n    <h1>Hello</h1>\n  </body>\n</html>\n",
         ),
         ("/csv", "name  value\none   1\n"),
+        ("/tsv", "name  value\none   a↵b\n"),
         ("/css", "body {\n  color: red;\n}\n"),
         (
             "/sse",
@@ -64,6 +67,61 @@ fn response_formatting_json_ndjson_xml_yaml_html_csv_css_and_sniffing() {
         assert_exit(&res, 0);
         assert_eq!(res.stdout, expected, "path {path}");
     }
+
+    let cases = [
+        (
+            "/json",
+            "Format: json (content type 'application/json'; buffered)",
+        ),
+        ("/sniff-json", "Format: json (sniffed from body"),
+        (
+            "/sse",
+            "Format: sse (content type 'text/event-stream'; streaming)",
+        ),
+    ];
+    for (path, expected) in cases {
+        let res = run_fetch(&[&format!("{}{}", server.url, path), "--format", "on", "-vvv"]);
+        assert_exit(&res, 0);
+        assert!(res.stderr.contains(expected), "stderr: {}", res.stderr);
+    }
+}
+
+#[test]
+fn toml_responses_are_highlighted_and_sniffed() {
+    let body = "[server]\nport = 8080 # default\n";
+    let server = TestServer::start(move |req| match req.path.as_str() {
+        "/toml" => TestResponse::ok(body).header("Content-Type", "application/toml"),
+        _ => TestResponse::ok(body),
+    });
+
+    let res = run_fetch(&[&format!("{}/toml", server.url), "--format", "on"]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, body);
+
+    let res = run_fetch(&[
+        &format!("{}/toml", server.url),
+        "--format",
+        "on",
+        "--color",
+        "on",
+    ]);
+    assert_exit(&res, 0);
+    for want in [
+        "\x1b[36m\x1b[1m[server]\x1b[0m",
+        "\x1b[34m\x1b[1mport\x1b[0m = \x1b[33m8080\x1b[0m",
+        "\x1b[2m# default\x1b[0m",
+    ] {
+        assert!(res.stdout.contains(want), "stdout: {:?}", res.stdout);
+    }
+
+    let res = run_fetch(&[&format!("{}/sniff", server.url), "--format", "on", "-vvv"]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, body);
+    assert!(
+        res.stderr.contains("Format: toml (sniffed from body"),
+        "stderr: {}",
+        res.stderr
+    );
 }
 
 #[test]
@@ -167,6 +225,452 @@ fn article_mode_writes_transformed_output_file_and_rejects_non_html() {
     );
 }
 
+#[test]
+fn schema_validates_json_responses_and_reports_violations() {
+    let server = TestServer::start(|req| match req.path.as_str() {
+        "/valid" => {
+            TestResponse::ok(r#"{"id":1,"name":"one"}"#).header("Content-Type", "application/json")
+        }
+        "/invalid" => TestResponse::ok(r#"{"id":"1","extra":true}"#)
+            .header("Content-Type", "application/json"),
+        "/text" => TestResponse::ok("not json").header("Content-Type", "text/plain"),
+        _ => TestResponse::status(404, "Not Found", r#"{"error":"missing"}"#)
+            .header("Content-Type", "application/json"),
+    });
+    let dir = TempDir::new().unwrap();
+    let schema = dir.path().join("schema.json");
+    fs::write(
+        &schema,
+        r#"{
+            "type": "object",
+            "required": ["id", "name"],
+            "properties": {"id": {"type": "integer"}, "name": {"type": "string"}},
+            "additionalProperties": false
+        }"#,
+    )
+    .unwrap();
+    let schema = schema.to_str().unwrap();
+
+    let res = run_fetch(&[&format!("{}/valid", server.url), "--schema", schema]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, r#"{"id":1,"name":"one"}"#);
+    assert!(res.stderr.is_empty(), "{}", res.stderr);
+
+    let res = run_fetch(&[&format!("{}/invalid", server.url), "--schema", schema]);
+    assert_exit(&res, 7);
+    assert_eq!(res.stdout, r#"{"id":"1","extra":true}"#);
+    assert!(
+        res.stderr.contains(&format!(
+            "error: response body does not match schema '{schema}'"
+        )),
+        "{}",
+        res.stderr
+    );
+    assert!(res.stderr.contains("  $: missing required property 'name'"));
+    assert!(res.stderr.contains("  $.id: expected integer, got string"));
+    assert!(res.stderr.contains("  $: unexpected property 'extra'"));
+
+    let res = run_fetch(&[
+        &format!("{}/invalid", server.url),
+        "--schema",
+        schema,
+        "--silent",
+    ]);
+    assert_exit(&res, 7);
+    assert!(res.stderr.is_empty(), "{}", res.stderr);
+
+    let res = run_fetch(&[
+        &format!("{}/invalid", server.url),
+        "--schema",
+        schema,
+        "--body-only",
+        "-v",
+    ]);
+    assert_exit(&res, 7);
+    assert_eq!(res.stdout, r#"{"id":"1","extra":true}"#);
+    assert!(
+        res.stderr.contains(&format!(
+            "error: response body does not match schema '{schema}'"
+        )),
+        "{}",
+        res.stderr
+    );
+    assert!(!res.stderr.contains("200 OK"), "{}", res.stderr);
+
+    let res = run_fetch(&[
+        "--from-curl",
+        &format!("curl {}/invalid", server.url),
+        "--schema",
+        schema,
+    ]);
+    assert_exit(&res, 7);
+    assert!(
+        res.stderr.contains("does not match schema"),
+        "{}",
+        res.stderr
+    );
+
+    let res = run_fetch(&[&format!("{}/text", server.url), "--schema", schema]);
+    assert_exit(&res, 7);
+    assert!(res.stderr.contains("$: response body is not valid JSON"));
+
+    let res = run_fetch(&[&format!("{}/missing", server.url), "--schema", schema]);
+    assert_exit(&res, 4);
+    assert!(!res.stderr.contains("does not match schema"));
+
+    let res = run_fetch(&[
+        &format!("{}/valid", server.url),
+        "--schema",
+        dir.path().join("nope.json").to_str().unwrap(),
+    ]);
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("does not exist"), "{}", res.stderr);
+}
+
+#[test]
+fn extract_prints_raw_json_values() {
+    let server = TestServer::start(|req| match req.path.as_str() {
+        "/token" => TestResponse::ok(
+            r#"{"access_token":"abc\"123","expires":3600,"users":[{"name":"a"},{"name":"b"}]}"#,
+        )
+        .header("Content-Type", "application/json"),
+        "/text" => TestResponse::ok("not json").header("Content-Type", "text/plain"),
+        _ => TestResponse::status(404, "Not Found", r#"{"error":"missing"}"#)
+            .header("Content-Type", "application/json"),
+    });
+    let token_url = format!("{}/token", server.url);
+
+    let cases = [
+        ("access_token", "abc\"123\n"),
+        ("$.expires", "3600\n"),
+        ("users[-1]", "{\"name\":\"b\"}\n"),
+    ];
+    for (path, expected) in cases {
+        let res = run_fetch(&[&token_url, "--extract", path, "--format", "on"]);
+        assert_exit(&res, 0);
+        assert_eq!(res.stdout, expected, "path {path}");
+    }
+
+    let res = run_fetch(&[&token_url, "--extract", "users[*].name", "--extract-all"]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "a\nb\n");
+
+    let res = run_fetch(&[&token_url, "--extract", "users[*].name"]);
+    assert_exit(&res, 1);
+    assert!(res.stdout.is_empty());
+    assert!(
+        res.stderr
+            .contains("extract path 'users[*].name' matches 2 values; use '--extract-all'"),
+        "{}",
+        res.stderr
+    );
+
+    let res = run_fetch(&[&token_url, "--extract", "missing"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("no value matches extract path 'missing'")
+    );
+
+    let res = run_fetch(&[&format!("{}/text", server.url), "--extract", "a"]);
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("response body is not valid JSON"));
+
+    let res = run_fetch(&[&format!("{}/missing", server.url), "--extract", "id"]);
+    assert_exit(&res, 4);
+    assert_eq!(res.stdout, r#"{"error":"missing"}"#);
+
+    let res = run_fetch(&[&token_url, "--extract", "a[x]"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("invalid value 'a[x]' for option '--extract': invalid array index 'x'"),
+        "{}",
+        res.stderr
+    );
+    assert_eq!(server.requests().len(), 8);
+}
+
+#[test]
+fn split_output_writes_each_array_element_to_a_file() {
+    let server = TestServer::start(|_| {
+        TestResponse::ok(r#"{"items":[{"id":1},{"id":2,"tags":["a"]}]}"#)
+            .header("Content-Type", "application/json")
+    });
+    let dir = TempDir::new().unwrap();
+    let pattern = dir.path().join("item.json");
+
+    let res = run_fetch(&[
+        &server.url,
+        "--extract",
+        "items",
+        "--split-output",
+        pattern.to_str().unwrap(),
+    ]);
+    assert_exit(&res, 0);
+    assert!(res.stdout.is_empty(), "stdout: {}", res.stdout);
+    assert_eq!(
+        std::fs::read_to_string(dir.path().join("item-0.json")).unwrap(),
+        "{\n  \"id\": 1\n}\n"
+    );
+    assert_eq!(
+        std::fs::read_to_string(dir.path().join("item-1.json")).unwrap(),
+        "{\n  \"id\": 2,\n  \"tags\": [\n    \"a\"\n  ]\n}\n"
+    );
+
+    let res = run_fetch(&[
+        &server.url,
+        "--extract",
+        "items",
+        "--split-output",
+        pattern.to_str().unwrap(),
+    ]);
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("already exists"), "{}", res.stderr);
+
+    let res = run_fetch(&[
+        &server.url,
+        "--split-output",
+        dir.path().join("{n}.json").to_str().unwrap(),
+    ]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("cannot split output: response body is not a JSON array"),
+        "{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn to_json_converts_xml_responses() {
+    let server = TestServer::start(|req| match req.path.as_str() {
+        "/xml" => TestResponse::ok(r#"<feed version="2"><item>a</item><item>b</item></feed>"#)
+            .header("Content-Type", "application/xml"),
+        "/json" => TestResponse::ok(r#"{"ok":true}"#).header("Content-Type", "application/json"),
+        _ => TestResponse::ok("plain").header("Content-Type", "text/plain"),
+    });
+    let xml_url = format!("{}/xml", server.url);
+
+    let res = run_fetch(&[&xml_url, "--to-json", "--format", "on"]);
+    assert_exit(&res, 0);
+    assert_eq!(
+        res.stdout,
+        "{\n  \"feed\": {\n    \"@version\": \"2\",\n    \"item\": [\n      \"a\",\n      \"b\"\n    ]\n  }\n}\n"
+    );
+
+    let res = run_fetch(&[&xml_url, "--to-json"]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, r#"{"feed":{"@version":"2","item":["a","b"]}}"#);
+
+    let res = run_fetch(&[&xml_url, "--to-json", "--extract", "feed.item[1]"]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "b\n");
+
+    let res = run_fetch(&[&format!("{}/json", server.url), "--to-json"]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, r#"{"ok":true}"#);
+
+    let res = run_fetch(&[&format!("{}/plain", server.url), "--to-json"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("response content type 'text/plain' cannot be converted with '--to-json'"),
+        "{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn to_yaml_and_to_json_convert_between_json_and_yaml() {
+    let server = TestServer::start(|req| match req.path.as_str() {
+        "/json" => TestResponse::ok(r#"{"name":"fetch","tags":["cli","http"],"meta":{"ok":true}}"#)
+            .header("Content-Type", "application/json"),
+        "/yaml" => TestResponse::ok("name: fetch\ntags: [cli, http]\ncount: 2\n")
+            .header("Content-Type", "application/yaml"),
+        _ => TestResponse::ok("a,b\n").header("Content-Type", "text/csv"),
+    });
+
+    let res = run_fetch(&[&format!("{}/json", server.url), "--to-yaml"]);
+    assert_exit(&res, 0);
+    assert_eq!(
+        res.stdout,
+        "name: fetch\ntags:\n  - cli\n  - http\nmeta:\n  ok: true\n"
+    );
+
+    let res = run_fetch(&[&format!("{}/yaml", server.url), "--to-json"]);
+    assert_exit(&res, 0);
+    assert_eq!(
+        res.stdout,
+        r#"{"name":"fetch","tags":["cli","http"],"count":2}"#
+    );
+
+    let res = run_fetch(&[&format!("{}/csv", server.url), "--to-yaml"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("response content type 'text/csv' cannot be converted with '--to-yaml'"),
+        "{}",
+        res.stderr
+    );
+
+    let res = run_fetch(&[&format!("{}/json", server.url), "--to-yaml", "--to-json"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("cannot be used together"),
+        "{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn decode_jwt_prints_tokens_after_formatted_body() {
+    // {"alg":"none"}.{"sub":"42"}
+    let token = "eyJhbGciOiJub25lIn0.eyJzdWIiOiI0MiJ9.c2ln";
+    let body = format!(r#"{{"id_token":"{token}","other":"eyJhbGciOi.bad.sig"}}"#);
+    let server = TestServer::start(move |_| {
+        TestResponse::ok(body.clone()).header("Content-Type", "application/json")
+    });
+
+    let res = run_fetch(&[&server.url, "--format", "on", "--decode-jwt"]);
+    assert_exit(&res, 0);
+    assert_eq!(
+        res.stdout,
+        format!(
+            "{{\n  \"id_token\": \"{token}\",\n  \"other\": \"eyJhbGciOi.bad.sig\"\n}}\n\n\
+             JWT header:\n{{\n  \"alg\": \"none\"\n}}\n\
+             JWT payload:\n{{\n  \"sub\": \"42\"\n}}\n\
+             JWT signature: c2ln\n"
+        )
+    );
+
+    let res = run_fetch(&[&server.url, "--format", "on"]);
+    assert_exit(&res, 0);
+    assert!(!res.stdout.contains("JWT"), "stdout: {}", res.stdout);
+
+    let res = run_fetch(&[
+        &server.url,
+        "--dry-run",
+        "--decode-jwt",
+        "-H",
+        &format!("Authorization: Bearer {token}"),
+    ]);
+    assert_exit(&res, 0);
+    assert!(
+        res.stderr.contains(&format!(
+            "authorization: Bearer {token}\n  JWT header:\n  {{\n    \"alg\": \"none\"\n  }}\n"
+        )),
+        "stderr: {}",
+        res.stderr
+    );
+}
+
+#[test]
+fn line_numbers_prefix_formatted_output_lines() {
+    let server = TestServer::start(|_| {
+        TestResponse::ok(r#"{"a":1,"b":[1,2,3,4,5,6,7]}"#)
+            .header("Content-Type", "application/json")
+    });
+
+    let res = run_fetch(&[&server.url, "--format", "on", "--line-numbers"]);
+    assert_exit(&res, 0);
+    let lines: Vec<&str> = res.stdout.lines().collect();
+    assert_eq!(lines.len(), 12, "stdout: {}", res.stdout);
+    assert_eq!(lines[0], " 1 │ {");
+    assert_eq!(lines[1], " 2 │   \"a\": 1,");
+    assert_eq!(lines[11], "12 │ }");
+
+    let res = run_fetch(&[&server.url, "--format", "off", "--line-numbers"]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, r#"{"a":1,"b":[1,2,3,4,5,6,7]}"#);
+}
+
+#[test]
+fn hex_prints_response_bodies_as_a_hexdump() {
+    let body: Vec<u8> = b"fetch\x00\x01\x02 hexdump\xff\xfe\n"
+        .iter()
+        .copied()
+        .cycle()
+        .take(40)
+        .collect();
+    let server = TestServer::start(move |_| {
+        TestResponse::ok(body.clone()).header("Content-Type", "application/octet-stream")
+    });
+
+    let res = run_fetch(&[&server.url, "--hex"]);
+    assert_exit(&res, 0);
+    assert_eq!(
+        res.stdout,
+        "00000000  66 65 74 63 68 00 01 02  20 68 65 78 64 75 6d 70  |fetch... hexdump|\n\
+         00000010  ff fe 0a 66 65 74 63 68  00 01 02 20 68 65 78 64  |...fetch... hexd|\n\
+         00000020  75 6d 70 ff fe 0a 66 65                           |ump...fe|\n"
+    );
+
+    let res = run_fetch(&[&server.url, "--hex", "-vvv"]);
+    assert_exit(&res, 0);
+    assert!(
+        res.stderr.contains("Format: hexdump (--hex; streaming)"),
+        "stderr: {}",
+        res.stderr
+    );
+
+    let res = run_fetch(&[&server.url, "--hex", "-o", "body.bin"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("cannot be used together"),
+        "{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn minify_strips_whitespace_from_html_and_css_responses() {
+    let server = TestServer::start(|req| {
+        match req.path.as_str() {
+        "/html" => TestResponse::ok(
+            "<html>\n  <body>\n    <!-- nav -->\n    <p>Hello,   <b>world</b></p>\n  </body>\n</html>\n",
+        )
+        .header("Content-Type", "text/html"),
+        "/css" => TestResponse::ok("body {\n  margin: 0;\n}\n\n/* links */\na:hover { color: red; }\n")
+            .header("Content-Type", "text/css"),
+        _ => TestResponse::ok(r#"{"ok":true}"#).header("Content-Type", "application/json"),
+    }
+    });
+
+    let res = run_fetch(&[
+        &format!("{}/html", server.url),
+        "--minify",
+        "--format",
+        "on",
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(
+        res.stdout,
+        "<html><body><p>Hello, <b>world</b></p></body></html>"
+    );
+
+    let res = run_fetch(&[&format!("{}/css", server.url), "--minify"]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "body{margin:0}a:hover{color:red}");
+
+    let res = run_fetch(&[
+        &format!("{}/json", server.url),
+        "--minify",
+        "--format",
+        "on",
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "{\n  \"ok\": true\n}\n");
+
+    let res = run_fetch(&[&format!("{}/css", server.url), "--minify", "--to-json"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("cannot be used together"),
+        "{}",
+        res.stderr
+    );
+}
+
 #[cfg(unix)]
 #[test]
 fn formatted_sse_outputs_events_before_stream_ends() {
@@ -659,6 +1163,12 @@ fn additional_formatting_sse_charset_image_and_compression_cases() {
     assert_exit(&res, 0);
     assert_eq!(res.stdout, "this is the test data");
     assert!(res.stderr.contains("gzip"));
+    assert!(
+        res.stderr.contains("compressed transfer (gzip): ")
+            && res.stderr.contains("received, 21B decoded"),
+        "stderr: {}",
+        res.stderr
+    );
     let res = run_fetch(&[&format!("{}/sse", compressed.url), "--format", "on"]);
     assert_exit(&res, 0);
     assert_eq!(res.stdout, "event: message\ndata: uncompressed\n\n");
@@ -685,10 +1195,25 @@ fn additional_formatting_sse_charset_image_and_compression_cases() {
     assert_exit(&res, 0);
     assert!(res.stdout.is_empty());
     assert!(res.stderr.contains("200 OK"));
-    let res = run_fetch(&[&format!("{}/too-large", compressed.url), "--format", "on"]);
+    assert!(!res.stderr.contains("compressed transfer"));
+    let res = run_fetch(&[
+        &format!("{}/too-large", compressed.url),
+        "--format",
+        "on",
+        "--max-decompress-ratio",
+        "0",
+    ]);
     assert_exit(&res, 1);
     assert!(res.stdout.is_empty());
     assert!(res.stderr.contains("cannot be buffered"));
+    let res = run_fetch(&[&format!("{}/too-large", compressed.url), "-o", "-"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("response body expanded more than 100x while decoding"),
+        "stderr:\n{}",
+        res.stderr
+    );
     let res = run_fetch(&[&format!("{}/zstd", compressed.url), "-v"]);
     assert_exit(&res, 0);
     assert_eq!(res.stdout, "this is the test data");
@@ -728,3 +1253,57 @@ fn additional_formatting_sse_charset_image_and_compression_cases() {
     assert_exit(&res, 1);
     assert!(res.stderr.contains("invalid value 'bad'"));
 }
+
+#[test]
+fn raw_skips_decoding_formatting_and_config_defaults() {
+    let mut gzip = GzEncoder::new(Vec::new(), Compression::default());
+    gzip.write_all(br#"{"ok":true}"#).unwrap();
+    let gzip_body = gzip.finish().unwrap();
+    let body = gzip_body.clone();
+    let server = TestServer::start(move |_| {
+        TestResponse::ok(body.clone())
+            .header("Content-Type", "application/json")
+            .header("Content-Encoding", "gzip")
+    });
+    let dir = TempDir::new().unwrap();
+    let config = dir.path().join("config");
+    fs::write(&config, "format = on\ncompress = gzip\n").unwrap();
+    let output = dir.path().join("body.gz");
+
+    let res = run_fetch(&[
+        &server.url,
+        "--config",
+        config.to_str().unwrap(),
+        "--raw",
+        "-o",
+        output.to_str().unwrap(),
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(fs::read(&output).unwrap(), gzip_body);
+    let requests = server.requests();
+    assert!(
+        requests[0].header("accept-encoding").is_empty(),
+        "{:?}",
+        requests[0].header("accept-encoding")
+    );
+
+    let res = run_fetch(&[&server.url, "--raw", "--format", "on"]);
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("--format"), "{}", res.stderr);
+}
+
+#[test]
+fn no_formatter_delegation_prints_markdown_code_blocks_verbatim() {
+    let server = TestServer::start(|_| {
+        TestResponse::ok("# Data\n\n```json\n{\"a\":1}\n```\n")
+            .header("Content-Type", "text/markdown")
+    });
+
+    let res = run_fetch(&[&server.url, "--format", "on"]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "# Data\n\n```json\n{\n  \"a\": 1\n}\n```\n");
+
+    let res = run_fetch(&[&server.url, "--format", "on", "--no-formatter-delegation"]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "# Data\n\n```json\n{\"a\":1}\n```\n");
+}
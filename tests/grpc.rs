@@ -354,6 +354,15 @@ fn grpc_h2c_stream_frames_and_status_trailers_are_handled() {
     assert!(res.stdout.is_empty(), "stdout:\n{}", res.stdout);
     assert!(res.stderr.contains("PERMISSION_DENIED"), "{}", res.stderr);
     assert!(res.stderr.contains("permission denied"), "{}", res.stderr);
+
+    let res = run_fetch(&[&format!("{server_url}/test.Status/Denied"), "--grpc", "-v"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("\nTrailers:\ngrpc-status: 7\ngrpc-message: permission%20denied\n"),
+        "{}",
+        res.stderr
+    );
 }
 
 #[cfg(unix)]
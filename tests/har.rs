@@ -169,6 +169,45 @@ fn rejects_equivalent_response_and_har_paths_before_clobbering() {
     }
 }
 
+#[test]
+fn records_correlation_ids_and_warns_on_mismatch() {
+    let server = TestServer::start(|request| {
+        let sent = request.header("x-request-id");
+        let echoed = if sent == "abc-123" {
+            sent
+        } else {
+            "other".into()
+        };
+        TestResponse::ok("ok").header("X-Request-Id", &echoed)
+    });
+    let dir = TempDir::new().unwrap();
+    let path = dir.path().join("request.har");
+
+    for (sent, received, warns) in [("abc-123", "abc-123", false), ("xyz", "other", true)] {
+        let res = run_fetch(&[
+            &server.url,
+            "--header",
+            &format!("X-Request-Id: {sent}"),
+            "--har",
+            path.to_str().unwrap(),
+            "--clobber",
+        ]);
+        assert_exit(&res, 0);
+        assert_eq!(
+            res.stderr
+                .contains("response x-request-id 'other' does not match the sent value 'xyz'"),
+            warns,
+            "stderr:\n{}",
+            res.stderr
+        );
+        let har: Value = serde_json::from_slice(&fs::read(&path).unwrap()).unwrap();
+        let correlation = &har["log"]["entries"][0]["_correlation"];
+        assert_eq!(correlation["header"], "x-request-id");
+        assert_eq!(correlation["sent"], sent);
+        assert_eq!(correlation["received"], received);
+    }
+}
+
 #[test]
 fn har_enables_runtime_dns_timing() {
     let server = TestServer::start(|_| TestResponse::ok("dns"));
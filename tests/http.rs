@@ -88,6 +88,77 @@ fn request_construction_and_data_sources() {
     let req = wait_for_requests(&server, 7).remove(6);
     assert_eq!(req.method, "GET");
     assert_eq!(req.body_string(), r#"{"key":"val"}"#);
+
+    let res = run_fetch_opts(
+        FetchOpts {
+            stdin: Some("from=stdin".to_string()),
+            ..Default::default()
+        },
+        &[
+            &server.url,
+            "-d",
+            "a=1",
+            "--data",
+            "@-",
+            "-d",
+            &format!("@{}", file.display()),
+        ],
+    );
+    assert_exit(&res, 0);
+    let req = wait_for_requests(&server, 8).remove(7);
+    assert_eq!(req.method, "POST");
+    assert_eq!(req.body_string(), "a=1&from=stdin&temp file data");
+    assert!(req.header("content-type").is_empty());
+}
+
+#[test]
+fn json_merge_applies_json_body_as_merge_patch() {
+    let server = TestServer::start(|_| TestResponse::ok(""));
+    let dir = TempDir::new().unwrap();
+    let base = temp_file(
+        dir.path(),
+        "current.json",
+        r#"{"id":7,"name":"old","tags":["a"],"meta":{"owner":"x","draft":true}}"#,
+    );
+    let base_path = base.to_str().unwrap();
+
+    let res = run_fetch(&[
+        &server.url,
+        "-m",
+        "PATCH",
+        "--json-merge",
+        base_path,
+        "-j",
+        r#"{"name":"new","tags":null,"meta":{"draft":null}}"#,
+    ]);
+    assert_exit(&res, 0);
+    let req = wait_for_requests(&server, 1).remove(0);
+    assert_eq!(req.method, "PATCH");
+    assert_eq!(
+        req.body_string(),
+        r#"{"id":7,"name":"new","meta":{"owner":"x"}}"#
+    );
+    assert_eq!(req.header("content-type"), "application/json");
+
+    let missing = dir.path().join("missing.json");
+    let res = run_fetch(&[
+        &server.url,
+        "--json-merge",
+        missing.to_str().unwrap(),
+        "-j",
+        "{}",
+    ]);
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("does not exist"), "{}", res.stderr);
+
+    let res = run_fetch(&[&server.url, "--json-merge", base_path, "-j", "{"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("invalid JSON merge patch"),
+        "{}",
+        res.stderr
+    );
+    assert_eq!(server.requests().len(), 1);
 }
 
 #[test]
@@ -418,6 +489,16 @@ fn basic_bearer_and_aws_auth_headers() {
     assert!(res.stderr.contains(
         "> x-amz-content-sha256: e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
     ));
+
+    let res = run_fetch(&[&format!("{}/aws", server.url), "--aws-sigv4", ""]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains(
+            "invalid aws-sigv4 format: cannot infer REGION/SERVICE from host '127.0.0.1'"
+        ),
+        "stderr:\n{}",
+        res.stderr
+    );
 }
 
 #[test]
@@ -705,6 +786,78 @@ fn cross_origin_redirect_does_not_reapply_cli_auth() {
     }
 }
 
+#[test]
+fn netrc_credentials_apply_as_basic_auth() {
+    let server = TestServer::start(|req| TestResponse::ok(req.header("authorization")));
+    let dir = TempDir::new().unwrap();
+    let netrc = temp_file(
+        dir.path(),
+        ".netrc",
+        "machine other.example login nobody password nothing\nmachine 127.0.0.1 login user password pass\n",
+    );
+    let expected = format!(
+        "Basic {}",
+        base64::engine::general_purpose::STANDARD.encode("user:pass")
+    );
+
+    let res = run_fetch(&[&server.url, "--netrc-file", netrc.to_str().unwrap()]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, expected);
+
+    let res = run_fetch_opts(
+        FetchOpts {
+            env: vec![("HOME".to_string(), dir.path().display().to_string())],
+            ..FetchOpts::default()
+        },
+        &[&server.url, "--netrc"],
+    );
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, expected);
+
+    let res = run_fetch(&[
+        &server.url,
+        "--netrc-file",
+        netrc.to_str().unwrap(),
+        "--bearer",
+        "token",
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "Bearer token");
+
+    let curl = format!("curl --netrc-file {} {}", netrc.display(), server.url);
+    let res = run_fetch(&["--from-curl", &curl]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, expected);
+
+    let res = run_fetch_opts(
+        FetchOpts {
+            env: vec![("HOME".to_string(), dir.path().display().to_string())],
+            ..FetchOpts::default()
+        },
+        &["--from-curl", &format!("curl -n {}", server.url)],
+    );
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, expected);
+
+    let unknown = temp_file(
+        dir.path(),
+        "unknown",
+        "machine other.example login a password b\n",
+    );
+    let res = run_fetch(&[&server.url, "--netrc-file", unknown.to_str().unwrap()]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "");
+
+    let missing = dir.path().join("missing");
+    let res = run_fetch(&[&server.url, "--netrc-file", missing.to_str().unwrap()]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("failed to read netrc file"),
+        "{}",
+        res.stderr
+    );
+}
+
 #[test]
 fn cross_origin_redirect_does_not_sign_with_aws_auth() {
     let target = TestServer::start(|req| {
@@ -766,6 +919,261 @@ fn cross_origin_redirect_does_not_retry_digest_auth() {
     assert!(requests[0].header("authorization").is_empty());
 }
 
+#[test]
+fn fail_on_empty_rejects_empty_success_bodies() {
+    let server = TestServer::start(|req| match req.path.as_str() {
+        "/data" => TestResponse::ok("data"),
+        "/missing" => TestResponse::status(404, "Not Found", ""),
+        _ => TestResponse::ok(""),
+    });
+
+    let res = run_fetch(&["--fail-on-empty", &format!("{}/empty", server.url)]);
+    assert_exit(&res, 1);
+    assert!(res.stdout.is_empty());
+    assert!(
+        res.stderr.contains("response body is empty"),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    let dir = TempDir::new().unwrap();
+    let output = dir.path().join("empty.txt");
+    let res = run_fetch(&[
+        "--fail-on-empty",
+        "-o",
+        output.to_str().unwrap(),
+        &format!("{}/empty", server.url),
+    ]);
+    assert_exit(&res, 1);
+    assert!(!output.exists());
+
+    for range_flags in [["--chunk-size", "8"], ["--continue-at", "auto"]] {
+        let res = run_fetch(&[
+            "--fail-on-empty",
+            range_flags[0],
+            range_flags[1],
+            "-o",
+            output.to_str().unwrap(),
+            &format!("{}/empty", server.url),
+        ]);
+        assert_exit(&res, 1);
+        assert!(
+            res.stderr.contains("response body is empty"),
+            "{range_flags:?} stderr:\n{}",
+            res.stderr
+        );
+    }
+
+    let res = run_fetch(&["--fail-on-empty", &format!("{}/data", server.url)]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "data");
+
+    let res = run_fetch(&["--fail-on-empty", &format!("{}/missing", server.url)]);
+    assert_exit(&res, 4);
+
+    let res = run_fetch(&[
+        "--fail-on-empty",
+        "-m",
+        "HEAD",
+        &format!("{}/data", server.url),
+    ]);
+    assert_exit(&res, 0);
+
+    let res = run_fetch(&[&format!("{}/empty", server.url)]);
+    assert_exit(&res, 0);
+}
+
+#[test]
+fn truncated_body_warns_or_fails_with_fail_on_truncation() {
+    let listener = TcpListener::bind("127.0.0.1:0").expect("bind truncating listener");
+    listener
+        .set_nonblocking(true)
+        .expect("set truncating listener nonblocking");
+    let url = format!("http://{}/file", listener.local_addr().unwrap());
+    let join = thread::spawn(move || {
+        for _ in 0..3 {
+            let Ok(stream) =
+                accept_tcp_connection(&listener, Duration::from_secs(5), "truncated body")
+            else {
+                return;
+            };
+            let _ = stream.set_nonblocking(false);
+            let mut writer = stream.try_clone().expect("clone truncating stream");
+            let _ = read_request(&mut BufReader::new(stream));
+            let _ = writer.write_all(b"HTTP/1.1 200 OK\r\ncontent-length: 10\r\n\r\nabc");
+            let _ = writer.flush();
+            let _ = writer.shutdown(Shutdown::Both);
+        }
+    });
+
+    let res = run_fetch_once(FetchOpts::default(), &[&url]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "abc");
+    assert!(
+        res.stderr
+            .contains("response body is truncated: received 3 of 10 bytes"),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    let res = run_fetch_once(FetchOpts::default(), &["--fail-on-truncation", &url]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("response body is truncated: received 3 of 10 bytes"),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    let dir = TempDir::new().unwrap();
+    let output = dir.path().join("file");
+    let res = run_fetch_once(
+        FetchOpts::default(),
+        &["--fail-on-truncation", "-o", output.to_str().unwrap(), &url],
+    );
+    assert_exit(&res, 1);
+    assert!(!output.exists());
+
+    join.join().expect("truncating listener thread");
+}
+
+#[test]
+fn checksum_verifies_response_bodies_before_saving() {
+    const SHA256_DATA: &str =
+        "sha256:3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7";
+    let server = TestServer::start(|req| match req.path.as_str() {
+        "/missing" => TestResponse::status(404, "Not Found", "missing"),
+        _ => TestResponse::ok("data"),
+    });
+    let url = format!("{}/data", server.url);
+
+    let res = run_fetch(&["--checksum", SHA256_DATA, &url]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "data");
+
+    let integrity = "sha512-AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA== \
+                     sha384-IDng8LknKEmfuI4j68PP0FVLKEALDte3UwVciLWGXDwqpyxqGprgp1XYeQCkpv9B";
+    let res = run_fetch(&["--checksum", integrity, &url]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "data");
+
+    let dir = TempDir::new().unwrap();
+    let output = dir.path().join("data.txt");
+    let res = run_fetch(&[
+        "--checksum",
+        "md5:5eb63bbbe01eeed093cb22bb8f5acdc3",
+        "-o",
+        output.to_str().unwrap(),
+        &url,
+    ]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains(
+            "response body checksum mismatch: expected md5:5eb63bbbe01eeed093cb22bb8f5acdc3, \
+             got md5:8d777f385d3dfec8815d20f7496026dc"
+        ),
+        "stderr:\n{}",
+        res.stderr
+    );
+    assert!(!output.exists());
+
+    let res = run_fetch(&[
+        "--checksum",
+        SHA256_DATA,
+        "-o",
+        output.to_str().unwrap(),
+        &url,
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(fs::read_to_string(&output).unwrap(), "data");
+
+    let res = run_fetch(&[
+        "--checksum",
+        SHA256_DATA,
+        &format!("{}/missing", server.url),
+    ]);
+    assert_exit(&res, 4);
+
+    let res = run_fetch(&["--checksum", "sha256:abc", &url]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("sha256 digests must be 64 hex characters"),
+        "stderr:\n{}",
+        res.stderr
+    );
+    assert_eq!(server.requests().len(), 5);
+}
+
+#[test]
+fn checksum_algorithm_prints_body_digest() {
+    const SHA256_DATA: &str =
+        "sha256:3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7";
+    let server = TestServer::start(|_req| TestResponse::ok("data"));
+    let url = format!("{}/data", server.url);
+
+    let res = run_fetch(&["--checksum", "sha256", &url]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "data");
+    assert!(
+        res.stderr.contains(&format!("checksum: {SHA256_DATA}")),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    let dir = TempDir::new().unwrap();
+    let output = dir.path().join("data.txt");
+    let res = run_fetch(&["--checksum", "md5", "-o", output.to_str().unwrap(), &url]);
+    assert_exit(&res, 0);
+    assert_eq!(fs::read_to_string(&output).unwrap(), "data");
+    assert!(
+        res.stderr
+            .contains("checksum: md5:8d777f385d3dfec8815d20f7496026dc"),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    let hex = SHA256_DATA.strip_prefix("sha256:").unwrap();
+    let res = run_fetch(&["--checksum", "sha256", "--checksum-verify", hex, &url]);
+    assert_exit(&res, 0);
+    assert!(
+        res.stderr.contains(&format!("checksum: {SHA256_DATA}")),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    let verified = dir.path().join("verified.txt");
+    let res = run_fetch(&[
+        "--checksum",
+        "md5",
+        "--checksum-verify",
+        "00000000000000000000000000000000",
+        "-o",
+        verified.to_str().unwrap(),
+        &url,
+    ]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains(
+            "response body checksum mismatch: expected md5:00000000000000000000000000000000, \
+             got md5:8d777f385d3dfec8815d20f7496026dc"
+        ),
+        "stderr:\n{}",
+        res.stderr
+    );
+    assert!(!verified.exists());
+
+    let res = run_fetch(&["--checksum-verify", hex, &url]);
+    assert_exit(&res, 1);
+    let res = run_fetch(&["--checksum", SHA256_DATA, "--checksum-verify", hex, &url]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("--checksum-verify"),
+        "stderr:\n{}",
+        res.stderr
+    );
+}
+
 #[test]
 fn redirects_range_status_and_timeouts() {
     let server = TestServer::start(|req| match req.path.as_str() {
@@ -848,31 +1256,88 @@ fn session_cookies_persist_after_invalid_redirect() {
 }
 
 #[test]
-fn dry_run_leaves_corrupted_session_unchanged() {
+fn very_verbose_output_lists_cookies_sent_and_received() {
+    let server = TestServer::start(|req| match req.path.as_str() {
+        "/login" => TestResponse::ok("")
+            .header(
+                "Set-Cookie",
+                "sid=abc123; Path=/; Max-Age=3600; HttpOnly; SameSite=Strict",
+            )
+            .header(
+                "Set-Cookie",
+                "old=; Path=/; Expires=Thu, 01 Jan 1970 00:00:00 GMT; Secure",
+            ),
+        _ => TestResponse::ok(req.header("cookie")),
+    });
     let dir = TempDir::new().unwrap();
-    let sessions_dir = dir.path().join("sessions");
-    fs::create_dir_all(&sessions_dir).unwrap();
-    let session_path = sessions_dir.join("dry-run.json");
-    let original = b"not valid session json\n";
-    fs::write(&session_path, original).unwrap();
-
-    let res = run_fetch_opts(
-        FetchOpts {
-            env: vec![(
-                "FETCH_INTERNAL_SESSIONS_DIR".to_string(),
-                sessions_dir.display().to_string(),
-            )],
-            ..Default::default()
-        },
-        &["https://example.com", "--dry-run", "--session", "dry-run"],
-    );
+    let opts = || FetchOpts {
+        env: vec![(
+            "FETCH_INTERNAL_SESSIONS_DIR".to_string(),
+            dir.path().display().to_string(),
+        )],
+        ..Default::default()
+    };
 
+    let url = format!("{}/login", server.url);
+    let res = run_fetch_opts(opts(), &[&url, "--session", "cookies", "-vv"]);
     assert_exit(&res, 0);
-    assert!(res.stderr.contains("session 'dry-run' is corrupted"));
-    assert_eq!(fs::read(session_path).unwrap(), original);
-}
-
-#[test]
+    assert!(
+        res.stderr.contains(
+            "* Cookie received: sid=abc123 (path /, max-age 3600s, httponly, samesite strict)\n"
+        ),
+        "{}",
+        res.stderr
+    );
+    assert!(
+        res.stderr.contains(
+            "* Cookie received: old= (path /, expires 1970-01-01T00:00:00Z (expired), secure)\n"
+        ),
+        "{}",
+        res.stderr
+    );
+    assert!(!res.stderr.contains("Cookie sent"), "{}", res.stderr);
+
+    let url = format!("{}/me", server.url);
+    let res = run_fetch_opts(opts(), &[&url, "--session", "cookies", "-vv"]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "sid=abc123");
+    assert!(
+        res.stderr.contains("* Cookie sent: sid=abc123\n"),
+        "{}",
+        res.stderr
+    );
+
+    let res = run_fetch_opts(opts(), &[&url, "--session", "cookies", "-v"]);
+    assert_exit(&res, 0);
+    assert!(!res.stderr.contains("* Cookie"), "{}", res.stderr);
+}
+
+#[test]
+fn dry_run_leaves_corrupted_session_unchanged() {
+    let dir = TempDir::new().unwrap();
+    let sessions_dir = dir.path().join("sessions");
+    fs::create_dir_all(&sessions_dir).unwrap();
+    let session_path = sessions_dir.join("dry-run.json");
+    let original = b"not valid session json\n";
+    fs::write(&session_path, original).unwrap();
+
+    let res = run_fetch_opts(
+        FetchOpts {
+            env: vec![(
+                "FETCH_INTERNAL_SESSIONS_DIR".to_string(),
+                sessions_dir.display().to_string(),
+            )],
+            ..Default::default()
+        },
+        &["https://example.com", "--dry-run", "--session", "dry-run"],
+    );
+
+    assert_exit(&res, 0);
+    assert!(res.stderr.contains("session 'dry-run' is corrupted"));
+    assert_eq!(fs::read(session_path).unwrap(), original);
+}
+
+#[test]
 fn post_to_get_redirect_strips_entity_headers() {
     let server = TestServer::start(|req| match req.path.as_str() {
         "/start" => TestResponse::status(302, "Found", "")
@@ -905,6 +1370,110 @@ fn post_to_get_redirect_strips_entity_headers() {
     assert_eq!(res.stdout, "clean");
 }
 
+#[test]
+fn temporary_and_permanent_redirects_resend_file_body() {
+    let server = TestServer::start(|req| match req.path.as_str() {
+        "/temporary" => TestResponse::status(307, "Temporary Redirect", "")
+            .header("Location", "/final")
+            .header("Connection", "keep-alive"),
+        "/permanent" => TestResponse::status(308, "Permanent Redirect", "")
+            .header("Location", "/final")
+            .header("Connection", "keep-alive"),
+        "/final" => TestResponse::ok(format!(
+            "{} {} {}",
+            req.method,
+            req.header("content-type"),
+            req.body_string()
+        )),
+        _ => TestResponse::status(404, "Not Found", "missing"),
+    });
+    let dir = TempDir::new().unwrap();
+    let payload = "{\"upload\":\"".to_string() + &"x".repeat(256 * 1024) + "\"}";
+    let file = temp_file(dir.path(), "upload.json", &payload);
+
+    for path in ["/temporary", "/permanent"] {
+        let res = run_fetch(&[
+            &format!("{}{path}", server.url),
+            "--json",
+            &format!("@{}", file.display()),
+            "--format",
+            "off",
+        ]);
+        assert_exit(&res, 0);
+        assert_eq!(
+            res.stdout,
+            format!("POST application/json {payload}"),
+            "redirect via {path}"
+        );
+    }
+}
+
+#[test]
+fn expand_env_substitutes_variables_in_request_bodies() {
+    let server = TestServer::start(|req| TestResponse::ok(req.body_string()));
+    let dir = TempDir::new().unwrap();
+    let file = temp_file(dir.path(), "template.json", "{\"token\":\"$API_TOKEN\"}");
+    let env = || vec![("API_TOKEN".to_string(), "s3cret".to_string())];
+
+    let res = run_fetch_opts(
+        FetchOpts {
+            env: env(),
+            ..Default::default()
+        },
+        &[
+            &server.url,
+            "--expand-env",
+            "--json",
+            r#"{"token":"${API_TOKEN}","cost":"$$5","user":"$FETCH_TEST_UNSET"}"#,
+            "--format",
+            "off",
+        ],
+    );
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, r#"{"token":"s3cret","cost":"$5","user":""}"#);
+    assert!(
+        res.stderr
+            .contains("environment variable 'FETCH_TEST_UNSET' is not set"),
+        "stderr: {}",
+        res.stderr
+    );
+
+    let file_arg = format!("@{}", file.display());
+    let res = run_fetch_opts(
+        FetchOpts {
+            env: env(),
+            ..Default::default()
+        },
+        &[
+            &server.url,
+            "--expand-env",
+            "--json",
+            &file_arg,
+            "--format",
+            "off",
+        ],
+    );
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "{\"token\":\"$API_TOKEN\"}");
+
+    let res = run_fetch_opts(
+        FetchOpts {
+            env: env(),
+            ..Default::default()
+        },
+        &[
+            &server.url,
+            "--expand-env=files",
+            "--json",
+            &file_arg,
+            "--format",
+            "off",
+        ],
+    );
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "{\"token\":\"s3cret\"}");
+}
+
 #[test]
 fn retry_statuses_and_request_body_replay() {
     let attempts = Arc::new(AtomicUsize::new(0));
@@ -950,6 +1519,121 @@ fn retry_statuses_and_request_body_replay() {
     assert_exit(&res, 0);
 }
 
+#[test]
+fn idempotency_key_is_reused_across_retries() {
+    let keys = Arc::new(Mutex::new(Vec::new()));
+    let keys_for_handler = Arc::clone(&keys);
+    let server = TestServer::start(move |req| {
+        let mut keys = keys_for_handler.lock().unwrap();
+        keys.push(req.header("idempotency-key").to_string());
+        if keys.len() % 2 == 1 {
+            TestResponse::status(503, "Service Unavailable", "retry")
+        } else {
+            TestResponse::ok("done")
+        }
+    });
+
+    let res = run_fetch(&[
+        &server.url,
+        "--idempotency-key",
+        "--retry",
+        "1",
+        "--retry-delay",
+        FAST_RETRY_DELAY,
+        "-d",
+        "payload",
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "done");
+    let generated = keys.lock().unwrap().clone();
+    assert_eq!(generated.len(), 2);
+    assert_eq!(generated[0].len(), 36, "{}", generated[0]);
+    assert_eq!(generated[0], generated[1]);
+
+    let res = run_fetch(&[
+        &server.url,
+        "--idempotency-key=order-42",
+        "--retry",
+        "1",
+        "--retry-delay",
+        FAST_RETRY_DELAY,
+    ]);
+    assert_exit(&res, 0);
+    let keys = keys.lock().unwrap();
+    assert_eq!(keys[2..], ["order-42", "order-42"]);
+    assert_ne!(keys[0], keys[2]);
+
+    let res = run_fetch(&[&server.url, "--idempotency-key=bad\nkey"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("must be a valid header value"),
+        "{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn accept_fallback_retries_not_acceptable_with_next_type() {
+    let accepts = Arc::new(Mutex::new(Vec::new()));
+    let accepts_for_handler = Arc::clone(&accepts);
+    let server = TestServer::start(move |req| {
+        let accept = req.header("accept").to_string();
+        accepts_for_handler.lock().unwrap().push(accept.clone());
+        if accept == "application/xml" {
+            TestResponse::ok("<ok/>")
+        } else {
+            TestResponse::status(406, "Not Acceptable", "")
+        }
+    });
+
+    let res = run_fetch(&[
+        &server.url,
+        "-v",
+        "-H",
+        "Accept: application/vnd.example.v2+json",
+        "--accept-fallback",
+        "json,xml,*",
+        "-d",
+        "payload",
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "<ok/>");
+    assert!(
+        res.stderr
+            .contains("Accept: application/xml (after 406 Not Acceptable)"),
+        "{}",
+        res.stderr
+    );
+    assert_eq!(
+        *accepts.lock().unwrap(),
+        [
+            "application/vnd.example.v2+json",
+            "application/json",
+            "application/xml"
+        ]
+    );
+    let requests = wait_for_requests(&server, 3);
+    assert!(requests.iter().all(|req| req.body_string() == "payload"));
+
+    let res = run_fetch(&[
+        &server.url,
+        "-H",
+        "Accept: text/plain",
+        "--accept-fallback",
+        "json",
+    ]);
+    assert_exit(&res, 4);
+    assert_eq!(
+        accepts.lock().unwrap()[3..],
+        ["text/plain", "application/json"]
+    );
+
+    let res = run_fetch(&[&server.url, "--accept-fallback", "json,nope"]);
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("--accept-fallback"), "{}", res.stderr);
+    assert_eq!(accepts.lock().unwrap().len(), 5);
+}
+
 #[test]
 fn retry_status_delay_obeys_request_timeout_budget() {
     let attempts = Arc::new(AtomicUsize::new(0));
@@ -990,6 +1674,124 @@ fn retry_status_delay_obeys_request_timeout_budget() {
     assert_eq!(attempts.load(Ordering::SeqCst), 1);
 }
 
+#[test]
+fn retry_max_time_stops_retries_that_exceed_budget() {
+    let attempts = Arc::new(AtomicUsize::new(0));
+    let attempts_for_handler = Arc::clone(&attempts);
+    let server = TestServer::start(move |_| {
+        attempts_for_handler.fetch_add(1, Ordering::SeqCst);
+        TestResponse::status(503, "Service Unavailable", "retry").header("Connection", "keep-alive")
+    });
+
+    let start = Instant::now();
+    let res = run_fetch(&[
+        &server.url,
+        "--retry",
+        "5",
+        "--retry-delay",
+        "1",
+        "--retry-max-time",
+        "0.5",
+    ]);
+    let elapsed = start.elapsed();
+
+    assert_exit(&res, 5);
+    assert_eq!(res.stdout, "retry");
+    assert!(
+        !res.stderr.contains("retry: attempt"),
+        "stderr:\n{}",
+        res.stderr
+    );
+    assert!(
+        elapsed < Duration::from_millis(1500),
+        "retry budget was not enforced; elapsed: {elapsed:?}\nstderr:\n{}",
+        res.stderr
+    );
+    assert_eq!(attempts.load(Ordering::SeqCst), 1);
+}
+
+#[test]
+fn retry_on_status_adds_to_default_statuses() {
+    let attempts = Arc::new(AtomicUsize::new(0));
+    let attempts_for_handler = Arc::clone(&attempts);
+    let server =
+        TestServer::start(
+            move |_| match attempts_for_handler.fetch_add(1, Ordering::SeqCst) % 3 {
+                0 => TestResponse::status(500, "Internal Server Error", "retry"),
+                1 => TestResponse::status(503, "Service Unavailable", "retry"),
+                _ => TestResponse::ok("done"),
+            },
+        );
+
+    let res = run_fetch(&[
+        &server.url,
+        "--retry",
+        "2",
+        "--retry-delay",
+        FAST_RETRY_DELAY,
+        "--retry-on-status",
+        "400,500-501",
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "done");
+    assert_eq!(attempts.load(Ordering::SeqCst), 3);
+
+    let res = run_fetch(&[
+        &server.url,
+        "--retry",
+        "2",
+        "--retry-delay",
+        FAST_RETRY_DELAY,
+    ]);
+    assert_exit(&res, 5);
+    assert_eq!(res.stdout, "retry");
+    assert_eq!(attempts.load(Ordering::SeqCst), 4);
+
+    let res = run_fetch(&[&server.url, "--retry-on-status", "500-400"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("--retry-on-status"),
+        "stderr:\n{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn respect_rate_limit_waits_for_reset_before_retrying() {
+    let attempts = Arc::new(AtomicUsize::new(0));
+    let attempts_for_handler = Arc::clone(&attempts);
+    let server = TestServer::start(move |_| {
+        attempts_for_handler.fetch_add(1, Ordering::SeqCst);
+        TestResponse::status(429, "Too Many Requests", "slow down")
+            .header("X-RateLimit-Remaining", "0")
+            .header("X-RateLimit-Reset", "5")
+            .header("Connection", "keep-alive")
+    });
+    let args = [
+        server.url.as_str(),
+        "--retry",
+        "1",
+        "--retry-delay",
+        FAST_RETRY_DELAY,
+        "--retry-max-time",
+        "2",
+    ];
+
+    let res = run_fetch(&args);
+    assert_exit(&res, 4);
+    assert!(res.stderr.contains("retry: attempt 2/2"), "{}", res.stderr);
+    assert_eq!(attempts.load(Ordering::SeqCst), 2);
+
+    let mut rate_limited = args.to_vec();
+    rate_limited.push("--respect-rate-limit");
+    let start = Instant::now();
+    let res = run_fetch(&rate_limited);
+    assert_exit(&res, 4);
+    assert!(!res.stderr.contains("retry: attempt"), "{}", res.stderr);
+    assert!(start.elapsed() < Duration::from_secs(4));
+    assert_eq!(attempts.load(Ordering::SeqCst), 3);
+}
+
 #[test]
 fn retry_transport_error_delay_obeys_request_timeout_budget() {
     let listener = TcpListener::bind("127.0.0.1:0").expect("bind unused port");
@@ -1026,6 +1828,43 @@ fn retry_transport_error_delay_obeys_request_timeout_budget() {
     );
 }
 
+#[test]
+fn retry_covers_refused_connections_with_or_without_connrefused() {
+    let listener = TcpListener::bind("127.0.0.1:0").expect("bind unused port");
+    let addr = listener.local_addr().expect("unused port local addr");
+    drop(listener);
+    let url = format!("http://{addr}");
+
+    let res = run_fetch_once(
+        FetchOpts::default(),
+        &[&url, "--retry", "1", "--retry-delay", FAST_RETRY_DELAY],
+    );
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("retry: attempt 2/2"),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    let res = run_fetch_once(
+        FetchOpts::default(),
+        &[
+            &url,
+            "--retry",
+            "1",
+            "--retry-delay",
+            FAST_RETRY_DELAY,
+            "--retry-connrefused",
+        ],
+    );
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("retry: attempt 2/2"),
+        "stderr:\n{}",
+        res.stderr
+    );
+}
+
 #[test]
 fn retry_status_rejects_stdin_body_replay() {
     let server = TestServer::start(|_| {
@@ -1292,103 +2131,486 @@ fn output_file_modes_match_go_harness() {
         "header-body"
     );
     assert!(
-        !res.stderr
-            .contains("Content-Disposition filename was not usable"),
+        !res.stderr
+            .contains("Content-Disposition filename was not usable"),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    let res = run_fetch_opts(
+        FetchOpts {
+            cwd: Some(dir.path().to_path_buf()),
+            ..Default::default()
+        },
+        &[
+            &format!("{}/missing-header.txt", server.url),
+            "--remote-name",
+            "--remote-header-name",
+        ],
+    );
+    assert_exit(&res, 0);
+    assert_eq!(
+        fs::read_to_string(dir.path().join("missing-header.txt")).unwrap(),
+        "missing-header-body"
+    );
+    assert!(
+        res.stderr.contains(
+            "warning: Content-Disposition filename was not usable; falling back to URL filename"
+        ),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    let res = run_fetch_opts(
+        FetchOpts {
+            cwd: Some(dir.path().to_path_buf()),
+            ..Default::default()
+        },
+        &[
+            &format!("{}/invalid-header.txt", server.url),
+            "--remote-name",
+            "--remote-header-name",
+        ],
+    );
+    assert_exit(&res, 0);
+    assert_eq!(
+        fs::read_to_string(dir.path().join("invalid-header.txt")).unwrap(),
+        "invalid-header-body"
+    );
+    assert!(
+        res.stderr.contains(
+            "warning: Content-Disposition filename was not usable; falling back to URL filename"
+        ),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    let out = dir.path().join("explicit.txt");
+    let res = run_fetch(&[
+        &format!("{}/file.txt", server.url),
+        "--output",
+        out.to_str().unwrap(),
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(fs::read_to_string(&out).unwrap(), "file-body");
+
+    let res = run_fetch(&[
+        &format!("{}/file.txt", server.url),
+        "--output",
+        out.to_str().unwrap(),
+    ]);
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("already exists"));
+
+    let res = run_fetch(&[
+        &format!("{}/file.txt", server.url),
+        "--output",
+        out.to_str().unwrap(),
+        "--clobber",
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(fs::read_to_string(&out).unwrap(), "file-body");
+
+    let res = run_fetch_opts(
+        FetchOpts {
+            cwd: Some(dir.path().to_path_buf()),
+            ..Default::default()
+        },
+        &[
+            &format!("{}/traversal", server.url),
+            "--remote-name",
+            "--remote-header-name",
+        ],
+    );
+    assert_exit(&res, 0);
+    assert_eq!(
+        fs::read_to_string(dir.path().join("bad.txt")).unwrap(),
+        "bad"
+    );
+    assert!(!dir.path().join("..").join("bad.txt").exists());
+}
+
+#[test]
+fn output_dir_saves_remote_name_downloads_under_directory() {
+    let server = TestServer::start(|_req| TestResponse::ok("report body"));
+    let dir = TempDir::new().unwrap();
+    let out_dir = dir.path().join("downloads").join("reports");
+    let url = format!("{}/files/report.txt", server.url);
+
+    let res = run_fetch(&[&url, "-O", "--output-dir", out_dir.to_str().unwrap()]);
+    assert_exit(&res, 0);
+    assert_eq!(
+        fs::read_to_string(out_dir.join("report.txt")).unwrap(),
+        "report body"
+    );
+
+    let res = run_fetch(&[&url, "--output-dir", out_dir.to_str().unwrap()]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("flag '--output-dir' requires '--remote-name'"),
+        "stderr:\n{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn write_meta_saves_response_metadata_beside_output() {
+    let server = TestServer::start(|_req| {
+        TestResponse::ok("a,b\n1,2\n")
+            .header("Content-Type", "text/csv")
+            .header("X-Request-Id", "abc123")
+    });
+    let dir = TempDir::new().unwrap();
+    let out = dir.path().join("report.csv");
+    let url = format!("{}/report.csv", server.url);
+
+    let res = run_fetch(&[&url, "-o", out.to_str().unwrap(), "--write-meta"]);
+    assert_exit(&res, 0);
+    assert_eq!(fs::read_to_string(&out).unwrap(), "a,b\n1,2\n");
+    let meta_path = dir.path().join("report.csv.meta.json");
+    let meta: serde_json::Value = serde_json::from_slice(&fs::read(&meta_path).unwrap()).unwrap();
+    assert_eq!(meta["url"], url.as_str());
+    assert_eq!(meta["status"], 200);
+    assert_eq!(meta["content_type"], "text/csv");
+    assert_eq!(meta["size"], 8);
+    assert!(
+        meta["headers"]
+            .as_array()
+            .unwrap()
+            .iter()
+            .any(|header| header["name"] == "x-request-id" && header["value"] == "abc123"),
+        "{meta}"
+    );
+    assert!(meta["timing"]["ttfb_ms"].is_number(), "{meta}");
+    assert!(meta["timing"]["total_ms"].is_number(), "{meta}");
+
+    let res = run_fetch(&[&url, "--write-meta"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("flag '--write-meta' requires '--output' or '--remote-name'"),
+        "stderr:\n{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn chunk_size_downloads_in_ranges_and_resumes_partial_file() {
+    const BODY: &[u8] = b"0123456789abcdefghij";
+    let server = TestServer::start(|req| {
+        let range = req.header("range");
+        let Some((start, end)) = range
+            .strip_prefix("bytes=")
+            .and_then(|range| range.split_once('-'))
+        else {
+            return TestResponse::ok(BODY);
+        };
+        let start: usize = start.parse().unwrap();
+        let end = end.parse::<usize>().unwrap().min(BODY.len() - 1);
+        if start >= BODY.len() {
+            return TestResponse::status(416, "Range Not Satisfiable", "")
+                .header("Content-Range", &format!("bytes */{}", BODY.len()));
+        }
+        TestResponse::status(206, "Partial Content", &BODY[start..=end]).header(
+            "Content-Range",
+            &format!("bytes {start}-{end}/{}", BODY.len()),
+        )
+    });
+    let dir = TempDir::new().unwrap();
+    let path = dir.path().join("download.bin");
+    let path_arg = path.to_str().unwrap();
+
+    let res = run_fetch(&[
+        &format!("{}/file", server.url),
+        "--chunk-size",
+        "8",
+        "-o",
+        path_arg,
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(fs::read(&path).unwrap(), BODY);
+    assert!(!dir.path().join("download.bin.part").exists());
+    let ranges: Vec<String> = server
+        .requests()
+        .iter()
+        .map(|req| req.header("range"))
+        .collect();
+    assert_eq!(ranges, ["bytes=0-7", "bytes=8-15", "bytes=16-19"]);
+    assert!(
+        server
+            .requests()
+            .iter()
+            .all(|req| req.header("accept-encoding").is_empty())
+    );
+
+    let resumed = dir.path().join("resumed.bin");
+    fs::write(dir.path().join("resumed.bin.part"), &BODY[..12]).unwrap();
+    let res = run_fetch(&[
+        &format!("{}/file", server.url),
+        "--chunk-size",
+        "100",
+        "-o",
+        resumed.to_str().unwrap(),
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(fs::read(&resumed).unwrap(), BODY);
+    assert!(res.stderr.contains("at byte 12"), "stderr:\n{}", res.stderr);
+    assert_eq!(
+        server.requests().last().unwrap().header("range"),
+        "bytes=12-111"
+    );
+
+    let res = run_fetch(&[
+        &format!("{}/file", server.url),
+        "--chunk-size",
+        "8",
+        "-o",
+        path_arg,
+    ]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("already exists"),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    let res = run_fetch(&[&format!("{}/file", server.url), "--chunk-size", "8"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("flag '--chunk-size' requires '--output' or '--remote-name'"),
         "stderr:\n{}",
         res.stderr
     );
 
-    let res = run_fetch_opts(
-        FetchOpts {
-            cwd: Some(dir.path().to_path_buf()),
-            ..Default::default()
-        },
-        &[
-            &format!("{}/missing-header.txt", server.url),
-            "--remote-name",
-            "--remote-header-name",
-        ],
-    );
-    assert_exit(&res, 0);
-    assert_eq!(
-        fs::read_to_string(dir.path().join("missing-header.txt")).unwrap(),
-        "missing-header-body"
-    );
+    let res = run_fetch(&[
+        &format!("{}/file", server.url),
+        "--chunk-size",
+        "0",
+        "-o",
+        path_arg,
+    ]);
+    assert_exit(&res, 1);
     assert!(
-        res.stderr.contains(
-            "warning: Content-Disposition filename was not usable; falling back to URL filename"
-        ),
+        res.stderr.contains("must be a positive integer"),
         "stderr:\n{}",
         res.stderr
     );
 
-    let res = run_fetch_opts(
-        FetchOpts {
-            cwd: Some(dir.path().to_path_buf()),
-            ..Default::default()
-        },
-        &[
-            &format!("{}/invalid-header.txt", server.url),
-            "--remote-name",
-            "--remote-header-name",
-        ],
-    );
+    let verified = dir.path().join("verified.bin");
+    let res = run_fetch(&[
+        &format!("{}/file", server.url),
+        "--chunk-size",
+        "8",
+        "--checksum",
+        "sha1:7c8e1dc5a4fd22f1311a7a1f3e3401215c0ccab3",
+        "-o",
+        verified.to_str().unwrap(),
+    ]);
     assert_exit(&res, 0);
-    assert_eq!(
-        fs::read_to_string(dir.path().join("invalid-header.txt")).unwrap(),
-        "invalid-header-body"
+    assert_eq!(fs::read(&verified).unwrap(), BODY);
+
+    let mismatched = dir.path().join("mismatched.bin");
+    let res = run_fetch(&[
+        &format!("{}/file", server.url),
+        "--chunk-size",
+        "8",
+        "--checksum",
+        "sha1:0000000000000000000000000000000000000000",
+        "-o",
+        mismatched.to_str().unwrap(),
+    ]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("response body checksum mismatch"),
+        "stderr:\n{}",
+        res.stderr
     );
+    assert!(!mismatched.exists());
+    assert!(!dir.path().join("mismatched.bin.part").exists());
+}
+
+#[test]
+fn chunk_size_rate_limit_wait_obeys_request_timeout() {
+    let server = TestServer::start(|_| {
+        TestResponse::status(206, "Partial Content", "01234567")
+            .header("Content-Range", "bytes 0-7/20")
+            .header("X-RateLimit-Remaining", "0")
+            .header("X-RateLimit-Reset", "10")
+    });
+    let dir = TempDir::new().unwrap();
+    let path = dir.path().join("download.bin");
+
+    let start = Instant::now();
+    let res = run_fetch(&[
+        &format!("{}/file", server.url),
+        "--chunk-size",
+        "8",
+        "--respect-rate-limit",
+        "--timeout",
+        "0.25",
+        "-o",
+        path.to_str().unwrap(),
+    ]);
+    let elapsed = start.elapsed();
+
+    assert_exit(&res, 1);
     assert!(
-        res.stderr.contains(
-            "warning: Content-Disposition filename was not usable; falling back to URL filename"
-        ),
+        res.stderr.contains("request timed out after 250ms"),
         "stderr:\n{}",
         res.stderr
     );
+    assert!(
+        elapsed < Duration::from_millis(1500),
+        "rate limit wait was not capped; elapsed: {elapsed:?}\nstderr:\n{}",
+        res.stderr
+    );
+    assert_eq!(server.requests().len(), 1);
+}
 
-    let out = dir.path().join("explicit.txt");
+#[test]
+fn continue_at_appends_to_existing_output() {
+    const BODY: &[u8] = b"0123456789abcdefghij";
+    let server = TestServer::start(|req| {
+        let range = req.header("range");
+        let start = range
+            .strip_prefix("bytes=")
+            .and_then(|range| range.strip_suffix('-'))
+            .and_then(|start| start.parse::<usize>().ok());
+        match start {
+            Some(start) if req.path == "/file" => {
+                TestResponse::status(206, "Partial Content", &BODY[start..]).header(
+                    "Content-Range",
+                    &format!("bytes {start}-{}/{}", BODY.len() - 1, BODY.len()),
+                )
+            }
+            _ => TestResponse::ok(BODY),
+        }
+    });
+    let dir = TempDir::new().unwrap();
+
+    let path = dir.path().join("resumed.bin");
+    fs::write(&path, &BODY[..12]).unwrap();
     let res = run_fetch(&[
-        &format!("{}/file.txt", server.url),
-        "--output",
-        out.to_str().unwrap(),
+        &format!("{}/file", server.url),
+        "-C",
+        "auto",
+        "-o",
+        path.to_str().unwrap(),
     ]);
     assert_exit(&res, 0);
-    assert_eq!(fs::read_to_string(&out).unwrap(), "file-body");
+    assert_eq!(fs::read(&path).unwrap(), BODY);
+    assert!(res.stderr.contains("at byte 12"), "stderr:\n{}", res.stderr);
+    assert_eq!(
+        server.requests().last().unwrap().header("range"),
+        "bytes=12-"
+    );
 
+    let restarted = dir.path().join("restarted.bin");
+    fs::write(&restarted, "stale").unwrap();
     let res = run_fetch(&[
-        &format!("{}/file.txt", server.url),
-        "--output",
-        out.to_str().unwrap(),
+        &format!("{}/ignores-range", server.url),
+        "--continue-at",
+        "auto",
+        "-o",
+        restarted.to_str().unwrap(),
     ]);
-    assert_exit(&res, 1);
-    assert!(res.stderr.contains("already exists"));
+    assert_exit(&res, 0);
+    assert_eq!(fs::read(&restarted).unwrap(), BODY);
 
+    let fresh = dir.path().join("fresh.bin");
     let res = run_fetch(&[
-        &format!("{}/file.txt", server.url),
-        "--output",
-        out.to_str().unwrap(),
-        "--clobber",
+        &format!("{}/file", server.url),
+        "-C",
+        "auto",
+        "-o",
+        fresh.to_str().unwrap(),
     ]);
     assert_exit(&res, 0);
-    assert_eq!(fs::read_to_string(&out).unwrap(), "file-body");
+    assert_eq!(fs::read(&fresh).unwrap(), BODY);
+    assert_eq!(server.requests().last().unwrap().header("range"), "");
 
-    let res = run_fetch_opts(
-        FetchOpts {
-            cwd: Some(dir.path().to_path_buf()),
-            ..Default::default()
-        },
-        &[
-            &format!("{}/traversal", server.url),
-            "--remote-name",
-            "--remote-header-name",
-        ],
+    // A checksum mismatch keeps the bytes the file held before the run, and
+    // only removes a file fetch created.
+    let mismatch = "sha1:0000000000000000000000000000000000000000";
+    let kept = dir.path().join("kept.bin");
+    fs::write(&kept, &BODY[..12]).unwrap();
+    let res = run_fetch(&[
+        &format!("{}/file", server.url),
+        "-C",
+        "auto",
+        "--checksum",
+        mismatch,
+        "-o",
+        kept.to_str().unwrap(),
+    ]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("response body checksum mismatch"),
+        "stderr:\n{}",
+        res.stderr
     );
-    assert_exit(&res, 0);
-    assert_eq!(
-        fs::read_to_string(dir.path().join("bad.txt")).unwrap(),
-        "bad"
+    assert_eq!(fs::read(&kept).unwrap(), &BODY[..12]);
+
+    let created = dir.path().join("created.bin");
+    let res = run_fetch(&[
+        &format!("{}/file", server.url),
+        "-C",
+        "auto",
+        "--checksum",
+        mismatch,
+        "-o",
+        created.to_str().unwrap(),
+    ]);
+    assert_exit(&res, 1);
+    assert!(!created.exists());
+
+    let res = run_fetch(&[&format!("{}/file", server.url), "-C", "auto"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("flag '--continue-at' requires '--output' or '--remote-name'"),
+        "stderr:\n{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn check_hosts_reports_status_and_latency_table() {
+    let server = TestServer::start(|req| match req.path.as_str() {
+        "/down" => TestResponse::status(503, "Service Unavailable", "down"),
+        _ => TestResponse::ok("ok"),
+    });
+    let dir = TempDir::new().unwrap();
+    let hosts = temp_file(
+        dir.path(),
+        "hosts.txt",
+        &format!("# services\n{0}/ok\n\n{0}/down\n", server.url),
+    );
+
+    let res = run_fetch(&["--check-hosts", &format!("@{}", hosts.display())]);
+    assert_exit(&res, 5);
+    let lines: Vec<&str> = res
+        .stdout
+        .lines()
+        .filter(|line| !line.trim().is_empty())
+        .collect();
+    assert_eq!(lines.len(), 4, "stdout:\n{}", res.stdout);
+    assert!(lines[0].contains("URL") && lines[0].contains("LATENCY"));
+    assert!(lines[2].contains(&format!("{}/ok", server.url)));
+    assert!(lines[2].contains("200 OK") && lines[2].contains(" ms"));
+    assert!(lines[3].contains("503 Service Unavailable"));
+    assert_eq!(wait_for_requests(&server, 2).len(), 2);
+
+    let empty = temp_file(dir.path(), "empty.txt", "# nothing\n");
+    let res = run_fetch(&["--check-hosts", &format!("@{}", empty.display())]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("no URLs found"),
+        "stderr:\n{}",
+        res.stderr
     );
-    assert!(!dir.path().join("..").join("bad.txt").exists());
 }
 
 #[test]
@@ -1495,7 +2717,7 @@ fn request_construction_host_header_form_and_http_version() {
     assert_exit(&res, 1);
     assert!(
         res.stderr.contains(
-            "plain HTTP/2 is only supported for gRPC h2c; use https://, --grpc, or --http 1."
+            "plain HTTP/2 requires h2c; use https://, --grpc, --http2-prior-knowledge, or --http 1."
         ),
         "{}",
         res.stderr
@@ -1505,7 +2727,7 @@ fn request_construction_host_header_form_and_http_version() {
     assert_exit(&res, 1);
     assert!(
         res.stderr.contains(
-            "plain HTTP/2 is only supported for gRPC h2c; use https://, --grpc, or --http 1."
+            "plain HTTP/2 requires h2c; use https://, --grpc, --http2-prior-knowledge, or --http 1."
         ),
         "{}",
         res.stderr
@@ -1541,6 +2763,34 @@ fn request_query_form_and_multipart_values_preserve_spaces_after_equals() {
     assert!(body.contains("name=\"note\"\r\n\r\n hello \r\n"), "{body}");
 }
 
+#[test]
+fn path_params_fill_encoded_url_placeholders() {
+    let server = TestServer::start(|_| TestResponse::ok(""));
+
+    let res = run_fetch(&[
+        &format!("{}/users/{{id}}/files/{{name}}", server.url),
+        "--path-param",
+        "id=42",
+        "--path-param",
+        "name=a b/c?.txt",
+    ]);
+    assert_exit(&res, 0);
+    let req = wait_for_requests(&server, 1).remove(0);
+    assert_eq!(req.path, "/users/42/files/a%20b%2Fc%3F.txt");
+
+    let res = run_fetch(&[
+        &format!("{}/users/{{id}}", server.url),
+        "--path-param",
+        "user=42",
+    ]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("URL placeholder '{id}' has no value"),
+        "stderr: {}",
+        res.stderr
+    );
+}
+
 #[test]
 fn detailed_output_status_range_redirect_and_unix_edges() {
     let statuses = Arc::new(AtomicUsize::new(200));
@@ -1606,6 +2856,109 @@ fn detailed_output_status_range_redirect_and_unix_edges() {
     assert!(!res.stderr.contains("For more information"));
 }
 
+#[test]
+fn from_curl_reads_command_from_stdin() {
+    let server = TestServer::start(|req| {
+        if req.method == "POST" && req.header("x-pasted") == "yes" && req.body_string() == "a=1" {
+            TestResponse::ok("pasted")
+        } else {
+            TestResponse::status(400, "Bad Request", format!("{req:?}"))
+        }
+    });
+
+    // Browser "Copy as cURL" output spans lines with backslash continuations.
+    let command = format!(
+        "curl '{}/paste' \\\n  -H 'X-Pasted: yes' \\\n  --data-raw 'a=1'\n",
+        server.url
+    );
+    let res = run_fetch_opts(
+        FetchOpts {
+            stdin: Some(command),
+            ..Default::default()
+        },
+        &["--from-curl", "-"],
+    );
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "pasted");
+
+    let res = run_fetch_opts(
+        FetchOpts {
+            stdin: Some(format!("curl -d @- {}/paste", server.url)),
+            ..Default::default()
+        },
+        &["--from-curl", "-"],
+    );
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("cannot also come from stdin"),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    let res = run_fetch_opts(
+        FetchOpts {
+            stdin: Some("\n".to_string()),
+            ..Default::default()
+        },
+        &["--from-curl", "-"],
+    );
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("requires a curl command on stdin"));
+    assert_eq!(server.requests().len(), 1);
+}
+
+#[test]
+fn from_curl_reads_headers_from_file() {
+    let server = TestServer::start(|req| {
+        if req.header("x-one") == "1"
+            && req.header("x-two") == "two words"
+            && req.header("content-type") == "text/plain"
+            && req.body_string() == "hi"
+        {
+            TestResponse::ok("headers-file")
+        } else {
+            TestResponse::status(400, "Bad Request", format!("{req:?}"))
+        }
+    });
+
+    let dir = TempDir::new().unwrap();
+    let headers = dir.path().join("headers.txt");
+    fs::write(
+        &headers,
+        "X-One: 1\r\n\nContent-Type: text/plain\r\nX-Two:  two words\n",
+    )
+    .unwrap();
+    let curl = format!("curl -H @{} -d hi {}/post", headers.display(), server.url);
+    let res = run_fetch(&["--from-curl", &curl]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "headers-file");
+
+    let bad = dir.path().join("bad.txt");
+    fs::write(&bad, "X-Ok: yes\nnot a header\n").unwrap();
+    let res = run_fetch(&[
+        "--from-curl",
+        &format!("curl -H @{} {}", bad.display(), server.url),
+    ]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains(r#"invalid header: "not a header""#),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    let res = run_fetch(&[
+        "--from-curl",
+        &format!(
+            "curl -H @{} {}",
+            dir.path().join("missing").display(),
+            server.url
+        ),
+    ]);
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("unable to read curl header file"));
+    assert_eq!(server.requests().len(), 1);
+}
+
 #[test]
 fn from_curl_individual_go_cases() {
     let server = TestServer::start(|req| {
@@ -1834,6 +3187,43 @@ fn multipart_form_and_redirect_replay() {
     assert!(seen.load(Ordering::SeqCst) >= 3);
 }
 
+#[test]
+fn multipart_file_part_type_and_filename_options() {
+    let server = TestServer::start(|req| TestResponse::ok(req.body_string()));
+    let dir = TempDir::new().unwrap();
+    let file = temp_file(dir.path(), "photo.dat", "image bytes");
+
+    let res = run_fetch(&[
+        &server.url,
+        "-F",
+        &format!("file=@{};type=image/png;filename=photo.png", file.display()),
+        "--format",
+        "off",
+    ]);
+    assert_exit(&res, 0);
+    assert!(
+        res.stdout.contains(
+            "Content-Disposition: form-data; name=\"file\"; filename=\"photo.png\"\r\n\
+             Content-Type: image/png\r\n\r\nimage bytes\r\n"
+        ),
+        "body: {}",
+        res.stdout
+    );
+
+    let res = run_fetch(&[
+        &server.url,
+        "-F",
+        &format!("file=@{};type=image", file.display()),
+    ]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("invalid multipart content type 'image': must be in the form TYPE/SUBTYPE"),
+        "stderr: {}",
+        res.stderr
+    );
+}
+
 #[test]
 fn timeout_copy_discard_and_session_cases() {
     let slow = TestServer::start(|_| {
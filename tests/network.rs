@@ -9,6 +9,7 @@ use std::thread;
 use std::time::Duration;
 use support::common::{
     FAST_RETRY_DELAY, FetchOpts, assert_exit, host_port, run_fetch, run_fetch_opts,
+    run_fetch_via_proxy,
 };
 use support::dns::{
     parse_dns_question, start_udp_dns_server, start_udp_dns_server_dropping_https,
@@ -25,10 +26,13 @@ use support::http::{TestResponse, TestServer};
 use support::http3::{H3Response, start_http3_server, wait_for_h3_requests};
 use support::proxy::{
     assert_socks_seen, start_http_connect_proxy, start_https_proxy, start_socks5_proxy,
-    start_stalling_proxy,
+    start_socks5_proxy_with_auth, start_stalling_proxy,
 };
+#[cfg(unix)]
+use support::tls::start_unix_tls_echo_server;
 use support::tls::{
-    start_h2_tls_server, start_h2_tls_server_with_accept_delay, start_mtls_server, start_tls_server,
+    start_h2_tls_server, start_h2_tls_server_with_accept_delay, start_h2c_server,
+    start_mtls_server, start_tls_server,
 };
 use tempfile::TempDir;
 use url::Url;
@@ -90,6 +94,28 @@ fn custom_dns_connects_after_fast_a_without_waiting_for_slow_aaaa() {
     assert!(res.stderr.contains("* TCP: 127.0.0.1:"));
 }
 
+#[test]
+fn custom_dns_fails_over_to_next_server() {
+    let server = TestServer::start(|_req| TestResponse::ok("failover ok"));
+    let port = Url::parse(&server.url).unwrap().port().unwrap();
+    let dns_addr = start_udp_dns_server("fetch-dns-failover.test.", Ipv4Addr::new(127, 0, 0, 1));
+    let closed = std::net::TcpListener::bind("127.0.0.1:0").unwrap();
+    let closed_dns = format!("tcp://{}", closed.local_addr().unwrap());
+    drop(closed);
+    let url = format!("http://fetch-dns-failover.test:{port}/");
+
+    let res = run_fetch(&["--dns-server", &closed_dns, "--dns-server", &dns_addr, &url]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "failover ok");
+
+    let res = run_fetch(&["--dns-server", &format!("{closed_dns},{dns_addr}"), &url]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "failover ok");
+
+    let res = run_fetch(&["--dns-server", &closed_dns, &url]);
+    assert_exit(&res, 1);
+}
+
 #[test]
 fn ech_discovery_overlaps_custom_address_resolution() {
     let tls = start_tls_server(|_| TestResponse::ok("ECH DNS overlap"));
@@ -176,7 +202,7 @@ fn connect_timeout_is_shared_between_preresolved_dns_and_tls() {
 
     assert_exit(&res, 1);
     assert!(
-        res.stderr.contains("request timed out after 1s"),
+        res.stderr.contains("connect timed out after 1s"),
         "{}",
         res.stderr
     );
@@ -205,7 +231,7 @@ fn proxy_config_environment_and_curl_http1_cases() {
         TestResponse::status(400, "Bad Request", format!("unexpected {}", req.path))
     });
 
-    let res = run_fetch(&[
+    let res = run_fetch_via_proxy(&[
         "--proxy",
         &proxy.url,
         "--format",
@@ -223,7 +249,7 @@ fn proxy_config_environment_and_curl_http1_cases() {
     let dir = TempDir::new().unwrap();
     let config = dir.path().join("config");
     fs::write(&config, format!("format = off\nproxy = {}\n", proxy.url)).unwrap();
-    let res = run_fetch(&[
+    let res = run_fetch_via_proxy(&[
         "--config",
         config.to_str().unwrap(),
         "http://config-proxy.example/from-config",
@@ -255,7 +281,7 @@ fn proxy_config_environment_and_curl_http1_cases() {
         "curl --proxy {} http://curl-proxy.example/from-curl",
         proxy.url
     );
-    let res = run_fetch(&["--format", "off", "--from-curl", &cmd]);
+    let res = run_fetch_via_proxy(&["--format", "off", "--from-curl", &cmd]);
     assert_exit(&res, 0);
     assert_eq!(res.stdout, "curl proxy");
 
@@ -306,6 +332,68 @@ fn proxy_config_environment_and_curl_http1_cases() {
     );
 }
 
+#[test]
+fn proxy_credentials_send_proxy_authorization() {
+    let proxy = TestServer::start(|req| TestResponse::ok(req.header("proxy-authorization")));
+    let authority = host_port(&proxy.url);
+
+    let res = run_fetch_via_proxy(&[
+        "--proxy",
+        &format!("http://user:pass@{authority}"),
+        "http://target.example/",
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "Basic dXNlcjpwYXNz");
+
+    let res = run_fetch_via_proxy(&[
+        "--proxy",
+        &format!("http://user:pass@{authority}"),
+        "--proxy-user",
+        "alice:s3:cret",
+        "http://target.example/",
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "Basic YWxpY2U6czM6Y3JldA==");
+
+    let curl = format!("curl -x {authority} -U bob:p@ss http://target.example/");
+    let res = run_fetch_via_proxy(&["--from-curl", &curl]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "Basic Ym9iOnBAc3M=");
+
+    let res = run_fetch_via_proxy(&["--proxy-user", "alice:secret", "http://target.example/"]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("flag '--proxy-user' requires '--proxy'"),
+        "{}",
+        res.stderr
+    );
+    assert_eq!(proxy.requests().len(), 3);
+}
+
+#[test]
+fn explicit_proxy_honors_no_proxy() {
+    let target = TestServer::start(|_| TestResponse::ok("direct"));
+    let proxy = TestServer::start(|_| TestResponse::ok("proxied"));
+    let opts = |no_proxy: &str| FetchOpts {
+        env: vec![
+            ("NO_PROXY".to_string(), no_proxy.to_string()),
+            ("no_proxy".to_string(), String::new()),
+        ],
+        ..Default::default()
+    };
+
+    let res = run_fetch_opts(opts("127.0.0.1"), &["--proxy", &proxy.url, &target.url]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "direct");
+    assert!(proxy.requests().is_empty());
+
+    let res = run_fetch_opts(opts("other.example"), &["--proxy", &proxy.url, &target.url]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "proxied");
+    assert_eq!(target.requests().len(), 1);
+}
+
 #[test]
 fn env_https_proxy_skips_local_target_dns_preresolution() {
     let target = start_tls_server(|req| {
@@ -357,7 +445,7 @@ fn https_proxy_tls_ignores_origin_tls_settings() {
     let ca = proxy.ca_cert_path.to_str().unwrap();
     let target = "http://origin.example/proxied";
 
-    let res = run_fetch(&[
+    let res = run_fetch_via_proxy(&[
         "--format",
         "off",
         "--proxy",
@@ -369,7 +457,7 @@ fn https_proxy_tls_ignores_origin_tls_settings() {
     assert_exit(&res, 1);
     assert!(proxy.requests().is_empty());
 
-    let res = run_fetch(&[
+    let res = run_fetch_via_proxy(&[
         "--format",
         "off",
         "--proxy",
@@ -388,7 +476,7 @@ fn https_proxy_mtls_does_not_receive_origin_client_certificate() {
     let key = proxy.client_key_path.to_str().unwrap();
     let target = "http://origin.example/mtls-proxy";
 
-    let res = run_fetch(&[
+    let res = run_fetch_via_proxy(&[
         "--format",
         "off",
         "--proxy",
@@ -804,6 +892,23 @@ fn default_https_uses_cached_http3_from_alt_svc_header() {
     assert_eq!(requests[0].path, "/alt-svc-cache");
 }
 
+#[test]
+fn http2_prior_knowledge_speaks_h2c_to_plain_http_servers() {
+    let h2c = start_h2c_server(|req| TestResponse::ok(format!("{} {}", req.method, req.path)));
+
+    let res = run_fetch(&["--http2-prior-knowledge", &format!("{}/items?x=1", h2c.url)]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "GET /items?x=1");
+
+    let res = run_fetch(&["--http2-prior-knowledge", "-v", &h2c.url]);
+    assert_exit(&res, 0);
+    assert!(res.stderr.contains("HTTP/2.0 200 OK"), "{}", res.stderr);
+
+    let http1 = TestServer::start(|_| TestResponse::ok("http/1.1 only"));
+    let res = run_fetch(&["--http2-prior-knowledge", &http1.url]);
+    assert_exit(&res, 1);
+}
+
 #[test]
 fn default_https_races_cached_alt_svc_without_waiting_for_slow_https_record_lookup() {
     let cache_dir = TempDir::new().unwrap();
@@ -1428,7 +1533,7 @@ fn dns_over_https_udp_and_inspect_dns_cases() {
         "https://fetch-inspect-dns-timeout.test",
     ]);
     assert_exit(&res, 1);
-    assert!(res.stderr.contains("request timed out after 50ms"));
+    assert!(res.stderr.contains("connect timed out after 50ms"));
 
     let res = run_fetch(&[
         "--inspect-dns",
@@ -1533,7 +1638,7 @@ fn dns_over_https_udp_and_inspect_dns_cases() {
         &format!("http://fetch-dns-timeout.test:{target_port}"),
     ]);
     assert_exit(&res, 1);
-    assert!(res.stderr.contains("request timed out after 50ms"));
+    assert!(res.stderr.contains("connect timed out after 50ms"));
     assert!(!res.stderr.contains("For more information"));
 }
 
@@ -1551,7 +1656,7 @@ fn socks_proxy_unix_socket_timing_and_grpc_binary_cases() {
     let target_addr = host_port(&server.url).to_string();
     let (proxy_url, seen) = start_socks5_proxy(target_addr.clone());
 
-    let res = run_fetch(&[
+    let res = run_fetch_via_proxy(&[
         "--proxy",
         &proxy_url,
         "--format",
@@ -1566,14 +1671,14 @@ fn socks_proxy_unix_socket_timing_and_grpc_binary_cases() {
         "curl --proxy {proxy_url} --silent {}/from-curl-socks",
         server.url
     );
-    let res = run_fetch(&["--from-curl", &curl]);
+    let res = run_fetch_via_proxy(&["--from-curl", &curl]);
     assert_exit(&res, 0);
     assert_eq!(res.stdout, "socks /from-curl-socks");
     assert_socks_seen(&seen, &target_addr);
 
     let dns_port = Url::parse(&server.url).unwrap().port().unwrap();
     let dns_addr = start_udp_dns_server("fetch-socks-dns.test.", Ipv4Addr::new(127, 0, 0, 1));
-    let res = run_fetch(&[
+    let res = run_fetch_via_proxy(&[
         "--dns-server",
         &dns_addr,
         "--proxy",
@@ -1725,6 +1830,50 @@ fn socks_proxy_unix_socket_timing_and_grpc_binary_cases() {
     assert!(res.stdout.contains("resource not found"));
 }
 
+#[test]
+fn socks5h_proxy_authenticates_and_resolves_remotely() {
+    let tls = start_tls_server(|_| TestResponse::ok("socks-h1"));
+    let tls_addr = host_port(&tls.url).to_string();
+    let (proxy_url, seen) = start_socks5_proxy_with_auth(tls_addr.clone(), "user", "p@ss");
+    let authed_url = proxy_url.replacen("socks5://", "socks5h://user:p%40ss@", 1);
+    let res = run_fetch_via_proxy(&[
+        "--proxy",
+        &authed_url,
+        "--ca-cert",
+        tls.ca_cert_path.to_str().unwrap(),
+        &tls.url,
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "socks-h1");
+    assert_socks_seen(&seen, &tls_addr);
+
+    let h2 = start_h2_tls_server(|_| TestResponse::ok("socks-h2"));
+    let h2_addr = host_port(&h2.url).to_string();
+    let (proxy_url, seen) = start_socks5_proxy_with_auth(h2_addr.clone(), "user", "p@ss");
+    let authed_url = proxy_url.replacen("socks5://", "socks5h://user:p%40ss@", 1);
+    let res = run_fetch_via_proxy(&[
+        "--proxy",
+        &authed_url,
+        "--http",
+        "2",
+        "--ca-cert",
+        h2.ca_cert_path.to_str().unwrap(),
+        &h2.url,
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "socks-h2");
+    assert_socks_seen(&seen, &h2_addr);
+
+    let wrong_url = proxy_url.replacen("socks5://", "socks5h://user:wrong@", 1);
+    let res = run_fetch_via_proxy(&["--proxy", &wrong_url, &h2.url]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("SOCKS5 proxy authentication failed"),
+        "{}",
+        res.stderr
+    );
+}
+
 #[test]
 fn tls_certificate_validation_inspection_and_bounds_cases() {
     let requests = Arc::new(AtomicUsize::new(0));
@@ -1867,7 +2016,7 @@ fn tls_certificate_validation_inspection_and_bounds_cases() {
         &stalling_tls,
     ]);
     assert_exit(&res, 1);
-    assert!(res.stderr.contains("request timed out after 50ms"));
+    assert!(res.stderr.contains("connect timed out after 50ms"));
 
     let dns_addr = start_udp_dns_server("fetch-tls.test.", Ipv4Addr::new(127, 0, 0, 1));
     let tls_url = Url::parse(&tls.url).unwrap();
@@ -1892,6 +2041,430 @@ fn tls_certificate_validation_inspection_and_bounds_cases() {
     assert!(res.stderr.contains("timing") || res.stderr.contains("TLS"));
 }
 
+#[test]
+fn double_verbose_prints_negotiated_tls_details() {
+    let tls = start_tls_server(|_| TestResponse::ok("tls details"));
+    let ca = tls.ca_cert_path.to_str().unwrap();
+
+    let res = run_fetch(&["--ca-cert", ca, "-vv", &tls.url]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "tls details");
+    assert!(res.stderr.contains("* TLS 1.3: TLS13_"), "{}", res.stderr);
+    assert!(
+        res.stderr.contains("* Certificate chain:"),
+        "{}",
+        res.stderr
+    );
+    assert!(res.stderr.contains("* SANs: localhost"), "{}", res.stderr);
+
+    let res = run_fetch(&["--ca-cert", ca, "-v", &tls.url]);
+    assert_exit(&res, 0);
+    assert!(!res.stderr.contains("Certificate chain"), "{}", res.stderr);
+}
+
+#[test]
+fn ca_path_trusts_certificates_in_directory() {
+    let tls = start_tls_server(|_| TestResponse::ok("ca path"));
+    let dir = TempDir::new().unwrap();
+    fs::copy(&tls.ca_cert_path, dir.path().join("ca.pem")).unwrap();
+    fs::write(dir.path().join("README"), "not a certificate").unwrap();
+    let ca_path = dir.path().to_str().unwrap();
+
+    let res = run_fetch(&["--ca-path", ca_path, &tls.url]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "ca path");
+
+    fs::write(dir.path().join("broken.crt"), "not a certificate").unwrap();
+    let res = run_fetch(&["--ca-path", ca_path, &tls.url]);
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("broken.crt"), "{}", res.stderr);
+    assert!(
+        res.stderr.contains("no certificates found"),
+        "{}",
+        res.stderr
+    );
+
+    let empty = TempDir::new().unwrap();
+    let res = run_fetch(&["--ca-path", empty.path().to_str().unwrap(), &tls.url]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("no .pem, .crt, or .cer files found"),
+        "{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn ciphers_limit_offered_cipher_suites() {
+    let tls = start_tls_server(|_| TestResponse::ok("ciphers"));
+    let ca = tls.ca_cert_path.to_str().unwrap();
+
+    let res = run_fetch(&[
+        "--ca-cert",
+        ca,
+        "--ciphers",
+        "TLS_CHACHA20_POLY1305_SHA256",
+        "-vvv",
+        &tls.url,
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "ciphers");
+    assert!(
+        res.stderr
+            .contains("* TLS 1.3: TLS13_CHACHA20_POLY1305_SHA256"),
+        "{}",
+        res.stderr
+    );
+
+    let res = run_fetch(&[
+        "--ca-cert",
+        ca,
+        "--min-tls",
+        "1.3",
+        "--ciphers",
+        "ECDHE-RSA-AES128-GCM-SHA256",
+        &tls.url,
+    ]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("--ciphers does not include a cipher suite for TLS 1.3"),
+        "{}",
+        res.stderr
+    );
+
+    let res = run_fetch(&["--ciphers", "RC4-MD5", &tls.url]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("unknown cipher suite 'RC4-MD5'"),
+        "{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn insecure_warns_and_can_be_locked_by_env() {
+    let tls = start_tls_server(|_| TestResponse::ok("tls-ok"));
+
+    let res = run_fetch(&["--insecure", &tls.url]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "tls-ok");
+    assert!(
+        res.stderr
+            .contains("warning: TLS certificate verification is disabled"),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    for args in [
+        vec!["--insecure", "--quiet-insecure", &tls.url],
+        vec!["--insecure", "--silent", &tls.url],
+    ] {
+        let res = run_fetch(&args);
+        assert_exit(&res, 0);
+        assert!(
+            !res.stderr.contains("verification is disabled"),
+            "args {args:?}: stderr:\n{}",
+            res.stderr
+        );
+    }
+
+    let locked = || FetchOpts {
+        env: vec![("FETCH_NO_INSECURE".to_string(), "1".to_string())],
+        ..Default::default()
+    };
+    let res = run_fetch_opts(locked(), &["--insecure", &tls.url]);
+    assert_exit(&res, 1);
+    assert!(res.stdout.is_empty());
+    assert!(
+        res.stderr.contains("'--insecure' is disabled by"),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    let curl = format!("curl -k {}", tls.url);
+    let res = run_fetch_opts(locked(), &["--from-curl", &curl]);
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("FETCH_NO_INSECURE"));
+
+    let dir = TempDir::new().unwrap();
+    let config = dir.path().join("config");
+    fs::write(&config, "insecure = true\n").unwrap();
+    let res = run_fetch_opts(locked(), &["--config", config.to_str().unwrap(), &tls.url]);
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("FETCH_NO_INSECURE"));
+
+    let res = run_fetch_opts(
+        FetchOpts {
+            env: vec![("FETCH_NO_INSECURE".to_string(), "0".to_string())],
+            ..Default::default()
+        },
+        &["--insecure", "--quiet-insecure", &tls.url],
+    );
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "tls-ok");
+}
+
+#[test]
+fn require_https_rejects_cleartext_requests_and_redirects() {
+    let plain = TestServer::start(|_| TestResponse::ok("plain"));
+    let plain_url = plain.url.clone();
+    let tls = start_tls_server(move |req| {
+        if req.path == "/downgrade" {
+            TestResponse::status(302, "Found", "").header("Location", &plain_url)
+        } else {
+            TestResponse::ok("secure")
+        }
+    });
+
+    let res = run_fetch(&[
+        "--insecure",
+        "--quiet-insecure",
+        "--require-https",
+        &tls.url,
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "secure");
+
+    let res = run_fetch(&[
+        "--insecure",
+        "--quiet-insecure",
+        "--require-https",
+        &format!("{}/downgrade", tls.url),
+    ]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("'--require-https' refuses to follow a redirect to http://"),
+        "stderr:\n{}",
+        res.stderr
+    );
+
+    let res = run_fetch(&["--require-https", &plain.url]);
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("refuses to send a request to http://"));
+    assert!(!res.stderr.contains("inferred"));
+
+    let schemeless = plain.url.trim_start_matches("http://");
+    let res = run_fetch(&["--require-https", schemeless]);
+    assert_exit(&res, 1);
+    assert!(res.stderr.contains("http was inferred"));
+    assert!(plain.requests().is_empty());
+
+    let res = run_fetch(&["-v", schemeless]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "plain");
+    assert!(
+        res.stderr.contains(
+            "* Scheme: http (no scheme given; loopback and IP address hosts default to http)"
+        ),
+        "stderr:\n{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn h2_settings_flags_apply_and_validate() {
+    let h2 = start_h2_tls_server(|_req| TestResponse::ok("h2-ok"));
+    let res = run_fetch(&[
+        "-vvv",
+        "--ca-cert",
+        h2.ca_cert_path.to_str().unwrap(),
+        "--h2-max-frame-size",
+        "65536",
+        "--h2-initial-window-size",
+        "1048576",
+        "--h2-max-concurrent-streams",
+        "10",
+        &h2.url,
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "h2-ok");
+    assert!(
+        res.stderr.contains(
+            "HTTP/2 client SETTINGS: max frame size 65536, initial window size 1048576, \
+             max concurrent streams 10"
+        ),
+        "{}",
+        res.stderr
+    );
+
+    let res = run_fetch(&["--h2-max-frame-size", "1024", &h2.url]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("must be between 16384 and 16777215"),
+        "{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn max_headers_rejects_oversized_response_headers() {
+    let padded = |_req| TestResponse::ok("ok").header("X-Padding", &"x".repeat(20_000));
+    let server = TestServer::start(padded);
+
+    let res = run_fetch(&[&server.url]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "ok");
+
+    let res = run_fetch(&["--max-headers", "16384", &server.url]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr
+            .contains("response headers exceed 16384 bytes; raise the limit with --max-headers"),
+        "{}",
+        res.stderr
+    );
+
+    let h2 = start_h2_tls_server(padded);
+    let res = run_fetch(&[
+        "--ca-cert",
+        h2.ca_cert_path.to_str().unwrap(),
+        "--max-headers",
+        "16384",
+        &h2.url,
+    ]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("response headers exceed 16384 bytes"),
+        "{}",
+        res.stderr
+    );
+
+    let res = run_fetch(&["--max-headers", "1024", &server.url]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("must be at least 8192"),
+        "{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn resolve_pins_host_and_port_to_address() {
+    let server = TestServer::start(|req| TestResponse::ok(req.header("host")));
+    let port = Url::parse(&server.url).unwrap().port().unwrap();
+    let url = format!("http://pinned.invalid:{port}/");
+
+    let res = run_fetch(&[
+        "--resolve",
+        &format!("pinned.invalid:{port}:127.0.0.1"),
+        &url,
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, format!("pinned.invalid:{port}"));
+
+    let other_port = if port == 1 { 2 } else { port - 1 };
+    let res = run_fetch(&[
+        "--resolve",
+        &format!("pinned.invalid:{other_port}:127.0.0.1"),
+        &url,
+    ]);
+    assert_exit(&res, 1);
+    assert_eq!(server.requests().len(), 1);
+
+    let tls = start_tls_server(|req| TestResponse::ok(req.header("host")));
+    let tls_port = Url::parse(&tls.url).unwrap().port().unwrap();
+    let res = run_fetch(&[
+        "--ca-cert",
+        tls.ca_cert_path.to_str().unwrap(),
+        "--dns-server",
+        "127.0.0.1:9",
+        "--resolve",
+        &format!("localhost:{tls_port}:127.0.0.1"),
+        &tls.url,
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, format!("localhost:{tls_port}"));
+
+    let res = run_fetch(&["--resolve", "pinned.invalid:80", &url]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("must be HOST:PORT:ADDR"),
+        "{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn connect_to_reroutes_connection_but_keeps_host() {
+    let server = TestServer::start(|req| TestResponse::ok(req.header("host")));
+    let port = Url::parse(&server.url).unwrap().port().unwrap();
+
+    let res = run_fetch(&[
+        "--connect-to",
+        &format!("vhost.invalid:80:127.0.0.1:{port}"),
+        "http://vhost.invalid/",
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "vhost.invalid");
+
+    let res = run_fetch(&[
+        "--connect-to",
+        &format!("::127.0.0.1:{port}"),
+        "http://any.invalid:8080/",
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "any.invalid:8080");
+
+    let res = run_fetch(&[
+        "--connect-to",
+        &format!("other.invalid:80:127.0.0.1:{port}"),
+        "http://vhost.invalid/",
+    ]);
+    assert_exit(&res, 1);
+    assert_eq!(server.requests().len(), 2);
+
+    let tls = start_tls_server(|req| TestResponse::ok(req.header("host")));
+    let tls_port = Url::parse(&tls.url).unwrap().port().unwrap();
+    let res = run_fetch(&[
+        "--ca-cert",
+        tls.ca_cert_path.to_str().unwrap(),
+        "--dns-server",
+        "127.0.0.1:9",
+        "--connect-to",
+        &format!("localhost:1:127.0.0.1:{tls_port}"),
+        "https://localhost:1/",
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "localhost:1");
+
+    let res = run_fetch(&["--connect-to", "vhost.invalid:80:127.0.0.1", &server.url]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains("must be HOST:PORT:HOST2:PORT2"),
+        "{}",
+        res.stderr
+    );
+}
+
+#[test]
+fn host_header_override_keeps_tls_server_name() {
+    let tls = start_tls_server(|req| TestResponse::ok(req.header("host")));
+    let res = run_fetch(&[
+        "--ca-cert",
+        tls.ca_cert_path.to_str().unwrap(),
+        "-H",
+        "Host: vhost.example",
+        &tls.url,
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "vhost.example");
+
+    let h2 = start_h2_tls_server(|req| TestResponse::ok(req.header("host")));
+    let res = run_fetch(&[
+        "--http",
+        "2",
+        "--ca-cert",
+        h2.ca_cert_path.to_str().unwrap(),
+        "-H",
+        "Host: vhost.example",
+        &h2.url,
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "vhost.example");
+}
+
 #[test]
 fn mtls_client_certificate_go_cases() {
     let mtls = start_mtls_server();
@@ -1951,4 +2524,80 @@ fn mtls_client_certificate_go_cases() {
     ]);
     assert_exit(&res, 1);
     assert!(res.stderr.contains("does not exist"));
+
+    let other_key = dir.path().join("other-client.key");
+    fs::write(
+        &other_key,
+        rcgen::KeyPair::generate().unwrap().serialize_pem(),
+    )
+    .unwrap();
+    let other_key = other_key.to_str().unwrap();
+    let res = run_fetch(&[
+        "--ca-cert",
+        ca,
+        "--cert",
+        cert,
+        "--key",
+        other_key,
+        &mtls.url,
+    ]);
+    assert_exit(&res, 1);
+    assert!(
+        res.stderr.contains(&format!(
+            "client certificate '{cert}' does not match key '{other_key}'"
+        )),
+        "{}",
+        res.stderr
+    );
+}
+
+#[cfg(unix)]
+#[test]
+fn unix_socket_keeps_url_host_for_host_header_and_sni() {
+    use std::os::unix::net::UnixListener;
+
+    let dir = TempDir::new().unwrap();
+    let sock = dir.path().join("docker.sock");
+    let listener = UnixListener::bind(&sock).unwrap();
+    thread::spawn(move || {
+        for stream in listener.incoming() {
+            let Ok(mut stream) = stream else {
+                break;
+            };
+            let mut buf = [0_u8; 1024];
+            let n = stream.read(&mut buf).unwrap_or(0);
+            let head = String::from_utf8_lossy(&buf[..n]).to_ascii_lowercase();
+            let line = head.lines().next().unwrap_or_default().to_string();
+            let host = head
+                .lines()
+                .find_map(|line| line.strip_prefix("host: "))
+                .unwrap_or_default()
+                .to_string();
+            let body = format!("{line} {host}");
+            let _ = write!(
+                stream,
+                "HTTP/1.1 200 OK\r\ncontent-length: {}\r\nconnection: close\r\n\r\n{body}",
+                body.len()
+            );
+        }
+    });
+    let res = run_fetch(&[
+        "--unix-socket",
+        sock.to_str().unwrap(),
+        "http://localhost/containers/json?all=1",
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "get /containers/json?all=1 http/1.1 localhost");
+
+    let tls_sock = dir.path().join("tls.sock");
+    let ca_cert = start_unix_tls_echo_server(&tls_sock);
+    let res = run_fetch(&[
+        "--unix",
+        tls_sock.to_str().unwrap(),
+        "--ca-cert",
+        ca_cert.to_str().unwrap(),
+        "https://docker.local/version",
+    ]);
+    assert_exit(&res, 0);
+    assert_eq!(res.stdout, "docker.local docker.local /version");
 }
@@ -51,6 +51,18 @@ pub(crate) fn run_fetch(args: &[&str]) -> FetchOutput {
     run_fetch_opts(FetchOpts::default(), args)
 }
 
+/// Run fetch with `NO_PROXY` cleared. The default `NO_PROXY=*` keeps system
+/// proxies away from test servers, but it also bypasses an explicit proxy.
+pub(crate) fn run_fetch_via_proxy(args: &[&str]) -> FetchOutput {
+    run_fetch_opts(
+        FetchOpts {
+            env: vec![("NO_PROXY".to_string(), String::new())],
+            ..Default::default()
+        },
+        args,
+    )
+}
+
 pub(crate) fn fetch_version() -> String {
     let res = run_fetch(&["--version"]);
     assert_exit(&res, 0);
@@ -38,6 +38,26 @@ impl Drop for HttpsProxyTestServer {
 }
 
 pub(crate) fn start_socks5_proxy(target_addr: String) -> (String, mpsc::Receiver<String>) {
+    spawn_socks5_proxy(target_addr, None)
+}
+
+/// Start a SOCKS5 proxy that requires username/password authentication. The
+/// returned URL has no credentials.
+pub(crate) fn start_socks5_proxy_with_auth(
+    target_addr: String,
+    username: &str,
+    password: &str,
+) -> (String, mpsc::Receiver<String>) {
+    spawn_socks5_proxy(
+        target_addr,
+        Some((username.to_string(), password.to_string())),
+    )
+}
+
+fn spawn_socks5_proxy(
+    target_addr: String,
+    auth: Option<(String, String)>,
+) -> (String, mpsc::Receiver<String>) {
     let listener = TcpListener::bind("127.0.0.1:0").expect("bind socks proxy");
     let proxy_url = format!("socks5://{}", listener.local_addr().unwrap());
     let (seen_tx, seen_rx) = mpsc::channel();
@@ -47,8 +67,9 @@ pub(crate) fn start_socks5_proxy(target_addr: String) -> (String, mpsc::Receiver
                 break;
             };
             let target_addr = target_addr.clone();
+            let auth = auth.clone();
             let seen_tx = seen_tx.clone();
-            thread::spawn(move || handle_socks5_conn(conn, &target_addr, seen_tx));
+            thread::spawn(move || handle_socks5_conn(conn, &target_addr, auth.as_ref(), seen_tx));
         }
     });
     (proxy_url, seen_rx)
@@ -57,6 +78,7 @@ pub(crate) fn start_socks5_proxy(target_addr: String) -> (String, mpsc::Receiver
 pub(crate) fn handle_socks5_conn(
     mut conn: TcpStream,
     target_addr: &str,
+    auth: Option<&(String, String)>,
     seen: mpsc::Sender<String>,
 ) {
     let mut header = [0_u8; 2];
@@ -64,9 +86,27 @@ pub(crate) fn handle_socks5_conn(
         return;
     }
     let mut methods = vec![0; header[1] as usize];
-    if conn.read_exact(&mut methods).is_err() || conn.write_all(&[0x05, 0x00]).is_err() {
+    if conn.read_exact(&mut methods).is_err() {
         return;
     }
+    match auth {
+        None => {
+            if conn.write_all(&[0x05, 0x00]).is_err() {
+                return;
+            }
+        }
+        Some(_) if !methods.contains(&0x02) => {
+            let _ = conn.write_all(&[0x05, 0xff]);
+            return;
+        }
+        Some((username, password)) => {
+            if conn.write_all(&[0x05, 0x02]).is_err()
+                || !read_socks5_credentials(&mut conn, username, password)
+            {
+                return;
+            }
+        }
+    }
     let mut req = [0_u8; 4];
     if conn.read_exact(&mut req).is_err() || req[0] != 0x05 || req[1] != 0x01 {
         let _ = conn.write_all(&[0x05, 0x07, 0x00, 0x01, 0, 0, 0, 0, 0, 0]);
@@ -119,6 +159,28 @@ pub(crate) fn handle_socks5_conn(
     let _ = std::io::copy(&mut target, &mut conn);
 }
 
+/// Read a username/password subnegotiation (RFC 1929) and reply with whether
+/// it matched.
+fn read_socks5_credentials(conn: &mut TcpStream, username: &str, password: &str) -> bool {
+    let read_field = |conn: &mut TcpStream| -> Option<Vec<u8>> {
+        let mut len = [0_u8; 1];
+        conn.read_exact(&mut len).ok()?;
+        let mut raw = vec![0; len[0] as usize];
+        conn.read_exact(&mut raw).ok()?;
+        Some(raw)
+    };
+    let mut version = [0_u8; 1];
+    if conn.read_exact(&mut version).is_err() || version[0] != 0x01 {
+        return false;
+    }
+    let (Some(got_username), Some(got_password)) = (read_field(conn), read_field(conn)) else {
+        return false;
+    };
+    let ok = got_username == username.as_bytes() && got_password == password.as_bytes();
+    let _ = conn.write_all(&[0x01, if ok { 0x00 } else { 0x01 }]);
+    ok
+}
+
 pub(crate) fn start_http_connect_proxy(target_addr: String) -> (String, mpsc::Receiver<String>) {
     let listener = TcpListener::bind("127.0.0.1:0").expect("bind HTTP CONNECT proxy");
     let proxy_url = format!("http://{}", listener.local_addr().unwrap());
@@ -114,6 +114,51 @@ pub(crate) fn start_tls_server(
     }
 }
 
+/// Serve TLS on a Unix socket. Each response body is the SNI the client sent,
+/// the request's Host header, and its path, separated by spaces. Returns the
+/// CA certificate path.
+#[cfg(unix)]
+pub(crate) fn start_unix_tls_echo_server(socket: &std::path::Path) -> PathBuf {
+    let _ = rustls::crypto::aws_lc_rs::default_provider().install_default();
+    let certified = rcgen::generate_simple_self_signed(vec!["docker.local".to_string()]).unwrap();
+    let dir = TempDir::new().unwrap().keep();
+    let ca_cert_path = dir.join("ca.pem");
+    fs::write(&ca_cert_path, certified.cert.pem()).unwrap();
+    let cert_der = certified.cert.der().clone();
+    let key_der = rustls::pki_types::PrivateKeyDer::Pkcs8(
+        rustls::pki_types::PrivatePkcs8KeyDer::from(certified.signing_key.serialize_der()),
+    );
+    let config = rustls::ServerConfig::builder()
+        .with_no_client_auth()
+        .with_single_cert(vec![cert_der], key_der)
+        .unwrap();
+    let config = Arc::new(config);
+    let listener = std::os::unix::net::UnixListener::bind(socket).expect("bind unix tls server");
+    thread::spawn(move || {
+        for stream in listener.incoming() {
+            let Ok(stream) = stream else {
+                break;
+            };
+            let config = Arc::clone(&config);
+            thread::spawn(move || {
+                let Ok(conn) = rustls::ServerConnection::new(config) else {
+                    return;
+                };
+                let mut tls = rustls::StreamOwned::new(conn, stream);
+                let mut reader = BufReader::new(&mut tls);
+                let Some(req) = read_request(&mut reader) else {
+                    return;
+                };
+                let tls = reader.into_inner();
+                let sni = tls.conn.server_name().unwrap_or("-").to_string();
+                let body = format!("{sni} {} {}", req.header("host"), req.path);
+                write_response(tls, TestResponse::ok(body));
+            });
+        }
+    });
+    ca_cert_path
+}
+
 pub(crate) fn start_h2_tls_server(
     handler: impl Fn(TestRequest) -> TestResponse + Send + Sync + 'static,
 ) -> TlsTestServer {
@@ -189,6 +234,51 @@ pub(crate) fn start_h2_tls_server_with_accept_delay(
     }
 }
 
+/// Start a cleartext HTTP/2 server that expects the client to speak h2c with
+/// prior knowledge. `ca_cert_path` is empty.
+pub(crate) fn start_h2c_server(
+    handler: impl Fn(TestRequest) -> TestResponse + Send + Sync + 'static,
+) -> TlsTestServer {
+    let listener = TcpListener::bind("127.0.0.1:0").expect("bind h2c server");
+    listener.set_nonblocking(true).unwrap();
+    let url = format!("http://{}", listener.local_addr().unwrap());
+    let handler: Arc<dyn Fn(TestRequest) -> TestResponse + Send + Sync> = Arc::new(handler);
+    let (tx, rx) = mpsc::channel();
+    let join = thread::spawn(move || {
+        let runtime = tokio::runtime::Builder::new_current_thread()
+            .enable_all()
+            .build()
+            .unwrap();
+        loop {
+            if rx.try_recv().is_ok() {
+                break;
+            }
+            match listener.accept() {
+                Ok((stream, _)) => {
+                    let _ = stream.set_nonblocking(true);
+                    let handler = Arc::clone(&handler);
+                    runtime.block_on(async move {
+                        let Ok(stream) = tokio::net::TcpStream::from_std(stream) else {
+                            return;
+                        };
+                        serve_test_h2_connection(stream, handler).await;
+                    });
+                }
+                Err(err) if err.kind() == std::io::ErrorKind::WouldBlock => {
+                    thread::sleep(Duration::from_millis(5));
+                }
+                Err(_) => break,
+            }
+        }
+    });
+    TlsTestServer {
+        url,
+        ca_cert_path: PathBuf::new(),
+        shutdown: Some(tx),
+        join: Some(join),
+    }
+}
+
 async fn serve_test_h2_connection<T>(
     stream: T,
     handler: Arc<dyn Fn(TestRequest) -> TestResponse + Send + Sync>,
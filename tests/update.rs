@@ -215,6 +215,7 @@ fn auto_update_preserves_explicit_config_proxy() {
                     "FETCH_INTERNAL_SYNC_AUTO_UPDATE".to_string(),
                     "1".to_string(),
                 ),
+                ("NO_PROXY".to_string(), String::new()),
             ],
             ..Default::default()
         },
@@ -11,7 +11,7 @@ use std::thread;
 use std::time::{Duration, Instant};
 use support::common::{
     FetchOpts, FetchOutput, assert_exit, fetch_bin, run_fetch, run_fetch_once, run_fetch_opts,
-    start_read_capture, url_host_port, wait_child,
+    run_fetch_via_proxy, start_read_capture, url_host_port, wait_child,
 };
 use support::dns::{start_udp_dns_server, start_unresponsive_udp_dns_server};
 use support::http::{TestResponse, TestServer, read_request, write_response};
@@ -799,7 +799,7 @@ fn websocket_custom_dns_and_proxy_cases() {
     let (proxy_target_url, proxy_seen_ws) = start_ws_echo_server(|_| Ok(()));
     let proxy_target_addr = url_host_port(&proxy_target_url);
     let (proxy_url, proxy_seen) = start_http_connect_proxy(proxy_target_addr.clone());
-    let res = run_fetch(&[
+    let res = run_fetch_via_proxy(&[
         "--proxy",
         &proxy_url,
         &proxy_target_url,
@@ -821,7 +821,7 @@ fn websocket_custom_dns_and_proxy_cases() {
     let (socks_target_url, socks_seen_ws) = start_ws_echo_server(|_| Ok(()));
     let socks_target_addr = url_host_port(&socks_target_url);
     let (socks_url, socks_seen) = start_socks5_proxy(socks_target_addr.clone());
-    let res = run_fetch(&[
+    let res = run_fetch_via_proxy(&[
         "--proxy",
         &socks_url,
         &socks_target_url,
@@ -851,7 +851,7 @@ fn websocket_custom_dns_and_proxy_cases() {
     let socks_dns_target_addr = url_host_port(&socks_dns_target_url);
     let (socks_dns_proxy_url, socks_dns_seen) = start_socks5_proxy(socks_dns_target_addr.clone());
     let socks_dns_addr = start_udp_dns_server("ws-socks-dns.test.", Ipv4Addr::new(127, 0, 0, 1));
-    let res = run_fetch(&[
+    let res = run_fetch_via_proxy(&[
         "--dns-server",
         &socks_dns_addr,
         "--proxy",
@@ -875,7 +875,7 @@ fn websocket_custom_dns_and_proxy_cases() {
     assert!(res.stdout.contains("echo: socks dns websocket"));
 
     let socks_dns_proxy_url = socks_dns_proxy_url.replacen("socks5://", "socks5h://", 1);
-    let res = run_fetch(&[
+    let res = run_fetch_via_proxy(&[
         "--dns-server",
         &socks_dns_addr,
         "--proxy",
@@ -917,10 +917,10 @@ fn websocket_connect_timeout_covers_dns_and_proxy_handshakes() {
         "off",
     ]);
     assert_exit(&res, 1);
-    assert!(res.stderr.contains("request timed out after 50ms"));
+    assert!(res.stderr.contains("connect timed out after 50ms"));
 
     let http_proxy = start_stalling_proxy("http");
-    let res = run_fetch(&[
+    let res = run_fetch_via_proxy(&[
         "--proxy",
         &http_proxy,
         "--connect-timeout",
@@ -932,10 +932,10 @@ fn websocket_connect_timeout_covers_dns_and_proxy_handshakes() {
         "off",
     ]);
     assert_exit(&res, 1);
-    assert!(res.stderr.contains("request timed out after 50ms"));
+    assert!(res.stderr.contains("connect timed out after 50ms"));
 
     let socks_proxy = start_stalling_proxy("socks5");
-    let res = run_fetch(&[
+    let res = run_fetch_via_proxy(&[
         "--proxy",
         &socks_proxy,
         "--connect-timeout",
@@ -947,7 +947,7 @@ fn websocket_connect_timeout_covers_dns_and_proxy_handshakes() {
         "off",
     ]);
     assert_exit(&res, 1);
-    assert!(res.stderr.contains("request timed out after 50ms"));
+    assert!(res.stderr.contains("connect timed out after 50ms"));
 }
 
 #[test]